@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// Build information, set via -ldflags by goreleaser, the Nix flake and the Makefile
+var (
+	version = "dev"
+	commit  = "none"
+	date    = "unknown"
+	builtBy = "" // Build channel, e.g. goreleaser, nix, make
+)
+
+var aboutReport bool
+
+// AboutField is a single line of the about report
+type AboutField struct {
+	Name  string
+	Value string
+}
+
+// runAbout prints version information, or the setup report with --report.
+// Everything is gathered locally; nothing is sent anywhere.
+func runAbout(cmd *cobra.Command, args []string) {
+	if !aboutReport {
+		fmt.Printf("cctop %s\n", currentBuildInfo())
+		return
+	}
+
+	fmt.Println("cctop setup report (generated offline, contains no usage data, paths or secrets)")
+	fmt.Println()
+	for _, field := range append(environmentFields(), configFields(config)...) {
+		fmt.Printf("%-18s %s\n", field.Name+":", field.Value)
+	}
+}
+
+// environmentFields describes the build, platform, terminal and ccusage install
+func environmentFields() []AboutField {
+	ccusageVersion := "not installed"
+	if path, err := exec.LookPath("ccusage"); err == nil {
+		if output, err := exec.Command(path, "--version").Output(); err == nil {
+			ccusageVersion = strings.TrimSpace(string(output))
+		} else {
+			ccusageVersion = "installed, --version failed"
+		}
+	}
+
+	terminal := os.Getenv("TERM")
+	if terminal == "" {
+		terminal = "unknown"
+	}
+	if width := terminalWidth(); width > 0 {
+		terminal += fmt.Sprintf(", %d columns", width)
+	}
+	if color.NoColor {
+		terminal += ", no color"
+	}
+
+	return []AboutField{
+		{"cctop", currentBuildInfo().String()},
+		{"go", runtime.Version()},
+		{"platform", runtime.GOOS + "/" + runtime.GOARCH},
+		{"terminal", terminal},
+		{"ccusage", ccusageVersion},
+	}
+}
+
+// configFields summarizes the configuration without paths, URLs, tokens or commands
+func configFields(cfg *Config) []AboutField {
+	return []AboutField{
+		{"plan", cfg.Plan},
+		{"estimation", estimationMethod},
+		{"timezone", cfg.Timezone},
+		{"output", cfg.Output},
+		{"intervals", fmt.Sprintf("%s active, %s idle, %s daily", cfg.UpdateInterval, cfg.IdleInterval, cfg.DailyInterval)},
+		{"profiles", fmt.Sprintf("%d", len(cfg.ClaudeDirs))},
+		{"cache weight", fmt.Sprintf("%g", cfg.CacheWeight)},
+		{"currency", cfg.Currency},
+		{"log level", cfg.Log.Level},
+		{"system log", orNone(cfg.SystemLog)},
+		{"hooks", hookEventSummary(cfg.HookSpecs)},
+		{"time tracker", orNone(cfg.TimeTracker.Service)},
+		{"features", enabledFeatures(cfg)},
+	}
+}
+
+// hookEventSummary lists the events hooks are registered for, leaving out the commands
+func hookEventSummary(specs []string) string {
+	var events []string
+	for _, spec := range specs {
+		event, _, _ := strings.Cut(spec, "=")
+		events = append(events, event)
+	}
+	if len(events) == 0 {
+		return "none"
+	}
+	sort.Strings(events)
+	return strings.Join(events, ", ")
+}
+
+// enabledFeatures lists the optional features that are turned on
+func enabledFeatures(cfg *Config) string {
+	features := []struct {
+		name    string
+		enabled bool
+	}{
+		{"notify", cfg.Notify.Enabled},
+		{"break-reminder", cfg.Breaks.Enabled},
+		{"bell", cfg.Bell.Enabled},
+		{"model-bars", cfg.ModelBars},
+		{"model-weights", len(cfg.ModelWeightSpecs) > 0},
+		{"projects", cfg.ProjectsPanel},
+		{"typical", cfg.TypicalShape},
+		{"forecast", cfg.ForecastChart},
+		{"daily-bar", cfg.DailyBar},
+		{"token-limit", cfg.TokenLimit > 0},
+		{"soft-limit", cfg.SoftLimit > 0},
+		{"alert-thresholds", len(cfg.AlertSpecs) > 0},
+		{"team", len(cfg.TeamShareSpecs) > 0},
+		{"budget", cfg.Budget > 0},
+		{"focus", cfg.Focus.OnShortcut != ""},
+		{"issue-webhook", cfg.Issue.WebhookURL != ""},
+		{"webhook", cfg.ChatWebhook.URL != ""},
+	}
+
+	var names []string
+	for _, feature := range features {
+		if feature.enabled {
+			names = append(names, feature.name)
+		}
+	}
+	for _, name := range cfg.Experimental {
+		names = append(names, "experimental:"+name)
+	}
+	return orNone(strings.Join(names, ", "))
+}
+
+// orNone returns "none" for an empty value
+func orNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfigFieldsOmitSecrets(t *testing.T) {
+	cfg := NewConfig()
+	cfg.ClaudeDirs = []string{"work=/home/alice/.claude-work"}
+	cfg.HookSpecs = []string{"session_end=curl https://example.com/secret", "threshold_80=say hi"}
+	cfg.TimeTracker = TimeTrackerConfig{Service: "toggl", Token: "tok-123", Workspace: "42"}
+	cfg.Issue = IssueConfig{ID: "ABC-123", WebhookURL: "https://hooks.example.com/xyz"}
+	cfg.Log.File = "/home/alice/.local/state/cctop/cctop.log"
+
+	var report []string
+	for _, field := range configFields(cfg) {
+		report = append(report, field.Name+": "+field.Value)
+	}
+	joined := strings.Join(report, "\n")
+
+	for _, secret := range []string{"alice", "example.com", "tok-123", "say hi", "ABC-123"} {
+		if strings.Contains(joined, secret) {
+			t.Errorf("configFields leaked %q:\n%s", secret, joined)
+		}
+	}
+	for _, expected := range []string{"profiles: 1", "hooks: session_end, threshold_80", "time tracker: toggl", "issue-webhook"} {
+		if !strings.Contains(joined, expected) {
+			t.Errorf("configFields missing %q:\n%s", expected, joined)
+		}
+	}
+}
+
+func TestEnabledFeatures(t *testing.T) {
+	cfg := NewConfig()
+	if got := enabledFeatures(cfg); got != "none" {
+		t.Errorf("enabledFeatures(defaults) = %q, expected %q", got, "none")
+	}
+
+	cfg.Notify.Enabled = true
+	cfg.DailyBar = true
+	if got := enabledFeatures(cfg); got != "notify, daily-bar" {
+		t.Errorf("enabledFeatures() = %q, expected %q", got, "notify, daily-bar")
+	}
+
+	cfg.Experimental = []string{ExperimentForecastV2}
+	if got := enabledFeatures(cfg); got != "notify, daily-bar, experimental:forecast-v2" {
+		t.Errorf("enabledFeatures() = %q, expected %q", got, "notify, daily-bar, experimental:forecast-v2")
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// lastActiveConflict is the conflict logged last, so a lasting conflict is logged once
+var lastActiveConflict string
+
+// findActiveBlock returns the active block. When ccusage reports several, e.g. after a
+// clock change or corrupted logs, the one that started last is picked.
+func findActiveBlock(blocks []Block) *Block {
+	var active *Block
+	for i := range blocks {
+		if blocks[i].IsActive && (active == nil || blocks[i].StartTime > active.StartTime) {
+			active = &blocks[i]
+		}
+	}
+	return active
+}
+
+// conflictingActiveBlocks returns the blocks besides picked that ccusage reported as active,
+// oldest first, and logs a new conflict
+func conflictingActiveBlocks(blocks []Block, picked *Block) []Block {
+	var others []Block
+	for i := range blocks {
+		if blocks[i].IsActive && &blocks[i] != picked {
+			others = append(others, blocks[i])
+		}
+	}
+	sort.Slice(others, func(i, j int) bool { return others[i].StartTime < others[j].StartTime })
+
+	conflict := ""
+	if len(others) > 0 {
+		conflict = fmt.Sprintf("%s over %s", picked.StartTime, blockStarts(others, time.UTC))
+	}
+	if conflict != lastActiveConflict && conflict != "" {
+		logger.Warnf("ccusage reported %d active blocks, using the one started %s", len(others)+1, conflict)
+	}
+	lastActiveConflict = conflict
+	return others
+}
+
+// blockStarts lists the start times of blocks
+func blockStarts(blocks []Block, loc *time.Location) string {
+	starts := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		start, err := time.Parse(time.RFC3339, block.StartTime)
+		if err != nil {
+			starts = append(starts, block.StartTime)
+			continue
+		}
+		starts = append(starts, start.In(loc).Format("01-02 15:04"))
+	}
+	return strings.Join(starts, ", ")
+}
+
+// checkActiveBlocks verifies ccusage reports at most one active block
+func checkActiveBlocks(blocks []Block, loc *time.Location) DoctorCheck {
+	check := DoctorCheck{Name: "active blocks", OK: true}
+	var active []Block
+	for _, block := range blocks {
+		if block.IsActive {
+			active = append(active, block)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].StartTime < active[j].StartTime })
+
+	switch len(active) {
+	case 0:
+		check.Detail = "none"
+	case 1:
+		check.Detail = "one, started " + blockStarts(active, loc)
+	default:
+		check.OK = false
+		check.Detail = fmt.Sprintf("%d overlapping blocks started %s; cctop shows the latest", len(active), blockStarts(active, loc))
+		check.Fix = "check the system clock and timezone, then look for JSONL lines with future timestamps in the Claude logs; run `ccusage blocks --active` to compare"
+	}
+	return check
+}
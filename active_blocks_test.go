@@ -0,0 +1,75 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFindActiveBlock(t *testing.T) {
+	tests := []struct {
+		name     string
+		blocks   []Block
+		expected string
+	}{
+		{"none", []Block{{ID: "a", StartTime: "2025-01-01T09:00:00Z"}}, ""},
+		{"single", []Block{{ID: "a", StartTime: "2025-01-01T09:00:00Z", IsActive: true}}, "a"},
+		{"latest start wins", []Block{
+			{ID: "a", StartTime: "2025-01-01T11:00:00Z", IsActive: true},
+			{ID: "b", StartTime: "2025-01-01T09:00:00Z", IsActive: true},
+			{ID: "c", StartTime: "2025-01-01T12:00:00Z"},
+		}, "a"},
+	}
+
+	for _, tt := range tests {
+		got := ""
+		if block := findActiveBlock(tt.blocks); block != nil {
+			got = block.ID
+		}
+		if got != tt.expected {
+			t.Errorf("%s: findActiveBlock() = %q, expected %q", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestConflictingActiveBlocks(t *testing.T) {
+	blocks := []Block{
+		{ID: "a", StartTime: "2025-01-01T11:00:00Z", IsActive: true},
+		{ID: "b", StartTime: "2025-01-01T09:00:00Z", IsActive: true},
+		{ID: "c", StartTime: "2025-01-01T06:00:00Z"},
+	}
+	others := conflictingActiveBlocks(blocks, findActiveBlock(blocks))
+	if len(others) != 1 || others[0].ID != "b" {
+		t.Errorf("conflictingActiveBlocks() = %v, expected only block b", others)
+	}
+
+	single := blocks[:1]
+	if others := conflictingActiveBlocks(single, findActiveBlock(single)); len(others) != 0 {
+		t.Errorf("conflictingActiveBlocks() with one active block = %v, expected none", others)
+	}
+}
+
+func TestCheckActiveBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		blocks   []Block
+		expected bool
+	}{
+		{"none", nil, true},
+		{"single", []Block{{StartTime: "2025-01-01T09:00:00Z", IsActive: true}}, true},
+		{"overlapping", []Block{
+			{StartTime: "2025-01-01T11:00:00Z", IsActive: true},
+			{StartTime: "2025-01-01T09:00:00Z", IsActive: true},
+		}, false},
+	}
+
+	for _, tt := range tests {
+		check := checkActiveBlocks(tt.blocks, time.UTC)
+		if check.OK != tt.expected {
+			t.Errorf("%s: checkActiveBlocks().OK = %v, expected %v", tt.name, check.OK, tt.expected)
+		}
+		if !check.OK && (check.Fix == "" || !strings.Contains(check.Detail, "01-01 09:00, 01-01 11:00")) {
+			t.Errorf("%s: checkActiveBlocks() = %+v, expected sorted starts and a fix", tt.name, check)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import "time"
+
+// Interval is a span of time
+type Interval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Duration returns the interval's length
+func (i Interval) Duration() time.Duration {
+	return i.End.Sub(i.Start)
+}
+
+// idleIntervals returns the stretches of [start, now] with no message for longer
+// than gap, including the time before the first and after the last message.
+// times must be sorted. Without any messages nothing is known, so nothing is idle.
+func idleIntervals(times []time.Time, start, now time.Time, gap time.Duration) []Interval {
+	if len(times) == 0 {
+		return nil
+	}
+
+	var idle []Interval
+	previous := start
+	for _, t := range times {
+		if !t.After(previous) {
+			continue
+		}
+		if t.After(now) {
+			break
+		}
+		if t.Sub(previous) > gap {
+			idle = append(idle, Interval{Start: previous, End: t})
+		}
+		previous = t
+	}
+	if now.Sub(previous) > gap {
+		idle = append(idle, Interval{Start: previous, End: now})
+	}
+	return idle
+}
+
+// idleOverlap returns how much of [start, end] falls within the idle intervals
+func idleOverlap(idle []Interval, start, end time.Time) time.Duration {
+	var total time.Duration
+	for _, interval := range idle {
+		overlapStart := maxTime(interval.Start, start)
+		overlapEnd := minTime(interval.End, end)
+		if overlapEnd.After(overlapStart) {
+			total += overlapEnd.Sub(overlapStart)
+		}
+	}
+	return total
+}
+
+// loadTimedTokens reads the block's messages with their tokens from the JSONL logs
+func loadTimedTokens(block *Block, currentTime time.Time) []TimedTokens {
+	endTime := block.ActualEndTime
+	if endTime == "" || block.IsActive {
+		endTime = currentTime.Format(time.RFC3339)
+	}
+	timed, err := NewMessageTokenReader().GetBlockTimedTokens(block.StartTime, endTime)
+	if err != nil {
+		return nil
+	}
+	return timed
+}
+
+// messageTimes returns the timestamps of messages
+func messageTimes(timed []TimedTokens) []time.Time {
+	times := make([]time.Time, 0, len(timed))
+	for _, message := range timed {
+		times = append(times, message.Time)
+	}
+	return times
+}
+
+// ActivityMetrics compares the time spent working with the wall time elapsed in a session
+type ActivityMetrics struct {
+	ActiveMinutes  float64 `json:"activeMinutes"`
+	ElapsedMinutes float64 `json:"elapsedMinutes"`
+	Percentage     float64 `json:"percentage"`
+}
+
+// calculateActivity counts the time between start and end not spent idle as active.
+// Messages closer together than gap form one stretch of activity.
+func calculateActivity(times []time.Time, start, end time.Time, gap time.Duration) ActivityMetrics {
+	elapsed := end.Sub(start)
+	if elapsed <= 0 {
+		return ActivityMetrics{}
+	}
+	active := elapsed
+	for _, interval := range idleIntervals(times, start, end, gap) {
+		active -= interval.Duration()
+	}
+
+	return ActivityMetrics{
+		ActiveMinutes:  active.Minutes(),
+		ElapsedMinutes: elapsed.Minutes(),
+		Percentage:     float64(active) / float64(elapsed) * 100,
+	}
+}
+
+// Activity returns the session's active time up to currentTime, or nil without message times
+func (s *Session) Activity(currentTime time.Time) *ActivityMetrics {
+	if len(s.MessageTimes) == 0 {
+		return nil
+	}
+	activity := calculateActivity(s.MessageTimes, s.StartTime, minTime(currentTime, s.EndTime), IdleThreshold)
+	return &activity
+}
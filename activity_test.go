@@ -0,0 +1,137 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+func TestIdleIntervals(t *testing.T) {
+	start := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+
+	tests := []struct {
+		name     string
+		times    []time.Time
+		now      time.Time
+		expected []Interval
+	}{
+		{"no messages", nil, at(60), nil},
+		{"continuous", []time.Time{at(1), at(4), at(8), at(12)}, at(15), nil},
+		{"gap between messages", []time.Time{at(1), at(30), at(33)}, at(35), []Interval{{at(1), at(30)}}},
+		{"late first message", []time.Time{at(20), at(22)}, at(25), []Interval{{start, at(20)}}},
+		{"idle since last message", []time.Time{at(1), at(3)}, at(60), []Interval{{at(3), at(60)}}},
+		{"messages after now ignored", []time.Time{at(1), at(90)}, at(4), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := idleIntervals(tt.times, start, tt.now, IdleThreshold)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("idleIntervals() = %v, expected %v", got, tt.expected)
+			}
+			for i := range got {
+				if !got[i].Start.Equal(tt.expected[i].Start) || !got[i].End.Equal(tt.expected[i].End) {
+					t.Errorf("idleIntervals()[%d] = %v, expected %v", i, got[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIdleTimeBarDimsIdleCells(t *testing.T) {
+	oldNoColor := color.NoColor
+	defer func() { color.NoColor = oldNoColor }()
+	color.NoColor = false
+
+	start := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+	display := NewDisplay("UTC")
+	// 60 of 300 minutes elapsed (10 cells), idle from minute 12 to 48 (cells 2-7)
+	idle := []Interval{{start.Add(12 * time.Minute), start.Add(48 * time.Minute)}}
+	bar := display.createIdleTimeBar(20, idle, start)
+
+	dimmed := strings.Count(bar, "\x1b[90m|")
+	active := strings.Count(bar, "\x1b[34m|")
+	if dimmed != 6 {
+		t.Errorf("dimmed cells = %d, expected 6", dimmed)
+	}
+	if active != 4 {
+		t.Errorf("active cells = %d, expected 4", active)
+	}
+	if plain := string(stripANSI([]byte(bar))); plain != "["+strings.Repeat("|", 10)+strings.Repeat(" ", 40)+"]" {
+		t.Errorf("bar = %q, expected 10 filled cells", plain)
+	}
+}
+
+func TestSessionIdleFollowsIdleSegments(t *testing.T) {
+	oldConfig := config
+	defer func() { config = oldConfig }()
+	config = NewConfig()
+
+	start := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+	// Message times are loaded for --active-time even with idle segments off
+	session := &Session{StartTime: start, MessageTimes: []time.Time{start.Add(time.Minute), start.Add(40 * time.Minute)}}
+	d := NewDisplay("UTC")
+	d.config = &DisplayConfig{CurrentTime: start.Add(time.Hour)}
+
+	if idle := d.sessionIdle(session); len(idle) == 0 {
+		t.Error("sessionIdle() = none, expected idle stretches with --idle-segments")
+	}
+	config.IdleSegments = false
+	if idle := d.sessionIdle(session); idle != nil {
+		t.Errorf("sessionIdle() = %v, expected none without --idle-segments", idle)
+	}
+}
+
+func TestCalculateActivity(t *testing.T) {
+	start := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+	every := func(from, to int) []time.Time {
+		var times []time.Time
+		for m := from; m <= to; m += 4 {
+			times = append(times, at(m))
+		}
+		return append(times, at(to))
+	}
+
+	tests := []struct {
+		name           string
+		times          []time.Time
+		end            time.Time
+		expectedActive float64
+		expectedPct    float64
+	}{
+		{"no time elapsed", []time.Time{start}, start, 0, 0},
+		{"steady work", []time.Time{at(0), at(4), at(8), at(12)}, at(12), 12, 100},
+		// Active 0-60, idle 60-150, active 150-190
+		{"long break", append(every(0, 60), every(150, 190)...), at(190), 100, 100.0 * 100 / 190},
+		{"idle until end", every(0, 30), at(120), 30, 25},
+		{"sparse messages", []time.Time{at(0), at(30), at(60)}, at(60), 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calculateActivity(tt.times, start, tt.end, IdleThreshold)
+			if got.ActiveMinutes != tt.expectedActive {
+				t.Errorf("ActiveMinutes = %v, expected %v", got.ActiveMinutes, tt.expectedActive)
+			}
+			if math.Abs(got.Percentage-tt.expectedPct) > 0.01 {
+				t.Errorf("Percentage = %v, expected %v", got.Percentage, tt.expectedPct)
+			}
+		})
+	}
+}
+
+func TestRenderActivity(t *testing.T) {
+	display := NewDisplay("UTC")
+	var buffer strings.Builder
+	display.renderActivity(&buffer, ActivityMetrics{ActiveMinutes: 102, ElapsedMinutes: 190, Percentage: 53.7})
+
+	expected := "Active  1h 42m of 3h 10m elapsed (54%)\n"
+	if got := string(stripANSI([]byte(buffer.String()))); got != expected {
+		t.Errorf("renderActivity() = %q, expected %q", got, expected)
+	}
+}
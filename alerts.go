@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AlertDefaultPlan is the --alert-thresholds key used by plans without their own entry
+const AlertDefaultPlan = "default"
+
+// AlertThreshold is a usage level, either a percentage of the limit or absolute tokens
+type AlertThreshold struct {
+	Value  float64
+	Tokens bool // Value is a token count rather than a percentage
+}
+
+// Percentage returns the threshold as a percentage of a token limit
+func (t AlertThreshold) Percentage(limit int) float64 {
+	if !t.Tokens {
+		return t.Value
+	}
+	if limit <= 0 {
+		return 100
+	}
+	return t.Value * 100 / float64(limit)
+}
+
+// String formats the threshold like it is configured, e.g. 80% or 25,000
+func (t AlertThreshold) String() string {
+	if t.Tokens {
+		return formatNumber(int(t.Value))
+	}
+	return strconv.FormatFloat(t.Value, 'f', -1, 64) + "%"
+}
+
+// AlertThresholds are the warning and critical levels configured for a plan
+type AlertThresholds struct {
+	Warning  AlertThreshold
+	Critical AlertThreshold
+}
+
+// AlertLevels are alert thresholds resolved to percentages of the session limit
+type AlertLevels struct {
+	Warning  float64 `json:"warning"`  // Token bars turn yellow and the status becomes WARNING from here
+	Critical float64 `json:"critical"` // Token bars turn red and the status becomes CRITICAL from here
+}
+
+// Color returns the color name of a token usage percentage
+func (l AlertLevels) Color(percentage float64) string {
+	switch {
+	case percentage < l.Warning:
+		return "green"
+	case percentage < l.Critical:
+		return "yellow"
+	default:
+		return "red"
+	}
+}
+
+// parseAlertThreshold parses 80% as a percentage of the limit or 25000 as absolute tokens
+func parseAlertThreshold(value string) (AlertThreshold, error) {
+	number, percent := strings.CutSuffix(strings.TrimSpace(value), "%")
+	parsed, err := strconv.ParseFloat(number, 64)
+	if err != nil || parsed <= 0 || (percent && parsed > 100) {
+		return AlertThreshold{}, fmt.Errorf("invalid alert threshold %q, expected a percentage like 80%% or a token count", value)
+	}
+	return AlertThreshold{Value: parsed, Tokens: !percent}, nil
+}
+
+// parseAlertThresholds parses --alert-thresholds values (plan=warning/critical), e.g.
+// max5=70%/90% or pro=5000/6500. The default key applies to plans without an entry.
+func parseAlertThresholds(specs map[string]string) (map[string]AlertThresholds, error) {
+	thresholds := make(map[string]AlertThresholds, len(specs))
+	for plan, value := range specs {
+		plan = strings.ToLower(plan)
+		switch plan {
+		case "pro", "max5", "max20", PlanAPI, AlertDefaultPlan:
+		default:
+			return nil, fmt.Errorf("invalid alert thresholds plan %q, expected pro, max5, max20, %s or %s", plan, PlanAPI, AlertDefaultPlan)
+		}
+		warningSpec, criticalSpec, ok := strings.Cut(value, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid alert thresholds %s=%s, expected warning/critical, e.g. 70%%/90%%", plan, value)
+		}
+		warning, err := parseAlertThreshold(warningSpec)
+		if err != nil {
+			return nil, err
+		}
+		critical, err := parseAlertThreshold(criticalSpec)
+		if err != nil {
+			return nil, err
+		}
+		if plan == PlanAPI && (warning.Tokens || critical.Tokens) {
+			return nil, fmt.Errorf("alert thresholds %s=%s: the %s plan alerts on spend, use percentages of --budget", plan, value, PlanAPI)
+		}
+		if warning.Tokens == critical.Tokens && warning.Value >= critical.Value {
+			return nil, fmt.Errorf("alert thresholds %s=%s: warning must be below critical", plan, value)
+		}
+		thresholds[plan] = AlertThresholds{Warning: warning, Critical: critical}
+	}
+	return thresholds, nil
+}
+
+// validAlertThresholds checks a config file value of alert-thresholds
+func validAlertThresholds(value string) error {
+	specs := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		plan, spec, _ := strings.Cut(pair, "=")
+		specs[plan] = spec
+	}
+	_, err := parseAlertThresholds(specs)
+	return err
+}
+
+// CustomAlertLevels returns the --alert-thresholds of a plan resolved against its limit,
+// and false when none are configured
+func (c *Config) CustomAlertLevels(plan string, limit int) (AlertLevels, bool) {
+	thresholds, ok := c.planAlertThresholds(plan)
+	if !ok {
+		return AlertLevels{}, false
+	}
+	return AlertLevels{
+		Warning:  thresholds.Warning.Percentage(limit),
+		Critical: thresholds.Critical.Percentage(limit),
+	}, true
+}
+
+// PercentAlertLevels returns the --alert-thresholds of a plan for usage measured against
+// something other than the session limit, like the weekly limit or the API budget.
+// Token counts only apply to the session limit, so they report false like no thresholds.
+func (c *Config) PercentAlertLevels(plan string) (AlertLevels, bool) {
+	thresholds, ok := c.planAlertThresholds(plan)
+	if !ok || thresholds.Warning.Tokens || thresholds.Critical.Tokens {
+		return AlertLevels{}, false
+	}
+	return AlertLevels{Warning: thresholds.Warning.Value, Critical: thresholds.Critical.Value}, true
+}
+
+// planAlertThresholds returns the thresholds of a plan, or of the default key
+func (c *Config) planAlertThresholds(plan string) (AlertThresholds, bool) {
+	thresholds, ok := c.AlertThresholds[plan]
+	if !ok {
+		thresholds, ok = c.AlertThresholds[AlertDefaultPlan]
+	}
+	return thresholds, ok
+}
+
+// AlertLevels returns the alert levels of a plan, the bar color thresholds when none are configured
+func (c *Config) AlertLevels(plan string, limit int) AlertLevels {
+	if levels, ok := c.CustomAlertLevels(plan, limit); ok {
+		return levels
+	}
+	return c.barAlertLevels()
+}
+
+// barAlertLevels returns the token bar color thresholds
+func (c *Config) barAlertLevels() AlertLevels {
+	return AlertLevels{Warning: c.ProgressBar.TokenColorLow, Critical: c.ProgressBar.TokenColorMedium}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAlertThresholds(t *testing.T) {
+	tests := []struct {
+		name    string
+		specs   map[string]string
+		want    map[string]AlertThresholds
+		wantErr bool
+	}{
+		{
+			name:  "percentages",
+			specs: map[string]string{"max5": "70%/90%"},
+			want:  map[string]AlertThresholds{"max5": {Warning: AlertThreshold{Value: 70}, Critical: AlertThreshold{Value: 90}}},
+		},
+		{
+			name:  "tokens and mixed",
+			specs: map[string]string{"Pro": "5000/95%"},
+			want:  map[string]AlertThresholds{"pro": {Warning: AlertThreshold{Value: 5000, Tokens: true}, Critical: AlertThreshold{Value: 95}}},
+		},
+		{name: "unknown plan", specs: map[string]string{"team": "70%/90%"}, wantErr: true},
+		{name: "one level", specs: map[string]string{"max5": "70%"}, wantErr: true},
+		{name: "warning above critical", specs: map[string]string{"max5": "90%/70%"}, wantErr: true},
+		{name: "over 100%", specs: map[string]string{"default": "70%/120%"}, wantErr: true},
+		{name: "not a number", specs: map[string]string{"default": "lots/more"}, wantErr: true},
+		{name: "api tokens", specs: map[string]string{"api": "5000/90%"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAlertThresholds(tt.specs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAlertThresholds() error = %v, expected error %v", err, tt.wantErr)
+			}
+			for plan, want := range tt.want {
+				if got[plan] != want {
+					t.Errorf("parseAlertThresholds()[%s] = %+v, expected %+v", plan, got[plan], want)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigAlertLevels(t *testing.T) {
+	cfg := NewConfig()
+	cfg.AlertThresholds = map[string]AlertThresholds{
+		"pro":            {Warning: AlertThreshold{Value: 3500, Tokens: true}, Critical: AlertThreshold{Value: 6300, Tokens: true}},
+		AlertDefaultPlan: {Warning: AlertThreshold{Value: 70}, Critical: AlertThreshold{Value: 95}},
+	}
+
+	tests := []struct {
+		plan string
+		want AlertLevels
+	}{
+		{"pro", AlertLevels{Warning: 50, Critical: 90}},
+		{"max5", AlertLevels{Warning: 70, Critical: 95}},
+	}
+	for _, tt := range tests {
+		if got := cfg.AlertLevels(tt.plan, 7000); got != tt.want {
+			t.Errorf("AlertLevels(%q) = %+v, expected %+v", tt.plan, got, tt.want)
+		}
+	}
+
+	cfg.AlertThresholds = nil
+	if _, ok := cfg.CustomAlertLevels("max5", 35000); ok {
+		t.Errorf("CustomAlertLevels() without thresholds reported configured levels")
+	}
+	if got, want := cfg.AlertLevels("max5", 35000), (AlertLevels{Warning: 60, Critical: 80}); got != want {
+		t.Errorf("AlertLevels() without thresholds = %+v, expected the bar colors %+v", got, want)
+	}
+}
+
+func TestPercentAlertLevels(t *testing.T) {
+	cfg := NewConfig()
+	cfg.AlertThresholds = map[string]AlertThresholds{
+		"pro":            {Warning: AlertThreshold{Value: 3500, Tokens: true}, Critical: AlertThreshold{Value: 95}},
+		AlertDefaultPlan: {Warning: AlertThreshold{Value: 70}, Critical: AlertThreshold{Value: 90}},
+	}
+	if _, ok := cfg.PercentAlertLevels("pro"); ok {
+		t.Error("PercentAlertLevels(pro) reported levels for a token threshold")
+	}
+	if got, ok := cfg.PercentAlertLevels(PlanAPI); !ok || got != (AlertLevels{Warning: 70, Critical: 90}) {
+		t.Errorf("PercentAlertLevels(api) = %+v, %v, expected the default 70/90", got, ok)
+	}
+}
+
+func TestAlertLevelsColor(t *testing.T) {
+	levels := AlertLevels{Warning: 50, Critical: 90}
+	tests := []struct {
+		percentage float64
+		want       string
+	}{
+		{49.9, "green"},
+		{50, "yellow"},
+		{89, "yellow"},
+		{90, "red"},
+	}
+	for _, tt := range tests {
+		if got := levels.Color(tt.percentage); got != tt.want {
+			t.Errorf("Color(%v) = %q, expected %q", tt.percentage, got, tt.want)
+		}
+	}
+}
+
+func TestSessionStatusAlertLevels(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name   string
+		used   int
+		alerts *AlertLevels
+		want   string
+	}{
+		{"below warning", 4000, &AlertLevels{Warning: 50, Critical: 90}, "OK"},
+		{"warning", 6000, &AlertLevels{Warning: 50, Critical: 90}, "WARNING"},
+		{"critical", 9500, &AlertLevels{Warning: 50, Critical: 90}, "CRITICAL"},
+		{"exceeded", 10500, &AlertLevels{Warning: 50, Critical: 90}, "LIMIT EXCEEDED"},
+		{"unconfigured", 9500, nil, "OK"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := newTestSession(start, tt.used, 10000)
+			session.Alerts = tt.alerts
+			if got := session.GetStatus(); got != tt.want {
+				t.Errorf("GetStatus() = %q, expected %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifierAlertLevels(t *testing.T) {
+	var sent []string
+	n := newTestNotifier(&sent)
+	n.thresholds = nil
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	now := start.Add(time.Hour)
+	levels := &AlertLevels{Warning: 50, Critical: 90}
+
+	for _, used := range []int{4000, 6000, 7000, 9500} {
+		session := newTestSession(start, used, 10000)
+		session.Alerts = levels
+		n.Check(session, now)
+	}
+	if len(sent) != 2 {
+		t.Fatalf("sent %d notifications, expected warning and critical: %v", len(sent), sent)
+	}
+	if want := "Token usage passed the critical threshold (9,500/10,000)"; sent[1] != want {
+		t.Errorf("critical notification = %q, expected %q", sent[1], want)
+	}
+}
+
+func TestNotifierSpendAlertLevels(t *testing.T) {
+	oldConfig := config
+	defer func() { config = oldConfig }()
+	config = NewConfig()
+	config.Plan = PlanAPI
+
+	var sent []string
+	n := newTestNotifier(&sent)
+	n.thresholds = nil
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	for _, spent := range []float64{4, 6, 9.5} {
+		session := newTestSession(start, 1000, 10000)
+		session.NoLimit = true
+		session.Alerts = &AlertLevels{Warning: 50, Critical: 90}
+		session.Cost = CostMetrics{Spent: spent, Budget: 10, Percentage: spent * 10, Period: BudgetPeriodDay}
+		n.Check(session, start.Add(time.Hour))
+	}
+	if len(sent) != 2 || !strings.HasPrefix(sent[1], "Spend passed the critical threshold") {
+		t.Errorf("sent %q, expected the spend warning and critical alerts", sent)
+	}
+}
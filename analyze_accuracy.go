@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
-	"os/exec"
 )
 
 // AccuracyAnalysis analyzes the accuracy of token limit estimation
@@ -20,8 +19,7 @@ type AccuracyAnalysis struct {
 
 func analyzeEstimationAccuracy() {
 	// Fetch usage data
-	cmd := exec.Command("ccusage", "blocks", "--json")
-	output, err := cmd.Output()
+	output, err := runCCUsage("blocks", "--json")
 	if err != nil {
 		fmt.Println("Error fetching usage data:", err)
 		return
@@ -46,10 +44,46 @@ func analyzeEstimationAccuracy() {
 		printAnalysis(analysis)
 	}
 
+	// Compare estimation strategies side by side
+	analyzeStrategies(data.Blocks, estimator)
+
 	// Analyze token per message variance
 	analyzeTokenPerMessageVariance(data.Blocks)
 }
 
+// analyzeStrategies prints each built-in strategy's tokens/message and resulting limit
+// for the highest consuming session
+func analyzeStrategies(blocks []Block, estimator *TokenLimitEstimator) {
+	fmt.Println("Estimation Strategies")
+	fmt.Println("=====================")
+
+	reference := estimator.findMaxTokenSession(blocks)
+	if reference.block == nil || reference.block.Entries == 0 {
+		fmt.Println("No data available for analysis")
+		fmt.Println()
+		return
+	}
+	messageTokens, err := estimator.getMessageTokens(reference.block)
+	if err != nil || len(messageTokens) == 0 {
+		fmt.Println("No message data in the Claude logs for the highest session")
+		fmt.Println()
+		return
+	}
+
+	plan := estimator.detectPlanFromHistory(blocks)
+	messages := estimator.baseLimits[plan].Messages
+	fmt.Printf("Highest session: %s tokens over %d msgs, plan %s (%d msgs)\n\n",
+		formatNumber(reference.block.TotalTokens), reference.block.Entries, plan, messages)
+	fmt.Printf("%-8s  %-26s  %10s  %12s\n", "Method", "Description", "Tokens/msg", "Base limit")
+	for _, method := range BuiltinEstimators {
+		strategy, _ := ParseEstimator(method)
+		tokensPerMsg := strategy.TokensPerMessage(messageTokens, reference.block)
+		fmt.Printf("%-8s  %-26s  %10s  %12s\n", strategy.Name(), strategy.Description(),
+			formatNumber(tokensPerMsg), formatNumber(tokensPerMsg*messages))
+	}
+	fmt.Println()
+}
+
 func performAnalysis(plan string, blocks []Block, estimator *TokenLimitEstimator) AccuracyAnalysis {
 	// Get estimated limit
 	estimated := estimator.EstimateLimit(plan, blocks)
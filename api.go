@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// APIBurnRate is the /v1/burnrate response
+type APIBurnRate struct {
+	TokensPerMinute float64          `json:"tokensPerMinute"`
+	CostPerHour     float64          `json:"costPerHour"` // Raw USD
+	Recent          *BurnRates       `json:"recent,omitempty"`
+	Windows         []WindowBurnRate `json:"windows,omitempty"`
+	Band            *BurnBand        `json:"band,omitempty"`
+}
+
+// APIEstimate is the /v1/estimate response
+type APIEstimate struct {
+	Plan                 string     `json:"plan"`
+	Limit                int        `json:"limit"`
+	Method               string     `json:"method"`
+	ColdStart            bool       `json:"coldStart"`
+	Coarse               bool       `json:"coarse"`               // Tokens/message from entry counts, the JSONL logs being unavailable
+	LowerBound           int        `json:"lowerBound,omitempty"` // Only for cold-start estimates
+	UpperBound           int        `json:"upperBound,omitempty"`
+	Confidence           Confidence `json:"confidence"`
+	PredictedEnd         time.Time  `json:"predictedEnd"`
+	PredictedEndEarliest *time.Time `json:"predictedEndEarliest,omitempty"`
+	PredictedEndLatest   *time.Time `json:"predictedEndLatest,omitempty"`
+	ResetAt              time.Time  `json:"resetAt"`
+}
+
+// APIServer answers the /v1 JSON endpoints from the latest refresh of cctop serve.
+// Responses are built when the refresh completes, so handlers never touch the
+// estimator while the refresh loop updates it.
+type APIServer struct {
+	mu       sync.RWMutex
+	err      error
+	report   *StatusReport
+	burnRate APIBurnRate
+	estimate APIEstimate
+	recent   []int         // Tokens per sparkline bucket over the last hour
+	blocks   []Block       // Kept while idle, completed blocks stay valid
+	changed  chan struct{} // Closed and replaced by every Update, waking /v1/events streams
+}
+
+// NewAPIServer creates an API server with no data yet
+func NewAPIServer() *APIServer {
+	return &APIServer{err: errors.New("no refresh yet"), changed: make(chan struct{})}
+}
+
+// Update records the latest refresh result
+func (a *APIServer) Update(session *Session, err error, plan string, currentTime time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	defer a.notify()
+
+	a.err = err
+	var idle *NoActiveSessionError
+	if session == nil {
+		if errors.As(err, &idle) {
+			a.report = nil
+		}
+		return
+	}
+
+	report := NewStatusReport(session, plan, currentTime)
+	info := estimator.GetEstimationInfo()
+	a.report = &report
+	a.blocks = session.AllBlocks
+	a.recent = session.RecentUsage
+	a.burnRate = APIBurnRate{
+		TokensPerMinute: session.BurnRate,
+		CostPerHour:     session.CostBurnRate,
+		Recent:          session.RecentRates,
+		Windows:         session.WindowRates,
+		Band:            session.BurnBand,
+	}
+	a.estimate = APIEstimate{
+		Plan:                 report.Plan,
+		Limit:                session.Metrics.Tokens.Limit,
+		Method:               info.Method,
+		ColdStart:            info.ColdStart,
+		Coarse:               info.Coarse,
+		LowerBound:           info.LowerBound,
+		UpperBound:           info.UpperBound,
+		Confidence:           report.Confidence,
+		PredictedEnd:         report.PredictedEnd,
+		PredictedEndEarliest: report.PredictedMin,
+		PredictedEndLatest:   report.PredictedMax,
+		ResetAt:              session.EndTime,
+	}
+}
+
+// notify wakes the event streams waiting for an update, called with the lock held
+func (a *APIServer) notify() {
+	close(a.changed)
+	a.changed = make(chan struct{})
+}
+
+// Handler returns the mux serving the /v1 endpoints
+func (a *APIServer) Handler() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/session", a.serveSession)
+	mux.HandleFunc("GET /v1/blocks", a.serveBlocks)
+	mux.HandleFunc("GET /v1/burnrate", a.serveBurnRate)
+	mux.HandleFunc("GET /v1/estimate", a.serveEstimate)
+	mux.HandleFunc("GET /v1/events", a.serveEvents)
+	return mux
+}
+
+// serveSession writes the status report of the active session
+func (a *APIServer) serveSession(w http.ResponseWriter, r *http.Request) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.sessionError(w) {
+		return
+	}
+	writeJSON(w, http.StatusOK, a.report)
+}
+
+// serveBlocks writes the session blocks, the last ones only with ?limit=N
+func (a *APIServer) serveBlocks(w http.ResponseWriter, r *http.Request) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.blocks == nil {
+		writeAPIError(w, http.StatusServiceUnavailable, a.err)
+		return
+	}
+	blocks := a.blocks
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			writeAPIError(w, http.StatusBadRequest, errors.New("limit must be a non-negative integer"))
+			return
+		}
+		blocks = blocks[max(len(blocks)-limit, 0):]
+	}
+	writeJSON(w, http.StatusOK, map[string][]Block{"blocks": blocks})
+}
+
+// serveBurnRate writes the burn rates of the active session
+func (a *APIServer) serveBurnRate(w http.ResponseWriter, r *http.Request) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.sessionError(w) {
+		return
+	}
+	writeJSON(w, http.StatusOK, a.burnRate)
+}
+
+// serveEstimate writes the limit estimate and predicted depletion of the active session
+func (a *APIServer) serveEstimate(w http.ResponseWriter, r *http.Request) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.sessionError(w) {
+		return
+	}
+	writeJSON(w, http.StatusOK, a.estimate)
+}
+
+// sessionError writes 404 while idle and 503 before the first successful refresh,
+// and reports whether it did. A failed refresh after a successful one keeps serving
+// the last data, like the metrics.
+func (a *APIServer) sessionError(w http.ResponseWriter) bool {
+	var idle *NoActiveSessionError
+	switch {
+	case errors.As(a.err, &idle):
+		writeAPIError(w, http.StatusNotFound, a.err)
+	case a.report == nil:
+		writeAPIError(w, http.StatusServiceUnavailable, a.err)
+	default:
+		return false
+	}
+	return true
+}
+
+// writeAPIError writes {"error": "..."} with the status code
+func writeAPIError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// writeJSON writes value as indented JSON
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(data, '\n'))
+}
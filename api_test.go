@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// getAPI requests path from the API server and decodes the JSON response
+func getAPI(t *testing.T, api *APIServer, path string, into any) int {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	api.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", path, nil))
+	if into != nil {
+		if err := json.Unmarshal(recorder.Body.Bytes(), into); err != nil {
+			t.Fatalf("GET %s returned invalid JSON: %v\n%s", path, err, recorder.Body.String())
+		}
+	}
+	return recorder.Code
+}
+
+func TestAPIServer(t *testing.T) {
+	oldConfig, oldCurrency, oldEstimator := config, currency, estimator
+	defer func() { config, currency, estimator = oldConfig, oldCurrency, oldEstimator }()
+	config = NewConfig()
+	currency = NewCurrencyConverter("USD", 0)
+	estimator = NewTokenLimitEstimator()
+
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	now := start.Add(time.Hour)
+	session := newTestSession(start, 3500, 7000)
+	session.BurnRate = 12.5
+	session.CostBurnRate = 1.5
+	session.AllBlocks = []Block{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	session.Metrics.Time = session.calculateTimeMetrics(now)
+
+	api := NewAPIServer()
+	var apiError map[string]string
+	if code := getAPI(t, api, "/v1/session", &apiError); code != http.StatusServiceUnavailable || apiError["error"] == "" {
+		t.Errorf("GET /v1/session before the first refresh = %d %v, expected 503 with an error", code, apiError)
+	}
+
+	api.Update(session, nil, "pro", now)
+
+	var report StatusReport
+	if code := getAPI(t, api, "/v1/session", &report); code != http.StatusOK || report.Tokens.Used != 3500 || report.Tokens.Limit != 7000 {
+		t.Errorf("GET /v1/session = %d %+v, expected 3500/7000 tokens", code, report.Tokens)
+	}
+
+	var burnRate APIBurnRate
+	if code := getAPI(t, api, "/v1/burnrate", &burnRate); code != http.StatusOK || burnRate.TokensPerMinute != 12.5 || burnRate.CostPerHour != 1.5 {
+		t.Errorf("GET /v1/burnrate = %d %+v, expected 12.5 tokens/min and $1.5/h", code, burnRate)
+	}
+
+	var estimate APIEstimate
+	if code := getAPI(t, api, "/v1/estimate", &estimate); code != http.StatusOK || estimate.Limit != 7000 || !estimate.ResetAt.Equal(session.EndTime) {
+		t.Errorf("GET /v1/estimate = %d %+v, expected limit 7000 resetting at %v", code, estimate, session.EndTime)
+	}
+
+	var blocks map[string][]Block
+	if code := getAPI(t, api, "/v1/blocks?limit=2", &blocks); code != http.StatusOK || len(blocks["blocks"]) != 2 || blocks["blocks"][0].ID != "b" {
+		t.Errorf("GET /v1/blocks?limit=2 = %d %v, expected blocks b and c", code, blocks)
+	}
+	if code := getAPI(t, api, "/v1/blocks?limit=x", nil); code != http.StatusBadRequest {
+		t.Errorf("GET /v1/blocks?limit=x = %d, expected 400", code)
+	}
+
+	// A failed refresh keeps serving the last data
+	api.Update(nil, errors.New("ccusage failed"), "pro", now)
+	if code := getAPI(t, api, "/v1/session", &report); code != http.StatusOK {
+		t.Errorf("GET /v1/session after a failed refresh = %d, expected 200", code)
+	}
+
+	// Idle: no session, but the blocks stay available
+	api.Update(nil, &NoActiveSessionError{}, "pro", now)
+	if code := getAPI(t, api, "/v1/session", &apiError); code != http.StatusNotFound {
+		t.Errorf("GET /v1/session while idle = %d, expected 404", code)
+	}
+	if code := getAPI(t, api, "/v1/blocks", &blocks); code != http.StatusOK || len(blocks["blocks"]) != 3 {
+		t.Errorf("GET /v1/blocks while idle = %d %v, expected all 3 blocks", code, blocks)
+	}
+}
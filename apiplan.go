@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PlanAPI is the pay-as-you-go API plan: no token limit, spend is tracked against --budget
+const PlanAPI = "api"
+
+// APIPlan reports whether usage is billed per token rather than capped per session
+func (c *Config) APIPlan() bool {
+	return c.Plan == PlanAPI
+}
+
+// TracksSpend reports whether spend against a budget replaces the token limit
+func (s *Session) TracksSpend() bool {
+	return config.APIPlan() && s.Cost.Budget > 0
+}
+
+// budgetEndTime returns when the spend reaches the budget at the current cost burn rate,
+// the zero time if it never does
+func (s *Session) budgetEndTime(currentTime time.Time) time.Time {
+	cost := s.Cost
+	if cost.Spent >= cost.Budget {
+		return currentTime
+	}
+	if s.CostBurnRate <= 0 {
+		return time.Time{}
+	}
+	hours := (cost.Budget - cost.Spent) / s.CostBurnRate
+	return currentTime.Add(time.Duration(hours * float64(time.Hour)))
+}
+
+// budgetRunsOut reports whether the budget is spent before its period ends
+func (s *Session) budgetRunsOut(currentTime time.Time) bool {
+	end := s.budgetEndTime(currentTime)
+	return !end.IsZero() && end.Before(budgetPeriodEnd(s.Cost.Period, currentTime, s.EndTime))
+}
+
+// spendStatus returns the status of spend against the budget, like GetStatus for tokens
+func (s *Session) spendStatus(currentTime time.Time) string {
+	cost := s.Cost
+	if cost.Spent > cost.Budget {
+		return "LIMIT EXCEEDED"
+	}
+	if s.Alerts != nil && cost.Percentage >= s.Alerts.Critical {
+		return "CRITICAL"
+	}
+	if s.budgetRunsOut(currentTime) {
+		return "WARNING"
+	}
+	if s.Alerts != nil && cost.Percentage >= s.Alerts.Warning {
+		return "WARNING"
+	}
+	return "OK"
+}
+
+// spendReason explains the spend status
+func (s *Session) spendReason(currentTime time.Time) Message {
+	cost := s.Cost
+	switch status := s.statusAt(currentTime); {
+	case status == "LIMIT EXCEEDED":
+		return newMessage("spent %s exceeded budget %s per %s", formatCost(cost.Spent), formatCost(cost.Budget), cost.Period)
+	case status == "WARNING" && s.budgetRunsOut(currentTime):
+		return newMessage("%s/h runs out of the %s budget at %s", formatCost(s.CostBurnRate), formatCost(cost.Budget),
+			s.budgetEndTime(currentTime).In(display.timezone).Format(TimeFormatShort))
+	default:
+		return newMessage("spent %s of budget %s per %s", formatCost(cost.Spent), formatCost(cost.Budget), cost.Period)
+	}
+}
+
+// spendMessage builds the notification text for a crossed budget threshold
+func spendMessage(threshold float64, cost CostMetrics) string {
+	if threshold >= 100 {
+		return tr("Budget exceeded (%s/%s per %s)", formatCost(cost.Spent), formatCost(cost.Budget), cost.Period)
+	}
+	return tr("Spend passed %.0f%% of the budget (%s/%s per %s)", threshold, formatCost(cost.Spent), formatCost(cost.Budget), cost.Period)
+}
+
+// renderSpendBar renders spend against the budget as the primary bar of the API plan
+func (d *Display) renderSpendBar(buffer *strings.Builder, session *Session) {
+	cost := session.Cost
+	d.writeBarLine(buffer, "Spend", d.createProgressBar(cost.Percentage, false, ""),
+		fmt.Sprintf("%.1f%% (%s/%s per %s, %s/h)", cost.Percentage, formatCost(cost.Spent), formatCost(cost.Budget), cost.Period, formatCost(session.CostBurnRate)))
+}
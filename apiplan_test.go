@@ -0,0 +1,118 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSpendStatus(t *testing.T) {
+	oldConfig := config
+	defer func() { config = oldConfig }()
+	config = NewConfig()
+	config.Plan = PlanAPI
+
+	start := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name     string
+		cost     CostMetrics
+		burn     float64 // USD per hour
+		alerts   *AlertLevels
+		expected string
+	}{
+		{"within budget", CostMetrics{Spent: 2, Budget: 10, Percentage: 20, Period: BudgetPeriodSession}, 1, nil, "OK"},
+		{"runs out before the reset", CostMetrics{Spent: 6, Budget: 10, Percentage: 60, Period: BudgetPeriodSession}, 2, nil, "WARNING"},
+		{"warning level", CostMetrics{Spent: 6, Budget: 10, Percentage: 60, Period: BudgetPeriodSession}, 0, &AlertLevels{Warning: 50, Critical: 90}, "WARNING"},
+		{"critical level", CostMetrics{Spent: 9.5, Budget: 10, Percentage: 95, Period: BudgetPeriodSession}, 0, &AlertLevels{Warning: 50, Critical: 90}, "CRITICAL"},
+		{"over budget", CostMetrics{Spent: 11, Budget: 10, Percentage: 110, Period: BudgetPeriodSession}, 0, nil, "LIMIT EXCEEDED"},
+		{"no budget", CostMetrics{Spent: 11, Period: BudgetPeriodDay}, 5, nil, "OK"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := newTestSession(start, 50000, 0)
+			session.NoLimit = true
+			session.Cost = tt.cost
+			session.CostBurnRate = tt.burn
+			session.Alerts = tt.alerts
+			if got := session.GetStatus(); got != tt.expected {
+				t.Errorf("GetStatus() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSpendReason(t *testing.T) {
+	oldConfig, oldDisplay := config, display
+	defer func() { config, display = oldConfig, oldDisplay }()
+	config = NewConfig()
+	config.Plan = PlanAPI
+	display = NewDisplay("UTC")
+
+	now := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+	session := newTestSession(now.Add(-time.Hour), 50000, 0)
+	session.NoLimit = true
+	session.Cost = CostMetrics{Spent: 6, Budget: 10, Percentage: 60, Period: BudgetPeriodDay}
+	if got, expected := session.spendReason(now).String(), "spent $6.00 of budget $10.00 per day"; got != expected {
+		t.Errorf("spendReason() = %q, expected %q", got, expected)
+	}
+
+	session.Cost.Spent, session.Cost.Percentage = 12, 120
+	if got, expected := session.spendReason(now).String(), "spent $12.00 exceeded budget $10.00 per day"; got != expected {
+		t.Errorf("spendReason() over budget = %q, expected %q", got, expected)
+	}
+}
+
+func TestRenderSpendBar(t *testing.T) {
+	oldConfig, oldDisplay := config, display
+	defer func() { config, display = oldConfig, oldDisplay }()
+	config = NewConfig()
+	config.Plan = PlanAPI
+	display = NewDisplay("UTC")
+
+	session := newTestSession(time.Now().Add(-time.Hour), 50000, 0)
+	session.NoLimit = true
+	session.Cost = CostMetrics{Spent: 4.2, Budget: 10, Percentage: 42, Period: BudgetPeriodDay}
+	session.CostBurnRate = 1.5
+
+	output := string(stripANSI([]byte(display.Render(session, NewTokenLimitEstimator(), PlanAPI))))
+	for _, expected := range []string{
+		"42.0% ($4.20/$10.00 per day, $1.50/h)",
+		"no token limit on the API plan",
+		"Spend: $4.20/$10.00 per day (api)",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Render() = %q, expected it to contain %q", output, expected)
+		}
+	}
+	if strings.Contains(output, "Cost    [") {
+		t.Errorf("Render() repeated the budget as a cost bar: %q", output)
+	}
+}
+
+func TestNotifierSpendThresholds(t *testing.T) {
+	oldConfig := config
+	defer func() { config = oldConfig }()
+	config = NewConfig()
+	config.Plan = PlanAPI
+
+	var sent []string
+	n := newTestNotifier(&sent)
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	now := start.Add(time.Hour)
+	check := func(percentage float64) {
+		session := newTestSession(start, 50000, 0)
+		session.NoLimit = true
+		session.Cost = CostMetrics{Spent: percentage / 10, Budget: 10, Percentage: percentage, Period: BudgetPeriodDay}
+		n.Check(session, now)
+	}
+
+	check(50)
+	check(85)
+	if len(sent) != 1 || sent[0] != "Spend passed 80% of the budget ($8.50/$10.00 per day)" {
+		t.Fatalf("sent %v after crossing 80%% of the budget, expected one spend notification", sent)
+	}
+	check(101)
+	if len(sent) != 3 || sent[2] != "Budget exceeded ($10.10/$10.00 per day)" {
+		t.Errorf("sent %v after going over budget, expected 95%% and exceeded notifications", sent)
+	}
+}
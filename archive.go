@@ -0,0 +1,342 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// archiveSchema creates the archive tables in WAL mode, so readers never block the
+// writer and the daemon, the monitor and one-shot commands can share the database.
+// Blocks are keyed by start time and days by date, so re-archiving a block or day
+// replaces the earlier, partial row.
+const archiveSchema = `
+PRAGMA journal_mode=WAL;
+CREATE TABLE IF NOT EXISTS blocks (
+	start_time TEXT PRIMARY KEY,
+	end_time TEXT NOT NULL,
+	actual_end_time TEXT NOT NULL,
+	models TEXT NOT NULL,
+	input_tokens INTEGER NOT NULL,
+	output_tokens INTEGER NOT NULL,
+	cache_creation_tokens INTEGER NOT NULL,
+	cache_read_tokens INTEGER NOT NULL,
+	total_tokens INTEGER NOT NULL,
+	cost_usd REAL NOT NULL,
+	entries INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS daily (
+	date TEXT PRIMARY KEY,
+	total_tokens INTEGER NOT NULL,
+	cost_usd REAL NOT NULL
+);
+`
+
+// Archive is an optional SQLite store of every observed block and daily cost, so history
+// survives ccusage and the Claude logs being pruned. It runs the sqlite3 command line tool,
+// which keeps cctop free of cgo. Several cctop processes may use one archive: sqlite3 waits
+// up to ArchiveBusyTimeout for a lock, and statements still failing with "database is
+// locked" are retried with backoff.
+type Archive struct {
+	path   string
+	binary string
+	retry  RetryPolicy
+	saved  map[string]string // Last archived statement per row, so unchanged rows are not written again
+	blocks []Block           // Archived blocks, read on first use
+	days   []DailyUsage      // Archived days, read on first use
+	loaded bool
+	mu     sync.Mutex
+}
+
+var archive *Archive
+
+// defaultArchivePath returns $XDG_DATA_HOME/cctop/history.db (~/.local/share/cctop/history.db
+// by default, %LOCALAPPDATA%\cctop\history.db on Windows)
+func defaultArchivePath() string {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" && runtime.GOOS == "windows" {
+		dataHome = os.Getenv("LOCALAPPDATA")
+	}
+	if dataHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dataHome = filepath.Join(homeDir, ".local", "share")
+	}
+	return filepath.Join(dataHome, "cctop", "history.db")
+}
+
+// NewArchive opens the archive at path, creating the database and its tables if needed
+func NewArchive(path string) (*Archive, error) {
+	if path == "" {
+		return nil, fmt.Errorf("no archive path")
+	}
+	binary, err := exec.LookPath("sqlite3")
+	if err != nil {
+		return nil, fmt.Errorf("the archive needs the sqlite3 command: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	a := &Archive{
+		path:   path,
+		binary: binary,
+		retry:  RetryPolicy{Attempts: ArchiveRetries, Base: ArchiveRetryBase, Max: ArchiveRetryMax},
+		saved:  make(map[string]string),
+	}
+	if _, err := a.exec(archiveSchema, false); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// exec runs SQL against the database, returning rows as JSON when asJSON is set.
+// Scripts failing because another process holds the database are run again.
+func (a *Archive) exec(sql string, asJSON bool) ([]byte, error) {
+	args := []string{"-batch", "-bail", "-cmd", fmt.Sprintf(".timeout %d", ArchiveBusyTimeout.Milliseconds())}
+	if asJSON {
+		args = append(args, "-json")
+	}
+	var output []byte
+	err := a.retry.Do(func() error {
+		cmd := exec.Command(a.binary, append(args, a.path)...)
+		cmd.Stdin = strings.NewReader(sql)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		var err error
+		if output, err = cmd.Output(); err != nil {
+			return fmt.Errorf("sqlite3 %s: %v: %s", a.path, err, strings.TrimSpace(stderr.String()))
+		}
+		return nil
+	}, isDatabaseLocked)
+	if err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+// isDatabaseLocked reports whether a sqlite3 error is lock contention worth retrying
+func isDatabaseLocked(err error) bool {
+	message := err.Error()
+	return strings.Contains(message, "database is locked") || strings.Contains(message, "database is busy")
+}
+
+// query runs a SELECT and decodes its rows into v
+func (a *Archive) query(sql string, v any) error {
+	output, err := a.exec(sql, true)
+	if err != nil {
+		return err
+	}
+	// sqlite3 prints nothing at all for an empty result
+	if len(bytes.TrimSpace(output)) == 0 {
+		return nil
+	}
+	return json.Unmarshal(output, v)
+}
+
+// load reads the archived blocks and days once
+func (a *Archive) load() {
+	if a.loaded {
+		return
+	}
+	a.loaded = true
+
+	var blockRows []struct {
+		StartTime     string  `json:"start_time"`
+		EndTime       string  `json:"end_time"`
+		ActualEndTime string  `json:"actual_end_time"`
+		Models        string  `json:"models"`
+		Input         int     `json:"input_tokens"`
+		Output        int     `json:"output_tokens"`
+		CacheCreation int     `json:"cache_creation_tokens"`
+		CacheRead     int     `json:"cache_read_tokens"`
+		TotalTokens   int     `json:"total_tokens"`
+		CostUSD       float64 `json:"cost_usd"`
+		Entries       int     `json:"entries"`
+	}
+	if err := a.query("SELECT * FROM blocks ORDER BY start_time;", &blockRows); err != nil {
+		logger.Warnf("reading archived blocks: %v", err)
+	}
+	for _, row := range blockRows {
+		block := Block{
+			ID:            row.StartTime,
+			StartTime:     row.StartTime,
+			EndTime:       row.EndTime,
+			ActualEndTime: row.ActualEndTime,
+			TotalTokens:   row.TotalTokens,
+			TokenCounts: TokenCounts{
+				InputTokens:              row.Input,
+				OutputTokens:             row.Output,
+				CacheCreationInputTokens: row.CacheCreation,
+				CacheReadInputTokens:     row.CacheRead,
+			},
+			CostUSD: row.CostUSD,
+			Entries: row.Entries,
+		}
+		_ = json.Unmarshal([]byte(row.Models), &block.Models)
+		a.blocks = append(a.blocks, block)
+	}
+
+	var dayRows []struct {
+		Date        string  `json:"date"`
+		TotalTokens int     `json:"total_tokens"`
+		CostUSD     float64 `json:"cost_usd"`
+	}
+	if err := a.query("SELECT * FROM daily ORDER BY date;", &dayRows); err != nil {
+		logger.Warnf("reading archived daily costs: %v", err)
+	}
+	for _, row := range dayRows {
+		a.days = append(a.days, DailyUsage{Date: row.Date, TotalTokens: row.TotalTokens, TotalCost: row.CostUSD})
+	}
+}
+
+// save writes the statements whose rows changed since they were last archived
+func (a *Archive) save(statements map[string]string) error {
+	var sql strings.Builder
+	var keys []string
+	for key, statement := range statements {
+		if a.saved[key] != statement {
+			sql.WriteString(statement)
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	// IMMEDIATE takes the write lock up front, waiting for it with the busy timeout,
+	// instead of failing at the first write when another process got there first
+	if _, err := a.exec("BEGIN IMMEDIATE;\n"+sql.String()+"COMMIT;\n", false); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		a.saved[key] = statements[key]
+	}
+	return nil
+}
+
+// ArchiveBlocks stores the blocks reported by ccusage and returns them merged with
+// archived blocks ccusage no longer reports
+func (a *Archive) ArchiveBlocks(blocks []Block) []Block {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.load()
+
+	statements := make(map[string]string)
+	var archived []Block
+	for _, block := range blocks {
+		if !block.IsGap {
+			statements["block "+block.StartTime] = blockStatement(block)
+			block.IsActive = false
+			archived = append(archived, block)
+		}
+	}
+	if err := a.save(statements); err != nil {
+		logger.Warnf("archiving blocks: %v", err)
+	} else {
+		// Keep the cache in step with the database, so blocks ccusage stops reporting
+		// while cctop runs are still merged in
+		a.blocks = mergeArchivedBlocks(a.blocks, archived)
+	}
+	return mergeArchivedBlocks(a.blocks, blocks)
+}
+
+// ArchiveDaily stores daily usage reported by ccusage and returns it merged with
+// archived days ccusage no longer reports
+func (a *Archive) ArchiveDaily(days []DailyUsage) []DailyUsage {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.load()
+
+	statements := make(map[string]string)
+	for _, day := range days {
+		statements["day "+day.Date] = fmt.Sprintf("INSERT OR REPLACE INTO daily VALUES (%s, %d, %s);\n",
+			sqlString(day.Date), day.TotalTokens, sqlReal(day.TotalCost))
+	}
+	if err := a.save(statements); err != nil {
+		logger.Warnf("archiving daily costs: %v", err)
+	} else {
+		a.days = mergeArchivedDays(a.days, days)
+	}
+	return mergeArchivedDays(a.days, days)
+}
+
+// blockStatement returns the statement archiving a block
+func blockStatement(block Block) string {
+	models, _ := json.Marshal(block.Models)
+	return fmt.Sprintf("INSERT OR REPLACE INTO blocks VALUES (%s, %s, %s, %s, %d, %d, %d, %d, %d, %s, %d);\n",
+		sqlString(block.StartTime),
+		sqlString(block.EndTime),
+		sqlString(block.ActualEndTime),
+		sqlString(string(models)),
+		block.TokenCounts.InputTokens,
+		block.TokenCounts.OutputTokens,
+		block.TokenCounts.CacheCreationInputTokens,
+		block.TokenCounts.CacheReadInputTokens,
+		block.TotalTokens,
+		sqlReal(block.CostUSD),
+		block.Entries)
+}
+
+// sqlString quotes a value as an SQL string literal. The sqlite3 command reads
+// statements as text, so NUL bytes, which would end the statement, are dropped.
+func sqlString(value string) string {
+	value = strings.ReplaceAll(value, "\x00", "")
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// sqlReal formats a float as an SQL literal, storing NaN and infinities, which
+// have no literal, as 0
+func sqlReal(value float64) string {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return "0"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// mergeArchivedBlocks adds archived blocks missing from the live blocks, sorted by start time.
+// Live blocks win, and archived blocks are never active.
+func mergeArchivedBlocks(archived, live []Block) []Block {
+	seen := make(map[string]bool, len(live))
+	for _, block := range live {
+		seen[block.StartTime] = true
+	}
+
+	merged := append([]Block(nil), live...)
+	for _, block := range archived {
+		if !seen[block.StartTime] {
+			block.IsActive = false
+			merged = append(merged, block)
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].StartTime < merged[j].StartTime })
+	return merged
+}
+
+// mergeArchivedDays adds archived days missing from the live days, sorted by date
+func mergeArchivedDays(archived, live []DailyUsage) []DailyUsage {
+	seen := make(map[string]bool, len(live))
+	for _, day := range live {
+		seen[day.Date] = true
+	}
+
+	merged := append([]DailyUsage(nil), live...)
+	for _, day := range archived {
+		if !seen[day.Date] {
+			merged = append(merged, day)
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].Date < merged[j].Date })
+	return merged
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMergeArchivedBlocks(t *testing.T) {
+	archived := []Block{
+		{StartTime: "2025-06-01T10:00:00.000Z", TotalTokens: 100},
+		{StartTime: "2025-07-01T10:00:00.000Z", TotalTokens: 200, IsActive: true},
+	}
+	live := []Block{
+		{StartTime: "2025-07-01T10:00:00.000Z", TotalTokens: 250},
+		{StartTime: "2025-07-02T10:00:00.000Z", TotalTokens: 300, IsActive: true},
+	}
+
+	merged := mergeArchivedBlocks(archived, live)
+	expected := []int{100, 250, 300}
+	if len(merged) != len(expected) {
+		t.Fatalf("mergeArchivedBlocks() returned %d blocks, expected %d", len(merged), len(expected))
+	}
+	for i, tokens := range expected {
+		if merged[i].TotalTokens != tokens {
+			t.Errorf("merged[%d].TotalTokens = %d, expected %d", i, merged[i].TotalTokens, tokens)
+		}
+	}
+	if merged[0].IsActive || !merged[2].IsActive {
+		t.Errorf("only the live active block should stay active, got %v %v", merged[0].IsActive, merged[2].IsActive)
+	}
+}
+
+func TestSQLString(t *testing.T) {
+	if got := sqlString("it's"); got != "'it''s'" {
+		t.Errorf("sqlString() = %s, expected 'it''s'", got)
+	}
+	if got := sqlString("a\x00'); DROP TABLE blocks; --"); got != "'a''); DROP TABLE blocks; --'" {
+		t.Errorf("sqlString() = %s, expected the quote escaped and NUL dropped", got)
+	}
+}
+
+func TestSQLReal(t *testing.T) {
+	tests := []struct {
+		value    float64
+		expected string
+	}{
+		{1.5, "1.5"},
+		{0, "0"},
+		{math.NaN(), "0"},
+		{math.Inf(1), "0"},
+	}
+
+	for _, tt := range tests {
+		if got := sqlReal(tt.value); got != tt.expected {
+			t.Errorf("sqlReal(%v) = %s, expected %s", tt.value, got, tt.expected)
+		}
+	}
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 not installed")
+	}
+	path := filepath.Join(t.TempDir(), "history.db")
+
+	first, err := NewArchive(path)
+	if err != nil {
+		t.Fatalf("NewArchive() error = %v", err)
+	}
+	first.ArchiveBlocks([]Block{
+		{StartTime: "2025-06-01T10:00:00.000Z", ActualEndTime: "2025-06-01T12:00:00.000Z", Models: []string{"claude-opus-4"}, TotalTokens: 1000, Entries: 10, CostUSD: 1.5},
+		{StartTime: "2025-06-01T15:00:00.000Z", IsGap: true},
+	})
+	first.ArchiveDaily([]DailyUsage{{Date: "2025-06-01", TotalTokens: 1000, TotalCost: 1.5}})
+
+	// A later run after ccusage pruned June still sees it
+	second, err := NewArchive(path)
+	if err != nil {
+		t.Fatalf("NewArchive() error = %v", err)
+	}
+	blocks := second.ArchiveBlocks([]Block{{StartTime: "2025-07-01T10:00:00.000Z", TotalTokens: 500}})
+	if len(blocks) != 2 {
+		t.Fatalf("ArchiveBlocks() returned %d blocks, expected 2", len(blocks))
+	}
+	june := blocks[0]
+	if june.TotalTokens != 1000 || june.Entries != 10 || june.CostUSD != 1.5 || len(june.Models) != 1 {
+		t.Errorf("archived block = %+v, expected the June block", june)
+	}
+	days := second.ArchiveDaily(nil)
+	if len(days) != 1 || days[0].TotalCost != 1.5 {
+		t.Errorf("ArchiveDaily() = %+v, expected the June day", days)
+	}
+
+	// Blocks archived by this process stay merged in once ccusage stops reporting them
+	blocks = second.ArchiveBlocks(nil)
+	if len(blocks) != 2 || blocks[1].TotalTokens != 500 || blocks[1].IsActive {
+		t.Errorf("ArchiveBlocks(nil) = %+v, expected the June and July blocks", blocks)
+	}
+}
+
+func TestIsDatabaseLocked(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected bool
+	}{
+		{"sqlite3 history.db: exit status 1: Runtime error near line 3: database is locked (5)", true},
+		{"sqlite3 history.db: exit status 1: Error: database is busy", true},
+		{"sqlite3 history.db: exit status 1: Parse error near line 1: no such table: blocks", false},
+	}
+	for _, tt := range tests {
+		if got := isDatabaseLocked(errors.New(tt.message)); got != tt.expected {
+			t.Errorf("isDatabaseLocked(%q) = %v, expected %v", tt.message, got, tt.expected)
+		}
+	}
+}
+
+// archivedBlockCount returns the number of archived blocks in a fresh archive of path
+func archivedBlockCount(t *testing.T, path string) int {
+	t.Helper()
+	var rows []struct {
+		Count int `json:"count"`
+	}
+	reader, err := NewArchive(path)
+	if err != nil {
+		t.Fatalf("NewArchive() error = %v", err)
+	}
+	if err := reader.query("SELECT COUNT(*) AS count FROM blocks;", &rows); err != nil || len(rows) != 1 {
+		t.Fatalf("counting blocks: %v", err)
+	}
+	return rows[0].Count
+}
+
+func TestArchiveWaitsForLock(t *testing.T) {
+	binary, err := exec.LookPath("sqlite3")
+	if err != nil {
+		t.Skip("sqlite3 not installed")
+	}
+	path := filepath.Join(t.TempDir(), "history.db")
+	archive, err := NewArchive(path)
+	if err != nil {
+		t.Fatalf("NewArchive() error = %v", err)
+	}
+
+	// Another process, e.g. the daemon, holds the write lock until told to commit
+	holder := exec.Command(binary, "-batch", path)
+	stdin, _ := holder.StdinPipe()
+	stdout, _ := holder.StdoutPipe()
+	if err := holder.Start(); err != nil {
+		t.Fatalf("starting sqlite3: %v", err)
+	}
+	fmt.Fprintf(stdin, "BEGIN IMMEDIATE;\n%sSELECT 'locked';\n", blockStatement(Block{StartTime: "2025-06-01T10:00:00.000Z"}))
+	if line, err := bufio.NewReader(stdout).ReadString('\n'); err != nil || line != "locked\n" {
+		t.Fatalf("lock holder printed %q, %v", line, err)
+	}
+
+	saved := make(chan error)
+	go func() {
+		saved <- archive.save(map[string]string{"block": blockStatement(Block{StartTime: "2025-06-01T15:00:00.000Z"})})
+	}()
+	time.Sleep(300 * time.Millisecond)
+	fmt.Fprintln(stdin, "COMMIT;")
+	stdin.Close()
+	if err := holder.Wait(); err != nil {
+		t.Fatalf("lock holder error = %v", err)
+	}
+
+	if err := <-saved; err != nil {
+		t.Fatalf("save() while locked error = %v, expected it to wait for the lock", err)
+	}
+	if count := archivedBlockCount(t, path); count != 2 {
+		t.Errorf("archived %d blocks, expected 2", count)
+	}
+}
+
+func TestArchiveConcurrentWriters(t *testing.T) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 not installed")
+	}
+	path := filepath.Join(t.TempDir(), "history.db")
+
+	// Separate archives run separate sqlite3 processes, like the daemon, the monitor
+	// and one-shot commands do
+	const writers, blocksPerWriter = 8, 5
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*blocksPerWriter)
+	for w := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			archive, err := NewArchive(path)
+			if err != nil {
+				errs <- err
+				return
+			}
+			for b := range blocksPerWriter {
+				start := time.Date(2025, 6, 1, w, b, 0, 0, time.UTC).Format(time.RFC3339)
+				if err := archive.save(map[string]string{start: blockStatement(Block{StartTime: start})}); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("concurrent write error = %v", err)
+	}
+	if count := archivedBlockCount(t, path); count != writers*blocksPerWriter {
+		t.Errorf("archived %d blocks, expected %d", count, writers*blocksPerWriter)
+	}
+}
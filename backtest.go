@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// CalibrationRatios are the fractions of the predicted limit the calibration curve is sampled at
+var CalibrationRatios = []float64{0.5, 0.75, 1, 1.25, 1.5}
+
+// BacktestPrediction is what a method predicted before a completed session
+type BacktestPrediction struct {
+	Start     time.Time
+	Predicted int
+	Actual    int
+}
+
+// ErrorPercent returns the prediction error relative to the session's actual tokens
+func (p BacktestPrediction) ErrorPercent() float64 {
+	if p.Actual == 0 {
+		return 0
+	}
+	return float64(p.Predicted-p.Actual) / float64(p.Actual) * 100
+}
+
+// BacktestResult holds one method's replay over history
+type BacktestResult struct {
+	Method      string
+	Predictions []BacktestPrediction
+	MAE         float64   // Mean absolute error in tokens
+	Bias        float64   // Mean signed error in tokens, positive when overestimating
+	Calibration []float64 // Share of sessions using at most each of CalibrationRatios of the prediction
+}
+
+var (
+	backtestMethods []string
+	backtestRows    int
+)
+
+// runBacktest replays history for each method and prints per-session errors and a summary
+func runBacktest(cmd *cobra.Command, args []string) {
+	data := fetchUsageData()
+	if data == nil {
+		fmt.Println("Failed to get usage data")
+		return
+	}
+
+	var results []BacktestResult
+	for _, method := range backtestMethods {
+		results = append(results, backtest(method, config.Plan, data.Blocks))
+	}
+	fmt.Print(display.RenderBacktest(results, backtestRows))
+}
+
+// backtest replays completed sessions chronologically, predicting each one's
+// limit from only the sessions before it
+func backtest(method, plan string, blocks []Block) BacktestResult {
+	// A fresh estimator without learned state, so limit hits observed later
+	// cannot leak into earlier predictions
+	e := NewTokenLimitEstimator()
+	e.SetEstimationMethod(method)
+
+	history := completedBlocks(blocks)
+	result := BacktestResult{Method: method}
+	for i := 1; i < len(history); i++ {
+		start, err := time.Parse(time.RFC3339, history[i].StartTime)
+		if err != nil {
+			continue
+		}
+		predicted := e.EstimateLimit(plan, history[:i])
+		if predicted <= 0 {
+			continue
+		}
+		result.Predictions = append(result.Predictions, BacktestPrediction{
+			Start:     start,
+			Predicted: predicted,
+			Actual:    history[i].TotalTokens,
+		})
+	}
+
+	result.summarize()
+	return result
+}
+
+// summarize computes the error statistics and calibration curve from the predictions
+func (r *BacktestResult) summarize() {
+	r.Calibration = make([]float64, len(CalibrationRatios))
+	if len(r.Predictions) == 0 {
+		return
+	}
+
+	var absolute, signed float64
+	for _, p := range r.Predictions {
+		diff := float64(p.Predicted - p.Actual)
+		absolute += math.Abs(diff)
+		signed += diff
+		for i, ratio := range CalibrationRatios {
+			if float64(p.Actual) <= ratio*float64(p.Predicted) {
+				r.Calibration[i]++
+			}
+		}
+	}
+
+	n := float64(len(r.Predictions))
+	r.MAE = absolute / n
+	r.Bias = signed / n
+	for i := range r.Calibration {
+		r.Calibration[i] = r.Calibration[i] / n * 100
+	}
+}
+
+// completedBlocks returns completed sessions with usage in chronological order
+func completedBlocks(blocks []Block) []Block {
+	var completed []Block
+	for _, block := range blocks {
+		if !block.IsGap && !block.IsActive && block.TotalTokens > 0 {
+			completed = append(completed, block)
+		}
+	}
+	sort.SliceStable(completed, func(i, j int) bool {
+		return completed[i].StartTime < completed[j].StartTime
+	})
+	return completed
+}
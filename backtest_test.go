@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBacktestSummarize(t *testing.T) {
+	result := BacktestResult{Predictions: []BacktestPrediction{
+		{Predicted: 100, Actual: 50},  // used 50%
+		{Predicted: 100, Actual: 100}, // used 100%
+		{Predicted: 100, Actual: 140}, // used 140%
+		{Predicted: 100, Actual: 90},  // used 90%
+	}}
+	result.summarize()
+
+	if result.MAE != 25 {
+		t.Errorf("MAE = %.2f, expected 25", result.MAE)
+	}
+	if result.Bias != 5 {
+		t.Errorf("Bias = %.2f, expected 5", result.Bias)
+	}
+	expected := []float64{25, 25, 75, 75, 100}
+	for i, share := range result.Calibration {
+		if share != expected[i] {
+			t.Errorf("Calibration[%.2f] = %.0f%%, expected %.0f%%", CalibrationRatios[i], share, expected[i])
+		}
+	}
+}
+
+func TestBacktestChronological(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	var blocks []Block
+	// Newest first, with a gap and the active block that must be skipped
+	for i := 3; i >= 0; i-- {
+		blocks = append(blocks, Block{
+			StartTime:   start.Add(time.Duration(i) * 6 * time.Hour).Format(time.RFC3339),
+			TotalTokens: 10000 * (i + 1),
+			Entries:     10,
+		})
+	}
+	blocks = append(blocks, Block{IsGap: true}, Block{StartTime: start.Add(24 * time.Hour).Format(time.RFC3339), TotalTokens: 500, Entries: 5, IsActive: true})
+
+	result := backtest("p40", "pro", blocks)
+	if len(result.Predictions) != 3 {
+		t.Fatalf("len(Predictions) = %d, expected 3 (every completed session after the first)", len(result.Predictions))
+	}
+	for i, p := range result.Predictions {
+		if expectedStart := start.Add(time.Duration(i+1) * 6 * time.Hour); !p.Start.Equal(expectedStart) {
+			t.Errorf("Predictions[%d].Start = %v, expected %v", i, p.Start, expectedStart)
+		}
+		if p.Actual != 10000*(i+2) {
+			t.Errorf("Predictions[%d].Actual = %d, expected %d", i, p.Actual, 10000*(i+2))
+		}
+	}
+	// Cold start predicts from tokens/message of earlier sessions only: 1000 tokens/msg x 45 messages
+	if result.Predictions[0].Predicted != 1000*ProPlanMessages {
+		t.Errorf("Predictions[0].Predicted = %d, expected %d", result.Predictions[0].Predicted, 1000*ProPlanMessages)
+	}
+}
+
+func TestRenderBacktest(t *testing.T) {
+	d := NewDisplay("UTC")
+	if output := d.RenderBacktest(nil, 0); !strings.Contains(output, "Not enough") {
+		t.Errorf("RenderBacktest(nil) = %q, expected not enough sessions message", output)
+	}
+
+	result := BacktestResult{Method: "p40", Predictions: []BacktestPrediction{
+		{Start: time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC), Predicted: 120, Actual: 100},
+	}}
+	result.summarize()
+	output := d.RenderBacktest([]BacktestResult{result}, 0)
+	for _, expected := range []string{"06-20 09:00", "+20%", "p40", "+20"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("RenderBacktest() missing %q:\n%s", expected, output)
+		}
+	}
+}
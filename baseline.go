@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+// BaselineVersion is the format version of baseline profiles
+const BaselineVersion = 1
+
+// BaselineStats is the spread of a per-session metric
+type BaselineStats struct {
+	P25 float64 `json:"p25"`
+	P50 float64 `json:"p50"`
+	P75 float64 `json:"p75"`
+}
+
+// BaselineProfile describes typical sessions, e.g. of a team, without any timestamps,
+// projects or models, so it can be published and imported by others
+type BaselineProfile struct {
+	Version            int           `json:"version"`
+	Name               string        `json:"name"`
+	Sessions           int           `json:"sessions"`
+	TokensPerSession   BaselineStats `json:"tokensPerSession"`
+	MessagesPerSession BaselineStats `json:"messagesPerSession"`
+	TokensPerMessage   BaselineStats `json:"tokensPerMessage"`
+	CostPerSession     BaselineStats `json:"costPerSession"`
+	CacheReadShare     BaselineStats `json:"cacheReadShare"` // Percent of tokens read from the prompt cache
+}
+
+// BaselineComparison compares one metric of your sessions with a baseline
+type BaselineComparison struct {
+	Metric   string
+	You      BaselineStats
+	Baseline BaselineStats
+	Format   func(float64) string
+}
+
+// Position reports where your median falls relative to the baseline's middle half
+func (c BaselineComparison) Position() string {
+	switch {
+	case c.You.P50 > c.Baseline.P75:
+		return "higher"
+	case c.You.P50 < c.Baseline.P25:
+		return "lower"
+	default:
+		return "typical"
+	}
+}
+
+var (
+	baselineName string
+	baselineOut  string
+)
+
+// defaultBaselinePath returns the imported baseline in the cctop state directory
+func defaultBaselinePath() string {
+	stateDir := cctopStateDir()
+	if stateDir == "" {
+		return ""
+	}
+	return filepath.Join(stateDir, "baseline.json")
+}
+
+// buildBaselineProfile summarizes completed sessions into a baseline profile
+func buildBaselineProfile(name string, blocks []Block) BaselineProfile {
+	completed := completedBlocks(blocks)
+	var tokens, messages, perMessage, cost, cacheShare []float64
+	for _, block := range completed {
+		tokens = append(tokens, float64(block.TotalTokens))
+		messages = append(messages, float64(block.Entries))
+		if block.Entries > 0 {
+			perMessage = append(perMessage, float64(block.TotalTokens)/float64(block.Entries))
+		}
+		cost = append(cost, block.CostUSD)
+		cacheShare = append(cacheShare, float64(block.TokenCounts.CacheReadInputTokens)/float64(block.TotalTokens)*100)
+	}
+
+	return BaselineProfile{
+		Version:            BaselineVersion,
+		Name:               name,
+		Sessions:           len(completed),
+		TokensPerSession:   baselineStats(tokens),
+		MessagesPerSession: baselineStats(messages),
+		TokensPerMessage:   baselineStats(perMessage),
+		CostPerSession:     baselineStats(cost),
+		CacheReadShare:     baselineStats(cacheShare),
+	}
+}
+
+// baselineStats returns the quartiles of values
+func baselineStats(values []float64) BaselineStats {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return BaselineStats{
+		P25: percentileOfSortedFloats(sorted, 25),
+		P50: percentileOfSortedFloats(sorted, 50),
+		P75: percentileOfSortedFloats(sorted, 75),
+	}
+}
+
+// compareBaseline pairs each metric of your profile with the baseline's
+func compareBaseline(you, baseline BaselineProfile) []BaselineComparison {
+	tokens := func(v float64) string { return formatNumber(int(v)) }
+	percent := func(v float64) string { return fmt.Sprintf("%.0f%%", v) }
+	return []BaselineComparison{
+		{"Tokens/session", you.TokensPerSession, baseline.TokensPerSession, tokens},
+		{"Messages/session", you.MessagesPerSession, baseline.MessagesPerSession, tokens},
+		{"Tokens/message", you.TokensPerMessage, baseline.TokensPerMessage, tokens},
+		{"Cost/session", you.CostPerSession, baseline.CostPerSession, formatCost},
+		{"Cache reads", you.CacheReadShare, baseline.CacheReadShare, percent},
+	}
+}
+
+// loadBaselineProfile reads and checks a baseline profile
+func loadBaselineProfile(path string) (BaselineProfile, error) {
+	var profile BaselineProfile
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return profile, err
+	}
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return profile, fmt.Errorf("%s: %w", path, err)
+	}
+	if profile.Version != BaselineVersion {
+		return profile, fmt.Errorf("%s: unsupported baseline version %d, expected %d", path, profile.Version, BaselineVersion)
+	}
+	if profile.Sessions == 0 {
+		return profile, fmt.Errorf("%s: baseline has no sessions", path)
+	}
+	return profile, nil
+}
+
+// runBaseline compares your completed sessions with a baseline file or the imported one
+func runBaseline(cmd *cobra.Command, args []string) {
+	path := defaultBaselinePath()
+	if len(args) > 0 {
+		path = args[0]
+	}
+	baseline, err := loadBaselineProfile(path)
+	if os.IsNotExist(err) && len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "No baseline imported, run 'cctop baseline import <file>'")
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	data := fetchUsageData()
+	if data == nil {
+		fmt.Println("Failed to get usage data")
+		return
+	}
+	you := buildBaselineProfile("you", data.Blocks)
+	fmt.Print(display.RenderBaselineComparison(you, baseline))
+}
+
+// runBaselineExport prints your sessions as a baseline profile for sharing
+func runBaselineExport(cmd *cobra.Command, args []string) {
+	data := fetchUsageData()
+	if data == nil {
+		fmt.Fprintln(os.Stderr, "Failed to get usage data")
+		os.Exit(1)
+	}
+	output, err := json.MarshalIndent(buildBaselineProfile(baselineName, data.Blocks), "", "  ")
+	if err == nil && baselineOut != "" {
+		err = os.WriteFile(baselineOut, append(output, '\n'), 0o644)
+	} else if err == nil {
+		fmt.Println(string(output))
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// runBaselineImport validates a baseline profile and keeps a copy as the default baseline
+func runBaselineImport(cmd *cobra.Command, args []string) {
+	profile, err := loadBaselineProfile(args[0])
+	if err == nil {
+		err = importBaselineProfile(profile, defaultBaselinePath())
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Imported baseline %q (%s)\n", profile.Name, formatSessionCount(profile))
+}
+
+// importBaselineProfile writes the profile to path
+func importBaselineProfile(profile BaselineProfile, path string) error {
+	if path == "" {
+		return fmt.Errorf("no state directory for the baseline")
+	}
+	data, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// formatSessionCount formats how many sessions a profile covers
+func formatSessionCount(profile BaselineProfile) string {
+	if profile.Sessions == 1 {
+		return "1 session"
+	}
+	return fmt.Sprintf("%d sessions", profile.Sessions)
+}
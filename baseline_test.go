@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildBaselineProfile(t *testing.T) {
+	block := func(start string, tokens, entries, cacheRead int, cost float64) Block {
+		return Block{StartTime: start, TotalTokens: tokens, Entries: entries, CostUSD: cost,
+			TokenCounts: TokenCounts{CacheReadInputTokens: cacheRead}}
+	}
+	blocks := []Block{
+		block("2025-06-20T09:00:00Z", 10000, 100, 5000, 1),
+		block("2025-06-20T15:00:00Z", 20000, 100, 5000, 2),
+		block("2025-06-21T09:00:00Z", 30000, 100, 15000, 3),
+		block("2025-06-21T15:00:00Z", 40000, 200, 30000, 4),
+		{StartTime: "2025-06-21T20:00:00Z", IsGap: true},
+		{StartTime: "2025-06-22T09:00:00Z", TotalTokens: 99999, IsActive: true},
+	}
+
+	profile := buildBaselineProfile("team", blocks)
+	if profile.Version != BaselineVersion || profile.Name != "team" || profile.Sessions != 4 {
+		t.Fatalf("buildBaselineProfile() = version %d, name %q, %d sessions, expected %d, %q, 4",
+			profile.Version, profile.Name, profile.Sessions, BaselineVersion, "team")
+	}
+
+	tests := []struct {
+		name     string
+		got      BaselineStats
+		expected BaselineStats
+	}{
+		{"TokensPerSession", profile.TokensPerSession, BaselineStats{P25: 10000, P50: 20000, P75: 30000}},
+		{"MessagesPerSession", profile.MessagesPerSession, BaselineStats{P25: 100, P50: 100, P75: 100}},
+		{"TokensPerMessage", profile.TokensPerMessage, BaselineStats{P25: 100, P50: 200, P75: 200}},
+		{"CostPerSession", profile.CostPerSession, BaselineStats{P25: 1, P50: 2, P75: 3}},
+		{"CacheReadShare", profile.CacheReadShare, BaselineStats{P25: 25, P50: 50, P75: 50}},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.expected {
+			t.Errorf("%s = %+v, expected %+v", tt.name, tt.got, tt.expected)
+		}
+	}
+}
+
+func TestBaselinePosition(t *testing.T) {
+	baseline := BaselineStats{P25: 100, P50: 150, P75: 200}
+	tests := []struct {
+		you      float64
+		expected string
+	}{
+		{50, "lower"},
+		{100, "typical"},
+		{200, "typical"},
+		{250, "higher"},
+	}
+	for _, tt := range tests {
+		comparison := BaselineComparison{You: BaselineStats{P50: tt.you}, Baseline: baseline}
+		if got := comparison.Position(); got != tt.expected {
+			t.Errorf("Position() with median %.0f = %s, expected %s", tt.you, got, tt.expected)
+		}
+	}
+}
+
+func TestBaselineImport(t *testing.T) {
+	dir := t.TempDir()
+	profile := BaselineProfile{Version: BaselineVersion, Name: "team", Sessions: 12, TokensPerSession: BaselineStats{P50: 50000}}
+	path := filepath.Join(dir, "state", "baseline.json")
+	if err := importBaselineProfile(profile, path); err != nil {
+		t.Fatalf("importBaselineProfile() = %v", err)
+	}
+	loaded, err := loadBaselineProfile(path)
+	if err != nil || loaded != profile {
+		t.Errorf("loadBaselineProfile() = %+v, %v, expected %+v", loaded, err, profile)
+	}
+
+	invalid := []struct {
+		name    string
+		content string
+	}{
+		{"future version", `{"version": 2, "sessions": 3}`},
+		{"no sessions", `{"version": 1, "sessions": 0}`},
+		{"not JSON", `tokens: 3`},
+	}
+	for _, tt := range invalid {
+		path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "-")+".json")
+		if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadBaselineProfile(path); err == nil {
+			t.Errorf("loadBaselineProfile(%s) = nil, expected an error", tt.name)
+		}
+	}
+}
+
+func TestRenderBaselineComparison(t *testing.T) {
+	you := BaselineProfile{Sessions: 8, TokensPerSession: BaselineStats{P50: 90000}, TokensPerMessage: BaselineStats{P50: 300}}
+	baseline := BaselineProfile{Name: "platform team", Sessions: 40,
+		TokensPerSession: BaselineStats{P25: 30000, P50: 45000, P75: 60000},
+		TokensPerMessage: BaselineStats{P25: 200, P50: 250, P75: 400}}
+
+	output := string(stripANSI([]byte(NewDisplay("UTC").RenderBaselineComparison(you, baseline))))
+	for _, expected := range []string{
+		`Your 8 sessions against "platform team" (40 sessions)`,
+		"Tokens/session        90,000      45,000          30,000 – 60,000  higher",
+		"Tokens/message           300         250                200 – 400  typical",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("RenderBaselineComparison() missing %q in:\n%s", expected, output)
+		}
+	}
+
+	if output := NewDisplay("UTC").RenderBaselineComparison(BaselineProfile{}, baseline); !strings.Contains(output, "No completed sessions") {
+		t.Errorf("RenderBaselineComparison() without sessions = %q", output)
+	}
+}
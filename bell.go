@@ -0,0 +1,99 @@
+package main
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// Bell levels, in increasing severity
+const (
+	bellNone = iota
+	bellWarning
+	bellExceeded
+)
+
+// BellConfig holds audible alert configuration
+type BellConfig struct {
+	Enabled  bool
+	Warning  float64       // Token usage percentage that rings the warning bell
+	Command  string        // Sound command run instead of the terminal bell (optional)
+	Cooldown time.Duration // Minimum time between rings for the same level
+}
+
+// Bell rings when token usage crosses the warning or limit-exceeded threshold
+type Bell struct {
+	warning   float64
+	cooldown  time.Duration
+	lastLevel int
+	lastRung  map[int]time.Time
+	ring      func() error
+}
+
+// NewBell creates a bell that plays the sound command, or writes BEL to stdout
+func NewBell(cfg BellConfig) *Bell {
+	b := &Bell{
+		warning:  cfg.Warning,
+		cooldown: cfg.Cooldown,
+		lastRung: make(map[int]time.Time),
+		ring:     func() error { return ringTerminal(os.Stdout) },
+	}
+	if cfg.Command != "" {
+		b.ring = func() error { return runSoundCommand(cfg.Command) }
+	}
+	return b
+}
+
+// Check rings when the session reaches a higher level than at the last check
+func (b *Bell) Check(session *Session, currentTime time.Time) error {
+	level := b.level(session)
+	rising := level > b.lastLevel
+	b.lastLevel = level
+	if !rising {
+		return nil
+	}
+
+	if last, ok := b.lastRung[level]; ok && currentTime.Sub(last) < b.cooldown {
+		return nil
+	}
+	b.lastRung[level] = currentTime
+	return b.ring()
+}
+
+// level returns the alert level for the session's token usage
+func (b *Bell) level(session *Session) int {
+	if session.NoLimit {
+		return bellNone
+	}
+	switch percentage := session.Metrics.Tokens.Percentage; {
+	case percentage >= 100:
+		return bellExceeded
+	case percentage >= b.warning:
+		return bellWarning
+	}
+	return bellNone
+}
+
+// ringTerminal writes the BEL character
+func ringTerminal(w io.Writer) error {
+	_, err := io.WriteString(w, "\a")
+	return err
+}
+
+// runSoundCommand plays a sound through the shell, e.g. "afplay /System/Library/Sounds/Ping.aiff"
+func runSoundCommand(command string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// Don't hold up the refresh while the sound plays
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestBellCheck(t *testing.T) {
+	rings := 0
+	b := NewBell(BellConfig{Enabled: true, Warning: 80, Cooldown: 10 * time.Minute})
+	b.ring = func() error {
+		rings++
+		return nil
+	}
+
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	steps := []struct {
+		used     int
+		offset   time.Duration
+		expected int
+	}{
+		{5000, 0, 0},                // Below warning
+		{8500, time.Minute, 1},      // Warning crossed
+		{9000, 2 * time.Minute, 1},  // Still warning, no repeat
+		{10500, 3 * time.Minute, 2}, // Limit exceeded
+		{1000, 4 * time.Minute, 2},  // New session drops back
+		{8500, 5 * time.Minute, 2},  // Warning again within cooldown
+		{8500, 6 * time.Minute, 2},  // Unchanged
+		{1000, 20 * time.Minute, 2}, // Drops back
+		{8500, 21 * time.Minute, 3}, // Warning after cooldown
+	}
+
+	for i, step := range steps {
+		if err := b.Check(newTestSession(start, step.used, 10000), start.Add(step.offset)); err != nil {
+			t.Fatal(err)
+		}
+		if rings != step.expected {
+			t.Errorf("step %d: rings = %d, expected %d", i, rings, step.expected)
+		}
+	}
+}
+
+func TestBellNoLimit(t *testing.T) {
+	rings := 0
+	b := NewBell(BellConfig{Enabled: true, Warning: 80})
+	b.ring = func() error {
+		rings++
+		return nil
+	}
+
+	session := newTestSession(time.Now(), 20000, 10000)
+	session.NoLimit = true
+	if err := b.Check(session, time.Now()); err != nil || rings != 0 {
+		t.Errorf("rings = %d without a known limit, expected 0", rings)
+	}
+}
+
+func TestRingTerminal(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := ringTerminal(&buffer); err != nil || buffer.String() != "\a" {
+		t.Errorf("ringTerminal() wrote %q, expected BEL", buffer.String())
+	}
+}
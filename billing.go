@@ -0,0 +1,66 @@
+package main
+
+import (
+	"time"
+)
+
+// BillingCycle represents a subscription billing period
+type BillingCycle struct {
+	Start time.Time
+	End   time.Time
+}
+
+// CycleUsage holds aggregated usage for a billing cycle
+type CycleUsage struct {
+	Cycle  BillingCycle
+	Cost   float64
+	Tokens int
+}
+
+// NewBillingCycle returns the billing cycle containing currentTime for the given anchor day.
+// Anchor days beyond the end of a month are clamped to the last day of that month.
+func NewBillingCycle(currentTime time.Time, anchorDay int) BillingCycle {
+	anchorDay = clampInt(anchorDay, 1, 31)
+	year, month, _ := currentTime.Date()
+
+	start := anchorDate(year, month, anchorDay, currentTime.Location())
+	if currentTime.Before(start) {
+		start = anchorDate(year, month-1, anchorDay, currentTime.Location())
+	}
+
+	startYear, startMonth, _ := start.Date()
+	end := anchorDate(startYear, startMonth+1, anchorDay, currentTime.Location())
+
+	return BillingCycle{Start: start, End: end}
+}
+
+// Contains reports whether the given date falls within the cycle
+func (c BillingCycle) Contains(t time.Time) bool {
+	return !t.Before(c.Start) && t.Before(c.End)
+}
+
+// anchorDate returns midnight of the anchor day in the given month, clamped to the month length
+func anchorDate(year int, month time.Month, day int, loc *time.Location) time.Time {
+	// Day 0 of the following month is the last day of this month
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, loc).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
+
+// summarizeCycle aggregates daily usage entries that fall within the cycle
+func summarizeCycle(days []DailyUsage, cycle BillingCycle) CycleUsage {
+	usage := CycleUsage{Cycle: cycle}
+	for _, day := range days {
+		date, err := time.ParseInLocation(DateFormat, day.Date, cycle.Start.Location())
+		if err != nil {
+			continue
+		}
+		if cycle.Contains(date) {
+			usage.Cost += day.TotalCost
+			usage.Tokens += day.TotalTokens
+		}
+	}
+	return usage
+}
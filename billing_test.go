@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewBillingCycle(t *testing.T) {
+	tests := []struct {
+		name      string
+		current   time.Time
+		anchorDay int
+		start     string
+		end       string
+	}{
+		{
+			name:      "Calendar month",
+			current:   time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC),
+			anchorDay: 1,
+			start:     "2025-06-01",
+			end:       "2025-07-01",
+		},
+		{
+			name:      "Before anchor uses previous month",
+			current:   time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC),
+			anchorDay: 15,
+			start:     "2025-05-15",
+			end:       "2025-06-15",
+		},
+		{
+			name:      "On anchor day starts new cycle",
+			current:   time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC),
+			anchorDay: 15,
+			start:     "2025-06-15",
+			end:       "2025-07-15",
+		},
+		{
+			name:      "Anchor clamped to short month",
+			current:   time.Date(2025, 2, 28, 12, 0, 0, 0, time.UTC),
+			anchorDay: 31,
+			start:     "2025-02-28",
+			end:       "2025-03-31",
+		},
+		{
+			name:      "Year boundary",
+			current:   time.Date(2025, 1, 5, 12, 0, 0, 0, time.UTC),
+			anchorDay: 20,
+			start:     "2024-12-20",
+			end:       "2025-01-20",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cycle := NewBillingCycle(tt.current, tt.anchorDay)
+			if got := cycle.Start.Format(DateFormat); got != tt.start {
+				t.Errorf("Start = %s, expected %s", got, tt.start)
+			}
+			if got := cycle.End.Format(DateFormat); got != tt.end {
+				t.Errorf("End = %s, expected %s", got, tt.end)
+			}
+		})
+	}
+}
+
+func TestSummarizeCycle(t *testing.T) {
+	cycle := NewBillingCycle(time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC), 15)
+	days := []DailyUsage{
+		{Date: "2025-06-14", TotalTokens: 1000, TotalCost: 1.0},
+		{Date: "2025-06-15", TotalTokens: 2000, TotalCost: 2.5},
+		{Date: "2025-06-20", TotalTokens: 3000, TotalCost: 3.5},
+		{Date: "2025-07-15", TotalTokens: 4000, TotalCost: 4.0},
+	}
+
+	usage := summarizeCycle(days, cycle)
+	if usage.Tokens != 5000 {
+		t.Errorf("Tokens = %d, expected 5000", usage.Tokens)
+	}
+	if usage.Cost != 6.0 {
+		t.Errorf("Cost = %.2f, expected 6.00", usage.Cost)
+	}
+}
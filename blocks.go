@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Timeline segment kinds
+const (
+	SegmentActive    = "active"
+	SegmentCompleted = "completed"
+	SegmentGap       = "gap"
+)
+
+// TimelineSegment is a session block or a gap between blocks on the timeline
+type TimelineSegment struct {
+	Kind       string
+	Start      time.Time
+	End        time.Time // Last activity, or the end of the gap
+	Tokens     int
+	Cost       float64
+	Percentage float64 // Of the estimated limit, 0 without one
+	Exceeded   bool
+}
+
+var blocksDays int
+
+// runBlocks prints a timeline of recent blocks and gaps against the estimated limit
+func runBlocks(cmd *cobra.Command, args []string) {
+	estimator.SetEstimationMethod(estimationMethod)
+
+	data := fetchUsageData()
+	if data == nil {
+		fmt.Fprintln(os.Stderr, "Failed to get usage data")
+		os.Exit(1)
+	}
+
+	now := time.Now()
+	since := startOfDay(now.In(display.timezone)).AddDate(0, 0, 1-blocksDays)
+	limit := tokenLimitOf(config.Plan, data.Blocks)
+	fmt.Print(display.RenderBlocks(buildTimeline(data.Blocks, limit, since, now), limit, since, now))
+}
+
+// buildTimeline converts blocks overlapping [since, now] into timeline segments, oldest first
+func buildTimeline(blocks []Block, limit int, since, now time.Time) []TimelineSegment {
+	var segments []TimelineSegment
+	for _, block := range blocks {
+		start, err := time.Parse(time.RFC3339, block.StartTime)
+		if err != nil {
+			continue
+		}
+
+		segment := TimelineSegment{Start: start, Tokens: block.TotalTokens, Cost: block.CostUSD}
+		switch {
+		case block.IsGap:
+			segment.Kind = SegmentGap
+			segment.End, err = time.Parse(time.RFC3339, block.EndTime)
+			if err != nil {
+				continue
+			}
+		case block.IsActive:
+			segment.Kind = SegmentActive
+			segment.End = now
+		default:
+			segment.Kind = SegmentCompleted
+			segment.End, err = time.Parse(time.RFC3339, block.ActualEndTime)
+			if err != nil {
+				segment.End = start.Add(SessionDuration)
+			}
+		}
+		if segment.End.Before(since) {
+			continue
+		}
+
+		if limit > 0 && segment.Kind != SegmentGap {
+			segment.Percentage = float64(block.TotalTokens) / float64(limit) * 100
+			segment.Exceeded = block.TotalTokens > limit
+		}
+		segments = append(segments, segment)
+	}
+	return segments
+}
+
+// timelineCell returns the segment covering the midpoint of [cellStart, cellEnd), or nil
+func timelineCell(segments []TimelineSegment, cellStart, cellEnd time.Time) *TimelineSegment {
+	mid := cellStart.Add(cellEnd.Sub(cellStart) / 2)
+	for i := range segments {
+		if !mid.Before(segments[i].Start) && mid.Before(segments[i].End) {
+			return &segments[i]
+		}
+	}
+	return nil
+}
+
+// timelineCellStart returns the wall clock start of a day's cell, so cells line up
+// with the axis on days when daylight saving time starts or ends
+func timelineCellStart(day time.Time, cell int) time.Time {
+	minutes := cell * 24 * 60 / TimelineCells
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, minutes, 0, 0, day.Location())
+}
+
+// startOfDay returns midnight of the time's day in its location
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// RenderBlocks renders a strip per day with blocks as colored cells, followed by
+// each block's tokens against the estimated limit.
+// Format: "Fri 06-20  │    ████████······██        │  86,800"
+func (d *Display) RenderBlocks(segments []TimelineSegment, limit int, since, now time.Time) string {
+	var buffer strings.Builder
+	fmt.Fprintf(&buffer, "Blocks since %s (limit: %s)\n\n", since.In(d.timezone).Format(DateFormat), formatLimit(limit))
+
+	fmt.Fprintf(&buffer, "%-9s   %s\n", "", timelineAxis())
+	for day := startOfDay(since.In(d.timezone)); !day.After(now); day = day.AddDate(0, 0, 1) {
+		var strip strings.Builder
+		for cell := 0; cell < TimelineCells; cell++ {
+			cellStart, cellEnd := timelineCellStart(day, cell), timelineCellStart(day, cell+1)
+			strip.WriteString(d.timelineCellString(timelineCell(segments, cellStart, cellEnd), cellStart.After(now)))
+		}
+
+		tokens := 0
+		next := day.AddDate(0, 0, 1)
+		for _, segment := range segments {
+			if segment.Kind != SegmentGap && !segment.Start.Before(day) && segment.Start.Before(next) {
+				tokens += segment.Tokens
+			}
+		}
+		fmt.Fprintf(&buffer, "%-9s  │%s│ %10s\n", day.Format("Mon 01-02"), strip.String(), formatNumber(tokens))
+	}
+	fmt.Fprintf(&buffer, "%s\n\n", mutedString("            %s completed  %s active  %s over limit  %s gap",
+		okString("█"), infoString("█"), dangerString("█"), "·"))
+
+	for _, segment := range segments {
+		start := segment.Start.In(d.timezone)
+		period := fmt.Sprintf("%s %s-%s", start.Format("01-02"), start.Format(TimeFormatShort),
+			segment.End.In(d.timezone).Format(TimeFormatShort))
+		if segment.Kind == SegmentGap {
+			fmt.Fprintf(&buffer, "%s\n", mutedString("%-17s  gap %s", period, formatTime(segment.End.Sub(segment.Start).Minutes())))
+			continue
+		}
+
+		line := fmt.Sprintf("%-17s  %-9s  %12s  %9s", period, segment.Kind, formatNumber(segment.Tokens), formatCost(segment.Cost))
+		if limit > 0 {
+			line += fmt.Sprintf("  %5.1f%%", segment.Percentage)
+		}
+		if segment.Exceeded {
+			line += "  " + dangerString("exceeded")
+		}
+		fmt.Fprintf(&buffer, "%s\n", line)
+	}
+	return buffer.String()
+}
+
+// timelineCellString renders one timeline cell
+func (d *Display) timelineCellString(segment *TimelineSegment, future bool) string {
+	switch {
+	case future || segment == nil:
+		return " "
+	case segment.Kind == SegmentGap:
+		return mutedString("·")
+	case segment.Exceeded:
+		return dangerString("█")
+	case segment.Kind == SegmentActive:
+		return infoString("█")
+	default:
+		return okString("█")
+	}
+}
+
+// timelineAxis labels every sixth hour above the day strips
+func timelineAxis() string {
+	cellsPerHour := TimelineCells / 24
+	var axis strings.Builder
+	for hour := 0; hour < 24; hour += 6 {
+		label := fmt.Sprintf("%02d", hour)
+		axis.WriteString(label + strings.Repeat(" ", 6*cellsPerHour-len(label)))
+	}
+	return strings.TrimRight(axis.String(), " ")
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildTimeline(t *testing.T) {
+	since := time.Date(2025, 6, 19, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 6, 20, 15, 0, 0, 0, time.UTC)
+	blocks := []Block{
+		{StartTime: "2025-06-18T09:00:00Z", ActualEndTime: "2025-06-18T12:00:00Z", TotalTokens: 5000},
+		{StartTime: "2025-06-20T04:00:00Z", ActualEndTime: "2025-06-20T06:30:00Z", TotalTokens: 12000, CostUSD: 3.5},
+		{StartTime: "2025-06-20T06:30:00Z", EndTime: "2025-06-20T12:00:00Z", IsGap: true},
+		{StartTime: "2025-06-20T12:00:00Z", TotalTokens: 4000, IsActive: true},
+	}
+
+	segments := buildTimeline(blocks, 10000, since, now)
+	if len(segments) != 3 {
+		t.Fatalf("buildTimeline() = %d segments, expected 3 (the 06-18 block is before since)", len(segments))
+	}
+
+	expected := []struct {
+		kind     string
+		end      time.Time
+		exceeded bool
+	}{
+		{SegmentCompleted, time.Date(2025, 6, 20, 6, 30, 0, 0, time.UTC), true},
+		{SegmentGap, time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC), false},
+		{SegmentActive, now, false},
+	}
+	for i, e := range expected {
+		if segments[i].Kind != e.kind || !segments[i].End.Equal(e.end) || segments[i].Exceeded != e.exceeded {
+			t.Errorf("segments[%d] = %+v, expected %s ending %v exceeded=%v", i, segments[i], e.kind, e.end, e.exceeded)
+		}
+	}
+	if segments[2].Percentage != 40 {
+		t.Errorf("active Percentage = %.1f, expected 40", segments[2].Percentage)
+	}
+}
+
+func TestRenderBlocks(t *testing.T) {
+	since := time.Date(2025, 6, 19, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 6, 20, 15, 0, 0, 0, time.UTC)
+	segments := []TimelineSegment{
+		{Kind: SegmentCompleted, Start: time.Date(2025, 6, 20, 4, 0, 0, 0, time.UTC), End: time.Date(2025, 6, 20, 6, 0, 0, 0, time.UTC), Tokens: 12000, Percentage: 120, Exceeded: true},
+		{Kind: SegmentGap, Start: time.Date(2025, 6, 20, 6, 0, 0, 0, time.UTC), End: time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)},
+		{Kind: SegmentActive, Start: time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC), End: now, Tokens: 4000, Percentage: 40},
+	}
+
+	output := string(stripANSI([]byte(NewDisplay("UTC").RenderBlocks(segments, 10000, since, now))))
+	lines := strings.Split(output, "\n")
+
+	var today string
+	for _, line := range lines {
+		if strings.HasPrefix(line, "Fri 06-20") {
+			today = line
+		}
+	}
+	// 04:00-06:00 over the limit, a gap until 12:00, then active until 15:00
+	strip := strings.Repeat(" ", 8) + strings.Repeat("█", 4) + strings.Repeat("·", 12) + strings.Repeat("█", 6) + strings.Repeat(" ", 18)
+	if !strings.Contains(today, "│"+strip+"│") || !strings.HasSuffix(today, "16,000") {
+		t.Errorf("today's strip = %q, expected %q and 16,000 tokens", today, strip)
+	}
+	for _, expected := range []string{"Thu 06-19", "06-20 04:00-06:00  completed", "120.0%  exceeded", "gap 6h", "active"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("RenderBlocks() missing %q:\n%s", expected, output)
+		}
+	}
+}
+
+func TestTimelineCellStartDST(t *testing.T) {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	// Daylight saving time starts on 2025-03-09, a 23-hour day
+	day := time.Date(2025, 3, 9, 0, 0, 0, 0, location)
+	tests := []struct {
+		cell     int
+		expected string
+	}{
+		{0, "00:00"},
+		{20, "10:00"},
+		{47, "23:30"},
+	}
+
+	for _, tt := range tests {
+		if got := timelineCellStart(day, tt.cell).Format(TimeFormatShort); got != tt.expected {
+			t.Errorf("timelineCellStart(%d) = %s, expected %s", tt.cell, got, tt.expected)
+		}
+	}
+	if next := timelineCellStart(day, TimelineCells); !next.Equal(day.AddDate(0, 0, 1)) {
+		t.Errorf("timelineCellStart(%d) = %v, expected the next midnight", TimelineCells, next)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// BreakConfig holds break reminder configuration
+type BreakConfig struct {
+	Enabled bool
+	Points  []float64 // Session progress percentages that trigger a reminder
+}
+
+// BreakReminder suggests breaks when session time progress crosses configured points
+type BreakReminder struct {
+	points       []float64
+	sessionStart time.Time
+	lastProgress float64
+	message      string
+	shownAt      time.Time
+	send         func(title, message string) error // nil disables desktop notifications
+}
+
+// NewBreakReminder creates a reminder, optionally sending desktop notifications
+func NewBreakReminder(cfg BreakConfig, notify bool) *BreakReminder {
+	b := &BreakReminder{points: cfg.Points}
+	if notify {
+		b.send = sendDesktopNotification
+	}
+	return b
+}
+
+// Check records progress and returns the reminder to display, if any
+func (b *BreakReminder) Check(session *Session, currentTime time.Time) string {
+	if !session.StartTime.Equal(b.sessionStart) {
+		b.sessionStart = session.StartTime
+		b.lastProgress = session.Metrics.Time.ProgressPercentage
+		b.message = ""
+		return ""
+	}
+
+	progress := session.Metrics.Time.ProgressPercentage
+	for _, point := range b.points {
+		if b.lastProgress < point && progress >= point {
+			b.message = breakMessage(point)
+			b.shownAt = currentTime
+			if b.send != nil {
+				_ = b.send("cctop", b.message)
+			}
+		}
+	}
+	b.lastProgress = progress
+
+	if b.message != "" && currentTime.Sub(b.shownAt) < BreakReminderDuration {
+		return b.message
+	}
+	return ""
+}
+
+// breakMessage builds the reminder text for a progress point
+func breakMessage(point float64) string {
+	if point >= 100 {
+		return "Session window complete. Time for a proper break!"
+	}
+	return fmt.Sprintf("%.0f%% through the session. Stand up and stretch for a few minutes.", point)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreakReminder(t *testing.T) {
+	var sent []string
+	b := NewBreakReminder(BreakConfig{Enabled: true, Points: []float64{50, 100}}, false)
+	b.send = func(title, message string) error {
+		sent = append(sent, message)
+		return nil
+	}
+
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	session := newTestSession(start, 1000, 7000)
+	check := func(elapsed time.Duration) string {
+		session.Metrics.Time = session.calculateTimeMetrics(start.Add(elapsed))
+		return b.Check(session, start.Add(elapsed))
+	}
+
+	// Starting mid-session does not fire for points already passed
+	if msg := check(3 * time.Hour); msg != "" {
+		t.Errorf("first check = %q, expected no reminder", msg)
+	}
+
+	b.sessionStart = time.Time{}
+	check(2 * time.Hour)
+	if msg := check(2*time.Hour + 31*time.Minute); msg == "" {
+		t.Error("expected reminder after crossing 50%")
+	}
+	if len(sent) != 1 {
+		t.Errorf("sent %d notifications, expected 1", len(sent))
+	}
+
+	// Reminder disappears after BreakReminderDuration
+	if msg := check(2*time.Hour + 31*time.Minute + BreakReminderDuration); msg != "" {
+		t.Errorf("reminder = %q, expected it to expire", msg)
+	}
+}
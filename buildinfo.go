@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var versionJSON bool
+
+// BuildInfo identifies a cctop binary and how it was built
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	BuiltBy   string `json:"builtBy"`
+	Modified  bool   `json:"modified"` // Built from a tree with uncommitted changes
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"`
+	Path      string `json:"path,omitempty"`
+}
+
+// currentBuildInfo combines the -ldflags metadata with what the Go toolchain embedded,
+// so binaries from go install and plain go build are identifiable too
+func currentBuildInfo() BuildInfo {
+	info, _ := debug.ReadBuildInfo()
+	build := buildInfoFrom(version, commit, date, builtBy, info)
+	build.Path, _ = os.Executable()
+	return build
+}
+
+// buildInfoFrom fills in the fields -ldflags left at their defaults from the Go build info
+func buildInfoFrom(version, commit, date, builtBy string, info *debug.BuildInfo) BuildInfo {
+	build := BuildInfo{
+		Version:   version,
+		Commit:    commit,
+		Date:      date,
+		BuiltBy:   builtBy,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if info == nil {
+		return build
+	}
+
+	if build.Version == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		build.Version = info.Main.Version
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if build.Commit == "none" {
+				build.Commit = setting.Value
+			}
+		case "vcs.time":
+			if build.Date == "unknown" {
+				build.Date = setting.Value
+			}
+		case "vcs.modified":
+			build.Modified = setting.Value == "true"
+		}
+	}
+	if build.BuiltBy == "" {
+		build.BuiltBy = "go" // Plain go build or go install
+	}
+	return build
+}
+
+// String formats the build info on one line
+func (b BuildInfo) String() string {
+	commit := b.Commit
+	if b.Modified {
+		commit += "-dirty"
+	}
+	return fmt.Sprintf("%s (commit %s, built %s by %s, %s %s)", b.Version, commit, b.Date, b.BuiltBy, b.GoVersion, b.Platform)
+}
+
+// runVersion prints the build info, as JSON with --json
+func runVersion(cmd *cobra.Command, args []string) {
+	build := currentBuildInfo()
+	if !versionJSON {
+		fmt.Printf("cctop %s\n", build)
+		return
+	}
+	output, err := json.MarshalIndent(build, "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println(string(output))
+}
+
+// checkBuildInfo verifies the binary is identifiable and the only cctop on PATH,
+// since a stale copy from another install channel can shadow an upgrade
+func checkBuildInfo(build BuildInfo, onPath []string) DoctorCheck {
+	check := DoctorCheck{Name: "cctop build", OK: true, Detail: build.String()}
+	switch {
+	case build.Version == "dev" && build.Commit == "none":
+		check.OK = false
+		check.Detail = "no version or commit embedded, this binary cannot be identified"
+		check.Fix = "build with `make build` or install with `go install github.com/Sixeight/cctop@latest`"
+	case build.Modified:
+		check.OK = false
+		check.Detail += ", built from uncommitted changes"
+		check.Fix = "rebuild from a clean checkout before reporting issues"
+	}
+
+	var others []string
+	for _, path := range onPath {
+		if !sameFile(path, build.Path) {
+			others = append(others, path)
+		}
+	}
+	if len(others) > 0 {
+		check.OK = false
+		check.Detail += fmt.Sprintf(", other cctop binaries on PATH: %s", strings.Join(others, ", "))
+		check.Fix = "remove the copies you no longer use, or compare them with `cctop version --json`"
+	}
+	return check
+}
+
+// cctopBinariesOnPath lists every cctop executable on PATH
+func cctopBinariesOnPath() []string {
+	name := "cctop"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	var paths []string
+	seen := make(map[string]bool)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		path := filepath.Join(dir, name)
+		if resolved, err := exec.LookPath(path); err == nil && !seen[resolved] {
+			seen[resolved] = true
+			paths = append(paths, resolved)
+		}
+	}
+	return paths
+}
+
+// sameFile reports whether two paths refer to the same file, following symlinks
+func sameFile(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestBuildInfoFrom(t *testing.T) {
+	toolchain := &debug.BuildInfo{
+		Main: debug.Module{Version: "v1.4.0"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "abc123"},
+			{Key: "vcs.time", Value: "2025-07-01T10:00:00Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		build    BuildInfo
+		expected BuildInfo
+	}{
+		{
+			name:     "ldflags win",
+			build:    buildInfoFrom("1.5.0", "def456", "2025-08-01T00:00:00Z", "goreleaser", toolchain),
+			expected: BuildInfo{Version: "1.5.0", Commit: "def456", Date: "2025-08-01T00:00:00Z", BuiltBy: "goreleaser", Modified: true},
+		},
+		{
+			name:     "go install",
+			build:    buildInfoFrom("dev", "none", "unknown", "", toolchain),
+			expected: BuildInfo{Version: "v1.4.0", Commit: "abc123", Date: "2025-07-01T10:00:00Z", BuiltBy: "go", Modified: true},
+		},
+		{
+			name:     "no toolchain info",
+			build:    buildInfoFrom("dev", "none", "unknown", "", nil),
+			expected: BuildInfo{Version: "dev", Commit: "none", Date: "unknown"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.build
+			got.GoVersion, got.Platform = "", ""
+			if got != tt.expected {
+				t.Errorf("buildInfoFrom() = %+v, expected %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckBuildInfo(t *testing.T) {
+	dir := t.TempDir()
+	self := filepath.Join(dir, "cctop")
+	other := filepath.Join(dir, "old-cctop")
+	for _, path := range []string{self, other} {
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	build := BuildInfo{Version: "1.5.0", Commit: "def456", Date: "2025-08-01", BuiltBy: "nix", Path: self}
+
+	if check := checkBuildInfo(build, []string{self}); !check.OK {
+		t.Errorf("checkBuildInfo() = %+v, expected OK for the only binary", check)
+	}
+
+	check := checkBuildInfo(build, []string{other, self})
+	if check.OK || !strings.Contains(check.Detail, other) {
+		t.Errorf("checkBuildInfo() = %+v, expected a failure naming %s", check, other)
+	}
+
+	unknown := BuildInfo{Version: "dev", Commit: "none", Path: self}
+	if check := checkBuildInfo(unknown, []string{self}); check.OK || check.Fix == "" {
+		t.Errorf("checkBuildInfo() for an unidentifiable binary = %+v, expected a failure with a fix", check)
+	}
+}
@@ -1,28 +1,93 @@
 package main
 
 import (
+	"math"
+	"sort"
+	"sync"
 	"time"
 )
 
 // BurnRateCalculator calculates token burn rate over a time window
 type BurnRateCalculator struct {
-	window time.Duration
+	window   time.Duration
+	messages func(startTime, endTime string) ([]TimedTokens, error) // Optional, see UseMessages
+	mu       sync.Mutex
+	timed    map[string][]TimedTokens // Messages by block start, nil without messages; read at timedAt
+	timedAt  time.Time
+}
+
+// WindowBurnRate is the burn rate averaged over one window
+type WindowBurnRate struct {
+	Window time.Duration `json:"-"`
+	Label  string        `json:"window"` // e.g. "5m", "1h"
+	Rate   float64       `json:"rate"`   // Tokens per minute
 }
 
 // NewBurnRateCalculator creates a new calculator with a 1-hour window
 func NewBurnRateCalculator() *BurnRateCalculator {
 	return &BurnRateCalculator{
-		window: time.Hour,
+		window: BurnRateWindow,
 	}
 }
 
+// SetWindow changes the window of Calculate and CalculateCost
+func (b *BurnRateCalculator) SetWindow(window time.Duration) {
+	b.window = window
+}
+
+// UseMessages makes the calculator apportion block tokens by the timestamps of the
+// block's messages in the Claude logs, instead of uniformly over the block's duration.
+// Blocks without readable messages still use the uniform share.
+func (b *BurnRateCalculator) UseMessages(reader *MessageTokenReader) {
+	b.messages = reader.GetBlockTimedTokens
+}
+
 // Calculate computes the burn rate in tokens per minute
 func (b *BurnRateCalculator) Calculate(blocks []Block, currentTime time.Time) float64 {
-	if len(blocks) == 0 {
+	return b.CalculateOver(blocks, currentTime, b.window)
+}
+
+// CalculateWindows computes the burn rate over each window, for showing short
+// bursts next to the longer average
+func (b *BurnRateCalculator) CalculateWindows(blocks []Block, currentTime time.Time, windows []time.Duration) []WindowBurnRate {
+	rates := make([]WindowBurnRate, 0, len(windows))
+	for _, window := range windows {
+		rates = append(rates, WindowBurnRate{
+			Window: window,
+			Label:  formatTime(window.Minutes()),
+			Rate:   b.CalculateOver(blocks, currentTime, window),
+		})
+	}
+	return rates
+}
+
+// useInstantRate makes the rate over InstantBurnWindow the monitor's instantaneous
+// rate, so the header shows one 5-minute figure from one calculation
+func useInstantRate(rates []WindowBurnRate, instant float64) {
+	for i := range rates {
+		if rates[i].Window == InstantBurnWindow {
+			rates[i].Rate = instant
+		}
+	}
+}
+
+// hasWindow reports whether rates include the window
+func hasWindow(rates []WindowBurnRate, window time.Duration) bool {
+	for _, rate := range rates {
+		if rate.Window == window {
+			return true
+		}
+	}
+	return false
+}
+
+// CalculateOver computes the burn rate in tokens per minute over the given window
+func (b *BurnRateCalculator) CalculateOver(blocks []Block, currentTime time.Time, window time.Duration) float64 {
+	if len(blocks) == 0 || window <= 0 {
 		return 0
 	}
 
-	windowStart := currentTime.Add(-b.window)
+	windowStart := currentTime.Add(-window)
 	totalTokens := 0.0
 
 	for _, block := range blocks {
@@ -30,28 +95,104 @@ func (b *BurnRateCalculator) Calculate(blocks []Block, currentTime time.Time) fl
 			continue
 		}
 
-		tokens := b.calculateBlockTokensInWindow(block, currentTime, windowStart)
+		tokens := float64(block.TotalTokens) * b.calculateBlockShareInWindow(block, currentTime, windowStart)
 		totalTokens += tokens
 	}
 
 	// Convert to tokens per minute
-	return totalTokens / b.window.Minutes()
+	return totalTokens / window.Minutes()
 }
 
-// calculateBlockTokensInWindow calculates tokens from a block within the time window
-func (b *BurnRateCalculator) calculateBlockTokensInWindow(block Block, windowEnd, windowStart time.Time) float64 {
+// CalculateCost computes the cost burn rate in USD per hour
+func (b *BurnRateCalculator) CalculateCost(blocks []Block, currentTime time.Time) float64 {
+	windowStart := currentTime.Add(-b.window)
+	totalCost := 0.0
+
+	for _, block := range blocks {
+		if block.IsGap {
+			continue
+		}
+		totalCost += block.CostUSD * b.calculateBlockShareInWindow(block, currentTime, windowStart)
+	}
+
+	return totalCost / b.window.Hours()
+}
+
+// BurnBand is the spread of burn rates over the slices of the burn window, in tokens per minute
+type BurnBand struct {
+	P10 float64 `json:"p10"`
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+}
+
+// CalculateBand computes the burn rate over each of BurnBandSlices consecutive slices of
+// the burn window and returns their spread. It reports false when no slice saw any tokens.
+func (b *BurnRateCalculator) CalculateBand(blocks []Block, currentTime time.Time) (BurnBand, bool) {
+	slice := b.window / BurnBandSlices
+	if slice <= 0 {
+		return BurnBand{}, false
+	}
+
+	rates := make([]float64, 0, BurnBandSlices)
+	for i := 0; i < BurnBandSlices; i++ {
+		sliceEnd := currentTime.Add(-time.Duration(i) * slice)
+		sliceStart := sliceEnd.Add(-slice)
+		tokens := 0.0
+		for _, block := range blocks {
+			if block.IsGap {
+				continue
+			}
+			tokens += float64(block.TotalTokens) * b.blockShare(block, currentTime, sliceStart, sliceEnd)
+		}
+		rates = append(rates, tokens/slice.Minutes())
+	}
+
+	sort.Float64s(rates)
+	if rates[len(rates)-1] == 0 {
+		return BurnBand{}, false
+	}
+	return BurnBand{
+		P10: percentileOfSortedFloats(rates, 10),
+		P50: percentileOfSortedFloats(rates, 50),
+		P90: percentileOfSortedFloats(rates, 90),
+	}, true
+}
+
+// percentileOfSortedFloats returns the nearest-rank percentile of sorted values
+func percentileOfSortedFloats(sorted []float64, percentile float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := int(math.Ceil(float64(len(sorted))*percentile/100.0)) - 1
+	return sorted[clampInt(index, 0, len(sorted)-1)]
+}
+
+// calculateBlockShareInWindow calculates the fraction of a block that falls within the time window
+func (b *BurnRateCalculator) calculateBlockShareInWindow(block Block, windowEnd, windowStart time.Time) float64 {
+	return b.blockShare(block, windowEnd, windowStart, windowEnd)
+}
+
+// blockShare calculates the fraction of a block, as observed at currentTime, that falls
+// within [windowStart, windowEnd]
+func (b *BurnRateCalculator) blockShare(block Block, currentTime, windowStart, windowEnd time.Time) float64 {
 	blockStart, err := time.Parse(time.RFC3339, block.StartTime)
 	if err != nil {
 		return 0
 	}
 
-	blockEnd := b.getBlockEndTime(block, windowEnd)
+	blockEnd := b.getBlockEndTime(block, currentTime)
 
 	// Check if block is outside the window
 	if blockEnd.Before(windowStart) {
 		return 0
 	}
 
+	if b.messages != nil {
+		if share, ok := b.messageShareInWindow(block, blockEnd, windowStart, windowEnd, currentTime); ok {
+			return share
+		}
+	}
+
 	// Calculate overlap with window
 	overlapStart := maxTime(blockStart, windowStart)
 	overlapEnd := minTime(blockEnd, windowEnd)
@@ -60,17 +201,47 @@ func (b *BurnRateCalculator) calculateBlockTokensInWindow(block Block, windowEnd
 		return 0
 	}
 
-	// Calculate portion of tokens in the window
+	// Calculate portion of the block in the window
 	totalDuration := blockEnd.Sub(blockStart).Minutes()
 	overlapDuration := overlapEnd.Sub(overlapStart).Minutes()
 
 	if totalDuration > 0 {
-		return float64(block.TotalTokens) * (overlapDuration / totalDuration)
+		return overlapDuration / totalDuration
 	}
 
 	return 0
 }
 
+// messageShareInWindow returns the fraction of the block's message tokens sent within
+// the window. Messages are cached per current time, so several windows, the band
+// slices and the cost rate share a single read of the logs.
+func (b *BurnRateCalculator) messageShareInWindow(block Block, blockEnd, windowStart, windowEnd, currentTime time.Time) (float64, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.timedAt.Equal(currentTime) {
+		b.timed = make(map[string][]TimedTokens)
+		b.timedAt = currentTime
+	}
+	timed, ok := b.timed[block.StartTime]
+	if !ok {
+		timed, _ = b.messages(block.StartTime, blockEnd.Format(time.RFC3339))
+		b.timed[block.StartTime] = timed
+	}
+	if len(timed) == 0 {
+		return 0, false // No messages, use the uniform share
+	}
+
+	total, inWindow := 0, 0
+	for _, message := range timed {
+		total += message.Tokens
+		if !message.Time.Before(windowStart) && !message.Time.After(windowEnd) {
+			inWindow += message.Tokens
+		}
+	}
+	return float64(inWindow) / float64(total), true
+}
+
 // getBlockEndTime determines the end time of a block
 func (b *BurnRateCalculator) getBlockEndTime(block Block, currentTime time.Time) time.Time {
 	if block.IsActive {
@@ -87,3 +258,52 @@ func (b *BurnRateCalculator) getBlockEndTime(block Block, currentTime time.Time)
 	// Fallback to current time if no end time is available
 	return currentTime
 }
+
+// BurnRates holds short-term burn rates from the monitor's own usage samples
+type BurnRates struct {
+	Instant  float64 `json:"instant"`  // Tokens per minute over the last InstantBurnWindow
+	Smoothed float64 `json:"smoothed"` // Exponentially weighted tokens per minute
+}
+
+// EWMABurnRate smooths the burn rate between refreshes with an exponentially
+// weighted moving average, reacting to bursts faster than the 1-hour window
+type EWMABurnRate struct {
+	HalfLife time.Duration // Age at which a rate's weight halves
+}
+
+// Calculate returns the instantaneous and smoothed rates from samples, oldest first.
+// It reports false until there are two samples to compare.
+func (c EWMABurnRate) Calculate(samples []usageSample, currentTime time.Time) (BurnRates, bool) {
+	if len(samples) < 2 {
+		return BurnRates{}, false
+	}
+
+	var rates BurnRates
+	for i := 1; i < len(samples); i++ {
+		elapsed := samples[i].Time.Sub(samples[i-1].Time)
+		if elapsed <= 0 {
+			continue
+		}
+		rate := math.Max(0, float64(samples[i].Tokens-samples[i-1].Tokens)) / elapsed.Minutes()
+		if i == 1 || c.HalfLife <= 0 {
+			rates.Smoothed = rate
+			continue
+		}
+		alpha := 1 - math.Pow(0.5, elapsed.Minutes()/c.HalfLife.Minutes())
+		rates.Smoothed = alpha*rate + (1-alpha)*rates.Smoothed
+	}
+
+	// Measure from the last sample at or before the window start, so the rate covers the whole window
+	last := samples[len(samples)-1]
+	base := samples[0]
+	for _, sample := range samples[:len(samples)-1] {
+		if sample.Time.After(currentTime.Add(-InstantBurnWindow)) {
+			break
+		}
+		base = sample
+	}
+	if elapsed := last.Time.Sub(base.Time); elapsed > 0 {
+		rates.Instant = math.Max(0, float64(last.Tokens-base.Tokens)) / elapsed.Minutes()
+	}
+	return rates, true
+}
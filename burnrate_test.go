@@ -0,0 +1,208 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestEWMABurnRate(t *testing.T) {
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	sample := func(minute, tokens int) usageSample {
+		return usageSample{Time: start.Add(time.Duration(minute) * time.Minute), Tokens: tokens}
+	}
+	calculator := EWMABurnRate{HalfLife: 10 * time.Minute}
+
+	if _, ok := calculator.Calculate([]usageSample{sample(0, 0)}, start); ok {
+		t.Error("Calculate() with one sample reported rates, expected none")
+	}
+
+	tests := []struct {
+		name             string
+		samples          []usageSample
+		expectedInstant  float64
+		expectedSmoothed float64
+	}{
+		{
+			name:             "Steady usage",
+			samples:          []usageSample{sample(0, 0), sample(10, 1000), sample(20, 2000), sample(30, 3000)},
+			expectedInstant:  100,
+			expectedSmoothed: 100,
+		},
+		{
+			name:             "Burst in the last five minutes",
+			samples:          []usageSample{sample(0, 0), sample(10, 1000), sample(20, 2000), sample(25, 2500), sample(30, 7500)},
+			expectedInstant:  1000,
+			expectedSmoothed: 100 + (1000-100)*(1-math.Pow(0.5, 0.5)), // Half a half-life of the burst
+		},
+		{
+			name:             "Idle after usage",
+			samples:          []usageSample{sample(0, 0), sample(10, 1000), sample(20, 1000), sample(30, 1000)},
+			expectedInstant:  0,
+			expectedSmoothed: 25, // 100 halved twice
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rates, ok := calculator.Calculate(tt.samples, tt.samples[len(tt.samples)-1].Time)
+			if !ok {
+				t.Fatal("Calculate() reported no rates")
+			}
+			if math.Abs(rates.Instant-tt.expectedInstant) > 0.01 {
+				t.Errorf("Instant = %.2f, expected %.2f", rates.Instant, tt.expectedInstant)
+			}
+			if math.Abs(rates.Smoothed-tt.expectedSmoothed) > 0.01 {
+				t.Errorf("Smoothed = %.2f, expected %.2f", rates.Smoothed, tt.expectedSmoothed)
+			}
+		})
+	}
+}
+
+func TestBurnRateFromMessages(t *testing.T) {
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	now := start.Add(2 * time.Hour)
+	block := Block{StartTime: start.Format(time.RFC3339), TotalTokens: 12000, IsActive: true}
+
+	// Uniform: half of the two hour block falls in the last hour
+	uniform := NewBurnRateCalculator()
+	if rate := uniform.Calculate([]Block{block}, now); rate != 100 {
+		t.Errorf("uniform Calculate() = %.2f, expected 100", rate)
+	}
+
+	// Messages: a quarter of the tokens were sent in the last hour
+	var reads int
+	timed := NewBurnRateCalculator()
+	timed.messages = func(startTime, endTime string) ([]TimedTokens, error) {
+		reads++
+		return []TimedTokens{
+			{Time: start.Add(10 * time.Minute), Tokens: 3000},
+			{Time: start.Add(50 * time.Minute), Tokens: 3000},
+			{Time: start.Add(90 * time.Minute), Tokens: 2000},
+		}, nil
+	}
+	if rate := timed.Calculate([]Block{block}, now); rate != 50 {
+		t.Errorf("message Calculate() = %.2f, expected 50", rate)
+	}
+	timed.CalculateCost([]Block{block}, now)
+	if reads != 1 {
+		t.Errorf("read messages %d times for one refresh, expected 1", reads)
+	}
+
+	// No messages in the logs: fall back to the uniform share
+	timed.messages = func(startTime, endTime string) ([]TimedTokens, error) { return nil, nil }
+	if rate := timed.Calculate([]Block{block}, now.Add(time.Second)); math.Abs(rate-100) > 1 {
+		t.Errorf("Calculate() without messages = %.2f, expected about 100", rate)
+	}
+}
+
+func TestCalculateWindows(t *testing.T) {
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	now := start.Add(2 * time.Hour)
+	block := Block{StartTime: start.Format(time.RFC3339), TotalTokens: 6000, IsActive: true}
+
+	var reads int
+	calc := NewBurnRateCalculator()
+	calc.messages = func(startTime, endTime string) ([]TimedTokens, error) {
+		reads++
+		return []TimedTokens{
+			{Time: now.Add(-30 * time.Minute), Tokens: 5000},
+			{Time: now.Add(-2 * time.Minute), Tokens: 1000},
+		}, nil
+	}
+
+	rates := calc.CalculateWindows([]Block{block}, now, []time.Duration{5 * time.Minute, time.Hour})
+	expected := []WindowBurnRate{
+		{Window: 5 * time.Minute, Label: "5m", Rate: 200},
+		{Window: time.Hour, Label: "1h", Rate: 100},
+	}
+	if len(rates) != len(expected) {
+		t.Fatalf("CalculateWindows() = %+v, expected %+v", rates, expected)
+	}
+	for i := range expected {
+		if rates[i] != expected[i] {
+			t.Errorf("CalculateWindows()[%d] = %+v, expected %+v", i, rates[i], expected[i])
+		}
+	}
+	if reads != 1 {
+		t.Errorf("read messages %d times for two windows, expected 1", reads)
+	}
+
+	// A shorter main window reacts to the burst
+	calc.SetWindow(15 * time.Minute)
+	if rate := calc.Calculate([]Block{block}, now); math.Abs(rate-1000.0/15) > 0.01 {
+		t.Errorf("Calculate() over 15m = %.2f, expected %.2f", rate, 1000.0/15)
+	}
+
+	// The monitor's instantaneous rate replaces the 5m window's, leaving others alone
+	useInstantRate(rates, 320)
+	if rates[0].Rate != 320 || rates[1].Rate != 100 {
+		t.Errorf("useInstantRate() = %+v, expected 5m at 320 and 1h at 100", rates)
+	}
+	if !hasWindow(rates, InstantBurnWindow) || hasWindow(rates, 15*time.Minute) {
+		t.Errorf("hasWindow() wrong for %+v", rates)
+	}
+}
+
+func TestCalculateBand(t *testing.T) {
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	now := start.Add(2 * time.Hour)
+	block := Block{StartTime: start.Format(time.RFC3339), TotalTokens: 5000, IsActive: true}
+
+	calc := NewBurnRateCalculator()
+	calc.messages = func(startTime, endTime string) ([]TimedTokens, error) {
+		return []TimedTokens{
+			{Time: now.Add(-12 * time.Minute), Tokens: 1000},
+			{Time: now.Add(-7 * time.Minute), Tokens: 1000},
+			{Time: now.Add(-2 * time.Minute), Tokens: 3000},
+		}, nil
+	}
+
+	// Twelve 5-minute slices: nine idle, two at 200/min and one at 600/min
+	band, ok := calc.CalculateBand([]Block{block}, now)
+	expected := BurnBand{P10: 0, P50: 0, P90: 200}
+	if !ok || band != expected {
+		t.Errorf("CalculateBand() = %+v, %v, expected %+v, true", band, ok, expected)
+	}
+
+	if _, ok := calc.CalculateBand(nil, now); ok {
+		t.Errorf("CalculateBand() without blocks reported a band")
+	}
+}
+
+func TestPredictedEndRange(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	now := time.Now()
+	session := newTestSession(start, 4000, 10000)
+	session.BurnRate = 10 // 600 minutes to depletion, after the reset
+
+	predicted := session.PredictedEndRange(now)
+	if !predicted.Start.Equal(predicted.End) || session.GetStatus() != "OK" {
+		t.Errorf("without a band, range = %v and status %s, expected a single OK estimate", predicted, session.GetStatus())
+	}
+
+	// Bursts of 100/min would deplete the remaining 6,000 tokens in an hour
+	session.BurnBand = &BurnBand{P10: 0, P50: 10, P90: 100}
+	predicted = session.PredictedEndRange(now)
+	if expected := now.Add(time.Hour); !predicted.Start.Equal(expected) {
+		t.Errorf("earliest depletion = %v, expected %v", predicted.Start, expected)
+	}
+	if !predicted.End.Equal(session.EndTime) {
+		t.Errorf("latest depletion = %v, expected the reset %v", predicted.End, session.EndTime)
+	}
+	if status := session.GetStatus(); status != "WARNING" {
+		t.Errorf("GetStatus() = %s, expected WARNING from the pessimistic bound", status)
+	}
+}
+
+func TestFormatTimeRange(t *testing.T) {
+	display := NewDisplay("UTC")
+	from := time.Date(2025, 6, 20, 14, 5, 0, 0, time.UTC)
+
+	if got := display.formatTimeRange(Interval{Start: from, End: from.Add(75 * time.Minute)}); got != "14:05–15:20" {
+		t.Errorf("formatTimeRange() = %q, expected %q", got, "14:05–15:20")
+	}
+	if got := display.formatTimeRange(Interval{Start: from, End: from.Add(10 * time.Second)}); got != "14:05" {
+		t.Errorf("formatTimeRange() = %q, expected %q", got, "14:05")
+	}
+}
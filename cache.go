@@ -0,0 +1,46 @@
+package main
+
+// TokenCounts is the per-type token breakdown ccusage reports for a block
+type TokenCounts struct {
+	InputTokens              int `json:"inputTokens"`
+	OutputTokens             int `json:"outputTokens"`
+	CacheCreationInputTokens int `json:"cacheCreationInputTokens"`
+	CacheReadInputTokens     int `json:"cacheReadInputTokens"`
+}
+
+// CacheMetrics summarizes prompt cache usage in a session
+type CacheMetrics struct {
+	ReadTokens     int     `json:"readTokens"`
+	CreationTokens int     `json:"creationTokens"`
+	HitRatio       float64 `json:"hitRatio"` // Cache reads as a fraction of all input tokens
+}
+
+// Total returns the tokens a message counts towards the limit.
+// Cache tokens are weighted by the configured cache weight (0 excludes them).
+func (u TokenUsage) Total() int {
+	cached := u.CacheCreationInputTokens + u.CacheReadInputTokens
+	return u.InputTokens + u.OutputTokens + int(float64(cached)*cacheWeight())
+}
+
+// cacheWeight returns the configured weight of cache tokens in limit estimation.
+// It applies to token counts read from the JSONL logs only: block totals from
+// ccusage always include cache tokens in full.
+func cacheWeight() float64 {
+	if config == nil {
+		return 0
+	}
+	return config.CacheWeight
+}
+
+// calculateCacheMetrics computes the cache hit ratio from a block's token counts
+func calculateCacheMetrics(counts TokenCounts) CacheMetrics {
+	metrics := CacheMetrics{
+		ReadTokens:     counts.CacheReadInputTokens,
+		CreationTokens: counts.CacheCreationInputTokens,
+	}
+	totalInput := counts.InputTokens + counts.CacheCreationInputTokens + counts.CacheReadInputTokens
+	if totalInput > 0 {
+		metrics.HitRatio = float64(counts.CacheReadInputTokens) / float64(totalInput)
+	}
+	return metrics
+}
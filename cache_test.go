@@ -0,0 +1,43 @@
+package main
+
+import "testing"
+
+func TestTokenUsageTotal(t *testing.T) {
+	oldConfig := config
+	defer func() { config = oldConfig }()
+	config = NewConfig()
+
+	usage := TokenUsage{InputTokens: 10, OutputTokens: 90, CacheCreationInputTokens: 200, CacheReadInputTokens: 800}
+
+	tests := []struct {
+		weight   float64
+		expected int
+	}{
+		{weight: 0, expected: 100},
+		{weight: 0.1, expected: 200},
+		{weight: 1, expected: 1100},
+	}
+
+	for _, tt := range tests {
+		config.CacheWeight = tt.weight
+		if result := usage.Total(); result != tt.expected {
+			t.Errorf("Total() with weight %v = %d, expected %d", tt.weight, result, tt.expected)
+		}
+	}
+}
+
+func TestCalculateCacheMetrics(t *testing.T) {
+	metrics := calculateCacheMetrics(TokenCounts{
+		InputTokens:              100,
+		OutputTokens:             5000,
+		CacheCreationInputTokens: 100,
+		CacheReadInputTokens:     800,
+	})
+	if metrics.HitRatio != 0.8 || metrics.ReadTokens != 800 || metrics.CreationTokens != 100 {
+		t.Errorf("calculateCacheMetrics() = %+v, expected 80%% hit ratio", metrics)
+	}
+
+	if metrics := calculateCacheMetrics(TokenCounts{}); metrics.HitRatio != 0 {
+		t.Errorf("calculateCacheMetrics() without input = %+v, expected 0 hit ratio", metrics)
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// CastRecorder writes what the monitor shows to an asciinema v2 cast file
+// (https://docs.asciinema.org/manual/asciicast/v2/), so `asciinema play` replays it
+// frame by frame. Events are written as they happen, so an interrupted recording
+// is still playable.
+type CastRecorder struct {
+	mu    sync.Mutex
+	file  *os.File
+	start time.Time
+	last  string // Last screen, unchanged redraws are skipped
+}
+
+var (
+	recordPath   string
+	castRecorder *CastRecorder // Nil unless --record is set
+)
+
+// castHeader is the first line of an asciinema v2 cast
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title"`
+	Env       map[string]string `json:"env"`
+}
+
+// NewCastRecorder creates the cast file with a header for a width x height terminal
+func NewCastRecorder(path string, width, height int, currentTime time.Time) (*CastRecorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	header, _ := json.Marshal(castHeader{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: currentTime.Unix(),
+		Title:     "cctop " + currentTime.In(display.timezone).Format("2006-01-02 15:04"),
+		Env:       map[string]string{"TERM": os.Getenv("TERM"), "SHELL": os.Getenv("SHELL")},
+	})
+	if _, err := fmt.Fprintf(file, "%s\n", header); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &CastRecorder{file: file, start: currentTime}, nil
+}
+
+// terminalSize returns the size of the terminal on stdout, 80x24 when unknown
+func terminalSize() (int, int) {
+	width, height, err := term.GetSize(os.Stdout.Fd())
+	if err != nil || width <= 0 || height <= 0 {
+		return 80, 24
+	}
+	return width, height
+}
+
+// Screen records a full-screen redraw, as the interactive monitor draws its views
+func (r *CastRecorder) Screen(view string, currentTime time.Time) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if view == r.last {
+		return
+	}
+	r.last = view
+	r.write(currentTime, "o", clearScreen+castNewlines(view))
+}
+
+// Output records text appended to the screen, as the plain monitor prints refreshes
+func (r *CastRecorder) Output(text string, currentTime time.Time) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.write(currentTime, "o", castNewlines(text))
+}
+
+// Resize records a change of the terminal size
+func (r *CastRecorder) Resize(width, height int, currentTime time.Time) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.write(currentTime, "r", fmt.Sprintf("%dx%d", width, height))
+}
+
+// Close finishes the recording
+func (r *CastRecorder) Close() error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+// write appends an event line [seconds, type, data]. After a write error the
+// recording stops and the monitor carries on.
+func (r *CastRecorder) write(currentTime time.Time, kind, data string) {
+	if r.file == nil {
+		return
+	}
+	elapsed := max(currentTime.Sub(r.start).Round(time.Microsecond).Seconds(), 0)
+	line, _ := json.Marshal([]any{elapsed, kind, data})
+	if _, err := fmt.Fprintf(r.file, "%s\n", line); err != nil {
+		logger.Warnf("recording to %s stopped: %v", r.file.Name(), err)
+		r.file.Close()
+		r.file = nil
+	}
+}
+
+// castNewlines turns line feeds into CRLF, as a terminal in raw mode needs them
+func castNewlines(text string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\n", "\r\n")
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCastRecorder(t *testing.T) {
+	oldDisplay := display
+	defer func() { display = oldDisplay }()
+	display = NewDisplay("UTC")
+
+	path := filepath.Join(t.TempDir(), "out.cast")
+	start := time.Date(2025, 6, 20, 10, 0, 0, 0, time.UTC)
+	recorder, err := NewCastRecorder(path, 100, 30, start)
+	if err != nil {
+		t.Fatalf("NewCastRecorder() error = %v", err)
+	}
+	recorder.Screen("Tokens: 10%\nOK\n", start.Add(500*time.Millisecond))
+	recorder.Screen("Tokens: 10%\nOK\n", start.Add(time.Second)) // Unchanged, skipped
+	recorder.Resize(120, 40, start.Add(1500*time.Millisecond))
+	recorder.Screen("Tokens: 95%\nWARNING\n", start.Add(2*time.Second))
+	recorder.Output("--- 10:00:03 ---\n", start.Add(3*time.Second))
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+
+	scanner.Scan()
+	var header castHeader
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		t.Fatalf("invalid header %q: %v", scanner.Text(), err)
+	}
+	if header.Version != 2 || header.Width != 100 || header.Height != 30 || header.Timestamp != start.Unix() {
+		t.Errorf("header = %+v, expected version 2, 100x30 at %d", header, start.Unix())
+	}
+
+	expected := []struct {
+		time float64
+		kind string
+		data string
+	}{
+		{0.5, "o", "\x1b[H\x1b[2JTokens: 10%\r\nOK\r\n"},
+		{1.5, "r", "120x40"},
+		{2, "o", "\x1b[H\x1b[2JTokens: 95%\r\nWARNING\r\n"},
+		{3, "o", "--- 10:00:03 ---\r\n"},
+	}
+	var events [][3]any
+	for scanner.Scan() {
+		var event [3]any
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("invalid event %q: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("recorded %d events, expected %d: %v", len(events), len(expected), events)
+	}
+	for i, want := range expected {
+		if events[i][0] != want.time || events[i][1] != want.kind || events[i][2] != want.data {
+			t.Errorf("event %d = %q, expected [%v %q %q]", i, events[i], want.time, want.kind, want.data)
+		}
+	}
+}
+
+func TestCastRecorderNil(t *testing.T) {
+	// Without --record the monitor calls a nil recorder
+	var recorder *CastRecorder
+	recorder.Screen("frame", time.Now())
+	recorder.Output("line\n", time.Now())
+	recorder.Resize(80, 24, time.Now())
+	if err := recorder.Close(); err != nil {
+		t.Errorf("Close() on nil recorder = %v, expected nil", err)
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// CCUsageErrorKind is why a ccusage run failed
+type CCUsageErrorKind int
+
+// ccusage failure kinds
+const (
+	CCUsageFailed       CCUsageErrorKind = iota // Anything else, likely transient and retried
+	CCUsageNotInstalled                         // ccusage is not on PATH
+	CCUsageNoData                               // ccusage found no Claude data to report
+)
+
+// CCUsageError is a failed ccusage run with what ccusage printed to stderr, which
+// exec only keeps in the ExitError
+type CCUsageError struct {
+	Kind   CCUsageErrorKind
+	Args   []string
+	Stderr string
+	Err    error
+}
+
+// Error describes the failure with its cause
+func (e *CCUsageError) Error() string {
+	command := "ccusage " + strings.Join(e.Args, " ")
+	switch {
+	case e.Kind == CCUsageNotInstalled:
+		return "ccusage is not installed (not found in PATH)"
+	case e.Kind == CCUsageNoData:
+		return fmt.Sprintf("%s found no Claude usage data: %s", command, e.Stderr)
+	case e.Stderr != "":
+		return fmt.Sprintf("%s failed: %v: %s", command, e.Err, e.Stderr)
+	default:
+		return fmt.Sprintf("%s failed: %v", command, e.Err)
+	}
+}
+
+// Unwrap returns the underlying exec or JSON error
+func (e *CCUsageError) Unwrap() error {
+	return e.Err
+}
+
+// Fix suggests how to resolve the failure
+func (e *CCUsageError) Fix() string {
+	switch e.Kind {
+	case CCUsageNotInstalled:
+		return "install it with `npm install -g ccusage` (or `bun add -g ccusage`)"
+	case CCUsageNoData:
+		return "check that Claude Code has logged usage, or point --claude-dir at its config directory"
+	default:
+		return "run `cctop doctor` or `ccusage " + strings.Join(e.Args, " ") + "` to investigate"
+	}
+}
+
+// ccusageNoDataMessages are lowercase fragments of ccusage errors about missing Claude data
+var ccusageNoDataMessages = []string{"no valid claude data", "no claude data", "no usage data"}
+
+// newCCUsageError classifies a failed ccusage run
+func newCCUsageError(args []string, err error) *CCUsageError {
+	ccusageErr := &CCUsageError{Args: args, Err: err}
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(err, exec.ErrNotFound):
+		ccusageErr.Kind = CCUsageNotInstalled
+	case errors.As(err, &exitErr):
+		ccusageErr.Stderr = string(bytes.TrimSpace(exitErr.Stderr))
+		stderr := strings.ToLower(ccusageErr.Stderr)
+		for _, message := range ccusageNoDataMessages {
+			if strings.Contains(stderr, message) {
+				ccusageErr.Kind = CCUsageNoData
+			}
+		}
+	}
+	return ccusageErr
+}
+
+// retryableCCUsage reports whether running ccusage again may succeed
+func retryableCCUsage(err error) bool {
+	var ccusageErr *CCUsageError
+	return errors.As(err, &ccusageErr) && ccusageErr.Kind == CCUsageFailed
+}
+
+// ccusageRetry retries failed ccusage runs within one refresh
+var ccusageRetry = RetryPolicy{Attempts: CCUsageAttempts, Base: CCUsageRetryBase, Max: CCUsageRetryMax}
+
+// runCCUsage runs a ccusage command and returns its output, retrying failures that
+// are not about a missing installation or missing data
+func runCCUsage(args ...string) ([]byte, error) {
+	var output []byte
+	err := ccusageRetry.Do(func() error {
+		start := time.Now()
+		var err error
+		output, err = ccusageCommand(args...).Output()
+		logger.Debugf("ccusage %s: %d bytes in %s", strings.Join(args, " "), len(output), time.Since(start).Round(time.Millisecond))
+		if err != nil {
+			err = newCCUsageError(args, err)
+			logger.Debugf("%v", err)
+		}
+		return err
+	}, retryableCCUsage)
+	return output, err
+}
+
+// usageFailures counts consecutive refreshes that failed to get usage data
+var usageFailures atomic.Int64
+
+// recordUsageFetch counts a failed refresh towards the backoff, or resets it. Missing
+// data is not a failure: it is polled like an idle session.
+func recordUsageFetch(err error) {
+	var ccusageErr *CCUsageError
+	if err == nil || (errors.As(err, &ccusageErr) && ccusageErr.Kind == CCUsageNoData) {
+		usageFailures.Store(0)
+		return
+	}
+	usageFailures.Add(1)
+}
+
+// failureBackoff returns the wait before the next refresh after failed ones, doubling
+// from the refresh interval up to CCUsageBackoffMax, and false without failures
+func failureBackoff() (time.Duration, bool) {
+	failures := usageFailures.Load()
+	if failures == 0 {
+		return 0, false
+	}
+	policy := RetryPolicy{Base: config.UpdateInterval, Max: maxDuration(CCUsageBackoffMax, config.UpdateInterval)}
+	return policy.Delay(int(min(failures, 30))), true
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeCCUsage puts a ccusage shell script on PATH that logs each run to the returned file
+func fakeCCUsage(t *testing.T, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake ccusage is a shell script")
+	}
+	dir := t.TempDir()
+	runs := filepath.Join(dir, "runs")
+	script := "#!/bin/sh\necho run >> " + runs + "\n" + body
+	if err := os.WriteFile(filepath.Join(dir, "ccusage"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+	return runs
+}
+
+func TestRunCCUsage(t *testing.T) {
+	oldSleep := sleep
+	defer func() { sleep = oldSleep }()
+	sleep = func(time.Duration) {}
+
+	tests := []struct {
+		name     string
+		script   string
+		kind     CCUsageErrorKind
+		expected string // Error message, "" for success
+		runs     int
+	}{
+		{"success", "echo '{\"blocks\":[]}'\n", 0, "", 1},
+		{"no data", "echo 'Error: No valid Claude data directories found' >&2\nexit 1\n", CCUsageNoData,
+			"ccusage blocks --json found no Claude usage data: Error: No valid Claude data directories found", 1},
+		{"transient", "echo 'ENOMEM' >&2\nexit 1\n", CCUsageFailed, "ccusage blocks --json failed: exit status 1: ENOMEM", CCUsageAttempts},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs := fakeCCUsage(t, tt.script)
+			_, err := runCCUsage("blocks", "--json")
+
+			var ccusageErr *CCUsageError
+			switch {
+			case tt.expected == "" && err != nil:
+				t.Errorf("runCCUsage() error = %v, expected none", err)
+			case tt.expected != "" && (!errors.As(err, &ccusageErr) || ccusageErr.Kind != tt.kind || err.Error() != tt.expected):
+				t.Errorf("runCCUsage() error = %v, expected %q of kind %d", err, tt.expected, tt.kind)
+			}
+			data, _ := os.ReadFile(runs)
+			if got := strings.Count(string(data), "run"); got != tt.runs {
+				t.Errorf("ccusage ran %d times, expected %d", got, tt.runs)
+			}
+		})
+	}
+}
+
+func TestRunCCUsageNotInstalled(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	_, err := runCCUsage("blocks", "--json")
+	var ccusageErr *CCUsageError
+	if !errors.As(err, &ccusageErr) || ccusageErr.Kind != CCUsageNotInstalled || !strings.Contains(ccusageErr.Fix(), "npm install -g ccusage") {
+		t.Errorf("runCCUsage() error = %v, expected ccusage not installed with an install fix", err)
+	}
+}
+
+func TestFailureBackoff(t *testing.T) {
+	oldConfig := config
+	defer func() {
+		config = oldConfig
+		usageFailures.Store(0)
+	}()
+	config = NewConfig()
+	usageFailures.Store(0)
+
+	if _, failing := failureBackoff(); failing {
+		t.Error("failureBackoff() reports failing before any failure")
+	}
+
+	transient := &CCUsageError{Kind: CCUsageFailed, Err: errors.New("exit status 1")}
+	for failures := 1; failures <= 10; failures++ {
+		recordUsageFetch(transient)
+		backoff, failing := failureBackoff()
+		expected := min(config.UpdateInterval<<(failures-1), CCUsageBackoffMax)
+		if !failing || backoff < expected/2 || backoff > expected {
+			t.Errorf("failureBackoff() after %d failures = %v, expected %v to %v", failures, backoff, expected/2, expected)
+		}
+	}
+	if interval := pollInterval(nil, time.Now()); interval < CCUsageBackoffMax/2 {
+		t.Errorf("pollInterval() while failing = %v, expected the backoff", interval)
+	}
+
+	// Missing data is polled like an idle session, and a success resets the backoff
+	recordUsageFetch(&CCUsageError{Kind: CCUsageNoData})
+	if _, failing := failureBackoff(); failing {
+		t.Error("failureBackoff() reports failing after a no data error")
+	}
+	recordUsageFetch(transient)
+	recordUsageFetch(nil)
+	if _, failing := failureBackoff(); failing {
+		t.Error("failureBackoff() reports failing after a success")
+	}
+}
+
+func TestRenderCCUsageError(t *testing.T) {
+	defer usageFailures.Store(0)
+	display := NewDisplay("UTC")
+	err := &CCUsageError{Kind: CCUsageFailed, Args: []string{"blocks", "--json"}, Err: errors.New("exit status 1"), Stderr: "ENOMEM"}
+	usageFailures.Store(3)
+
+	got := string(stripANSI([]byte(display.RenderError(err))))
+	expected := "ccusage blocks --json failed: exit status 1: ENOMEM\n" +
+		"fix: run `cctop doctor` or `ccusage blocks --json` to investigate\n" +
+		"failed 3 times in a row, retrying less often (at most every 5m)\n"
+	if got != expected {
+		t.Errorf("RenderError() = %q, expected %q", got, expected)
+	}
+}
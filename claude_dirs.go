@@ -0,0 +1,66 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// defaultClaudeDirs returns the Claude config directories to read when no --claude-dir
+// is given. CLAUDE_CONFIG_DIR, a comma-separated list as understood by Claude Code and
+// ccusage, takes precedence over discovery. Discovered directories are merged, skipping
+// links to one already found, and ~/.config/claude is used when none exist yet.
+func defaultClaudeDirs() []string {
+	if env := os.Getenv("CLAUDE_CONFIG_DIR"); env != "" {
+		return splitClaudeConfigDir(env)
+	}
+
+	homeDir, _ := os.UserHomeDir()
+	candidates := claudeDirCandidates(homeDir, runtime.GOOS, os.Getenv("APPDATA"))
+
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, dir := range candidates {
+		info, err := os.Stat(filepath.Join(dir, "projects"))
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		resolved, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			resolved = dir
+		}
+		if !seen[resolved] {
+			seen[resolved] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	if len(dirs) == 0 {
+		return candidates[:1]
+	}
+	return dirs
+}
+
+// splitClaudeConfigDir parses a comma-separated CLAUDE_CONFIG_DIR value
+func splitClaudeConfigDir(value string) []string {
+	var dirs []string
+	for _, dir := range strings.Split(value, ",") {
+		if dir = strings.TrimSpace(dir); dir != "" {
+			dirs = append(dirs, expandHome(dir))
+		}
+	}
+	return dirs
+}
+
+// claudeDirCandidates lists where Claude Code keeps its data, in the order it is searched:
+// ~/.config/claude (current releases), ~/.claude (older ones) and %APPDATA%\claude on Windows
+func claudeDirCandidates(homeDir, goos, appData string) []string {
+	candidates := []string{
+		filepath.Join(homeDir, ".config", "claude"),
+		filepath.Join(homeDir, ".claude"),
+	}
+	if goos == "windows" && appData != "" {
+		candidates = append(candidates, filepath.Join(appData, "claude"))
+	}
+	return candidates
+}
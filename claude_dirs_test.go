@@ -0,0 +1,93 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestClaudeDirCandidates(t *testing.T) {
+	home := filepath.Join("home", "me")
+	appData := filepath.Join("C:", "Users", "me", "AppData", "Roaming")
+	unix := []string{filepath.Join(home, ".config", "claude"), filepath.Join(home, ".claude")}
+
+	tests := []struct {
+		name     string
+		goos     string
+		appData  string
+		expected []string
+	}{
+		{name: "Linux", goos: "linux", appData: appData, expected: unix},
+		{name: "Windows", goos: "windows", appData: appData, expected: append(unix, filepath.Join(appData, "claude"))},
+		{name: "Windows without APPDATA", goos: "windows", expected: unix},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := claudeDirCandidates(home, tt.goos, tt.appData); !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("claudeDirCandidates() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDefaultClaudeDirs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("CLAUDE_CONFIG_DIR", "")
+
+	// Nothing exists yet, so the current default is used
+	if result := defaultClaudeDirs(); !reflect.DeepEqual(result, []string{filepath.Join(home, ".config", "claude")}) {
+		t.Errorf("defaultClaudeDirs() = %v, expected ~/.config/claude", result)
+	}
+
+	// Only directories holding projects are returned
+	legacy := filepath.Join(home, ".claude")
+	if err := os.MkdirAll(filepath.Join(legacy, "projects"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if result := defaultClaudeDirs(); !reflect.DeepEqual(result, []string{legacy}) {
+		t.Errorf("defaultClaudeDirs() = %v, expected [%s]", result, legacy)
+	}
+}
+
+func TestDefaultClaudeDirsMerge(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("CLAUDE_CONFIG_DIR", "")
+
+	current := filepath.Join(home, ".config", "claude")
+	legacy := filepath.Join(home, ".claude")
+	for _, dir := range []string{current, legacy} {
+		if err := os.MkdirAll(filepath.Join(dir, "projects"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if result := defaultClaudeDirs(); !reflect.DeepEqual(result, []string{current, legacy}) {
+		t.Errorf("defaultClaudeDirs() = %v, expected both directories", result)
+	}
+
+	// A legacy directory linked to the current one is only read once
+	if err := os.RemoveAll(legacy); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(current, legacy); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	if result := defaultClaudeDirs(); !reflect.DeepEqual(result, []string{current}) {
+		t.Errorf("defaultClaudeDirs() with a linked legacy directory = %v, expected [%s]", result, current)
+	}
+}
+
+func TestDefaultClaudeDirsEnv(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("CLAUDE_CONFIG_DIR", "~/work-claude, /opt/claude")
+
+	expected := []string{filepath.Join(home, "work-claude"), "/opt/claude"}
+	if result := defaultClaudeDirs(); !reflect.DeepEqual(result, expected) {
+		t.Errorf("defaultClaudeDirs() = %v, expected %v", result, expected)
+	}
+}
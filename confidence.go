@@ -0,0 +1,98 @@
+package main
+
+import "math"
+
+// Confidence levels for the estimated limit
+const (
+	ConfidenceNone   = "none" // No completed session yet, the limit is a plan default guess
+	ConfidenceLow    = "low"
+	ConfidenceMedium = "medium"
+	ConfidenceHigh   = "high"
+	ConfidenceManual = "manual" // Set with --token-limit, not estimated
+)
+
+// Confidence describes how far the estimated limit can be trusted
+type Confidence struct {
+	Level       string  `json:"level"`       // none, low, medium or high
+	Uncertainty float64 `json:"uncertainty"` // Relative ± band around the limit (0.18 = ±18%)
+	Sessions    int     `json:"sessions"`    // Sessions the estimate is based on
+}
+
+// Confidence derives a confidence score from the same inputs as calculateDynamicWeight:
+// the number of sessions and how much their token totals vary
+func (e *TokenLimitEstimator) Confidence(blocks []Block) Confidence {
+	sessions, cv := sessionTokenStats(blocks)
+	result := Confidence{Level: ConfidenceLow, Sessions: sessions}
+
+	if !hasCompletedSession(blocks) {
+		result.Level = ConfidenceNone
+		result.Uncertainty = 1
+		return result
+	}
+	if sessions < MinHistoricalSessions {
+		result.Uncertainty = ColdStartUncertainty
+		if sessions > 0 {
+			result.Uncertainty /= math.Sqrt(float64(sessions))
+		}
+		return result
+	}
+
+	// 95% band of the mean session size
+	result.Uncertainty = ConfidenceZScore * cv / math.Sqrt(float64(sessions))
+
+	switch weight := e.calculateDynamicWeight(blocks); {
+	case weight >= WeightLargeSample:
+		result.Level = ConfidenceHigh
+	case weight >= WeightMediumSample:
+		result.Level = ConfidenceMedium
+	}
+	return result
+}
+
+// limitConfidence returns the confidence in the session limit: manual with --token-limit,
+// the estimator's otherwise
+func limitConfidence(blocks []Block) Confidence {
+	if config.TokenLimit > 0 {
+		return Confidence{Level: ConfidenceManual}
+	}
+	return estimator.Confidence(blocks)
+}
+
+// hasCompletedSession reports whether any non-gap block other than the active one has usage
+func hasCompletedSession(blocks []Block) bool {
+	for _, block := range blocks {
+		if !block.IsGap && !block.IsActive && block.TotalTokens > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// sessionTokenStats returns the number of non-gap sessions and the coefficient of variation of their tokens
+func sessionTokenStats(blocks []Block) (int, float64) {
+	var sessionTokens []int
+	for _, block := range blocks {
+		if !block.IsGap && block.TotalTokens > 0 {
+			sessionTokens = append(sessionTokens, block.TotalTokens)
+		}
+	}
+
+	if len(sessionTokens) < 2 {
+		return len(sessionTokens), 0
+	}
+
+	mean := 0
+	for _, v := range sessionTokens {
+		mean += v
+	}
+	mean /= len(sessionTokens)
+
+	variance := 0.0
+	for _, v := range sessionTokens {
+		diff := float64(v - mean)
+		variance += diff * diff
+	}
+	variance /= float64(len(sessionTokens))
+
+	return len(sessionTokens), math.Sqrt(variance) / float64(mean)
+}
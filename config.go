@@ -6,12 +6,79 @@ import (
 
 // Config holds all application configuration
 type Config struct {
-	TokenLimits    map[string]int
-	Plan           string
-	Timezone       string
-	Thresholds     ThresholdConfig
-	ProgressBar    ProgressBarConfig
-	UpdateInterval time.Duration
+	TokenLimits        map[string]int
+	Plan               string
+	Timezone           string
+	Thresholds         ThresholdConfig
+	ProgressBar        ProgressBarConfig
+	UpdateInterval     time.Duration   // Refresh interval while a session is active
+	FrameInterval      time.Duration   // Redraw interval of time-based values between refreshes
+	BurnHalfLife       time.Duration   // Half-life of the smoothed (EWMA) burn rate
+	BurnWindow         time.Duration   // Window of the burn rate used for depletion predictions
+	BurnWindows        []time.Duration // Windows whose burn rates are shown side by side
+	MessageBurnRate    bool            // Apportion block tokens to the burn rate window by message timestamps
+	IdleInterval       time.Duration   // Refresh interval when no session is active
+	Watch              bool            // Refresh when the Claude logs change instead of only polling
+	Archive            bool            // Keep every observed block and daily cost in the SQLite archive
+	ArchivePath        string          // SQLite database of the archive
+	DailyInterval      time.Duration   // Minimum time between ccusage daily fetches
+	LimitRefresh       time.Duration   // Interval between re-estimates of the token limit (0 = only when exceeded)
+	Experimental       []string        // Enabled experiments, see experiments
+	BillingAnchorDay   int             // Day of month the subscription renews
+	Currency           string          // ISO 4217 code costs are displayed in
+	CurrencyRate       float64         // Static units of Currency per USD (0 = fetch ECB rates)
+	CostMultiplier     float64         // Applied to displayed costs for tax or markup
+	Notify             NotifyConfig
+	NoLimit            bool               // No known token limit (e.g. API key accounts): show absolute usage
+	CacheWeight        float64            // Weight of cache tokens in JSONL based limit estimation (0 = excluded)
+	ModelBars          bool               // Show per-model share bars under the token bar
+	ModelWeightSpecs   map[string]string  // Raw --model-weights values
+	ModelWeights       map[string]float64 // Limit weight per model family, parsed from ModelWeightSpecs
+	ProjectsPanel      bool               // Show the per-project token breakdown
+	TypicalShape       bool               // Overlay the median historical usage at this point in the session
+	IdleSegments       bool               // Dim stretches of the session bar without messages
+	ActiveTime         bool               // Show how much of the elapsed session was active
+	ForecastChart      bool               // Chart cumulative tokens with their projection to the limit or reset
+	WhatsNew           bool               // Show the release notes once after an upgrade
+	ExitSummary        bool               // Print a session summary under the exit banner
+	NumberFormat       string             // Token count format: comma, locale or si
+	ShortNumbers       bool               // Abbreviate token counts (1.23M); same as NumberFormat si
+	ThousandsSeparator string             // Custom thousands separator ("" = from NumberFormat)
+	FormatFile         string             // Template replacing the built-in session view ("" = built-in)
+	Theme              string             // Built-in color theme
+	Lang               string             // Display language: auto, en or ja
+	Keys               map[string]string  // Key binding overrides of action to space separated keys
+	Colors             map[string]string  // Per-role color overrides of the theme, e.g. bar-high=magenta
+	Output             string             // Output format: tui or json
+	DailyBar           bool               // Show today's tokens across all sessions against a daily budget
+	DailyBudget        int                // Daily token budget (0 = estimate from history)
+	WeeklyBar          bool               // Show tokens over the rolling 7-day window against the weekly limit
+	WeeklyLimit        int                // Weekly token limit (0 = estimate from the plan)
+	TokenLimit         int                // Known token limit replacing the estimate, e.g. for API or Team plans (0 = estimate)
+	SoftLimit          int                // Personal per-session token cap, independent of the plan limit (0 = off)
+	TeamShareSpecs     map[string]string  // Raw --team-share values (member=percent)
+	TeamShares         map[string]float64 // Each member profile's share of the session limit in percent
+	TeamMember         string             // Profile of the person running cctop, highlighted in the team panel
+	WeeklySessions     map[string]int     // Guessed full sessions per week of each plan, without --weekly-limit
+	Breaks             BreakConfig
+	AlertSpecs         map[string]string          // Raw --alert-thresholds values (plan=warning/critical)
+	AlertThresholds    map[string]AlertThresholds // Warning and critical levels per plan, parsed from AlertSpecs
+	Bell               BellConfig
+	Budget             float64 // Cost budget in USD per BudgetPeriod (0 = disabled)
+	BudgetPeriod       string  // day, week or session
+	Focus              FocusConfig
+	ClaudeDirs         []string      // Raw --claude-dir values
+	RemoteSync         time.Duration // Interval between mirroring remote machines (0 = only 'cctop remote sync')
+	Profiles           []Profile     // Parsed from ClaudeDirs
+	Profile            string        // Selected profile name ("" = aggregate all)
+	TimeTracker        TimeTrackerConfig
+	Issue              IssueConfig
+	ChatWebhook        ChatWebhookConfig
+	Tracker            TimeTracker // Created from TimeTracker
+	Log                LogConfig
+	SystemLog          string       // System log backend for events ("" = disabled)
+	HookSpecs          []string     // Raw --hook values
+	Hooks              []HookAction // Parsed from HookSpecs
 }
 
 // ProgressBarConfig holds progress bar configuration
@@ -33,14 +100,65 @@ type ThresholdConfig struct {
 // NewConfig creates a new Config with default values
 func NewConfig() *Config {
 	return &Config{
-		Plan:           "auto",
-		Timezone:       "Asia/Tokyo",
-		UpdateInterval: 3 * time.Second,
+		Plan:             "auto",
+		Timezone:         "Asia/Tokyo",
+		UpdateInterval:   UpdateInterval,
+		FrameInterval:    FrameInterval,
+		BurnHalfLife:     BurnHalfLife,
+		BurnWindow:       BurnRateWindow,
+		BurnWindows:      []time.Duration{InstantBurnWindow, BurnRateWindow},
+		MessageBurnRate:  true,
+		IdleInterval:     IdleInterval,
+		Watch:            true,
+		ArchivePath:      defaultArchivePath(),
+		DailyInterval:    DailyInterval,
+		LimitRefresh:     LimitRefresh,
+		RemoteSync:       RemoteSyncInterval,
+		BillingAnchorDay: 1,
+		Currency:         "USD",
+		CostMultiplier:   1.0,
+		Output:           OutputTUI,
+		NumberFormat:     NumberFormatComma,
+		Theme:            "default",
+		Lang:             LangAuto,
+		BudgetPeriod:     BudgetPeriodDay,
+		IdleSegments:     true,
+		ActiveTime:       true,
+		ExitSummary:      true,
+		WhatsNew:         true,
+		Log: LogConfig{
+			Level:   "off",
+			File:    defaultLogPath(),
+			MaxSize: DefaultLogMaxSize,
+			Backups: DefaultLogBackups,
+		},
+		Focus: FocusConfig{
+			Threshold: 80,
+		},
+		Bell: BellConfig{
+			Warning:  TokenColorThresholdMedium,
+			Cooldown: 10 * time.Minute,
+		},
+		ChatWebhook: ChatWebhookConfig{
+			Thresholds: []float64{80, 95, 100},
+		},
+		Breaks: BreakConfig{
+			Points: []float64{50, 100},
+		},
+		Notify: NotifyConfig{
+			Thresholds: []float64{80, 95, 100},
+			Cooldown:   30 * time.Minute,
+		},
 		TokenLimits: map[string]int{
 			"pro":   7000,
 			"max5":  35000,
 			"max20": 140000,
 		},
+		WeeklySessions: map[string]int{
+			"pro":   8,
+			"max5":  28,
+			"max20": 48,
+		},
 		ProgressBar: ProgressBarConfig{
 			Width:            50,
 			TokenColorLow:    60,
@@ -64,19 +182,12 @@ func (c *Config) GetTokenLimit(plan string) int {
 	return c.TokenLimits["pro"] // Default to pro plan
 }
 
-// ShouldAutoSwitch checks if auto-switching should occur
-func (c *Config) ShouldAutoSwitch(currentPlan string, tokensUsed int) bool {
-	return currentPlan == "pro" && tokensUsed > c.Thresholds.AutoSwitchTokens
-}
-
-// GetProgressBarColor returns the color name based on percentage
-func (c *Config) GetProgressBarColor(percentage float64) string {
-	if percentage < c.ProgressBar.TokenColorLow {
-		return "green"
-	} else if percentage < c.ProgressBar.TokenColorMedium {
-		return "yellow"
+// ShouldAutoSwitch checks if usage outgrew the limit, so the plan should be detected again
+func (c *Config) ShouldAutoSwitch(currentPlan string, tokensUsed, limit int) bool {
+	if currentPlan == "pro" && tokensUsed > c.Thresholds.AutoSwitchTokens {
+		return true
 	}
-	return "red"
+	return limit > 0 && tokensUsed > limit
 }
 
 // ValidatePlan ensures the plan is valid
@@ -86,6 +197,7 @@ func (c *Config) ValidatePlan() {
 		"pro":   true,
 		"max5":  true,
 		"max20": true,
+		PlanAPI: true,
 	}
 
 	if !validPlans[c.Plan] {
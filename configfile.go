@@ -0,0 +1,466 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// ConfigFileState records what happened when the config file was loaded
+type ConfigFileState struct {
+	Path     string
+	Applied  []string // Flag names set from the file
+	Problems []string // Parse and validation errors; non-empty means safe mode
+}
+
+// SafeMode reports whether the config file was ignored because of problems
+func (s ConfigFileState) SafeMode() bool {
+	return len(s.Problems) > 0
+}
+
+var (
+	configPath string
+	configFile ConfigFileState
+)
+
+// defaultConfigPath returns $XDG_CONFIG_HOME/cctop/config.json (~/.config/cctop/config.json
+// by default, %APPDATA%\cctop\config.json on Windows)
+func defaultConfigPath() string {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" && runtime.GOOS == "windows" {
+		configHome = os.Getenv("APPDATA")
+	}
+	if configHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		configHome = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(configHome, "cctop", "config.json")
+}
+
+// loadConfigFile applies a JSON object of flag names to values, e.g. {"plan": "max5", "notify": true}.
+// Flags given on the command line take precedence. If the file cannot be parsed or any value is
+// invalid, nothing is applied and the problems are returned so cctop can start in safe mode.
+func loadConfigFile(path string, lookup func(name string) *pflag.Flag) ConfigFileState {
+	state := ConfigFileState{Path: path}
+	if path == "" {
+		return state
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state
+	}
+	if err != nil {
+		state.Problems = append(state.Problems, err.Error())
+		return state
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		state.Problems = append(state.Problems, fmt.Sprintf("parse error: %v", err))
+		return state
+	}
+
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := make(map[string][]string, len(raw))
+	for _, name := range names {
+		flag := lookup(name)
+		if flag == nil || name == "config" {
+			state.Problems = append(state.Problems, fmt.Sprintf("%s: unknown setting", name))
+			continue
+		}
+		flagValues, err := configValues(raw[name])
+		if err == nil {
+			err = checkFlagValues(flag, flagValues)
+		}
+		if err != nil {
+			state.Problems = append(state.Problems, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		values[name] = flagValues
+	}
+	if state.SafeMode() {
+		return state
+	}
+
+	for _, name := range names {
+		flag := lookup(name)
+		if flag.Changed {
+			continue
+		}
+		for _, value := range values[name] {
+			if err := flag.Value.Set(value); err != nil {
+				state.Problems = append(state.Problems, fmt.Sprintf("%s: %v", name, err))
+			}
+		}
+		state.Applied = append(state.Applied, name)
+	}
+	return state
+}
+
+// configValues converts a JSON value to the strings passed to a flag's Set.
+// Arrays set a repeatable flag once per element; objects become key=value pairs.
+func configValues(value any) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case bool:
+		return []string{strconv.FormatBool(v)}, nil
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}, nil
+	case []any:
+		var values []string
+		for _, element := range v {
+			elementValues, err := configValues(element)
+			if err != nil || len(elementValues) != 1 {
+				return nil, fmt.Errorf("arrays may only contain strings, numbers and booleans")
+			}
+			values = append(values, elementValues...)
+		}
+		return values, nil
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, 0, len(keys))
+		for _, key := range keys {
+			elementValues, err := configValues(v[key])
+			if err != nil || len(elementValues) != 1 {
+				return nil, fmt.Errorf("objects may only contain strings, numbers and booleans")
+			}
+			pairs = append(pairs, key+"="+elementValues[0])
+		}
+		return []string{strings.Join(pairs, ",")}, nil
+	default:
+		return nil, fmt.Errorf("unsupported value %v", value)
+	}
+}
+
+// checkFlagValues verifies values parse as the flag's type and pass its validator
+func checkFlagValues(flag *pflag.Flag, values []string) error {
+	if len(values) == 0 {
+		return fmt.Errorf("empty value")
+	}
+	repeatable := strings.HasSuffix(flag.Value.Type(), "Array") || strings.HasSuffix(flag.Value.Type(), "Slice")
+	if len(values) > 1 && !repeatable {
+		return fmt.Errorf("expected a single %s, got an array", flag.Value.Type())
+	}
+
+	scratch := scratchValue(flag.Value.Type())
+	for _, value := range values {
+		if err := checkFlagType(flag.Value.Type(), value); err != nil {
+			return err
+		}
+		if validate, ok := configValidators[flag.Name]; ok {
+			if err := validate(value); err != nil {
+				return err
+			}
+		}
+		if scratch != nil {
+			if err := scratch.Set(value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// scratchValue returns an unbound flag value of the given pflag type, so config
+// values are applied to a copy before any of them reach the real flags
+func scratchValue(typeName string) pflag.Value {
+	flags := pflag.NewFlagSet("scratch", pflag.ContinueOnError)
+	switch typeName {
+	case "bool":
+		flags.Bool("value", false, "")
+	case "int":
+		flags.Int("value", 0, "")
+	case "int64":
+		flags.Int64("value", 0, "")
+	case "float64":
+		flags.Float64("value", 0, "")
+	case "float64Slice":
+		flags.Float64Slice("value", nil, "")
+	case "duration":
+		flags.Duration("value", 0, "")
+	case "durationSlice":
+		flags.DurationSlice("value", nil, "")
+	case "string":
+		flags.String("value", "", "")
+	case "stringArray":
+		flags.StringArray("value", nil, "")
+	case "stringSlice":
+		flags.StringSlice("value", nil, "")
+	case "stringToString":
+		flags.StringToString("value", nil, "")
+	default:
+		return nil
+	}
+	return flags.Lookup("value").Value
+}
+
+// checkFlagType verifies a value parses as the given pflag type
+func checkFlagType(typeName, value string) error {
+	var err error
+	switch typeName {
+	case "bool":
+		_, err = strconv.ParseBool(value)
+	case "int", "int64":
+		_, err = strconv.ParseInt(value, 10, 64)
+	case "float64", "float64Slice":
+		for _, part := range strings.Split(value, ",") {
+			if _, err = strconv.ParseFloat(part, 64); err != nil {
+				break
+			}
+		}
+	case "duration":
+		_, err = time.ParseDuration(value)
+	case "stringToString":
+		for _, pair := range strings.Split(value, ",") {
+			if !strings.Contains(pair, "=") {
+				return fmt.Errorf("expected an object of key/value pairs")
+			}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s %q", typeName, value)
+	}
+	return nil
+}
+
+// configValidators check the meaning of values beyond their type
+var configValidators = map[string]func(value string) error{
+	"plan":          oneOf("auto", "pro", "max5", "max20", PlanAPI),
+	"output":        oneOf(OutputTUI, OutputPlain, OutputJSON),
+	"budget-period": oneOf(BudgetPeriodDay, BudgetPeriodWeek, BudgetPeriodSession),
+	"number-format": oneOf(NumberFormatComma, NumberFormatLocale, NumberFormatSI),
+	"experimental":  validExperiment,
+	"lang":          oneOf(LangAuto, LangEnglish, LangJapanese),
+	"theme": func(value string) error {
+		_, err := themeColors(value, nil)
+		return err
+	},
+	"colors": validColorOverrides,
+	"keys":   validKeyBindings,
+	"timezone": func(value string) error {
+		_, err := time.LoadLocation(value)
+		return err
+	},
+	"estimator": func(value string) error {
+		_, err := ParseEstimator(value)
+		return err
+	},
+	"est": func(value string) error {
+		_, err := ParseEstimator(value)
+		return err
+	},
+	"alert-thresholds": validAlertThresholds,
+	"currency":         validCurrency,
+	"log-level": func(value string) error {
+		_, err := parseLogLevel(value)
+		return err
+	},
+	"billing-day": func(value string) error {
+		if day, _ := strconv.Atoi(value); day < 1 || day > 31 {
+			return fmt.Errorf("must be between 1 and 31")
+		}
+		return nil
+	},
+	"token-limit": func(value string) error {
+		if limit, _ := strconv.Atoi(value); limit < 0 {
+			return fmt.Errorf("must not be negative")
+		}
+		return nil
+	},
+	"interval":       positiveDuration,
+	"frame-interval": positiveDuration,
+	"idle-interval":  positiveDuration,
+	"daily-interval": positiveDuration,
+	"burn-half-life": positiveDuration,
+	"burn-window":    positiveDuration,
+}
+
+// oneOf returns a validator accepting only the given values
+func oneOf(allowed ...string) func(value string) error {
+	return func(value string) error {
+		for _, a := range allowed {
+			if value == a {
+				return nil
+			}
+		}
+		return fmt.Errorf("%q is not one of %s", value, strings.Join(allowed, ", "))
+	}
+}
+
+// positiveDuration rejects zero and negative durations
+func positiveDuration(value string) error {
+	if d, err := time.ParseDuration(value); err == nil && d <= 0 {
+		return fmt.Errorf("must be positive")
+	}
+	return nil
+}
+
+// Sources a setting's effective value can come from
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// configEnvVars are environment variables that supply a setting's default
+var configEnvVars = map[string]string{
+	"issue":              "CCTOP_ISSUE",
+	"webhook":            "CCTOP_WEBHOOK_URL",
+	"time-tracker-token": "CCTOP_TIME_TRACKER_TOKEN",
+}
+
+// secretSettings are masked when the effective configuration is printed
+var secretSettings = map[string]bool{
+	"time-tracker-token": true,
+	"issue-webhook":      true,
+	"webhook":            true,
+	"bell-command":       true,
+}
+
+// commandSettings hold event=command pairs whose commands may carry credentials,
+// so only the events are printed
+var commandSettings = map[string]bool{
+	"hook": true,
+}
+
+var configShowEffective bool
+
+// EffectiveSetting is a setting's value after merging defaults, file, environment and flags
+type EffectiveSetting struct {
+	Name   string
+	Value  string
+	Source string
+}
+
+// rootFlags returns the root command's local and persistent flags, sorted by name
+func rootFlags() []*pflag.Flag {
+	seen := make(map[string]bool)
+	var flags []*pflag.Flag
+	visit := func(flag *pflag.Flag) {
+		if !seen[flag.Name] && flag.Name != "help" {
+			seen[flag.Name] = true
+			flags = append(flags, flag)
+		}
+	}
+	rootCmd.Flags().VisitAll(visit)
+	rootCmd.PersistentFlags().VisitAll(visit)
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+	return flags
+}
+
+// settingSource reports where a flag's current value came from
+func settingSource(flag *pflag.Flag, state ConfigFileState) string {
+	for _, name := range state.Applied {
+		if name == flag.Name {
+			return SourceFile
+		}
+	}
+	if flag.Changed {
+		return SourceFlag
+	}
+	if env, ok := configEnvVars[flag.Name]; ok && os.Getenv(env) != "" {
+		return SourceEnv
+	}
+	return SourceDefault
+}
+
+// effectiveSettings lists every setting with its value and source, masking secrets
+func effectiveSettings(flags []*pflag.Flag, state ConfigFileState) []EffectiveSetting {
+	settings := make([]EffectiveSetting, 0, len(flags))
+	for _, flag := range flags {
+		value := flag.Value.String()
+		if secretSettings[flag.Name] && value != "" {
+			value = "********"
+		}
+		if slice, ok := flag.Value.(pflag.SliceValue); ok && commandSettings[flag.Name] {
+			value = maskCommands(slice.GetSlice())
+		}
+		settings = append(settings, EffectiveSetting{Name: flag.Name, Value: value, Source: settingSource(flag, state)})
+	}
+	return settings
+}
+
+// maskCommands masks the command of each event=command pair
+func maskCommands(specs []string) string {
+	masked := make([]string, len(specs))
+	for i, spec := range specs {
+		event, _, _ := strings.Cut(spec, "=")
+		masked[i] = event + "=********"
+	}
+	return "[" + strings.Join(masked, ",") + "]"
+}
+
+// validateSettings checks effective values, wherever they came from
+func validateSettings(settings []EffectiveSetting) []string {
+	var problems []string
+	for _, setting := range settings {
+		validate, ok := configValidators[setting.Name]
+		if !ok {
+			continue
+		}
+		if err := validate(setting.Value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s (%s): %v", setting.Name, setting.Source, err))
+		}
+	}
+	return problems
+}
+
+// runConfigValidate checks the config file and the effective settings, exiting non-zero on problems
+func runConfigValidate(cmd *cobra.Command, args []string) {
+	problems := append([]string(nil), configFile.Problems...)
+	problems = append(problems, validateSettings(effectiveSettings(rootFlags(), configFile))...)
+
+	if len(problems) == 0 {
+		fmt.Printf("%s %s is valid (%d settings applied)\n", okString("✓"), configFile.Path, len(configFile.Applied))
+		return
+	}
+	for _, problem := range problems {
+		fmt.Printf("%s %s\n", dangerString("✗"), problem)
+	}
+	os.Exit(1)
+}
+
+// runConfigShow prints the config file, or with --effective every setting and its source
+func runConfigShow(cmd *cobra.Command, args []string) {
+	if !configShowEffective {
+		data, err := os.ReadFile(configFile.Path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Print(string(data))
+		return
+	}
+
+	fmt.Printf("# config file: %s\n", configFile.Path)
+	for _, setting := range effectiveSettings(rootFlags(), configFile) {
+		source := setting.Source
+		if source != SourceDefault {
+			source = infoString("%s", source)
+		}
+		fmt.Printf("%-24s %-40s %s\n", setting.Name, setting.Value, source)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+// newTestFlagSet returns flags covering the value types used by cctop
+func newTestFlagSet() (*pflag.FlagSet, *Config) {
+	cfg := NewConfig()
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.StringVar(&cfg.Plan, "plan", cfg.Plan, "")
+	flags.BoolVar(&cfg.Notify.Enabled, "notify", cfg.Notify.Enabled, "")
+	flags.DurationVar(&cfg.UpdateInterval, "interval", cfg.UpdateInterval, "")
+	flags.Float64SliceVar(&cfg.Notify.Thresholds, "notify-thresholds", cfg.Notify.Thresholds, "")
+	flags.StringArrayVar(&cfg.HookSpecs, "hook", cfg.HookSpecs, "")
+	flags.StringToStringVar(&cfg.ModelWeightSpecs, "model-weights", cfg.ModelWeightSpecs, "")
+	return flags, cfg
+}
+
+func writeTestConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	flags, cfg := newTestFlagSet()
+	path := writeTestConfig(t, `{
+		"plan": "max5",
+		"notify": true,
+		"interval": "10s",
+		"notify-thresholds": [50, 90],
+		"hook": ["session_end=echo a", "session_start=echo b"],
+		"model-weights": {"opus": 5, "sonnet": 1}
+	}`)
+
+	state := loadConfigFile(path, flags.Lookup)
+	if state.SafeMode() {
+		t.Fatalf("loadConfigFile() problems = %v, expected none", state.Problems)
+	}
+	if cfg.Plan != "max5" || !cfg.Notify.Enabled || cfg.UpdateInterval.String() != "10s" {
+		t.Errorf("config = plan %q notify %v interval %v, expected max5 true 10s", cfg.Plan, cfg.Notify.Enabled, cfg.UpdateInterval)
+	}
+	if !reflect.DeepEqual(cfg.Notify.Thresholds, []float64{50, 90}) {
+		t.Errorf("thresholds = %v, expected [50 90]", cfg.Notify.Thresholds)
+	}
+	if len(cfg.HookSpecs) != 2 {
+		t.Errorf("hooks = %v, expected 2", cfg.HookSpecs)
+	}
+	if cfg.ModelWeightSpecs["opus"] != "5" {
+		t.Errorf("model weights = %v, expected opus=5", cfg.ModelWeightSpecs)
+	}
+	if len(state.Applied) != 6 {
+		t.Errorf("applied = %v, expected 6 settings", state.Applied)
+	}
+}
+
+func TestLoadConfigFileFlagPrecedence(t *testing.T) {
+	flags, cfg := newTestFlagSet()
+	if err := flags.Parse([]string{"--plan", "pro"}); err != nil {
+		t.Fatal(err)
+	}
+
+	state := loadConfigFile(writeTestConfig(t, `{"plan": "max20", "notify": true}`), flags.Lookup)
+	if cfg.Plan != "pro" {
+		t.Errorf("plan = %q, expected the command line value pro", cfg.Plan)
+	}
+	if !reflect.DeepEqual(state.Applied, []string{"notify"}) {
+		t.Errorf("applied = %v, expected [notify]", state.Applied)
+	}
+}
+
+func TestLoadConfigFileSafeMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		problems int
+	}{
+		{"parse error", `{"plan": `, 1},
+		{"unknown setting", `{"plan": "max5", "colour": "red"}`, 1},
+		{"invalid plan", `{"plan": "max50"}`, 1},
+		{"wrong type", `{"notify": "sometimes", "interval": "-1s"}`, 2},
+		{"array for scalar", `{"plan": ["pro", "max5"]}`, 1},
+		{"set fails after type check", `{"plan": "max5", "model-weights": {"a": "1", "b": "x\"y"}}`, 1},
+	}
+
+	for _, tt := range tests {
+		flags, cfg := newTestFlagSet()
+		state := loadConfigFile(writeTestConfig(t, tt.content), flags.Lookup)
+		if len(state.Problems) != tt.problems {
+			t.Errorf("%s: problems = %v, expected %d", tt.name, state.Problems, tt.problems)
+		}
+		if cfg.Plan != "auto" || len(state.Applied) != 0 {
+			t.Errorf("%s: applied %v (plan %q), expected defaults", tt.name, state.Applied, cfg.Plan)
+		}
+	}
+}
+
+func TestLoadConfigFileMissing(t *testing.T) {
+	flags, _ := newTestFlagSet()
+	state := loadConfigFile(filepath.Join(t.TempDir(), "missing.json"), flags.Lookup)
+	if state.SafeMode() || len(state.Applied) != 0 {
+		t.Errorf("loadConfigFile(missing) = %+v, expected nothing applied and no problems", state)
+	}
+}
+
+func TestEffectiveSettings(t *testing.T) {
+	flags, _ := newTestFlagSet()
+	flags.String("issue", "", "")
+	flags.String("issue-webhook", "", "")
+	t.Setenv("CCTOP_ISSUE", "ABC-1")
+	args := []string{"--interval", "5s", "--issue-webhook", "https://example.com/hook",
+		"--hook", "session_end=curl -H 'Authorization: Bearer secret' https://example.com", "--hook", "threshold_90=say limit"}
+	if err := flags.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	state := loadConfigFile(writeTestConfig(t, `{"plan": "max5"}`), flags.Lookup)
+
+	var all []*pflag.Flag
+	flags.VisitAll(func(flag *pflag.Flag) { all = append(all, flag) })
+
+	expected := map[string]EffectiveSetting{
+		"plan":          {"plan", "max5", SourceFile},
+		"interval":      {"interval", "5s", SourceFlag},
+		"issue":         {"issue", "", SourceEnv},
+		"issue-webhook": {"issue-webhook", "********", SourceFlag},
+		"notify":        {"notify", "false", SourceDefault},
+		"hook":          {"hook", "[session_end=********,threshold_90=********]", SourceFlag},
+	}
+	for _, setting := range effectiveSettings(all, state) {
+		if want, ok := expected[setting.Name]; ok && setting != want {
+			t.Errorf("setting %s = %+v, expected %+v", setting.Name, setting, want)
+		}
+	}
+}
+
+func TestValidateSettings(t *testing.T) {
+	settings := []EffectiveSetting{
+		{"plan", "max50", SourceFlag},
+		{"interval", "0s", SourceFile},
+		{"timezone", "Asia/Tokyo", SourceDefault},
+		{"notify", "true", SourceFile},
+	}
+	if problems := validateSettings(settings); len(problems) != 2 {
+		t.Errorf("validateSettings() = %v, expected 2 problems", problems)
+	}
+}
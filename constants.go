@@ -4,28 +4,96 @@ import "time"
 
 // Time-related constants
 const (
-	SessionDurationMinutes = 300.0           // 5 hours in minutes
-	SessionDuration        = 5 * time.Hour   // 5 hours
-	UpdateInterval         = 3 * time.Second // Display refresh interval
-	BurnRateWindow         = 1 * time.Hour   // Window for burn rate calculation
-	MinutesPerHour         = 60.0            // Minutes in an hour
+	SessionDurationMinutes = 300.0                  // 5 hours in minutes
+	SessionDuration        = 5 * time.Hour          // 5 hours
+	UpdateInterval         = 3 * time.Second        // Display refresh interval
+	FrameInterval          = 1 * time.Second        // Redraw interval of the clock and countdowns between refreshes
+	IdleInterval           = 60 * time.Second       // Refresh interval when no session is active
+	DailyInterval          = 60 * time.Second       // Minimum time between daily usage fetches
+	IdleThreshold          = 5 * time.Minute        // Time without messages before a session counts as idle
+	BurnRateWindow         = 1 * time.Hour          // Window for burn rate calculation
+	MinutesPerHour         = 60.0                   // Minutes in an hour
+	CurrencyRateTTL        = 24 * time.Hour         // How long fetched exchange rates are reused
+	CurrencyRetryBase      = 1 * time.Minute        // Wait before refetching exchange rates after a failure, doubled per failure
+	BreakReminderDuration  = 5 * time.Minute        // How long a break reminder stays on screen
+	SparklineWindow        = 1 * time.Hour          // Time covered by the recent usage sparkline
+	InstantBurnWindow      = 5 * time.Minute        // Time covered by the instantaneous burn rate
+	BurnHalfLife           = 10 * time.Minute       // Default half-life of the smoothed burn rate
+	LogFollowInterval      = 500 * time.Millisecond // Polling interval of logs tail --follow
+	WatchedRefreshInterval = 30 * time.Second       // Refresh interval while watching the Claude logs for new messages
+	WatchDebounce          = 1 * time.Second        // Log writes within this time of each other trigger one refresh
+	WatchMinInterval       = 5 * time.Second        // Minimum time between refreshes triggered by log writes
+	MaxFramesPerSecond     = 10                     // Upper bound on TUI redraws; bursts of updates share a frame
+	HookTimeout            = 30 * time.Second       // Maximum run time of a hook command
+	WeeklyWindow           = 7 * 24 * time.Hour     // Rolling window of the weekly usage limit
+	SnapshotMaxAge         = 3 * time.Minute        // Age after which status ignores the daemon snapshot
+	LimitRefresh           = 15 * time.Minute       // Interval between re-estimates of the token limit while monitoring
+	LimitAnimationDuration = 3 * time.Second        // Time the token bar takes to move to a changed limit
+	LimitChangeHighlight   = 1 * time.Minute        // How long a changed limit stays highlighted
+	NativeSourceLookback   = 30 * 24 * time.Hour    // History the native-source experiment reads from the Claude logs
+	RemoteSyncInterval     = 1 * time.Minute        // Interval between mirroring the Claude logs of remote machines
 )
 
 // Display constants
 const (
-	ProgressBarWidth = 50           // Width of progress bars in characters
-	TimeFormat       = "15:04:05"   // HH:MM:SS format
-	TimeFormatShort  = "15:04"      // HH:MM format
-	DateFormat       = "2006-01-02" // YYYY-MM-DD format
+	ProgressBarWidth  = 50           // Width of progress bars when the terminal width is unknown
+	ModelBarWidth     = 20           // Width of per-model mini-bars in characters
+	TimeFormat        = "15:04:05"   // HH:MM:SS format
+	TimeFormatShort   = "15:04"      // HH:MM format
+	DateFormat        = "2006-01-02" // YYYY-MM-DD format
+	HistoryViewRows   = 20           // Blocks shown in the history view
+	DailyViewRows     = 14           // Days shown in the daily view
+	ProjectPanelRows  = 5            // Projects shown in the session view panel
+	EventsViewRows    = 20           // Status events shown in the events view
+	SparklineBuckets  = 30           // Buckets in the recent usage sparkline
+	ForecastChartRows = 4            // Rows of the forecast chart, 4 braille dots each
+	LimitHistoryRows  = 20           // Most recent limit changes listed by limit-history
+	TimelineCells     = 48           // Cells per day in the blocks timeline (30 minutes each)
+	TimelineDays      = 7            // Default days shown by the blocks timeline
+)
+
+// Responsive layout constants
+const (
+	MinProgressBarWidth = 10  // Narrowest progress bar on small terminals
+	MaxProgressBarWidth = 100 // Widest progress bar on large terminals
+	BarLineReserve      = 40  // Columns next to a progress bar for its label and values
+	CompactLayoutWidth  = 60  // Terminals narrower than this stack bars under their labels
+	WideLayoutWidth     = 140 // Terminals at least this wide show panels in a second column
+	ColumnGap           = 4   // Spaces between the columns of the wide layout
+)
+
+// Usage history constants
+const (
+	UsageHistorySize = 1200 // Samples kept for the sparkline (an hour at the default interval)
+)
+
+// Log file constants
+const (
+	DefaultLogMaxSize = 10 * 1024 * 1024 // Log file size before rotation
+	DefaultLogBackups = 3                // Rotated log files kept
+)
+
+// Doctor constants
+const (
+	MinTerminalWidth       = 80 // Columns needed to show the progress bars without wrapping
+	DoctorJSONLSampleFiles = 20 // Most recent JSONL files parsed by doctor
+)
+
+// File reading constants
+const (
+	MaxJSONLLineSize      = 16 * 1024 * 1024 // Longest JSONL line read from Claude logs
+	FileIndexSaveInterval = 1 * time.Minute  // Minimum time between writes of the JSONL file index
+	MaxTailedFiles        = 256              // JSONL files whose parsed entries stay in memory
 )
 
 // Token limit constants
 const (
-	DefaultTokenLimit   = 7000 // Default token limit for unknown plans
-	ProPlanMessages     = 45   // Messages allowed in Pro plan
-	Max5PlanMessages    = 225  // Messages allowed in Max5 plan
-	Max20PlanMessages   = 900  // Messages allowed in Max20 plan
-	DefaultTokensPerMsg = 150  // Default tokens per message estimate
+	DefaultTokenLimit    = 7000 // Default token limit for unknown plans
+	ProPlanMessages      = 45   // Messages allowed in Pro plan
+	Max5PlanMessages     = 225  // Messages allowed in Max5 plan
+	Max20PlanMessages    = 900  // Messages allowed in Max20 plan
+	DefaultTokensPerMsg  = 150  // Default tokens per message estimate
+	DefaultDailySessions = 3    // Full sessions per day assumed when estimating a daily budget
 )
 
 // Threshold constants
@@ -34,11 +102,15 @@ const (
 	TokenColorThresholdMedium = 80.0 // Below this percentage shows yellow
 	MinHistoricalSessions     = 5    // Minimum sessions for historical estimation
 	MinCleanedSessions        = 3    // Minimum sessions after outlier removal
+	ColdStartMinMessages      = 5    // Messages needed before a cold-start estimate is made
+	ColdStartUncertainty      = 0.5  // Uncertainty band (fraction) with a single session
 	OutlierIQRMultiplier      = 1.5  // IQR multiplier for outlier detection
 	HistoricalPercentile      = 90.0 // Percentile for historical estimation
 	FallbackPercentile        = 85.0 // Percentile when too many outliers removed
 	AccuracyWarningThreshold  = 10.0 // Percentage deviation for accuracy warning
 	AutoSwitchThreshold       = 7000 // Token threshold for auto plan switching
+	Max5OpusSwitchPercent     = 20.0 // Token usage percentage where Max5 switches from Opus to Sonnet
+	Max20OpusSwitchPercent    = 50.0 // Token usage percentage where Max20 switches from Opus to Sonnet
 )
 
 // Estimation weight constants
@@ -50,19 +122,33 @@ const (
 	WeightMediumVariance = 0.6 // Weight when CV > 0.3
 )
 
-// Statistical constants
+// Learning state constants
 const (
-	VarianceCoefficientHigh   = 0.5 // High coefficient of variation
-	VarianceCoefficientMedium = 0.3 // Medium coefficient of variation
-	RecentSessionsCount       = 10  // Number of recent sessions to analyze
+	LimitHitRatio        = 0.9              // Fraction of the estimate a block must reach to count as a limit hit
+	LimitHitIdleTime     = 30 * time.Minute // Minimum unused time before reset for a limit hit
+	MaxRecordedLimitHits = 50               // Limit hits kept in the state file
+	MaxStatusEvents      = 100              // Status events kept in the events file
+	MaxLimitChanges      = 1000             // Estimated limit changes kept in the limits file
+	MinImpliedPercentage = 5.0              // Reported usage below this is too coarse to derive a limit from
+	WeightObservedBase   = 0.3              // Weight of observed limits with no hits
+	WeightObservedPerHit = 0.1              // Additional weight per recorded hit
+	WeightObservedMax    = 0.9              // Maximum weight of observed limits
 )
 
-// Terminal control sequences
+// Limit history constants
 const (
-	HideCursor   = "\033[?25l"     // ANSI escape to hide cursor
-	ShowCursor   = "\033[?25h"     // ANSI escape to show cursor
-	ClearScreen  = "\033[2J\033[H" // Clear entire screen and move to home
-	ClearAndHome = "\033[H\033[J"  // Move to home and clear to end
+	LimitOscillationReversals = 2   // Direction changes within a week reported as oscillating
+	LimitStableSpread         = 5.0 // Range within a week, in percent of the mean, reported as stable
+)
+
+// Statistical constants
+const (
+	VarianceCoefficientHigh   = 0.5  // High coefficient of variation
+	VarianceCoefficientMedium = 0.3  // Medium coefficient of variation
+	RecentSessionsCount       = 10   // Number of recent sessions to analyze
+	UsageTrendRatio           = 1.25 // Change between early and late sparkline usage reported as a trend
+	ConfidenceZScore          = 1.96 // z-score for the 95% confidence band
+	BurnBandSlices            = 12   // Slices of the burn window whose rates give the depletion range
 )
 
 // Plan detection thresholds
@@ -70,3 +156,35 @@ const (
 	Max20DetectionThreshold = 100000 // Tokens indicating Max20 plan
 	Max5DetectionThreshold  = 25000  // Tokens indicating Max5 plan
 )
+
+// Hook constants
+const (
+	HookQueueSize = 64 // Hook commands that can wait to run before further ones are dropped
+)
+
+// Archive concurrency constants
+const (
+	ArchiveBusyTimeout = 5 * time.Second // How long sqlite3 waits for another process's lock
+	ArchiveRetries     = 4               // Tries of a statement still failing with "database is locked"
+	ArchiveRetryBase   = 200 * time.Millisecond
+	ArchiveRetryMax    = 2 * time.Second
+)
+
+// ccusage failure constants
+const (
+	CCUsageAttempts   = 3                      // Tries of a failing ccusage run within one refresh
+	CCUsageRetryBase  = 500 * time.Millisecond // Delay before the first retry of a ccusage run
+	CCUsageRetryMax   = 2 * time.Second        // Longest delay between retries of a ccusage run
+	CCUsageBackoffMax = 5 * time.Minute        // Longest wait between refreshes while ccusage keeps failing
+)
+
+// Dashboard constants
+const (
+	DashboardBlocks = 48               // Blocks in the dashboard's session history graph
+	SSEKeepAlive    = 30 * time.Second // Interval of keep-alive comments on /v1/events
+)
+
+// MCP server constants
+const (
+	MCPReportMaxAge = 30 * time.Second // Age after which tool calls refresh usage
+)
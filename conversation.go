@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Conversation is one Claude Code session: a conversation logged to <session-id>.jsonl.
+// Not to be confused with the 5-hour usage sessions, which span conversations.
+type Conversation struct {
+	ID          string                `json:"id"`
+	Project     string                `json:"project"`
+	File        string                `json:"file"`
+	Start       time.Time             `json:"start"`
+	End         time.Time             `json:"end"`
+	Usage       TokenUsage            `json:"usage"`
+	TotalTokens int                   `json:"totalTokens"` // Tokens counted towards the limit, see --cache-weight
+	Models      []ModelTokens         `json:"models"`
+	Messages    []ConversationMessage `json:"messages"`
+}
+
+// ConversationMessage is an assistant message of a conversation
+type ConversationMessage struct {
+	Index      int        `json:"index"` // From 1, in log order
+	Time       time.Time  `json:"time"`
+	Model      string     `json:"model"`
+	Usage      TokenUsage `json:"usage"`
+	Tokens     int        `json:"tokens"`
+	Cumulative int        `json:"cumulative"`
+}
+
+// ModelTokens is a model's share of a conversation
+type ModelTokens struct {
+	Model    string `json:"model"`
+	Messages int    `json:"messages"`
+	Tokens   int    `json:"tokens"`
+}
+
+var (
+	conversationTop  int
+	conversationJSON bool
+)
+
+// findConversationFile returns the log of a session ID, or of the only session whose
+// ID starts with it
+func findConversationFile(projectsDirs []string, id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\*?[`) {
+		return "", fmt.Errorf("invalid session ID %q", id)
+	}
+	var matches []string
+	for _, projectsDir := range projectsDirs {
+		files, _ := filepath.Glob(filepath.Join(projectsDir, "*", id+"*.jsonl"))
+		for _, file := range files {
+			if strings.TrimSuffix(filepath.Base(file), ".jsonl") == id {
+				return file, nil
+			}
+		}
+		matches = append(matches, files...)
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no session %s in %s", id, strings.Join(projectsDirs, ", "))
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("session ID %s is ambiguous, matching %d sessions", id, len(matches))
+	}
+}
+
+// newConversation builds a conversation from its log entries, counting responses
+// logged more than once only once
+func newConversation(file string, entries []jsonlEntry) Conversation {
+	conversation := Conversation{
+		ID:       strings.TrimSuffix(filepath.Base(file), ".jsonl"),
+		Project:  projectName(filepath.Dir(file), ""),
+		File:     file,
+		Messages: []ConversationMessage{},
+	}
+	models := make(map[string]*ModelTokens)
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if key := entry.dedupKey(); key != "" {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		if entry.Cwd != "" {
+			conversation.Project = projectName("", entry.Cwd)
+		}
+
+		usage := entry.Message.Usage
+		tokens := usage.Total()
+		conversation.TotalTokens += tokens
+		conversation.Usage.InputTokens += usage.InputTokens
+		conversation.Usage.OutputTokens += usage.OutputTokens
+		conversation.Usage.CacheCreationInputTokens += usage.CacheCreationInputTokens
+		conversation.Usage.CacheReadInputTokens += usage.CacheReadInputTokens
+		conversation.Messages = append(conversation.Messages, ConversationMessage{
+			Index:      len(conversation.Messages) + 1,
+			Time:       entry.Time,
+			Model:      entry.Message.Model,
+			Usage:      usage,
+			Tokens:     tokens,
+			Cumulative: conversation.TotalTokens,
+		})
+
+		if models[entry.Message.Model] == nil {
+			models[entry.Message.Model] = &ModelTokens{Model: entry.Message.Model}
+		}
+		models[entry.Message.Model].Messages++
+		models[entry.Message.Model].Tokens += tokens
+	}
+
+	if len(conversation.Messages) > 0 {
+		conversation.Start = conversation.Messages[0].Time
+		conversation.End = conversation.Messages[len(conversation.Messages)-1].Time
+	}
+	for _, model := range models {
+		conversation.Models = append(conversation.Models, *model)
+	}
+	sort.Slice(conversation.Models, func(i, j int) bool {
+		return conversation.Models[i].Tokens > conversation.Models[j].Tokens
+	})
+	return conversation
+}
+
+// Largest returns the n messages with the most tokens, largest first
+func (c Conversation) Largest(n int) []ConversationMessage {
+	largest := append([]ConversationMessage(nil), c.Messages...)
+	sort.SliceStable(largest, func(i, j int) bool { return largest[i].Tokens > largest[j].Tokens })
+	return largest[:min(n, len(largest))]
+}
+
+// RenderConversation renders a conversation message by message, followed by its largest messages
+func (d *Display) RenderConversation(conversation Conversation, top int) string {
+	var buffer strings.Builder
+	fmt.Fprintf(&buffer, "Session %s (%s)\n", conversation.ID, conversation.Project)
+	fmt.Fprintf(&buffer, "%s\n", mutedString("%s", conversation.File))
+	if len(conversation.Messages) == 0 {
+		buffer.WriteString("\nNo assistant messages\n")
+		return buffer.String()
+	}
+	fmt.Fprintf(&buffer, "%s - %s, %d messages, %s tokens\n",
+		conversation.Start.In(d.timezone).Format("2006-01-02 15:04"),
+		conversation.End.In(d.timezone).Format(TimeFormatShort),
+		len(conversation.Messages),
+		formatNumber(conversation.TotalTokens))
+	for _, model := range conversation.Models {
+		fmt.Fprintf(&buffer, "  %-28s %5d msgs  %12s tokens\n", formatModelName(model.Model), model.Messages, formatNumber(model.Tokens))
+	}
+
+	fmt.Fprintf(&buffer, "\n%5s  %-8s  %-10s  %10s  %10s  %11s  %11s  %10s  %12s\n",
+		"#", "Time", "Model", "Input", "Output", "Cache write", "Cache read", "Tokens", "Cumulative")
+	for _, message := range conversation.Messages {
+		d.writeConversationMessage(&buffer, message)
+	}
+
+	if top > 0 {
+		fmt.Fprintf(&buffer, "\nLargest messages\n")
+		for _, message := range conversation.Largest(top) {
+			d.writeConversationMessage(&buffer, message)
+		}
+	}
+	return buffer.String()
+}
+
+// writeConversationMessage writes a message table row
+func (d *Display) writeConversationMessage(buffer *strings.Builder, message ConversationMessage) {
+	fmt.Fprintf(buffer, "%5d  %-8s  %-10s  %10s  %10s  %11s  %11s  %10s  %12s\n",
+		message.Index,
+		message.Time.In(d.timezone).Format(TimeFormat),
+		modelFamily(message.Model),
+		formatNumber(message.Usage.InputTokens),
+		formatNumber(message.Usage.OutputTokens),
+		formatNumber(message.Usage.CacheCreationInputTokens),
+		formatNumber(message.Usage.CacheReadInputTokens),
+		formatNumber(message.Tokens),
+		formatNumber(message.Cumulative))
+}
+
+// runConversation shows the token consumption of one Claude Code session
+func runConversation(cmd *cobra.Command, args []string) {
+	file, err := findConversationFile(defaultProjectsDirs(), args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	entries, err := readJSONLEntries(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	conversation := newConversation(file, entries)
+
+	if conversationJSON || config.Output == OutputJSON {
+		output, err := json.MarshalIndent(conversation, "", "  ")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(string(output))
+		return
+	}
+	fmt.Fprint(NewTerminal(os.Stdout), display.RenderConversation(conversation, conversationTop))
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindConversationFile(t *testing.T) {
+	projectsDir := t.TempDir()
+	project := filepath.Join(projectsDir, "-home-me-src-cctop")
+	if err := os.MkdirAll(project, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"3f2a9c1e-aaaa", "3f2a9c1e-aaaa-resumed", "7b41d0e2-bbbb", "7b41d0e2-cccc"} {
+		if err := os.WriteFile(filepath.Join(project, id+".jsonl"), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		id        string
+		expected  string
+		expectErr string
+	}{
+		{"3f2a9c1e-aaaa", "3f2a9c1e-aaaa.jsonl", ""}, // Exact match wins over longer IDs
+		{"3f2a9c1e-aaaa-r", "3f2a9c1e-aaaa-resumed.jsonl", ""},
+		{"7b41d0e2", "", "ambiguous"},
+		{"ffff", "", "no session"},
+		{"../x", "", "invalid"},
+	}
+	for _, tt := range tests {
+		file, err := findConversationFile([]string{projectsDir}, tt.id)
+		if tt.expectErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+				t.Errorf("findConversationFile(%q) error = %v, expected %q", tt.id, err, tt.expectErr)
+			}
+			continue
+		}
+		if err != nil || filepath.Base(file) != tt.expected {
+			t.Errorf("findConversationFile(%q) = %s, %v, expected %s", tt.id, file, err, tt.expected)
+		}
+	}
+}
+
+func TestNewConversation(t *testing.T) {
+	oldDisplay := display
+	defer func() { display = oldDisplay }()
+	display = NewDisplay("UTC")
+
+	file := filepath.Join(t.TempDir(), "-home-me-src-cctop", "3f2a9c1e.jsonl")
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	lines := `{"timestamp":"2025-06-20T10:00:00Z","type":"user","message":{"role":"user"}}
+{"timestamp":"2025-06-20T10:00:05Z","type":"assistant","cwd":"/home/me/src/cctop","requestId":"req_1","message":{"id":"msg_1","model":"claude-sonnet-4-20250514","usage":{"input_tokens":100,"output_tokens":200}}}
+{"timestamp":"2025-06-20T10:00:05Z","type":"assistant","cwd":"/home/me/src/cctop","requestId":"req_1","message":{"id":"msg_1","model":"claude-sonnet-4-20250514","usage":{"input_tokens":100,"output_tokens":200}}}
+{"timestamp":"2025-06-20T10:05:00Z","type":"assistant","cwd":"/home/me/src/cctop","requestId":"req_2","message":{"id":"msg_2","model":"claude-opus-4-20250514","usage":{"input_tokens":50,"output_tokens":4000,"cache_read_input_tokens":9000}}}
+{"timestamp":"2025-06-20T10:09:00Z","type":"assistant","cwd":"/home/me/src/cctop","requestId":"req_3","message":{"id":"msg_3","model":"claude-sonnet-4-20250514","usage":{"input_tokens":10,"output_tokens":90}}}
+`
+	if err := os.WriteFile(file, []byte(lines), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := readJSONLEntries(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conversation := newConversation(file, entries)
+	if conversation.ID != "3f2a9c1e" || conversation.Project != "cctop" {
+		t.Errorf("conversation %s in %s, expected 3f2a9c1e in cctop", conversation.ID, conversation.Project)
+	}
+	// The duplicated msg_1 counts once; cache tokens are excluded at the default cache weight
+	cumulative := []int{300, 4350, 4450}
+	if len(conversation.Messages) != len(cumulative) {
+		t.Fatalf("got %d messages, expected %d", len(conversation.Messages), len(cumulative))
+	}
+	for i, expected := range cumulative {
+		if conversation.Messages[i].Cumulative != expected {
+			t.Errorf("message %d cumulative = %d, expected %d", i+1, conversation.Messages[i].Cumulative, expected)
+		}
+	}
+	if conversation.Usage.CacheReadInputTokens != 9000 || conversation.TotalTokens != 4450 {
+		t.Errorf("usage = %+v, total %d, expected 9000 cache reads and 4450 tokens", conversation.Usage, conversation.TotalTokens)
+	}
+	if len(conversation.Models) != 2 || conversation.Models[0].Model != "claude-opus-4-20250514" || conversation.Models[1].Messages != 2 {
+		t.Errorf("models = %+v, expected Opus first, then 2 Sonnet messages", conversation.Models)
+	}
+
+	largest := conversation.Largest(2)
+	if len(largest) != 2 || largest[0].Index != 2 || largest[1].Index != 1 {
+		t.Errorf("Largest(2) = %+v, expected messages 2 and 1", largest)
+	}
+
+	output := string(stripANSI([]byte(display.RenderConversation(conversation, 1))))
+	_, top, found := strings.Cut(output, "Largest messages\n")
+	if !found || !strings.Contains(top, "Opus") || strings.Count(top, "\n") != 1 {
+		t.Errorf("RenderConversation() largest messages = %q, expected the Opus message only", top)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"time"
+)
+
+// Budget periods
+const (
+	BudgetPeriodDay     = "day"
+	BudgetPeriodWeek    = "week"
+	BudgetPeriodSession = "session" // The 5-hour session block
+)
+
+// CostMetrics holds spending against the configured budget
+type CostMetrics struct {
+	Spent      float64 `json:"spent"`  // Raw USD spent in the current period
+	Budget     float64 `json:"budget"` // USD budget for the period
+	Percentage float64 `json:"percentage"`
+	Period     string  `json:"period"`
+}
+
+// budgetPeriodStart returns the start of the budget period containing currentTime.
+// Weeks start on Monday.
+func budgetPeriodStart(period string, currentTime time.Time) time.Time {
+	year, month, day := currentTime.Date()
+	start := time.Date(year, month, day, 0, 0, 0, 0, currentTime.Location())
+	if period != BudgetPeriodWeek {
+		return start
+	}
+
+	daysSinceMonday := (int(start.Weekday()) + 6) % 7
+	return start.AddDate(0, 0, -daysSinceMonday)
+}
+
+// budgetPeriodEnd returns when the budget period containing currentTime ends
+func budgetPeriodEnd(period string, currentTime, sessionEnd time.Time) time.Time {
+	switch period {
+	case BudgetPeriodSession:
+		return sessionEnd
+	case BudgetPeriodWeek:
+		return budgetPeriodStart(period, currentTime).AddDate(0, 0, 7)
+	default:
+		return budgetPeriodStart(period, currentTime).AddDate(0, 0, 1)
+	}
+}
+
+// sessionCostMetrics compares the cost of the session block with the budget
+func sessionCostMetrics(block *Block, budget float64) CostMetrics {
+	metrics := CostMetrics{Spent: block.CostUSD, Budget: budget, Period: BudgetPeriodSession}
+	if budget > 0 {
+		metrics.Percentage = metrics.Spent / budget * 100
+	}
+	return metrics
+}
+
+// calculateCostMetrics sums daily costs within the budget period
+func calculateCostMetrics(days []DailyUsage, currentTime time.Time, budget float64, period string) CostMetrics {
+	if period != BudgetPeriodWeek {
+		period = BudgetPeriodDay
+	}
+	metrics := CostMetrics{Budget: budget, Period: period}
+
+	start := budgetPeriodStart(period, currentTime)
+	for _, day := range days {
+		date, err := time.ParseInLocation(DateFormat, day.Date, currentTime.Location())
+		if err != nil {
+			continue
+		}
+		if !date.Before(start) && !date.After(currentTime) {
+			metrics.Spent += day.TotalCost
+		}
+	}
+
+	if budget > 0 {
+		metrics.Percentage = metrics.Spent / budget * 100
+	}
+	return metrics
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalculateCostMetrics(t *testing.T) {
+	// Friday
+	now := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+	days := []DailyUsage{
+		{Date: "2025-06-15", TotalCost: 100}, // Previous week (Sunday)
+		{Date: "2025-06-16", TotalCost: 10},  // Monday
+		{Date: "2025-06-18", TotalCost: 5},
+		{Date: "2025-06-20", TotalCost: 2.5},
+	}
+
+	daily := calculateCostMetrics(days, now, 10, BudgetPeriodDay)
+	if daily.Spent != 2.5 || daily.Percentage != 25 {
+		t.Errorf("daily = %+v, expected $2.50 (25%%)", daily)
+	}
+
+	weekly := calculateCostMetrics(days, now, 50, BudgetPeriodWeek)
+	if weekly.Spent != 17.5 || weekly.Percentage != 35 {
+		t.Errorf("weekly = %+v, expected $17.50 (35%%)", weekly)
+	}
+
+	if unknown := calculateCostMetrics(days, now, 10, "month"); unknown.Period != BudgetPeriodDay {
+		t.Errorf("Period = %s, expected fallback to %s", unknown.Period, BudgetPeriodDay)
+	}
+}
+
+func TestSessionCostMetrics(t *testing.T) {
+	metrics := sessionCostMetrics(&Block{CostUSD: 3}, 12)
+	if metrics.Spent != 3 || metrics.Percentage != 25 || metrics.Period != BudgetPeriodSession {
+		t.Errorf("sessionCostMetrics() = %+v, expected $3.00 (25%%) per session", metrics)
+	}
+}
+
+func TestBudgetPeriodEnd(t *testing.T) {
+	// Friday
+	now := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+	sessionEnd := now.Add(2 * time.Hour)
+	tests := []struct {
+		period   string
+		expected time.Time
+	}{
+		{BudgetPeriodDay, time.Date(2025, 6, 21, 0, 0, 0, 0, time.UTC)},
+		{BudgetPeriodWeek, time.Date(2025, 6, 23, 0, 0, 0, 0, time.UTC)},
+		{BudgetPeriodSession, sessionEnd},
+	}
+	for _, tt := range tests {
+		if got := budgetPeriodEnd(tt.period, now, sessionEnd); !got.Equal(tt.expected) {
+			t.Errorf("budgetPeriodEnd(%s) = %v, expected %v", tt.period, got, tt.expected)
+		}
+	}
+}
+
+func TestCalculateCostBurnRate(t *testing.T) {
+	currentTime := time.Now()
+	blocks := []Block{
+		{
+			StartTime: currentTime.Add(-30 * time.Minute).Format(time.RFC3339),
+			CostUSD:   3.0,
+			IsActive:  true,
+		},
+		{
+			StartTime: currentTime.Add(-20 * time.Minute).Format(time.RFC3339),
+			CostUSD:   50.0,
+			IsGap:     true,
+		},
+	}
+
+	rate := NewBurnRateCalculator().CalculateCost(blocks, currentTime)
+	if rate < 2.9 || rate > 3.1 {
+		t.Errorf("CalculateCost() = %.2f, expected about 3.00 $/h", rate)
+	}
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ecbRatesURL is the European Central Bank daily reference rate feed (EUR based)
+const ecbRatesURL = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+
+// currencyCodePattern matches ISO 4217 currency codes
+var currencyCodePattern = regexp.MustCompile(`^[A-Za-z]{3}$`)
+
+// currencySymbols maps currency codes to display symbols
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"CNY": "¥",
+	"KRW": "₩",
+	"INR": "₹",
+}
+
+// zeroDecimalCurrencies are displayed without fractional digits
+var zeroDecimalCurrencies = map[string]bool{
+	"JPY": true,
+	"KRW": true,
+}
+
+// CurrencyConverter converts USD costs into the configured display currency
+type CurrencyConverter struct {
+	code      string
+	rate      float64 // Units of code per USD
+	static    bool    // Rate was configured explicitly and is never fetched
+	converted bool    // A rate is known; until then amounts are shown in USD
+	cachePath string
+	fetchedAt time.Time
+	failures  int       // Consecutive failed fetches, backing off the next one
+	retryAt   time.Time // No fetch is tried before this after a failure
+	mu        sync.Mutex
+}
+
+// ecbEnvelope mirrors the structure of the ECB daily XML feed
+type ecbEnvelope struct {
+	Cube struct {
+		Cube struct {
+			Time  string `xml:"time,attr"`
+			Rates []struct {
+				Currency string  `xml:"currency,attr"`
+				Rate     float64 `xml:"rate,attr"`
+			} `xml:"Cube"`
+		} `xml:"Cube"`
+	} `xml:"Cube"`
+}
+
+// rateCache is the on-disk cache of fetched ECB rates
+type rateCache struct {
+	FetchedAt time.Time          `json:"fetchedAt"`
+	Rates     map[string]float64 `json:"rates"` // Units per EUR
+}
+
+// NewCurrencyConverter creates a converter for the given currency code.
+// A positive staticRate disables ECB fetching.
+func NewCurrencyConverter(code string, staticRate float64) *CurrencyConverter {
+	code = strings.ToUpper(code)
+	c := &CurrencyConverter{
+		code: code,
+		rate: 1,
+	}
+
+	if code == "" || code == "USD" {
+		c.code = "USD"
+		c.static = true
+		c.converted = true
+		return c
+	}
+
+	if staticRate > 0 {
+		c.rate = staticRate
+		c.static = true
+		c.converted = true
+		return c
+	}
+
+	if cacheDir, err := os.UserCacheDir(); err == nil {
+		c.cachePath = filepath.Join(cacheDir, "cctop", "ecb-rates.json")
+	}
+	return c
+}
+
+// Refresh updates the rate from cache or the ECB feed when it is older than CurrencyRateTTL.
+// On failure the previous rate is kept and fetching backs off, from CurrencyRetryBase
+// up to CurrencyRateTTL between tries.
+func (c *CurrencyConverter) Refresh(currentTime time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.static || currentTime.Sub(c.fetchedAt) < CurrencyRateTTL || currentTime.Before(c.retryAt) {
+		return
+	}
+
+	cache, err := c.loadCache()
+	if err != nil || currentTime.Sub(cache.FetchedAt) >= CurrencyRateTTL {
+		rates, fetchErr := fetchRates()
+		if fetchErr != nil {
+			c.failed(currentTime, fetchErr)
+			return
+		}
+		cache = rateCache{FetchedAt: currentTime, Rates: rates}
+		c.saveCache(cache)
+	}
+
+	rate, ok := usdRate(cache.Rates, c.code)
+	if !ok {
+		c.failed(currentTime, fmt.Errorf("no ECB rate for %s", c.code))
+		return
+	}
+	c.rate = rate
+	c.converted = true
+	c.fetchedAt = cache.FetchedAt
+	c.failures = 0
+	c.retryAt = time.Time{}
+}
+
+// failed schedules the next fetch after a failed one
+func (c *CurrencyConverter) failed(currentTime time.Time, err error) {
+	c.failures++
+	delay := RetryPolicy{Base: CurrencyRetryBase, Max: CurrencyRateTTL}.Delay(c.failures)
+	c.retryAt = currentTime.Add(delay)
+	logger.Warnf("exchange rate: %v, retrying in %s", err, delay.Round(time.Second))
+}
+
+// Code returns the display currency code, USD while no exchange rate is known
+func (c *CurrencyConverter) Code() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.converted {
+		return "USD"
+	}
+	return c.code
+}
+
+// Convert converts a USD amount into the display currency
+func (c *CurrencyConverter) Convert(usd float64) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return usd * c.rate
+}
+
+// Format converts and formats a USD amount with the currency symbol
+func (c *CurrencyConverter) Format(usd float64) string {
+	amount := c.Convert(usd)
+	code := c.Code()
+
+	symbol, ok := currencySymbols[code]
+	if !ok {
+		symbol = code + " "
+	}
+	if zeroDecimalCurrencies[code] {
+		return fmt.Sprintf("%s%.0f", symbol, amount)
+	}
+	return fmt.Sprintf("%s%.2f", symbol, amount)
+}
+
+// loadCache reads cached ECB rates from disk
+func (c *CurrencyConverter) loadCache() (rateCache, error) {
+	var cache rateCache
+	if c.cachePath == "" {
+		return cache, fmt.Errorf("no cache directory")
+	}
+
+	data, err := os.ReadFile(c.cachePath)
+	if err != nil {
+		return cache, err
+	}
+	err = json.Unmarshal(data, &cache)
+	return cache, err
+}
+
+// saveCache writes ECB rates to disk, ignoring failures
+func (c *CurrencyConverter) saveCache(cache rateCache) {
+	if c.cachePath == "" {
+		return
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return
+	}
+	_ = writeFileAtomic(c.cachePath, data, 0o600)
+}
+
+// fetchRates downloads the exchange rates, replaced in tests
+var fetchRates = fetchECBRates
+
+// fetchECBRates downloads the latest EUR based reference rates
+func fetchECBRates() (map[string]float64, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(ecbRatesURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ECB rates request failed: %s", resp.Status)
+	}
+
+	var envelope ecbEnvelope
+	if err := xml.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return nil, err
+	}
+	return parseECBRates(envelope), nil
+}
+
+// parseECBRates converts the ECB feed into a rate map, including EUR itself
+func parseECBRates(envelope ecbEnvelope) map[string]float64 {
+	rates := map[string]float64{"EUR": 1}
+	for _, r := range envelope.Cube.Cube.Rates {
+		rates[r.Currency] = r.Rate
+	}
+	return rates
+}
+
+// usdRate derives units of code per USD from EUR based rates
+func usdRate(eurRates map[string]float64, code string) (float64, bool) {
+	usd, ok := eurRates["USD"]
+	if !ok || usd == 0 {
+		return 0, false
+	}
+	target, ok := eurRates[code]
+	if !ok {
+		return 0, false
+	}
+	return target / usd, true
+}
+
+// validCurrency checks a --currency value
+func validCurrency(value string) error {
+	if value != "" && !currencyCodePattern.MatchString(value) {
+		return fmt.Errorf("invalid currency %q, expected a three-letter code like EUR", value)
+	}
+	return nil
+}
+
+// adjustCost applies the configured cost multiplier (tax, markup) to a raw USD cost
+func adjustCost(usd float64) float64 {
+	if config == nil || config.CostMultiplier <= 0 {
+		return usd
+	}
+	return usd * config.CostMultiplier
+}
+
+// formatCost formats a raw USD cost, adjusted and converted for display
+func formatCost(usd float64) string {
+	adjusted := adjustCost(usd)
+	if currency == nil {
+		return fmt.Sprintf("$%.2f", adjusted)
+	}
+	return currency.Format(adjusted)
+}
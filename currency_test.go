@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseECBRates(t *testing.T) {
+	feed := `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<Cube>
+		<Cube time="2025-06-20">
+			<Cube currency="USD" rate="1.1500"/>
+			<Cube currency="JPY" rate="167.50"/>
+			<Cube currency="GBP" rate="0.8550"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+	var envelope ecbEnvelope
+	if err := xml.Unmarshal([]byte(feed), &envelope); err != nil {
+		t.Fatalf("failed to parse feed: %v", err)
+	}
+	rates := parseECBRates(envelope)
+
+	tests := []struct {
+		code     string
+		expected float64
+	}{
+		{code: "EUR", expected: 1 / 1.15},
+		{code: "JPY", expected: 167.5 / 1.15},
+		{code: "GBP", expected: 0.855 / 1.15},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			rate, ok := usdRate(rates, tt.code)
+			if !ok {
+				t.Fatalf("usdRate(%s) not found", tt.code)
+			}
+			if rate < tt.expected-0.0001 || rate > tt.expected+0.0001 {
+				t.Errorf("usdRate(%s) = %.4f, expected %.4f", tt.code, rate, tt.expected)
+			}
+		})
+	}
+
+	if _, ok := usdRate(rates, "XXX"); ok {
+		t.Error("usdRate(XXX) should not be found")
+	}
+}
+
+func TestCurrencyFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		rate     float64
+		usd      float64
+		expected string
+	}{
+		{name: "USD default", code: "", rate: 0, usd: 12.345, expected: "$12.35"},
+		{name: "EUR static rate", code: "eur", rate: 0.9, usd: 10, expected: "€9.00"},
+		{name: "JPY has no decimals", code: "JPY", rate: 150, usd: 12.34, expected: "¥1851"},
+		{name: "Unknown symbol uses code", code: "CHF", rate: 0.8, usd: 10, expected: "CHF 8.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCurrencyConverter(tt.code, tt.rate)
+			if result := c.Format(tt.usd); result != tt.expected {
+				t.Errorf("Format(%.2f) = %s, expected %s", tt.usd, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatCostMultiplier(t *testing.T) {
+	oldConfig, oldCurrency := config, currency
+	defer func() { config, currency = oldConfig, oldCurrency }()
+
+	config = NewConfig()
+	currency = NewCurrencyConverter("EUR", 0.5)
+
+	config.CostMultiplier = 1.2
+	if result := formatCost(10); result != "€6.00" {
+		t.Errorf("formatCost(10) = %s, expected €6.00", result)
+	}
+
+	config.CostMultiplier = 0
+	if result := adjustCost(10); result != 10 {
+		t.Errorf("adjustCost(10) = %.2f, expected raw cost for invalid multiplier", result)
+	}
+}
+
+func TestCurrencyRefreshFailure(t *testing.T) {
+	oldFetch := fetchRates
+	defer func() { fetchRates = oldFetch }()
+	fetches := 0
+	fetchRates = func() (map[string]float64, error) {
+		fetches++
+		return nil, errors.New("offline")
+	}
+
+	c := NewCurrencyConverter("EUR", 0)
+	c.cachePath = ""
+	now := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+	c.Refresh(now)
+	if result := c.Format(10); result != "$10.00" {
+		t.Errorf("Format() without a rate = %s, expected USD", result)
+	}
+
+	c.Refresh(now.Add(10 * time.Second))
+	if fetches != 1 {
+		t.Errorf("fetched %d times right after a failure, expected to back off", fetches)
+	}
+	c.Refresh(now.Add(CurrencyRetryBase))
+	if fetches != 2 {
+		t.Errorf("fetched %d times after the backoff, expected a retry", fetches)
+	}
+
+	fetchRates = func() (map[string]float64, error) {
+		return map[string]float64{"EUR": 1, "USD": 1.25}, nil
+	}
+	c.Refresh(now.Add(time.Hour))
+	if result := c.Format(10); result != "€8.00" {
+		t.Errorf("Format() after a successful fetch = %s, expected €8.00", result)
+	}
+}
+
+func TestValidCurrency(t *testing.T) {
+	for _, code := range []string{"", "EUR", "jpy"} {
+		if err := validCurrency(code); err != nil {
+			t.Errorf("validCurrency(%q) = %v, expected no error", code, err)
+		}
+	}
+	for _, code := range []string{"EURO", "$", "E1R"} {
+		if err := validCurrency(code); err == nil {
+			t.Errorf("validCurrency(%q) accepted an invalid code", code)
+		}
+	}
+}
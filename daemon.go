@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Snapshot is the compact status the daemon writes after every refresh
+type Snapshot struct {
+	UpdatedAt time.Time     `json:"updatedAt"`
+	Error     string        `json:"error,omitempty"` // Refresh error, e.g. no active session
+	Status    *StatusReport `json:"status,omitempty"`
+}
+
+var (
+	snapshotPath string
+	statusFresh  bool
+)
+
+// defaultSnapshotPath returns the snapshot file in the cctop state directory
+func defaultSnapshotPath() string {
+	stateDir := cctopStateDir()
+	if stateDir == "" {
+		return ""
+	}
+	return filepath.Join(stateDir, "snapshot.json")
+}
+
+// runDaemon polls usage without a terminal and writes a snapshot every interval
+func runDaemon(cmd *cobra.Command, args []string) {
+	if snapshotPath == "" {
+		fmt.Fprintln(os.Stderr, "no snapshot path, set --snapshot")
+		os.Exit(1)
+	}
+	estimator.SetEstimationMethod(estimationMethod)
+	tokenLimit := getInitialTokenLimit(config.Plan)
+	logger.Infof("daemon writing snapshots to %s", snapshotPath)
+	startProjectWatcher()
+	startRemoteSync()
+
+	for {
+		session, err := loadSession(config.Plan, config.Profile, &tokenLimit)
+		if err := writeSnapshot(snapshotPath, newSnapshot(session, err, time.Now())); err != nil {
+			logger.Errorf("writing snapshot: %v", err)
+		}
+		waitForRefresh(refreshInterval(session, time.Now()), projectWatcher.Changes(), nil)
+	}
+}
+
+// newSnapshot builds a snapshot from a refresh result
+func newSnapshot(session *Session, err error, currentTime time.Time) Snapshot {
+	snapshot := Snapshot{UpdatedAt: currentTime}
+	if err != nil {
+		snapshot.Error = err.Error()
+		return snapshot
+	}
+	report := NewStatusReport(session, config.Plan, currentTime)
+	snapshot.Status = &report
+	return snapshot
+}
+
+// writeSnapshot replaces the snapshot file atomically so readers never see a partial write
+func writeSnapshot(path string, snapshot Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0o600)
+}
+
+// readSnapshot loads the snapshot if it exists and is newer than SnapshotMaxAge
+func readSnapshot(path string, currentTime time.Time) (*Snapshot, bool) {
+	if path == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, false
+	}
+	if currentTime.Sub(snapshot.UpdatedAt) > SnapshotMaxAge {
+		return nil, false
+	}
+	return &snapshot, true
+}
+
+// printSnapshot prints a daemon snapshot as JSON or a one-line summary
+func printSnapshot(snapshot *Snapshot) {
+	if snapshot.Status == nil {
+		fmt.Fprintln(os.Stderr, snapshot.Error)
+		os.Exit(1)
+	}
+	if config.Output == OutputJSON {
+		output, err := json.MarshalIndent(snapshot.Status, "", "  ")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(string(output))
+		return
+	}
+	fmt.Fprintln(NewTerminal(os.Stdout), formatSnapshotLine(snapshot, display.timezone))
+}
+
+// formatSnapshotLine summarizes a snapshot on one line
+func formatSnapshotLine(snapshot *Snapshot, loc *time.Location) string {
+	status := snapshot.Status
+	// Format: "Tokens: 12,345/140,000 (8.8%)  Burn: 120.0/min  Reset: 15:00  OK  (updated 14:02:10)"
+	return fmt.Sprintf("Tokens: %s/%s (%.1f%%)  Burn: %.1f/min  Reset: %s  %s  (updated %s)",
+		formatNumber(status.Tokens.Used),
+		formatNumber(status.Tokens.Limit),
+		status.Tokens.Percentage,
+		status.BurnRate,
+		status.EndTime.In(loc).Format(TimeFormatShort),
+		status.Status,
+		snapshot.UpdatedAt.In(loc).Format(TimeFormat))
+}
+
+// currentStatusReport returns the status from a recent daemon snapshot, or refreshes it via ccusage
+func currentStatusReport(currentTime time.Time) (*StatusReport, error) {
+	if snapshot, ok := readSnapshot(snapshotPath, currentTime); ok {
+		if snapshot.Status == nil {
+			return nil, fmt.Errorf("%s", snapshot.Error)
+		}
+		return snapshot.Status, nil
+	}
+
+	estimator.SetEstimationMethod(estimationMethod)
+	tokenLimit := getInitialTokenLimit(config.Plan)
+	session, err := loadSession(config.Plan, config.Profile, &tokenLimit)
+	if err != nil {
+		return nil, err
+	}
+	report := NewStatusReport(session, config.Plan, currentTime)
+	return &report, nil
+}
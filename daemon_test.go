@@ -0,0 +1,61 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "snapshot.json")
+	now := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+	snapshot := Snapshot{
+		UpdatedAt: now,
+		Status: &StatusReport{
+			Tokens: TokenMetrics{Used: 12345, Limit: 140000, Percentage: 8.8},
+			Status: "OK",
+		},
+	}
+
+	if err := writeSnapshot(path, snapshot); err != nil {
+		t.Fatalf("writeSnapshot() error = %v", err)
+	}
+
+	loaded, ok := readSnapshot(path, now.Add(time.Minute))
+	if !ok {
+		t.Fatal("readSnapshot() ok = false, expected true")
+	}
+	if loaded.Status.Tokens.Used != 12345 || !loaded.UpdatedAt.Equal(now) {
+		t.Errorf("readSnapshot() = %+v, expected the written snapshot", loaded)
+	}
+
+	if _, ok := readSnapshot(path, now.Add(SnapshotMaxAge+time.Second)); ok {
+		t.Error("readSnapshot() accepted a stale snapshot")
+	}
+	if _, ok := readSnapshot(filepath.Join(t.TempDir(), "missing.json"), now); ok {
+		t.Error("readSnapshot() accepted a missing file")
+	}
+}
+
+func TestFormatSnapshotLine(t *testing.T) {
+	now := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+	snapshot := &Snapshot{
+		UpdatedAt: now,
+		Status: &StatusReport{
+			EndTime:  now.Add(3 * time.Hour),
+			Tokens:   TokenMetrics{Used: 12345, Limit: 140000, Percentage: 8.8},
+			BurnRate: 120,
+			Status:   "OK",
+		},
+	}
+
+	line := formatSnapshotLine(snapshot, time.UTC)
+	expected := "Tokens: 12,345/140,000 (8.8%)  Burn: 120.0/min  Reset: 15:00  OK  (updated 12:00:00)"
+	if line != expected {
+		t.Errorf("formatSnapshotLine() = %q, expected %q", line, expected)
+	}
+	if strings.Contains(line, "\x1b") {
+		t.Error("formatSnapshotLine() contains escape codes")
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"time"
+)
+
+// findDay returns the daily usage entry for the given time's date
+func findDay(days []DailyUsage, currentTime time.Time) (DailyUsage, bool) {
+	dateStr := currentTime.Format(DateFormat)
+	for _, day := range days {
+		if day.Date == dateStr {
+			return day, true
+		}
+	}
+	return DailyUsage{}, false
+}
+
+// estimateDailyBudget returns a comfortable daily token budget.
+// It is the median of previous days' totals, or DefaultDailySessions full
+// sessions when there is not enough history.
+func estimateDailyBudget(days []DailyUsage, currentTime time.Time, sessionLimit int) int {
+	today := currentTime.Format(DateFormat)
+
+	var totals []int
+	for _, day := range days {
+		if day.Date != today && day.TotalTokens > 0 {
+			totals = append(totals, day.TotalTokens)
+		}
+	}
+
+	if len(totals) < MinHistoricalSessions {
+		return sessionLimit * DefaultDailySessions
+	}
+	return CalculateMedianTokens(totals)
+}
+
+// calculateDailyMetrics calculates today's token usage against the daily budget
+func calculateDailyMetrics(days []DailyUsage, currentTime time.Time, sessionLimit, budget int) TokenMetrics {
+	if budget <= 0 {
+		budget = estimateDailyBudget(days, currentTime, sessionLimit)
+	}
+
+	day, _ := findDay(days, currentTime)
+	percentage := 0.0
+	if budget > 0 {
+		percentage = float64(day.TotalTokens) / float64(budget) * 100
+	}
+
+	return TokenMetrics{
+		Used:       day.TotalTokens,
+		Limit:      budget,
+		Percentage: percentage,
+		Remaining:  budget - day.TotalTokens,
+	}
+}
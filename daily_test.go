@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEstimateDailyBudget(t *testing.T) {
+	now := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+	days := []DailyUsage{
+		{Date: "2025-06-15", TotalTokens: 10000},
+		{Date: "2025-06-16", TotalTokens: 30000},
+		{Date: "2025-06-17", TotalTokens: 20000},
+		{Date: "2025-06-18", TotalTokens: 50000},
+		{Date: "2025-06-19", TotalTokens: 40000},
+		{Date: "2025-06-20", TotalTokens: 90000}, // Today is excluded
+	}
+
+	if budget := estimateDailyBudget(days, now, 7000); budget != 30000 {
+		t.Errorf("estimateDailyBudget() = %d, expected 30000", budget)
+	}
+
+	// Not enough history falls back to full sessions
+	if budget := estimateDailyBudget(days[:2], now, 7000); budget != 7000*DefaultDailySessions {
+		t.Errorf("estimateDailyBudget() = %d, expected %d", budget, 7000*DefaultDailySessions)
+	}
+}
+
+func TestCalculateDailyMetrics(t *testing.T) {
+	now := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+	days := []DailyUsage{{Date: "2025-06-20", TotalTokens: 15000}}
+
+	metrics := calculateDailyMetrics(days, now, 7000, 60000)
+	if metrics.Used != 15000 || metrics.Limit != 60000 || metrics.Percentage != 25 {
+		t.Errorf("metrics = %+v, expected 15000/60000 (25%%)", metrics)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// dashboardHTML is the single-page dashboard served at / by cctop serve. It has no
+// external dependencies and redraws from the /v1/events stream.
+//
+//go:embed dashboard.html
+var dashboardHTML []byte
+
+// APIEvent is an update on /v1/events, everything the dashboard draws
+type APIEvent struct {
+	Error         string        `json:"error,omitempty"` // Last refresh error, e.g. no active session
+	Session       *StatusReport `json:"session,omitempty"`
+	BurnRate      *APIBurnRate  `json:"burnRate,omitempty"`
+	Estimate      *APIEstimate  `json:"estimate,omitempty"`
+	RecentUsage   []int         `json:"recentUsage,omitempty"` // Tokens per bucket, oldest first
+	BucketMinutes float64       `json:"bucketMinutes"`
+	Blocks        []Block       `json:"blocks"` // The last DashboardBlocks blocks
+}
+
+// serveDashboard writes the dashboard page
+func serveDashboard(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(dashboardHTML)
+}
+
+// event builds the current update, called with the read lock held
+func (a *APIServer) event() APIEvent {
+	event := APIEvent{
+		BucketMinutes: SparklineWindow.Minutes() / SparklineBuckets,
+		Blocks:        a.blocks[max(len(a.blocks)-DashboardBlocks, 0):],
+	}
+	if a.err != nil {
+		event.Error = a.err.Error()
+	}
+	var idle *NoActiveSessionError
+	if a.report != nil && !errors.As(a.err, &idle) {
+		event.Session = a.report
+		event.BurnRate = &a.burnRate
+		event.Estimate = &a.estimate
+		event.RecentUsage = a.recent
+	}
+	return event
+}
+
+// serveEvents streams an update as a server-sent event after every refresh, starting
+// with the current state, and a comment every SSEKeepAlive so proxies keep it open
+func (a *APIServer) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeAPIError(w, http.StatusInternalServerError, errors.New("streaming unsupported"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	for {
+		a.mu.RLock()
+		changed := a.changed
+		data, err := json.Marshal(a.event())
+		a.mu.RUnlock()
+		if err != nil {
+			logger.Errorf("dashboard event: %v", err)
+			return
+		}
+		fmt.Fprintf(w, "event: update\ndata: %s\n\n", data)
+		flusher.Flush()
+
+		for waiting := true; waiting; {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-changed:
+				waiting = false
+			case <-time.After(SSEKeepAlive):
+				fmt.Fprint(w, ": keep-alive\n\n")
+				flusher.Flush()
+			}
+		}
+	}
+}
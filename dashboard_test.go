@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServeDashboard(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	serveDashboard(recorder, httptest.NewRequest("GET", "/", nil))
+	if !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/html") {
+		t.Errorf("Content-Type = %q, expected text/html", recorder.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(recorder.Body.String(), `new EventSource("v1/events")`) {
+		t.Error("dashboard page does not subscribe to v1/events")
+	}
+}
+
+// nextEvent reads the next update from a server-sent event stream
+func nextEvent(t *testing.T, reader *bufio.Reader) APIEvent {
+	t.Helper()
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading /v1/events: %v", err)
+		}
+		if data, ok := strings.CutPrefix(line, "data: "); ok {
+			var event APIEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				t.Fatalf("invalid event %q: %v", data, err)
+			}
+			return event
+		}
+	}
+}
+
+func TestServeEvents(t *testing.T) {
+	oldConfig, oldCurrency, oldEstimator := config, currency, estimator
+	defer func() { config, currency, estimator = oldConfig, oldCurrency, oldEstimator }()
+	config = NewConfig()
+	currency = NewCurrencyConverter("USD", 0)
+	estimator = NewTokenLimitEstimator()
+
+	api := NewAPIServer()
+	server := httptest.NewServer(api.Handler())
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	request, _ := http.NewRequestWithContext(ctx, "GET", server.URL+"/v1/events", nil)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if response.Header.Get("Content-Type") != "text/event-stream" {
+		t.Errorf("Content-Type = %q, expected text/event-stream", response.Header.Get("Content-Type"))
+	}
+	reader := bufio.NewReader(response.Body)
+
+	// The current state is sent right away
+	if event := nextEvent(t, reader); event.Session != nil || event.Error == "" {
+		t.Errorf("first event = %+v, expected an error before the first refresh", event)
+	}
+
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	session := newTestSession(start, 3500, 7000)
+	session.AllBlocks = make([]Block, DashboardBlocks+2)
+	session.RecentUsage = []int{100, 200}
+	api.Update(session, nil, "pro", start.Add(time.Hour))
+
+	event := nextEvent(t, reader)
+	if event.Session == nil || event.Session.Tokens.Used != 3500 || event.Estimate == nil || event.BurnRate == nil {
+		t.Fatalf("event after a refresh = %+v, expected the session", event)
+	}
+	if len(event.Blocks) != DashboardBlocks || len(event.RecentUsage) != 2 || event.BucketMinutes != 2 {
+		t.Errorf("event has %d blocks, recent usage %v per %v minutes, expected %d blocks, [100 200] per 2",
+			len(event.Blocks), event.RecentUsage, event.BucketMinutes, DashboardBlocks)
+	}
+
+	// Idle sessions clear the live data
+	api.Update(nil, &NoActiveSessionError{}, "pro", start.Add(6*time.Hour))
+	if event := nextEvent(t, reader); event.Session != nil || len(event.Blocks) != DashboardBlocks {
+		t.Errorf("event while idle = %+v, expected no session and the last blocks", event)
+	}
+}
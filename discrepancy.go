@@ -0,0 +1,206 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// ReportedUsage is what Claude Code itself said about the session limit,
+// from /status output or a limit warning
+type ReportedUsage struct {
+	Percentage float64   // Percent of the session limit used, -1 when not stated
+	Reset      time.Time // Zero when not stated
+}
+
+// Discrepancy compares Claude Code's reported usage with cctop's estimate at the same moment
+type Discrepancy struct {
+	Time                time.Time `json:"time"`
+	Text                string    `json:"text"`
+	ReportedPercentage  float64   `json:"reportedPercentage"` // -1 when not stated
+	EstimatedPercentage float64   `json:"estimatedPercentage"`
+	Tokens              int       `json:"tokens"`
+	EstimatedLimit      int       `json:"estimatedLimit"`
+	ImpliedLimit        int       `json:"impliedLimit,omitempty"`       // Limit that makes the reported percentage true
+	ResetOffset         float64   `json:"resetOffsetMinutes,omitempty"` // Reported reset minus estimated reset
+}
+
+var discrepancyRecalibrate bool
+
+var (
+	percentUsedPattern  = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)\s*%\s*(?:of\s+(?:your\s+)?(?:session\s+)?(?:limit\s+)?)?used`)
+	limitReachedPattern = regexp.MustCompile(`(?i)(usage\s+)?limit\s+(reached|hit)`)
+	resetPattern        = regexp.MustCompile(`(?i)resets?\s+(?:at\s+)?(\d{1,2})(?::(\d{2}))?\s*(am|pm)?(?:\s*\(([^)]+)\))?`)
+)
+
+// defaultDiscrepancyLogPath returns the discrepancy log next to the estimator state
+func defaultDiscrepancyLogPath() string {
+	stateDir := cctopStateDir()
+	if stateDir == "" {
+		return ""
+	}
+	return filepath.Join(stateDir, "discrepancies.jsonl")
+}
+
+// runDiscrepancy compares pasted Claude Code output (arguments or stdin) with the current estimate
+func runDiscrepancy(cmd *cobra.Command, args []string) {
+	text := strings.Join(args, " ")
+	if text == "" {
+		input, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error reading stdin:", err)
+			os.Exit(1)
+		}
+		text = hookMessage(input)
+	}
+
+	now := time.Now()
+	reported, ok := parseReportedUsage(text, now, display.timezone)
+	if !ok {
+		fmt.Fprintln(os.Stderr, "No usage percentage, limit message or reset time found in the text")
+		os.Exit(1)
+	}
+
+	report, err := currentStatusReport(now)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	discrepancy := newDiscrepancy(text, reported, report, now)
+	fmt.Print(formatDiscrepancy(discrepancy, display.timezone))
+	logger.Infof("discrepancy: Claude %.0f%%, cctop %.1f%%, implied limit %d", discrepancy.ReportedPercentage, discrepancy.EstimatedPercentage, discrepancy.ImpliedLimit)
+	if err := appendDiscrepancy(defaultDiscrepancyLogPath(), discrepancy); err != nil {
+		logger.Warnf("discrepancy log: %v", err)
+	}
+
+	if discrepancyRecalibrate {
+		if discrepancy.ImpliedLimit == 0 {
+			fmt.Printf("Not recalibrating: the text gives no usage percentage of at least %.0f%%\n", MinImpliedPercentage)
+			return
+		}
+		estimator.RecordReportedLimit(report.BlockStart, discrepancy.ImpliedLimit, discrepancy.EstimatedLimit)
+		fmt.Printf("Recorded %s as an observed limit; future estimates move towards it\n", formatNumber(discrepancy.ImpliedLimit))
+	}
+}
+
+// hookMessage extracts the message from a Claude Code hook's JSON input, or returns the input as text
+func hookMessage(input []byte) string {
+	var event struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(input, &event); err == nil && event.Message != "" {
+		return event.Message
+	}
+	return string(input)
+}
+
+// parseReportedUsage finds the used percentage, a reached limit and the reset time in
+// Claude Code output. Reset times without a date are the next occurrence after now.
+func parseReportedUsage(text string, now time.Time, loc *time.Location) (ReportedUsage, bool) {
+	reported := ReportedUsage{Percentage: -1}
+	if match := percentUsedPattern.FindStringSubmatch(text); match != nil {
+		reported.Percentage, _ = strconv.ParseFloat(match[1], 64)
+	} else if limitReachedPattern.MatchString(text) {
+		reported.Percentage = 100
+	}
+
+	if match := resetPattern.FindStringSubmatch(text); match != nil {
+		hour, _ := strconv.Atoi(match[1])
+		minute, _ := strconv.Atoi(match[2])
+		switch strings.ToLower(match[3]) {
+		case "pm":
+			if hour < 12 {
+				hour += 12
+			}
+		case "am":
+			if hour == 12 {
+				hour = 0
+			}
+		}
+		if zone, err := time.LoadLocation(match[4]); match[4] != "" && err == nil {
+			loc = zone
+		}
+		if hour < 24 && minute < 60 {
+			local := now.In(loc)
+			reset := time.Date(local.Year(), local.Month(), local.Day(), hour, minute, 0, 0, loc)
+			if reset.Before(now) {
+				reset = reset.AddDate(0, 0, 1)
+			}
+			reported.Reset = reset
+		}
+	}
+
+	return reported, reported.Percentage >= 0 || !reported.Reset.IsZero()
+}
+
+// newDiscrepancy compares reported usage with the status report
+func newDiscrepancy(text string, reported ReportedUsage, report *StatusReport, now time.Time) Discrepancy {
+	discrepancy := Discrepancy{
+		Time:                now,
+		Text:                strings.TrimSpace(text),
+		ReportedPercentage:  reported.Percentage,
+		EstimatedPercentage: report.Tokens.Percentage,
+		Tokens:              report.Tokens.Used,
+		EstimatedLimit:      report.Tokens.Limit,
+	}
+	if reported.Percentage >= MinImpliedPercentage {
+		discrepancy.ImpliedLimit = int(float64(report.Tokens.Used) / (reported.Percentage / 100))
+	}
+	if !reported.Reset.IsZero() {
+		discrepancy.ResetOffset = reported.Reset.Sub(report.EndTime).Minutes()
+	}
+	return discrepancy
+}
+
+// formatDiscrepancy describes a discrepancy for the terminal
+func formatDiscrepancy(d Discrepancy, loc *time.Location) string {
+	var buffer strings.Builder
+	if d.ReportedPercentage >= 0 {
+		fmt.Fprintf(&buffer, "Claude: %.0f%% used\n", d.ReportedPercentage)
+	}
+	fmt.Fprintf(&buffer, "cctop:  %.1f%% used (%s/%s)\n", d.EstimatedPercentage, formatNumber(d.Tokens), formatNumber(d.EstimatedLimit))
+	if d.ReportedPercentage >= 0 {
+		fmt.Fprintf(&buffer, "Difference: %+.1f points\n", d.EstimatedPercentage-d.ReportedPercentage)
+	}
+	if d.ImpliedLimit > 0 && d.EstimatedLimit > 0 {
+		fmt.Fprintf(&buffer, "Implied limit: %s (%+.1f%% vs. estimate)\n", formatNumber(d.ImpliedLimit),
+			float64(d.ImpliedLimit-d.EstimatedLimit)/float64(d.EstimatedLimit)*100)
+	}
+	switch {
+	case d.ResetOffset > 0:
+		fmt.Fprintf(&buffer, "Reset: Claude's is %s after cctop's\n", formatTime(d.ResetOffset))
+	case d.ResetOffset < 0:
+		fmt.Fprintf(&buffer, "Reset: Claude's is %s before cctop's\n", formatTime(-d.ResetOffset))
+	}
+	return buffer.String()
+}
+
+// appendDiscrepancy adds a discrepancy to the JSON lines log at path
+func appendDiscrepancy(path string, d Discrepancy) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
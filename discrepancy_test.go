@@ -0,0 +1,76 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseReportedUsage(t *testing.T) {
+	now := time.Date(2025, 6, 20, 13, 10, 0, 0, time.UTC)
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+
+	tests := []struct {
+		text            string
+		expectedPercent float64
+		expectedReset   time.Time
+		ok              bool
+	}{
+		{"Current session 45% used · Resets 3pm", 45, time.Date(2025, 6, 20, 15, 0, 0, 0, time.UTC), true},
+		{"Session: 62.5% of limit used, resets at 15:30", 62.5, time.Date(2025, 6, 20, 15, 30, 0, 0, time.UTC), true},
+		{"Claude usage limit reached. Your limit will reset at 4pm (Europe/Berlin).", 100, time.Date(2025, 6, 20, 16, 0, 0, 0, berlin), true},
+		{"Approaching usage limit · resets at 1am", -1, time.Date(2025, 6, 21, 1, 0, 0, 0, time.UTC), true},
+		{"Resets 12am", -1, time.Date(2025, 6, 21, 0, 0, 0, 0, time.UTC), true},
+		{"All good here", -1, time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		reported, ok := parseReportedUsage(tt.text, now, time.UTC)
+		if ok != tt.ok {
+			t.Errorf("parseReportedUsage(%q) ok = %v, expected %v", tt.text, ok, tt.ok)
+			continue
+		}
+		if reported.Percentage != tt.expectedPercent {
+			t.Errorf("parseReportedUsage(%q) percentage = %.1f, expected %.1f", tt.text, reported.Percentage, tt.expectedPercent)
+		}
+		if !reported.Reset.Equal(tt.expectedReset) {
+			t.Errorf("parseReportedUsage(%q) reset = %v, expected %v", tt.text, reported.Reset, tt.expectedReset)
+		}
+	}
+}
+
+func TestNewDiscrepancy(t *testing.T) {
+	now := time.Date(2025, 6, 20, 13, 0, 0, 0, time.UTC)
+	report := &StatusReport{
+		Tokens:  TokenMetrics{Used: 45000, Limit: 140000, Percentage: 45000.0 / 140000 * 100},
+		EndTime: time.Date(2025, 6, 20, 15, 0, 0, 0, time.UTC),
+	}
+
+	d := newDiscrepancy("45% used", ReportedUsage{Percentage: 50, Reset: report.EndTime.Add(30 * time.Minute)}, report, now)
+	if d.ImpliedLimit != 90000 {
+		t.Errorf("ImpliedLimit = %d, expected 90000", d.ImpliedLimit)
+	}
+	if d.ResetOffset != 30 {
+		t.Errorf("ResetOffset = %.0f, expected 30", d.ResetOffset)
+	}
+	output := formatDiscrepancy(d, time.UTC)
+	for _, expected := range []string{"Claude: 50% used", "Implied limit: 90,000 (-35.7% vs. estimate)", "30m after"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("formatDiscrepancy() missing %q:\n%s", expected, output)
+		}
+	}
+
+	// A reset time alone or a tiny percentage implies no limit
+	if d := newDiscrepancy("", ReportedUsage{Percentage: 2}, report, now); d.ImpliedLimit != 0 {
+		t.Errorf("ImpliedLimit = %d from 2%%, expected 0", d.ImpliedLimit)
+	}
+}
+
+func TestHookMessage(t *testing.T) {
+	if got := hookMessage([]byte(`{"session_id":"x","message":"Claude usage limit reached"}`)); got != "Claude usage limit reached" {
+		t.Errorf("hookMessage(json) = %q, expected the message field", got)
+	}
+	if got := hookMessage([]byte("45% used")); got != "45% used" {
+		t.Errorf("hookMessage(text) = %q, expected the text", got)
+	}
+}
@@ -1,17 +1,20 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"strings"
+	"text/template"
 	"time"
-
-	"github.com/fatih/color"
 )
 
 // Display handles all terminal display operations
 type Display struct {
 	timezone *time.Location
 	config   *DisplayConfig
+	layout   *template.Template // Custom session view from --format-file (nil for built-in)
+	width    int                // Terminal columns the views are laid out for (0 = unknown)
 }
 
 // NewDisplay creates a new Display instance
@@ -35,87 +38,562 @@ func (d *Display) Render(session *Session, estimator *TokenLimitEstimator, plan
 		CurrentTime: time.Now(),
 		Timezone:    d.timezone,
 		BurnRate:    session.BurnRate,
+		Alerts:      session.Alerts,
+	}
+
+	if d.layout != nil {
+		return d.renderLayout(session, estimator, plan)
 	}
 
 	// Resolve actual plan for display (auto -> detected plan)
 	displayPlan := estimator.GetActualPlan(plan, session.AllBlocks)
 
-	// Build display sections
+	// Build display sections. Panels sit between the token and session bars, or in a
+	// second column on wide terminals.
 	d.renderHeader(&buffer, session)
-	d.renderTokenBar(&buffer, session.Metrics.Tokens)
-	d.renderTimeBar(&buffer, session.Metrics.Time)
+	var bars, moreBars, panels strings.Builder
+	if session.NoLimit {
+		if session.TracksSpend() {
+			d.renderSpendBar(&bars, session)
+		}
+		d.renderTokenUsage(&bars, session)
+	} else {
+		tokens := session.Metrics.Tokens
+		if session.LimitChange != nil {
+			tokens = session.LimitChange.Animate(tokens, d.config.CurrentTime)
+		}
+		d.renderTokenBar(&bars, tokens, session.Plan, session.Typical, session.SoftLimit)
+		if session.LimitChange != nil {
+			d.renderLimitChange(&bars, *session.LimitChange)
+		}
+		d.renderConfidence(&bars, session.Metrics.Tokens.Limit, limitConfidence(session.AllBlocks))
+	}
+	if session.SoftLimit.Limit > 0 {
+		d.renderSoftLimit(&bars, session.SoftLimit)
+	}
+	if len(session.RecentUsage) > 0 {
+		d.renderUsageSparkline(&panels, session.RecentUsage)
+	}
+	if session.Cache.ReadTokens+session.Cache.CreationTokens > 0 {
+		d.renderCacheInfo(&panels, session.Cache)
+	}
+	if config.ModelBars {
+		d.renderModelBars(&panels, session.ModelShares())
+	}
+	if len(session.Projects) > 0 {
+		d.renderProjectBars(&panels, session.Projects, ProjectPanelRows)
+	}
+	if len(session.Team) > 0 {
+		d.renderTeam(&panels, session.Team, session.Metrics.Tokens)
+	} else if len(session.ProfileUsage) > 0 {
+		d.renderProfileUsage(&panels, session.ProfileUsage, session.Profile)
+	}
+	d.renderTimeBar(&moreBars, session.Metrics.Time, d.sessionIdle(session), session.StartTime)
+	if config.ForecastChart {
+		d.renderForecastChart(&moreBars, session)
+	}
+	if activity := session.Activity(d.config.CurrentTime); config.ActiveTime && activity != nil {
+		d.renderActivity(&moreBars, *activity)
+	}
+	if config.WeeklyBar {
+		d.renderWeeklyBar(&moreBars, session.Weekly)
+	}
+	if config.DailyBar {
+		d.renderDailyBar(&moreBars, session.DailyTokens)
+	}
+	if session.Cost.Budget > 0 && !session.TracksSpend() {
+		d.renderCostBar(&moreBars, session.Cost)
+	}
+	if d.wide() && panels.Len() > 0 {
+		buffer.WriteString(joinColumns(bars.String()+moreBars.String(), panels.String(), d.mainWidth(), ColumnGap))
+	} else {
+		buffer.WriteString(bars.String() + panels.String() + moreBars.String())
+	}
+	buffer.WriteString("\n")
 	d.renderStatusBar(&buffer, session, displayPlan)
+	d.renderCycleInfo(&buffer, session.Cycle)
 
 	// Add notifications
 	d.renderNotifications(&buffer, session, plan)
+	if session.BreakReminder != "" {
+		fmt.Fprintf(&buffer, "\n%s", infoString("Break: %s", session.BreakReminder))
+	}
 
 	// Add estimation info
 	d.renderEstimationInfo(&buffer, estimator, session, displayPlan)
 
-	return buffer.String()
+	return d.fitWidth(buffer.String())
 }
 
 // renderHeader renders the header section
 func (d *Display) renderHeader(buffer *strings.Builder, session *Session) {
-	fmt.Fprintf(buffer, "cctop - %s  cost: $%.2f  burn rate: %.2f tokens/min\n\n",
+	fmt.Fprintf(buffer, "cctop - %s  cost: %s  burn rate: %.2f tokens/min (%s/h)",
 		d.config.CurrentTime.Format("15:04:05"),
-		session.TodayCost,
-		d.config.BurnRate)
+		formatCost(session.TodayCost),
+		d.config.BurnRate,
+		formatCost(session.CostBurnRate))
+	if rates := session.RecentRates; rates != nil {
+		if hasWindow(session.WindowRates, InstantBurnWindow) {
+			// The instantaneous rate is the window line's 5m rate
+			fmt.Fprintf(buffer, "  %s", mutedString("smoothed %s/min", formatNumber(int(rates.Smoothed))))
+		} else {
+			// Format: "now 320/min  smoothed 210/min"
+			fmt.Fprintf(buffer, "  %s", mutedString("now %s/min  smoothed %s/min",
+				formatNumber(int(rates.Instant)), formatNumber(int(rates.Smoothed))))
+		}
+	}
+	buffer.WriteString("\n")
+	if len(session.WindowRates) > 0 {
+		d.renderWindowRates(buffer, session.WindowRates)
+	}
+	buffer.WriteString("\n")
+}
+
+// renderWindowRates renders burn rates over several windows side by side, so a short
+// burst shows even while the hour-long average is low.
+// Format: "Burn    5m 1,250/min  1h 310/min"
+func (d *Display) renderWindowRates(buffer *strings.Builder, rates []WindowBurnRate) {
+	parts := make([]string, 0, len(rates))
+	for _, rate := range rates {
+		parts = append(parts, fmt.Sprintf("%s %s/min", rate.Label, formatNumber(int(rate.Rate))))
+	}
+	fmt.Fprintf(buffer, "%s\n", mutedString("Burn    %s", strings.Join(parts, "  ")))
 }
 
 // renderTokenBar renders the token usage progress bar
-func (d *Display) renderTokenBar(buffer *strings.Builder, tokens TokenMetrics) {
-	fmt.Fprintf(buffer, "Tokens  %s %.1f%% (%s/%s)\n",
-		d.createProgressBar(tokens.Percentage, false, config.Plan),
-		tokens.Percentage,
-		formatNumber(tokens.Used),
-		formatNumber(tokens.Limit))
+func (d *Display) renderTokenBar(buffer *strings.Builder, tokens TokenMetrics, plan string, typical TypicalShape, soft TokenMetrics) {
+	markers := make(map[int]string)
+	if typical.Valid && tokens.Limit > 0 {
+		markers[d.markerPosition(typical.Tokens, tokens.Limit)] = ":"
+	}
+	if soft.Limit > 0 && soft.Limit < tokens.Limit {
+		markers[d.markerPosition(soft.Limit, tokens.Limit)] = "!"
+	}
+
+	d.writeBarLine(buffer, "Tokens",
+		d.createProgressBarWithMarkers(tokens.Percentage, false, plan, markers),
+		fmt.Sprintf("%.1f%% (%s/%s)", tokens.Percentage, formatNumber(tokens.Used), formatNumber(tokens.Limit)))
+
+	if typical.Valid {
+		d.renderTypicalInfo(buffer, tokens.Used, typical)
+	}
+}
+
+// renderLimitChange highlights a limit that changed while monitoring
+// Format: "Limit   ↑ 7,000 → 35,000 (usage exceeded 7,000)"
+func (d *Display) renderLimitChange(buffer *strings.Builder, change LimitTransition) {
+	arrow := "↑"
+	if change.To < change.From {
+		arrow = "↓"
+	}
+	fmt.Fprintf(buffer, "%s\n", infoString("Limit   %s %s → %s (%s)",
+		arrow, formatNumber(change.From), formatNumber(change.To), change.Reason))
+}
+
+// renderTeam renders each member's usage against their share, and the combined usage
+func (d *Display) renderTeam(buffer *strings.Builder, team []TeamMemberUsage, combined TokenMetrics) {
+	fmt.Fprintf(buffer, "Team    combined %s/%s (%.1f%%)\n",
+		formatNumber(combined.Used), formatNumber(combined.Limit), combined.Percentage)
+	for _, member := range team {
+		name := fmt.Sprintf("%-8s", member.Name)
+		if member.Name == config.TeamMember {
+			name = infoString("%s", name)
+		}
+		usage := fmt.Sprintf("%.0f%% of %.0f%% share (%s/%s)", member.Tokens.Percentage, member.Share,
+			formatNumber(member.Tokens.Used), formatNumber(member.Tokens.Limit))
+		if member.Tokens.Percentage >= 100 {
+			usage = dangerString("%s", usage)
+		}
+		fmt.Fprintf(buffer, "  %s %s %s\n", name, d.createProgressBar(member.Tokens.Percentage, false, ""), usage)
+	}
+}
+
+// markerPosition returns the bar position of tokens within limit
+func (d *Display) markerPosition(tokens, limit int) int {
+	percentage := d.clampPercentage(float64(tokens) / float64(limit) * 100)
+	width := d.barWidth()
+	return clampInt(int(float64(width)*percentage/100), 0, width-1)
+}
+
+// renderSoftLimit shows usage against the personal soft limit
+func (d *Display) renderSoftLimit(buffer *strings.Builder, soft TokenMetrics) {
+	if soft.Remaining < 0 {
+		fmt.Fprintf(buffer, "%s\n", warningString("        %s", tr("! soft limit %s exceeded by %s",
+			formatNumber(soft.Limit), formatNumber(-soft.Remaining))))
+		return
+	}
+	fmt.Fprintf(buffer, "%s\n", mutedString("        %s", tr("! soft limit %s: %.0f%% used, %s left",
+		formatNumber(soft.Limit), soft.Percentage, formatNumber(soft.Remaining))))
+}
+
+// renderTokenUsage renders absolute usage and burn rate when no limit is known
+func (d *Display) renderTokenUsage(buffer *strings.Builder, session *Session) {
+	fmt.Fprintf(buffer, "Tokens  %s used  burn %s/min (%s/h)\n",
+		infoString("%s", formatNumber(session.Metrics.Tokens.Used)),
+		formatNumber(int(session.BurnRate)),
+		formatNumber(int(session.BurnRate*MinutesPerHour)))
+
+	reason := "no limit known yet, percentages appear after the first completed session"
+	switch {
+	case config.NoLimit:
+		reason = "no token limit (--no-limit)"
+	case config.APIPlan() && !session.TracksSpend():
+		reason = "no token limit on the API plan, set --budget to track spend"
+	case config.APIPlan():
+		reason = "no token limit on the API plan"
+	}
+	fmt.Fprintf(buffer, "%s\n", mutedString("        %s", reason))
+}
+
+// renderConfidence shows how far the estimated limit can be trusted
+func (d *Display) renderConfidence(buffer *strings.Builder, limit int, confidence Confidence) {
+	if confidence.Level == ConfidenceManual {
+		fmt.Fprintf(buffer, "%s\n", mutedString("        limit %s · manual limit", formatNumber(limit)))
+		return
+	}
+	// Format: "limit 141,000 ±18% · medium confidence · 14 sessions"
+	fmt.Fprintf(buffer, "%s\n", mutedString("        limit %s ±%.0f%% · %s confidence · %d sessions",
+		formatNumber(limit), confidence.Uncertainty*100, confidence.Level, confidence.Sessions))
+}
+
+// renderUsageSparkline shows tokens used over the last hour and whether usage is accelerating
+func (d *Display) renderUsageSparkline(buffer *strings.Builder, buckets []int) {
+	fmt.Fprintf(buffer, "        %s %s\n", infoString("%s", sparkline(buckets)),
+		mutedString("last hour, %s", usageTrend(buckets)))
+}
+
+// renderCacheInfo shows the session's prompt cache hit ratio
+func (d *Display) renderCacheInfo(buffer *strings.Builder, cache CacheMetrics) {
+	fmt.Fprintf(buffer, "%s\n", mutedString("        cache %.0f%% hit (%s read, %s written)",
+		cache.HitRatio*100, formatNumber(cache.ReadTokens), formatNumber(cache.CreationTokens)))
 }
 
-// renderTimeBar renders the session time progress bar
-func (d *Display) renderTimeBar(buffer *strings.Builder, times TimeMetrics) {
-	fmt.Fprintf(buffer, "Session %s %.1f%% (%s remaining)\n\n",
-		d.createProgressBar(times.ProgressPercentage, true, ""),
-		times.ProgressPercentage,
-		formatTime(times.MinutesRemaining))
+// renderTypicalInfo compares current usage with the typical usage at this point
+func (d *Display) renderTypicalInfo(buffer *strings.Builder, used int, typical TypicalShape) {
+	comparison := "on par with"
+	if typical.Tokens > 0 {
+		diff := float64(used-typical.Tokens) / float64(typical.Tokens) * 100
+		switch {
+		case diff > 5:
+			comparison = fmt.Sprintf("%.0f%% above", diff)
+		case diff < -5:
+			comparison = fmt.Sprintf("%.0f%% below", -diff)
+		}
+	}
+	fmt.Fprintf(buffer, "%s\n", mutedString("        : typical %s tokens by now (%d sessions), you are %s",
+		formatNumber(typical.Tokens), typical.Samples, comparison))
+}
+
+// renderModelBars renders a mini-bar for each model family's share of session tokens.
+// With model weights configured, the shares are of the weighted tokens.
+func (d *Display) renderModelBars(buffer *strings.Builder, shares []ModelShare) {
+	weighted := false
+	weightedTotal := 0
+	for _, share := range shares {
+		weighted = weighted || share.Weight != 1
+		weightedTotal += share.WeightedTokens
+	}
+
+	width := d.miniBarWidth()
+	for _, share := range shares {
+		detail := formatNumber(share.Tokens)
+		if weighted {
+			detail = fmt.Sprintf("%s x%g", detail, share.Weight)
+		}
+		filled := clampInt(int(float64(width)*share.Percentage/100), 0, width)
+		fmt.Fprintf(buffer, "  %-6s [%s%s] %5.1f%% (%s)\n",
+			share.Family,
+			infoString("%s", strings.Repeat("|", filled)),
+			strings.Repeat(" ", width-filled),
+			share.Percentage,
+			detail)
+	}
+
+	if weighted {
+		fmt.Fprintf(buffer, "%s\n", mutedString("        weighted: %s tokens", formatNumber(weightedTotal)))
+	}
+}
+
+// renderProjectBars renders a mini-bar for each of the top projects by token usage.
+// A maxRows of 0 or less renders every project.
+func (d *Display) renderProjectBars(buffer *strings.Builder, projects []ProjectUsage, maxRows int) {
+	buffer.WriteString("Projects\n")
+	width := d.miniBarWidth()
+	for i, project := range projects {
+		if maxRows > 0 && i >= maxRows {
+			fmt.Fprintf(buffer, "%s\n", mutedString("  ... %d more", len(projects)-maxRows))
+			break
+		}
+		filled := clampInt(int(float64(width)*project.Percentage/100), 0, width)
+		fmt.Fprintf(buffer, "  [%s%s] %5.1f%% %-10s %s\n",
+			accentString("%s", strings.Repeat("|", filled)),
+			strings.Repeat(" ", width-filled),
+			project.Percentage,
+			formatNumber(project.Tokens),
+			project.Name)
+	}
+}
+
+// RenderProjects renders the full per-project breakdown for the projects command
+func (d *Display) RenderProjects(projects []ProjectUsage) string {
+	if len(projects) == 0 {
+		return "No project usage found in the current session\n"
+	}
+	var buffer strings.Builder
+	d.renderProjectBars(&buffer, projects, 0)
+	return buffer.String()
+}
+
+// renderProfileUsage renders per-profile token usage in the session window
+func (d *Display) renderProfileUsage(buffer *strings.Builder, usage []ProfileUsage, selected string) {
+	buffer.WriteString("Profiles")
+	for _, profile := range usage {
+		label := profile.Name
+		if profile.Name == selected {
+			label = infoString("%s", profile.Name)
+		}
+		fmt.Fprintf(buffer, "  %s %s", label, formatNumber(profile.Tokens))
+	}
+	buffer.WriteString("\n")
+}
+
+// sessionIdle returns the stretches of the session without messages, none unless
+// --idle-segments is on. Message times are also loaded for other panels.
+func (d *Display) sessionIdle(session *Session) []Interval {
+	if !config.IdleSegments {
+		return nil
+	}
+	return idleIntervals(session.MessageTimes, session.StartTime, d.config.CurrentTime, IdleThreshold)
+}
+
+// renderTimeBar renders the session time progress bar, dimming cells that were mostly idle
+func (d *Display) renderTimeBar(buffer *strings.Builder, times TimeMetrics, idle []Interval, start time.Time) {
+	bar := d.createProgressBar(times.ProgressPercentage, true, "")
+	if len(idle) > 0 {
+		bar = d.createIdleTimeBar(times.ProgressPercentage, idle, start)
+	}
+	d.writeBarLine(buffer, "Session", bar,
+		fmt.Sprintf("%.1f%% (%s remaining)", times.ProgressPercentage, formatTime(times.MinutesRemaining)))
+}
+
+// renderActivity renders active time against wall time elapsed in the session
+// Format: "Active  1h 42m of 3h 10m elapsed (54%)"
+func (d *Display) renderActivity(buffer *strings.Builder, activity ActivityMetrics) {
+	fmt.Fprintf(buffer, "%s\n", mutedString("Active  %s of %s elapsed (%.0f%%)",
+		formatTime(activity.ActiveMinutes),
+		formatTime(activity.ElapsedMinutes),
+		activity.Percentage))
+}
+
+// renderWeeklyBar renders tokens over the rolling weekly window against the weekly limit
+func (d *Display) renderWeeklyBar(buffer *strings.Builder, weekly WeeklyMetrics) {
+	reset := "no usage"
+	if !weekly.Reset.IsZero() {
+		reset = "resets " + weekly.Reset.In(d.timezone).Format("Mon 15:04")
+	}
+	d.writeBarLine(buffer, "Week", d.createProgressBar(weekly.Tokens.Percentage, false, ""),
+		fmt.Sprintf("%.1f%% (%s/%s, %s)", weekly.Tokens.Percentage, formatNumber(weekly.Tokens.Used), formatNumber(weekly.Tokens.Limit), reset))
+}
+
+// renderDailyBar renders today's tokens across all sessions against the daily budget
+func (d *Display) renderDailyBar(buffer *strings.Builder, daily TokenMetrics) {
+	d.writeBarLine(buffer, "Today", d.createProgressBar(daily.Percentage, false, ""),
+		fmt.Sprintf("%.1f%% (%s/%s)", daily.Percentage, formatNumber(daily.Used), formatNumber(daily.Limit)))
+}
+
+// renderCostBar renders spending against the cost budget
+func (d *Display) renderCostBar(buffer *strings.Builder, cost CostMetrics) {
+	d.writeBarLine(buffer, "Cost", d.createProgressBar(cost.Percentage, false, ""),
+		fmt.Sprintf("%.1f%% (%s/%s per %s)", cost.Percentage, formatCost(cost.Spent), formatCost(cost.Budget), cost.Period))
 }
 
 // renderStatusBar renders the status information bar
 func (d *Display) renderStatusBar(buffer *strings.Builder, session *Session, plan string) {
-	predictedEnd := session.GetPredictedEndTime(d.config.CurrentTime)
+	predictedEnd := session.PredictedEndRange(d.config.CurrentTime)
 
-	fmt.Fprintf(buffer, "Tokens: %s/%s (%s)  Estimate: %s  Reset: %s  ",
-		formatNumber(session.Metrics.Tokens.Used),
-		formatNumber(session.Metrics.Tokens.Limit),
-		plan,
-		predictedEnd.In(d.timezone).Format("15:04"),
-		session.EndTime.In(d.timezone).Format("15:04"))
+	if session.TracksSpend() {
+		status := session.GetStatus()
+		statusText := colorizeStatus(status, "%s", tr("Status: %s", statusLabel(status)))
+		if status != "OK" {
+			statusText += " " + mutedString("(%s)", session.StatusReason(d.config.CurrentTime))
+		}
+		buffer.WriteString(strings.Join([]string{
+			tr("Spend: %s/%s per %s (%s)", formatCost(session.Cost.Spent), formatCost(session.Cost.Budget), session.Cost.Period, plan),
+			tr("Reset: %s", session.EndTime.In(d.timezone).Format("15:04")),
+			statusText,
+		}, d.statusSeparator()))
+		return
+	}
+	if session.NoLimit {
+		buffer.WriteString(strings.Join([]string{
+			tr("Tokens: %s (no limit)", formatNumber(session.Metrics.Tokens.Used)),
+			tr("Reset: %s", session.EndTime.In(d.timezone).Format("15:04")),
+			colorizeStatus("OK", "%s", tr("Status: %s", statusLabel(session.GetStatus()))),
+		}, d.statusSeparator()))
+		return
+	}
 
 	// Status message with color
 	status := session.GetStatus()
-	switch session.GetStatusColor() {
+	statusText := colorizeStatus(status, "%s", tr("Status: %s", statusLabel(status)))
+	if status != "OK" {
+		statusText += " " + mutedString("(%s)", session.StatusReason(d.config.CurrentTime))
+	}
+	if config.TokenLimit > 0 {
+		plan = tr("manual limit")
+	}
+	parts := []string{
+		tr("Tokens: %s/%s (%s)", formatNumber(session.Metrics.Tokens.Used), formatNumber(session.Metrics.Tokens.Limit), plan),
+		tr("Estimate: %s", d.formatTimeRange(predictedEnd)),
+		tr("Reset: %s", session.EndTime.In(d.timezone).Format("15:04")),
+		statusText,
+	}
+	if session.ModelSwitch != nil {
+		parts = append(parts, d.formatModelSwitch(*session.ModelSwitch))
+	}
+	buffer.WriteString(strings.Join(parts, d.statusSeparator()))
+}
+
+// formatModelSwitch formats the predicted Opus to Sonnet switch
+// Format: "Opus remaining: ~35m", or "Opus: switched to Sonnet at 20%"
+func (d *Display) formatModelSwitch(modelSwitch ModelSwitch) string {
+	switch {
+	case modelSwitch.Switched:
+		return mutedString("%s", tr("Opus: switched to Sonnet at %.0f%%", modelSwitch.Percent))
+	case modelSwitch.At.IsZero():
+		return accentString("%s", tr("Opus remaining: %s tokens", formatNumber(modelSwitch.Remaining)))
+	default:
+		return accentString("%s", tr("Opus remaining: ~%s", formatTime(modelSwitch.At.Sub(d.config.CurrentTime).Minutes())))
+	}
+}
+
+// formatTimeRange formats a range as "14:05–15:20", or a single time when both ends match
+func (d *Display) formatTimeRange(r Interval) string {
+	start := r.Start.In(d.timezone).Format(TimeFormatShort)
+	end := r.End.In(d.timezone).Format(TimeFormatShort)
+	if start == end {
+		return start
+	}
+	return start + "–" + end
+}
+
+// colorizeStatus formats text in the color of the given status
+func colorizeStatus(status, format string, args ...interface{}) string {
+	switch statusColor(status) {
 	case "red":
-		buffer.WriteString(color.RedString("Status: %s", status))
+		return dangerString(format, args...)
 	case "yellow":
-		buffer.WriteString(color.YellowString("Status: %s", status))
+		return warningString(format, args...)
 	default:
-		buffer.WriteString(color.GreenString("Status: %s", status))
+		return okString(format, args...)
 	}
 }
 
+// RenderEvents renders status transitions, newest first.
+// A maxRows of 0 or less renders every event.
+func (d *Display) RenderEvents(events []StatusEvent, maxRows int) string {
+	if len(events) == 0 {
+		return "No status changes recorded yet\n"
+	}
+
+	var buffer strings.Builder
+	buffer.WriteString("Status changes\n\n")
+	for i := len(events) - 1; i >= 0; i-- {
+		if maxRows > 0 && len(events)-1-i >= maxRows {
+			break
+		}
+		event := events[i]
+		fmt.Fprintf(&buffer, "%s %s %s\n",
+			event.Time.In(d.timezone).Format("01-02 15:04"),
+			colorizeStatus(event.Status, "%s:", statusLabel(event.Status)),
+			event.DisplayReason())
+	}
+	return buffer.String()
+}
+
+// RenderLimitHistory renders a daily chart of the estimated limit over the last days,
+// whether it settled in the last week, and the most recent changes with their inputs
+func (d *Display) RenderLimitHistory(changes []LimitChange, currentTime time.Time, days int) string {
+	if len(changes) == 0 || days < 1 {
+		return "No estimated limits recorded yet\n"
+	}
+
+	var buffer strings.Builder
+	limits := dailyLimits(changes, currentTime, days)
+	first := 0
+	for first < len(limits)-1 && limits[first] == 0 {
+		first++
+	}
+	fmt.Fprintf(&buffer, "Estimated limit, last %d days\n\n", days)
+	fmt.Fprintf(&buffer, "%s  %s -> %s\n", infoString("%s", sparkline(limits)),
+		formatNumber(limits[first]), formatNumber(limits[len(limits)-1]))
+	fmt.Fprintf(&buffer, "%s\n\n", mutedString("Last 7 days: %s", limitTrend(changes, currentTime)))
+
+	fmt.Fprintf(&buffer, "%-11s  %12s  %7s  %-8s  %-5s  %8s  %10s  %s\n",
+		"Time", "Limit", "Change", "Method", "Plan", "Sessions", "Tokens/msg", "Hits")
+	for i := len(changes) - 1; i >= 0 && len(changes)-i <= LimitHistoryRows; i-- {
+		change := changes[i]
+		delta := ""
+		if i > 0 && changes[i-1].Limit > 0 {
+			delta = fmt.Sprintf("%+.1f%%", float64(change.Limit-changes[i-1].Limit)/float64(changes[i-1].Limit)*100)
+		}
+		method := change.Method
+		if change.ColdStart {
+			method = "cold"
+		} else if change.Coarse {
+			method = "coarse"
+		}
+		fmt.Fprintf(&buffer, "%-11s  %12s  %7s  %-8s  %-5s  %8d  %10s  %d\n",
+			change.Time.In(d.timezone).Format("01-02 15:04"),
+			formatNumber(change.Limit),
+			delta,
+			method,
+			change.Plan,
+			change.Sessions,
+			formatNumber(change.TokensPerMsg),
+			change.LimitHits)
+	}
+	return buffer.String()
+}
+
+// renderCycleInfo renders billing cycle-to-date usage
+func (d *Display) renderCycleInfo(buffer *strings.Builder, cycle CycleUsage) {
+	fmt.Fprintf(buffer, "\nCycle: %s  %s tokens  (%s - %s)",
+		formatCost(cycle.Cost),
+		formatNumber(cycle.Tokens),
+		cycle.Cycle.Start.Format("01-02"),
+		cycle.Cycle.End.Format("01-02"))
+}
+
 // renderNotifications adds any relevant notifications
 func (d *Display) renderNotifications(buffer *strings.Builder, session *Session, plan string) {
-	if session.Metrics.Tokens.Used > 7000 && plan == "pro" && session.Metrics.Tokens.Limit > 7000 {
+	if len(session.ConflictingBlocks) > 0 {
+		fmt.Fprintf(buffer, "\n%s", warningString("%s", tr("Warning: ccusage reported %d active blocks, showing the latest (also active: %s); run `cctop doctor`",
+			len(session.ConflictingBlocks)+1, blockStarts(session.ConflictingBlocks, d.timezone))))
+	}
+	if session.Metrics.Tokens.Used > 7000 && plan == "pro" && session.Metrics.Tokens.Limit > 7000 && config.TokenLimit == 0 {
 		fmt.Fprintf(buffer, "\n%s",
-			color.HiBlackString("Note: Auto-switched to auto plan (%s tokens)",
-				formatNumber(session.Metrics.Tokens.Limit)))
+			mutedString("%s", tr("Note: Auto-switched to auto plan (%s tokens)",
+				formatNumber(session.Metrics.Tokens.Limit))))
+	}
+	weeklyLevels := config.barAlertLevels()
+	if session.WeeklyAlerts != nil {
+		weeklyLevels = *session.WeeklyAlerts
+	}
+	if weekly := session.Weekly; config.WeeklyBar && weekly.Tokens.Percentage >= weeklyLevels.Critical {
+		warning := warningString
+		if weekly.Tokens.Percentage >= 100 {
+			warning = dangerString
+		}
+		fmt.Fprintf(buffer, "\n%s", warning("%s", tr("Warning: weekly usage at %.0f%%, resets %s",
+			weekly.Tokens.Percentage, weekly.Reset.In(d.timezone).Format("Mon 15:04"))))
 	}
 }
 
 // renderEstimationInfo shows how the token limit was estimated
 func (d *Display) renderEstimationInfo(buffer *strings.Builder, estimator *TokenLimitEstimator, session *Session, displayPlan string) {
 	info := estimator.GetEstimationInfo()
-	if info.SessionIndex == 0 {
+	if info.SessionIndex == 0 || config.TokenLimit > 0 {
 		// No estimation info available
 		return
 	}
@@ -133,28 +611,57 @@ func (d *Display) renderEstimationInfo(buffer *strings.Builder, estimator *Token
 		planMessages = ProPlanMessages
 	}
 
-	// Format: "300 tokens/msg (13000 tokens, 500 msgs) x 45 messages (p40)"
+	if info.ColdStart {
+		// Format: "cold start: 150 tokens/msg (3,000 tokens, 20 msgs) x 45 messages, range 4,773-8,726"
+		fmt.Fprintf(buffer, "\n%s",
+			mutedString("cold start: %d tokens/msg (%s tokens, %d msgs) x %d messages, range %s-%s",
+				info.TokensPerMsg,
+				formatNumber(info.TotalTokens),
+				info.Messages,
+				planMessages,
+				formatNumber(info.LowerBound),
+				formatNumber(info.UpperBound)))
+		return
+	}
+
+	// Format: "300 tokens/msg (13000 tokens, 500 msgs) x 45 messages (p40)", or "(coarse)"
+	// when the JSONL logs are missing and the strategy could not be applied
+	method := estimator.GetEstimationMethod()
+	if info.Coarse {
+		method = "coarse"
+	}
 	fmt.Fprintf(buffer, "\n%s",
-		color.HiBlackString("%d tokens/msg (%s tokens, %d msgs) x %d messages (%s)",
+		mutedString("%d tokens/msg (%s tokens, %d msgs) x %d messages (%s)",
 			info.TokensPerMsg,
 			formatNumber(info.TotalTokens),
 			info.Messages,
 			planMessages,
-			estimator.GetEstimationMethod()))
+			method))
 
 	// Add link to Claude usage documentation
 	fmt.Fprintf(buffer, "\n%s",
-		color.HiBlackString("https://support.anthropic.com/en/articles/11014257-about-claude-s-max-plan-usage"))
+		mutedString("https://support.anthropic.com/en/articles/11014257-about-claude-s-max-plan-usage"))
 }
 
 // createProgressBar creates a colored progress bar with optional switch line
 func (d *Display) createProgressBar(percentage float64, isTime bool, plan string) string {
+	return d.createProgressBarWithMarkers(percentage, isTime, plan, nil)
+}
+
+// createProgressBarWithMarkers creates a progress bar with faint marker characters keyed by position
+func (d *Display) createProgressBarWithMarkers(percentage float64, isTime bool, plan string, markers map[int]string) string {
 	percentage = d.clampPercentage(percentage)
-	filled := int(float64(ProgressBarWidth) * percentage / 100)
-	filled = clampInt(filled, 0, ProgressBarWidth)
+	width := d.barWidth()
+	filled := int(float64(width) * percentage / 100)
+	filled = clampInt(filled, 0, width)
 
 	switchLinePos := d.getSwitchLinePosition(plan, isTime)
 	barParts := d.buildBarParts(filled, switchLinePos)
+	for pos, marker := range markers {
+		if pos >= 0 && pos < len(barParts) && pos != switchLinePos {
+			barParts[pos] = mutedString("%s", marker)
+		}
+	}
 
 	if isTime {
 		return d.colorTimeBar(barParts, filled)
@@ -178,20 +685,18 @@ func (d *Display) getSwitchLinePosition(plan string, isTime bool) int {
 	if isTime || plan == "" {
 		return -1
 	}
-	switch plan {
-	case "max5":
-		return int(float64(ProgressBarWidth) * 20 / 100) // 20% for Max5
-	case "max20":
-		return int(float64(ProgressBarWidth) * 50 / 100) // 50% for Max20
-	default:
+	percent, ok := opusSwitchPercent(plan)
+	if !ok {
 		return -1
 	}
+	return int(float64(d.barWidth()) * percent / 100)
 }
 
 // buildBarParts builds the bar structure with markers
 func (d *Display) buildBarParts(filled, switchLinePos int) []string {
-	var barParts []string
-	for i := 0; i < ProgressBarWidth; i++ {
+	width := d.barWidth()
+	barParts := make([]string, 0, width)
+	for i := 0; i < width; i++ {
 		switch {
 		case i == switchLinePos:
 			barParts = append(barParts, "|") // Switch line marker
@@ -208,8 +713,8 @@ func (d *Display) buildBarParts(filled, switchLinePos int) []string {
 func (d *Display) colorTimeBar(barParts []string, filled int) string {
 	var coloredParts []string
 	for i, part := range barParts {
-		if i < filled && part != color.RedString("|") {
-			coloredParts = append(coloredParts, color.BlueString(part))
+		if i < filled && part != paint(RoleBarSwitch, "|") {
+			coloredParts = append(coloredParts, paint(RoleBarTime, "%s", part))
 		} else {
 			coloredParts = append(coloredParts, part)
 		}
@@ -217,6 +722,29 @@ func (d *Display) colorTimeBar(barParts []string, filled int) string {
 	return fmt.Sprintf("[%s]", strings.Join(coloredParts, ""))
 }
 
+// createIdleTimeBar creates the session time bar with elapsed cells that were at least half idle dimmed
+func (d *Display) createIdleTimeBar(percentage float64, idle []Interval, start time.Time) string {
+	percentage = d.clampPercentage(percentage)
+	width := d.barWidth()
+	filled := clampInt(int(float64(width)*percentage/100), 0, width)
+	cell := SessionDuration / time.Duration(width)
+
+	barParts := d.buildBarParts(filled, -1)
+	coloredParts := make([]string, 0, len(barParts))
+	for i, part := range barParts {
+		cellStart := start.Add(time.Duration(i) * cell)
+		switch {
+		case i >= filled:
+			coloredParts = append(coloredParts, part)
+		case idleOverlap(idle, cellStart, cellStart.Add(cell))*2 >= cell:
+			coloredParts = append(coloredParts, mutedString("%s", part))
+		default:
+			coloredParts = append(coloredParts, paint(RoleBarTime, "%s", part))
+		}
+	}
+	return fmt.Sprintf("[%s]", strings.Join(coloredParts, ""))
+}
+
 // colorTokenBar colors the token progress bar
 func (d *Display) colorTokenBar(barParts []string, filled, switchLinePos int, percentage float64) string {
 	var coloredParts []string
@@ -240,26 +768,250 @@ func (d *Display) getTokenBarColor(position, switchLinePos int, percentage float
 
 // getSwitchLineColor returns color for switch line position
 func (d *Display) getSwitchLineColor(switchLinePos int, percentage float64) string {
-	switchThreshold := float64(switchLinePos) * 100 / float64(ProgressBarWidth)
+	switchThreshold := float64(switchLinePos) * 100 / float64(d.barWidth())
 	if percentage <= switchThreshold {
-		return color.RedString("|")
+		return paint(RoleBarSwitch, "|")
 	}
 	return d.getRegularBarColor(percentage)
 }
 
 // getRegularBarColor returns color based on percentage
 func (d *Display) getRegularBarColor(percentage float64) string {
-	switch {
-	case percentage < 60:
-		return color.GreenString("|")
-	case percentage < 80:
-		return color.YellowString("|")
+	switch d.alertLevels().Color(percentage) {
+	case "green":
+		return paint(RoleBarLow, "|")
+	case "yellow":
+		return paint(RoleBarMid, "|")
 	default:
-		return color.RedString("|")
+		return paint(RoleBarHigh, "|")
+	}
+}
+
+// alertLevels returns the levels of the session being rendered
+func (d *Display) alertLevels() AlertLevels {
+	if d.config != nil && d.config.Alerts != nil {
+		return *d.config.Alerts
 	}
+	return config.barAlertLevels()
 }
 
-// RenderError displays an error message
-func (d *Display) RenderError(message string) string {
-	return message + "\n"
+// RenderHistory renders completed session blocks annotated against the token limit
+func (d *Display) RenderHistory(rows []HistoryRow, limit int) string {
+	var buffer strings.Builder
+	fmt.Fprintf(&buffer, "Session history (limit: %s)\n\n", formatLimit(limit))
+	fmt.Fprintf(&buffer, "%-11s  %-5s  %12s  %6s  %10s  %9s  %12s  %s\n",
+		"Start", "End", "Tokens", "Msgs", "Tokens/msg", "Cost", "Active", "Limit")
+
+	for _, row := range rows {
+		exceeded := okString("ok")
+		if row.Exceeded {
+			exceeded = dangerString("exceeded")
+		}
+		active := "-"
+		if row.Activity != nil {
+			active = fmt.Sprintf("%s %3.0f%%", formatTime(row.Activity.ActiveMinutes), row.Activity.Percentage)
+		}
+		fmt.Fprintf(&buffer, "%-11s  %-5s  %12s  %6d  %10s  %9s  %12s  %s\n",
+			row.Start.In(d.timezone).Format("01-02 15:04"),
+			row.End.In(d.timezone).Format(TimeFormatShort),
+			formatNumber(row.Tokens),
+			row.Entries,
+			formatNumber(row.TokensPerMsg),
+			formatCost(row.Cost),
+			active,
+			exceeded)
+	}
+	return buffer.String()
+}
+
+// RenderBacktest renders per-session prediction errors for each method, most
+// recent first, followed by an error and calibration summary per method.
+// A maxRows of 0 or less shows every session.
+func (d *Display) RenderBacktest(results []BacktestResult, maxRows int) string {
+	var buffer strings.Builder
+	if len(results) == 0 || len(results[0].Predictions) == 0 {
+		buffer.WriteString("Not enough completed sessions to backtest\n")
+		return buffer.String()
+	}
+
+	predictions := results[0].Predictions
+	fmt.Fprintf(&buffer, "Backtest over %d sessions (error of the predicted limit vs. tokens used)\n\n", len(predictions))
+	fmt.Fprintf(&buffer, "%-11s  %12s", "Start", "Tokens")
+	for _, result := range results {
+		fmt.Fprintf(&buffer, "  %7s", result.Method)
+	}
+	buffer.WriteString("\n")
+
+	for i := len(predictions) - 1; i >= 0; i-- {
+		if maxRows > 0 && len(predictions)-i > maxRows {
+			break
+		}
+		fmt.Fprintf(&buffer, "%-11s  %12s",
+			predictions[i].Start.In(d.timezone).Format("01-02 15:04"),
+			formatNumber(predictions[i].Actual))
+		for _, result := range results {
+			fmt.Fprintf(&buffer, "  %+6.0f%%", result.Predictions[i].ErrorPercent())
+		}
+		buffer.WriteString("\n")
+	}
+
+	buffer.WriteString("\nCalibration: share of sessions that used at most N% of the predicted limit\n\n")
+	fmt.Fprintf(&buffer, "%-8s  %12s  %12s", "Method", "MAE", "Bias")
+	for _, ratio := range CalibrationRatios {
+		fmt.Fprintf(&buffer, "  %5s", fmt.Sprintf("%.0f%%", ratio*100))
+	}
+	buffer.WriteString("\n")
+	for _, result := range results {
+		bias := formatNumber(int(math.Abs(result.Bias)))
+		if result.Bias < 0 {
+			bias = "-" + bias
+		} else {
+			bias = "+" + bias
+		}
+		fmt.Fprintf(&buffer, "%-8s  %12s  %12s", result.Method, formatNumber(int(result.MAE)), bias)
+		for _, share := range result.Calibration {
+			fmt.Fprintf(&buffer, "  %4.0f%%", share)
+		}
+		buffer.WriteString("\n")
+	}
+	return buffer.String()
+}
+
+// RenderBaselineComparison renders your session medians next to a baseline's quartiles
+func (d *Display) RenderBaselineComparison(you, baseline BaselineProfile) string {
+	var buffer strings.Builder
+	if you.Sessions == 0 {
+		buffer.WriteString("No completed sessions to compare yet\n")
+		return buffer.String()
+	}
+
+	fmt.Fprintf(&buffer, "Your %s against %q (%s)\n\n", formatSessionCount(you), baseline.Name, formatSessionCount(baseline))
+	fmt.Fprintf(&buffer, "%-16s  %10s  %10s  %23s\n", "Metric", "You", "Baseline", "Baseline middle half")
+	for _, comparison := range compareBaseline(you, baseline) {
+		position := comparison.Position()
+		switch position {
+		case "higher":
+			position = warningString("%s", position)
+		case "lower":
+			position = infoString("%s", position)
+		}
+		fmt.Fprintf(&buffer, "%-16s  %10s  %10s  %23s  %s\n",
+			comparison.Metric,
+			comparison.Format(comparison.You.P50),
+			comparison.Format(comparison.Baseline.P50),
+			comparison.Format(comparison.Baseline.P25)+" – "+comparison.Format(comparison.Baseline.P75),
+			position)
+	}
+	buffer.WriteString("\nMedians per completed session; higher or lower means outside the baseline's middle half\n")
+	return buffer.String()
+}
+
+// RenderDaily renders per-day usage, most recent first
+func (d *Display) RenderDaily(days []DailyUsage) string {
+	var buffer strings.Builder
+	buffer.WriteString("Daily usage\n\n")
+	fmt.Fprintf(&buffer, "%-10s  %12s  %9s\n", "Date", "Tokens", "Cost")
+
+	for i := len(days) - 1; i >= 0 && len(days)-i <= DailyViewRows; i-- {
+		fmt.Fprintf(&buffer, "%-10s  %12s  %9s\n",
+			days[i].Date,
+			formatNumber(days[i].TotalTokens),
+			formatCost(days[i].TotalCost))
+	}
+	return buffer.String()
+}
+
+// RenderReport renders a weekly or monthly usage summary
+func (d *Display) RenderReport(report UsageReport) string {
+	var buffer strings.Builder
+	fmt.Fprintf(&buffer, "Usage report: last %s (%s - %s)\n\n",
+		report.Period,
+		report.Start.Format(DateFormat),
+		report.End.AddDate(0, 0, -1).Format(DateFormat))
+
+	fmt.Fprintf(&buffer, "%-10s  %12s  %9s\n", "Date", "Tokens", "Cost")
+	tokens := make([]int, 0, len(report.Days))
+	for _, day := range report.Days {
+		fmt.Fprintf(&buffer, "%-10s  %12s  %9s\n", day.Date, formatNumber(day.TotalTokens), formatCost(day.TotalCost))
+		tokens = append(tokens, day.TotalTokens)
+	}
+
+	fmt.Fprintf(&buffer, "\nDaily tokens:   %s\n", sparkline(tokens))
+	fmt.Fprintf(&buffer, "Total tokens:   %s\n", formatNumber(report.TotalTokens))
+	fmt.Fprintf(&buffer, "Total cost:     %s\n", formatCost(report.TotalCost))
+	fmt.Fprintf(&buffer, "Sessions:       %d\n", report.Sessions)
+	fmt.Fprintf(&buffer, "Avg burn rate:  %.2f tokens/min\n", report.AvgBurnRate)
+	if report.PeakBlock != nil {
+		peakStart, _ := time.Parse(time.RFC3339, report.PeakBlock.StartTime)
+		fmt.Fprintf(&buffer, "Peak session:   %s tokens (%s)\n",
+			formatNumber(report.PeakBlock.TotalTokens),
+			peakStart.In(d.timezone).Format("01-02 15:04"))
+	}
+	return buffer.String()
+}
+
+// RenderFooter renders the key binding help line, highlighting the active view
+func (d *Display) RenderFooter(view ViewMode, paused bool, err error) string {
+	var buffer strings.Builder
+	buffer.WriteString("\n\n")
+	if err != nil {
+		fmt.Fprintf(&buffer, "%s\n", dangerString("%s", tr("Error: %s", err)))
+	}
+	if paused {
+		buffer.WriteString(warningString("%s  ", translate("PAUSED")))
+	}
+
+	views := []struct{ action, label string }{
+		{ActionSessionView, "session"}, {ActionHistoryView, "history"}, {ActionDailyView, "daily"}, {ActionEventsView, "events"},
+	}
+	for i, v := range views {
+		label := strings.TrimSpace(keyMap.Hint(v.action) + " " + v.label)
+		if ViewMode(i) == view {
+			buffer.WriteString(infoString("%s", label))
+		} else {
+			buffer.WriteString(mutedString("%s", label))
+		}
+		buffer.WriteString("  ")
+	}
+
+	// Actions without a key are left out of the hints
+	var hints []string
+	for _, h := range []struct{ action, label string }{
+		{ActionNextView, "next"}, {ActionPause, "pause"}, {ActionPlan, "plan"}, {ActionProfile, "profile"}, {ActionQuit, "quit"},
+	} {
+		if hint := keyMap.Hint(h.action); hint != "" {
+			hints = append(hints, hint+" "+h.label)
+		}
+	}
+	buffer.WriteString(mutedString("%s", strings.Join(hints, "  ")))
+	return buffer.String()
+}
+
+// RenderError displays an error message, with a fix and the retry backoff for ccusage failures
+func (d *Display) RenderError(err error) string {
+	var buffer strings.Builder
+	fmt.Fprintf(&buffer, "%s\n", err)
+	var ccusageErr *CCUsageError
+	if errors.As(err, &ccusageErr) {
+		fmt.Fprintf(&buffer, "%s\n", mutedString("fix: %s", ccusageErr.Fix()))
+	}
+	if failures := usageFailures.Load(); failures > 1 {
+		fmt.Fprintf(&buffer, "%s\n", mutedString("failed %d times in a row, retrying less often (at most every %s)",
+			failures, formatTime(CCUsageBackoffMax.Minutes())))
+	}
+	return buffer.String()
+}
+
+// RenderSafeModeBanner lists the config file problems that made cctop ignore it
+func (d *Display) RenderSafeModeBanner(state ConfigFileState) string {
+	if !state.SafeMode() {
+		return ""
+	}
+	var buffer strings.Builder
+	fmt.Fprintf(&buffer, "%s\n", paint(RoleBanner, " SAFE MODE: ignoring %s, using defaults ", state.Path))
+	for _, problem := range state.Problems {
+		fmt.Fprintf(&buffer, "%s\n", warningString("  - %s", problem))
+	}
+	buffer.WriteString("\n")
+	return buffer.String()
 }
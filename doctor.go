@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// DoctorCheck is the result of a single environment check
+type DoctorCheck struct {
+	Name   string
+	OK     bool
+	Detail string
+	Fix    string // Actionable fix, only for failed checks
+}
+
+// runDoctor checks the environment and prints a fix for each problem
+func runDoctor(cmd *cobra.Command, args []string) {
+	data, err := loadUsageData()
+	checks := []DoctorCheck{checkBuildInfo(currentBuildInfo(), cctopBinariesOnPath()), checkCCUsage(), checkCCUsageData(data, err)}
+	if data != nil {
+		checks = append(checks, checkActiveBlocks(data.Blocks, display.timezone))
+	}
+	reader := NewMessageTokenReader()
+	for _, dir := range reader.claudeProjectsDirs {
+		checks = append(checks, checkProjectsDir(dir))
+	}
+	checks = append(checks,
+		checkJSONLFiles(reader.claudeProjectsDirs, DoctorJSONLSampleFiles),
+		checkTimezone(config.Timezone),
+		checkTerminalWidth(terminalWidth()),
+	)
+
+	failed := 0
+	for _, check := range checks {
+		if check.OK {
+			fmt.Printf("%s %s: %s\n", okString("✓"), check.Name, check.Detail)
+			continue
+		}
+		failed++
+		fmt.Printf("%s %s: %s\n", dangerString("✗"), check.Name, check.Detail)
+		if check.Fix != "" {
+			fmt.Printf("    %s %s\n", warningString("fix:"), check.Fix)
+		}
+	}
+
+	if failed > 0 {
+		fmt.Printf("\n%d of %d checks failed\n", failed, len(checks))
+		os.Exit(1)
+	}
+	fmt.Printf("\nAll %d checks passed\n", len(checks))
+}
+
+// checkCCUsage verifies ccusage is on PATH and reports its version
+func checkCCUsage() DoctorCheck {
+	check := DoctorCheck{Name: "ccusage installed"}
+	path, err := exec.LookPath("ccusage")
+	if err != nil {
+		check.Detail = "ccusage not found in PATH"
+		check.Fix = "install it with `npm install -g ccusage` (or `bun add -g ccusage`)"
+		return check
+	}
+
+	output, err := exec.Command(path, "--version").Output()
+	if err != nil {
+		check.Detail = fmt.Sprintf("%s --version failed: %v", path, err)
+		check.Fix = "reinstall ccusage with `npm install -g ccusage`"
+		return check
+	}
+	check.OK = true
+	check.Detail = fmt.Sprintf("%s (%s)", strings.TrimSpace(string(output)), path)
+	return check
+}
+
+// checkCCUsageData verifies ccusage returned parseable block data
+func checkCCUsageData(data *CCUsageData, err error) DoctorCheck {
+	check := DoctorCheck{Name: "ccusage blocks"}
+	var ccusageErr *CCUsageError
+	switch {
+	case errors.As(err, &ccusageErr) && ccusageErr.Kind == CCUsageNoData:
+		check.Detail = err.Error()
+		check.Fix = ccusageErr.Fix()
+		return check
+	case err != nil:
+		check.Detail = err.Error()
+		check.Fix = "run `ccusage blocks --json` to see the error; upgrade with `npm install -g ccusage@latest`"
+		return check
+	}
+	check.OK = true
+	check.Detail = fmt.Sprintf("%d blocks", len(data.Blocks))
+	if findActiveBlock(data.Blocks) == nil {
+		check.Detail += ", no active session"
+	}
+	return check
+}
+
+// checkProjectsDir verifies a Claude projects directory exists and is readable
+func checkProjectsDir(dir string) DoctorCheck {
+	check := DoctorCheck{Name: "projects directory " + dir}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		check.Detail = err.Error()
+		if os.IsNotExist(err) {
+			check.Fix = "use Claude Code once to create it, or point --claude-dir at your Claude config directory"
+		} else {
+			check.Fix = fmt.Sprintf("make it readable, e.g. `chmod u+rx %s`", dir)
+		}
+		return check
+	}
+	check.OK = true
+	check.Detail = fmt.Sprintf("%d entries", len(entries))
+	return check
+}
+
+// checkJSONLFiles parses the most recently modified JSONL files and counts malformed lines
+func checkJSONLFiles(projectsDirs []string, sampleFiles int) DoctorCheck {
+	check := DoctorCheck{Name: "JSONL logs"}
+
+	var files []string
+	for _, projectsDir := range projectsDirs {
+		matches, _ := filepath.Glob(filepath.Join(projectsDir, "*", "*.jsonl"))
+		files = append(files, matches...)
+	}
+	if len(files) == 0 {
+		check.Detail = "no JSONL files found"
+		check.Fix = "limit estimation falls back to defaults until Claude Code has written session logs"
+		return check
+	}
+
+	modTimes := make(map[string]time.Time, len(files))
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			modTimes[file] = info.ModTime()
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return modTimes[files[i]].After(modTimes[files[j]]) })
+	if len(files) > sampleFiles {
+		files = files[:sampleFiles]
+	}
+
+	lines, malformed := 0, 0
+	var unreadable []string
+	for _, file := range files {
+		fileLines, fileMalformed, err := countJSONLLines(file)
+		if err != nil {
+			unreadable = append(unreadable, file)
+			continue
+		}
+		lines += fileLines
+		malformed += fileMalformed
+	}
+
+	check.Detail = fmt.Sprintf("%d lines in %d recent files, %d malformed", lines, len(files), malformed)
+	switch {
+	case len(unreadable) > 0:
+		check.Detail += fmt.Sprintf(", %d unreadable (%s)", len(unreadable), unreadable[0])
+		check.Fix = "check file permissions, or lines longer than the 16MB reader limit"
+	case lines > 0 && malformed*100 > lines:
+		check.Fix = "more than 1% of lines are malformed; update Claude Code or remove the damaged files"
+	default:
+		check.OK = true
+	}
+	return check
+}
+
+// countJSONLLines counts lines and lines that are not valid JSON in a file
+func countJSONLLines(path string) (int, int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	lines, malformed := 0, 0
+	scanner := newJSONLScanner(file)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		lines++
+		if !json.Valid(scanner.Bytes()) {
+			malformed++
+		}
+	}
+	return lines, malformed, scanner.Err()
+}
+
+// checkTimezone verifies the configured timezone can be loaded
+func checkTimezone(timezone string) DoctorCheck {
+	check := DoctorCheck{Name: "timezone"}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		check.Detail = fmt.Sprintf("%q: %v", timezone, err)
+		check.Fix = "use an IANA name such as `--timezone America/New_York`, or install tzdata"
+		return check
+	}
+	check.OK = true
+	check.Detail = timezone
+	return check
+}
+
+// checkTerminalWidth verifies the terminal fits the progress bars
+func checkTerminalWidth(width int) DoctorCheck {
+	check := DoctorCheck{Name: "terminal width"}
+	switch {
+	case width == 0:
+		check.OK = true
+		check.Detail = "not a terminal"
+	case width < MinTerminalWidth:
+		check.Detail = fmt.Sprintf("%d columns, %d needed", width, MinTerminalWidth)
+		check.Fix = fmt.Sprintf("widen the terminal to at least %d columns", MinTerminalWidth)
+	default:
+		check.OK = true
+		check.Detail = fmt.Sprintf("%d columns", width)
+	}
+	return check
+}
+
+// terminalWidth returns the width of stdout, or 0 when it is not a terminal
+func terminalWidth() int {
+	return NewTerminal(os.Stdout).Width()
+}
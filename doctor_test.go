@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckTimezone(t *testing.T) {
+	tests := []struct {
+		timezone string
+		expected bool
+	}{
+		{"Asia/Tokyo", true},
+		{"UTC", true},
+		{"Mars/Olympus_Mons", false},
+	}
+
+	for _, tt := range tests {
+		check := checkTimezone(tt.timezone)
+		if check.OK != tt.expected {
+			t.Errorf("checkTimezone(%q).OK = %v, expected %v", tt.timezone, check.OK, tt.expected)
+		}
+		if !check.OK && check.Fix == "" {
+			t.Errorf("checkTimezone(%q) failed without a fix", tt.timezone)
+		}
+	}
+}
+
+func TestCheckTerminalWidth(t *testing.T) {
+	tests := []struct {
+		width    int
+		expected bool
+	}{
+		{0, true},
+		{MinTerminalWidth - 1, false},
+		{MinTerminalWidth, true},
+		{200, true},
+	}
+
+	for _, tt := range tests {
+		if check := checkTerminalWidth(tt.width); check.OK != tt.expected {
+			t.Errorf("checkTerminalWidth(%d).OK = %v, expected %v", tt.width, check.OK, tt.expected)
+		}
+	}
+}
+
+func TestCheckProjectsDir(t *testing.T) {
+	dir := t.TempDir()
+	if check := checkProjectsDir(dir); !check.OK {
+		t.Errorf("checkProjectsDir(existing).OK = false, expected true: %s", check.Detail)
+	}
+	if check := checkProjectsDir(filepath.Join(dir, "missing")); check.OK || check.Fix == "" {
+		t.Errorf("checkProjectsDir(missing) = %+v, expected failure with a fix", check)
+	}
+}
+
+func TestCheckJSONLFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected bool
+	}{
+		{"valid", "{\"a\":1}\n{\"b\":2}\n", true},
+		{"malformed", "{\"a\":1}\n{broken\n", false},
+	}
+
+	for _, tt := range tests {
+		projects := t.TempDir()
+		project := filepath.Join(projects, "project")
+		if err := os.Mkdir(project, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(project, "session.jsonl"), []byte(tt.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		if check := checkJSONLFiles([]string{projects}, DoctorJSONLSampleFiles); check.OK != tt.expected {
+			t.Errorf("checkJSONLFiles(%s).OK = %v, expected %v (%s)", tt.name, check.OK, tt.expected, check.Detail)
+		}
+	}
+
+	if check := checkJSONLFiles([]string{t.TempDir()}, DoctorJSONLSampleFiles); check.OK {
+		t.Errorf("checkJSONLFiles(empty).OK = true, expected false")
+	}
+}
@@ -9,13 +9,15 @@ import (
 // TokenLimitEstimator manages dynamic token limit estimation
 type TokenLimitEstimator struct {
 	baseLimits         map[string]BaseLimit
-	estimationMethod   string
+	strategy           Estimator
 	lastEstimationInfo EstimationInfo
+	state              *EstimatorState // Learning state, nil until LoadState
+	statePath          string
 }
 
 // GetEstimationMethod returns the current estimation method
 func (e *TokenLimitEstimator) GetEstimationMethod() string {
-	return e.estimationMethod
+	return e.strategy.Name()
 }
 
 // EstimationInfo contains details about the last estimation
@@ -26,6 +28,10 @@ type EstimationInfo struct {
 	Messages     int
 	TokensPerMsg int
 	IsFromJSONL  bool
+	Coarse       bool // Tokens/message averaged from ccusage entry counts, the JSONL logs being unavailable
+	ColdStart    bool // Estimated from too little history for statistics
+	LowerBound   int  // Uncertainty band, only set for cold-start estimates
+	UpperBound   int
 }
 
 // BaseLimit represents official plan limits
@@ -42,30 +48,54 @@ func NewTokenLimitEstimator() *TokenLimitEstimator {
 			"max5":  {Messages: Max5PlanMessages, DefaultTokensPerMsg: DefaultTokensPerMsg},
 			"max20": {Messages: Max20PlanMessages, DefaultTokensPerMsg: DefaultTokensPerMsg},
 		},
-		estimationMethod: "p40", // Default to 40th percentile
+		strategy: PercentileEstimator{Percentile: 40}, // Default to 40th percentile
 	}
 }
 
-// SetEstimationMethod sets the estimation method
+// SetEstimationMethod sets the estimation method, falling back to the default for unknown methods
 func (e *TokenLimitEstimator) SetEstimationMethod(method string) {
-	e.estimationMethod = method
+	strategy, err := ParseEstimator(method)
+	if err != nil {
+		strategy, _ = ParseEstimator(DefaultEstimationMethod)
+	}
+	e.SetStrategy(strategy)
+}
+
+// SetStrategy sets the estimation strategy
+func (e *TokenLimitEstimator) SetStrategy(strategy Estimator) {
+	e.strategy = strategy
 }
 
-// EstimateLimit estimates token limit using historical data and official limits
+// EstimateLimit estimates token limit using historical data, official limits and observed limit hits
 func (e *TokenLimitEstimator) EstimateLimit(plan string, blocks []Block) int {
+	return e.applyObservedLimits(e.estimateLimit(plan, blocks))
+}
+
+// estimateLimit estimates token limit using historical data and official limits
+func (e *TokenLimitEstimator) estimateLimit(plan string, blocks []Block) int {
+	if coldLimit, ok := e.estimateColdStart(plan, blocks); ok {
+		logger.Debugf("limit %d: cold start, %d tokens/msg over %d sessions", coldLimit, e.lastEstimationInfo.TokensPerMsg, e.lastEstimationInfo.SessionIndex)
+		return coldLimit
+	}
+
 	// First try dynamic estimation from historical data
 	if dynamicLimit := e.estimateFromHistory(blocks); dynamicLimit > 0 {
 		// If we have historical data, use hybrid approach
 		if baseLimit := e.calculateBaseLimit(plan, blocks); baseLimit > 0 {
 			// Adaptive weighting based on sample size and variance
 			weight := e.calculateDynamicWeight(blocks)
-			return int(float64(dynamicLimit)*weight + float64(baseLimit)*(1-weight))
+			limit := int(float64(dynamicLimit)*weight + float64(baseLimit)*(1-weight))
+			logger.Debugf("limit %d: history %d weighted %.2f, plan base %d", limit, dynamicLimit, weight, baseLimit)
+			return limit
 		}
+		logger.Debugf("limit %d: history only", dynamicLimit)
 		return dynamicLimit
 	}
 
 	// Fallback to base calculation
-	return e.calculateBaseLimit(plan, blocks)
+	baseLimit := e.calculateBaseLimit(plan, blocks)
+	logger.Debugf("limit %d: plan base, too few sessions for history", baseLimit)
+	return baseLimit
 }
 
 // estimateFromHistory analyzes historical session data
@@ -169,6 +199,7 @@ func (e *TokenLimitEstimator) calculateAvgTokensPerMessage(blocks []Block) int {
 	// Find the session with the highest token consumption
 	maxTokenSession := e.findMaxTokenSession(blocks)
 	if maxTokenSession.block == nil || maxTokenSession.block.Entries == 0 {
+		e.lastEstimationInfo = EstimationInfo{}
 		return 0
 	}
 
@@ -192,8 +223,13 @@ func (e *TokenLimitEstimator) calculateAvgTokensPerMessage(blocks []Block) int {
 		return tokensPerMsg
 	}
 	
-	// Fallback to average if we can't read JSONL data
+	// Without JSONL logs (e.g. cleaned up) only ccusage's entry counts are left, so the
+	// strategy can't be applied and the estimate is marked coarse
 	avgTokensPerMsg := maxTokenSession.block.TotalTokens / maxTokenSession.block.Entries
+	if !e.lastEstimationInfo.Coarse {
+		logger.Infof("no JSONL logs for the session at %s (%v), estimating tokens/message from entry counts",
+			maxTokenSession.block.StartTime, err)
+	}
 	
 	// Store estimation info
 	e.lastEstimationInfo = EstimationInfo{
@@ -201,8 +237,9 @@ func (e *TokenLimitEstimator) calculateAvgTokensPerMessage(blocks []Block) int {
 		TotalTokens:  maxTokenSession.block.TotalTokens,
 		Messages:     maxTokenSession.block.Entries,
 		TokensPerMsg: avgTokensPerMsg,
-		Method:       "average",
+		Method:       "entries",
 		IsFromJSONL:  false,
+		Coarse:       true,
 	}
 	
 	return avgTokensPerMsg
@@ -215,15 +252,7 @@ func (e *TokenLimitEstimator) calculatePercentile(values []int, percentile float
 	}
 
 	sort.Ints(values)
-	index := int(math.Ceil(float64(len(values))*percentile/100.0)) - 1
-	if index < 0 {
-		index = 0
-	}
-	if index >= len(values) {
-		index = len(values) - 1
-	}
-
-	return values[index]
+	return percentileOfSorted(values, percentile)
 }
 
 // calculateDeviation calculates the percentage deviation between actual and estimated values
@@ -270,14 +299,7 @@ func (e *TokenLimitEstimator) GetActualPlan(plan string, blocks []Block) string
 
 // calculateDynamicWeight determines how much to trust historical data
 func (e *TokenLimitEstimator) calculateDynamicWeight(blocks []Block) float64 {
-	var sessionTokens []int
-	for _, block := range blocks {
-		if !block.IsGap && block.TotalTokens > 0 {
-			sessionTokens = append(sessionTokens, block.TotalTokens)
-		}
-	}
-
-	sampleSize := len(sessionTokens)
+	sampleSize, cv := sessionTokenStats(blocks)
 
 	// Less weight for small sample sizes
 	if sampleSize < 10 {
@@ -286,30 +308,11 @@ func (e *TokenLimitEstimator) calculateDynamicWeight(blocks []Block) float64 {
 		return WeightMediumSample
 	}
 
-	// Calculate coefficient of variation (CV)
-	if len(sessionTokens) > 1 {
-		mean := 0
-		for _, v := range sessionTokens {
-			mean += v
-		}
-		mean /= len(sessionTokens)
-
-		variance := 0.0
-		for _, v := range sessionTokens {
-			diff := float64(v - mean)
-			variance += diff * diff
-		}
-		variance /= float64(len(sessionTokens))
-		stdDev := math.Sqrt(variance)
-
-		cv := stdDev / float64(mean)
-
-		// High variance = less trust in historical data
-		if cv > VarianceCoefficientHigh {
-			return WeightHighVariance
-		} else if cv > VarianceCoefficientMedium {
-			return WeightMediumVariance
-		}
+	// High variance = less trust in historical data
+	if cv > VarianceCoefficientHigh {
+		return WeightHighVariance
+	} else if cv > VarianceCoefficientMedium {
+		return WeightMediumVariance
 	}
 
 	return WeightLargeSample // High confidence with good data
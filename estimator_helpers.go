@@ -1,7 +1,7 @@
 package main
 
 import (
-	"fmt"
+	"math"
 	"time"
 )
 
@@ -32,6 +32,70 @@ func (e *TokenLimitEstimator) findMaxTokenSession(blocks []Block) maxTokenSessio
 	return result
 }
 
+// estimateColdStart estimates a limit for users with fewer than MinHistoricalSessions sessions.
+// It pools tokens/message across every session so far, including the live one, and
+// reports an uncertainty band that narrows as sessions accumulate.
+func (e *TokenLimitEstimator) estimateColdStart(plan string, blocks []Block) (int, bool) {
+	var sessions, totalTokens, totalEntries int
+	for _, block := range blocks {
+		if block.IsGap || block.Entries == 0 {
+			continue
+		}
+		sessions++
+		totalTokens += block.TotalTokens
+		totalEntries += block.Entries
+	}
+
+	if sessions == 0 || sessions >= MinHistoricalSessions || totalEntries < ColdStartMinMessages {
+		return 0, false
+	}
+
+	if plan == "auto" {
+		plan = e.detectPlanFromHistory(blocks)
+	}
+	base, exists := e.baseLimits[plan]
+	if !exists {
+		base = e.baseLimits["pro"]
+	}
+
+	tokensPerMsg, method := e.coldStartTokensPerMessage(blocks, totalTokens, totalEntries)
+	limit := base.Messages * tokensPerMsg
+	uncertainty := ColdStartUncertainty / math.Sqrt(float64(sessions))
+
+	e.lastEstimationInfo = EstimationInfo{
+		Method:       method,
+		SessionIndex: sessions,
+		TotalTokens:  totalTokens,
+		Messages:     totalEntries,
+		TokensPerMsg: tokensPerMsg,
+		ColdStart:    true,
+		LowerBound:   int(float64(limit) * (1 - uncertainty)),
+		UpperBound:   int(float64(limit) * (1 + uncertainty)),
+	}
+	return limit, true
+}
+
+// coldStartTokensPerMessage applies the selected strategy to the messages of every
+// session so far, falling back to the pooled average when the JSONL logs hold too few
+func (e *TokenLimitEstimator) coldStartTokensPerMessage(blocks []Block, totalTokens, totalEntries int) (int, string) {
+	var messageTokens []int
+	for i := range blocks {
+		if blocks[i].IsGap || blocks[i].Entries == 0 {
+			continue
+		}
+		if tokens, err := e.getMessageTokens(&blocks[i]); err == nil {
+			messageTokens = append(messageTokens, tokens...)
+		}
+	}
+	if len(messageTokens) >= ColdStartMinMessages {
+		pooled := &Block{TotalTokens: totalTokens, Entries: totalEntries}
+		if tokensPerMsg := e.strategy.TokensPerMessage(messageTokens, pooled); tokensPerMsg > 0 {
+			return tokensPerMsg, "cold start, " + e.strategy.Description()
+		}
+	}
+	return totalTokens / totalEntries, "cold start"
+}
+
 // getMessageTokens retrieves message tokens from JSONL files
 func (e *TokenLimitEstimator) getMessageTokens(block *Block) ([]int, error) {
 	reader := NewMessageTokenReader()
@@ -44,42 +108,7 @@ func (e *TokenLimitEstimator) getMessageTokens(block *Block) ([]int, error) {
 	return reader.GetBlockTokens(block.StartTime, endTime)
 }
 
-// calculateTokensPerMessage calculates tokens per message using the selected method
+// calculateTokensPerMessage calculates tokens per message using the selected strategy
 func (e *TokenLimitEstimator) calculateTokensPerMessage(messageTokens []int, block *Block) (tokensPerMsg int, methodDesc string) {
-	switch e.estimationMethod {
-	case "median":
-		return e.calculatePercentile(messageTokens, 50), "median"
-	case "mode":
-		return CalculateMode(messageTokens), "mode"
-	case "avg":
-		return block.TotalTokens / block.Entries, "average"
-	default:
-		return e.parseCustomMethod(messageTokens, block)
-	}
+	return e.strategy.TokensPerMessage(messageTokens, block), e.strategy.Description()
 }
-
-// parseCustomMethod handles custom percentile and trim methods
-func (e *TokenLimitEstimator) parseCustomMethod(messageTokens []int, block *Block) (tokensPerMsg int, methodDesc string) {
-	// Parse percentile (e.g., "p35")
-	if len(e.estimationMethod) > 1 && e.estimationMethod[0] == 'p' {
-		var percentile float64
-		if _, err := fmt.Sscanf(e.estimationMethod, "p%f", &percentile); err == nil && percentile >= 0 && percentile <= 100 {
-			tokensPerMsg := e.calculatePercentile(messageTokens, percentile)
-			if percentile == 50 {
-				return tokensPerMsg, "median"
-			}
-			return tokensPerMsg, fmt.Sprintf("%.0fth percentile", percentile)
-		}
-	}
-	
-	// Parse trim percentage (e.g., "trim15")
-	if len(e.estimationMethod) > 4 && e.estimationMethod[:4] == "trim" {
-		var trimPercent float64
-		if _, err := fmt.Sscanf(e.estimationMethod, "trim%f", &trimPercent); err == nil && trimPercent >= 0 && trimPercent < 50 {
-			return CalculateTrimmedMean(messageTokens, trimPercent), fmt.Sprintf("%.0f%% trimmed mean", trimPercent)
-		}
-	}
-	
-	// Fallback to 40th percentile
-	return e.calculatePercentile(messageTokens, 40), "40th percentile"
-}
\ No newline at end of file
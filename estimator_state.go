@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// LimitHit records a completed session that appears to have run into the real limit
+type LimitHit struct {
+	StartTime string `json:"startTime"`
+	Tokens    int    `json:"tokens"`
+	Estimated int    `json:"estimated"` // Estimated limit when the hit was observed
+}
+
+// EstimatorState is the estimator learning state persisted across runs
+type EstimatorState struct {
+	LimitHits []LimitHit `json:"limitHits"`
+}
+
+// cctopStateDir returns $XDG_STATE_HOME/cctop (~/.local/state/cctop by default,
+// %LOCALAPPDATA%\cctop on Windows)
+func cctopStateDir() string {
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" && runtime.GOOS == "windows" {
+		stateHome = os.Getenv("LOCALAPPDATA")
+	}
+	if stateHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		stateHome = filepath.Join(homeDir, ".local", "state")
+	}
+	return filepath.Join(stateHome, "cctop")
+}
+
+// defaultEstimatorStatePath returns the estimator state file in the cctop state directory
+func defaultEstimatorStatePath() string {
+	stateDir := cctopStateDir()
+	if stateDir == "" {
+		return ""
+	}
+	return filepath.Join(stateDir, "estimator.json")
+}
+
+// LoadState loads persisted learning state; a missing or corrupt file starts fresh
+func (e *TokenLimitEstimator) LoadState(path string) {
+	e.statePath = path
+	e.readState()
+}
+
+// readState reads the learning state from disk; a missing or corrupt file starts fresh
+func (e *TokenLimitEstimator) readState() {
+	e.state = &EstimatorState{}
+
+	data, err := os.ReadFile(e.statePath)
+	if err != nil {
+		return
+	}
+	if err := json.Unmarshal(data, e.state); err != nil {
+		e.state = &EstimatorState{}
+	}
+}
+
+// lockState locks the state file and rereads it, so hits recorded meanwhile by other
+// monitors or the daemon are kept on save. The returned function releases the lock.
+func (e *TokenLimitEstimator) lockState() func() {
+	if e.statePath == "" {
+		return func() {}
+	}
+	unlock, err := lockStateFile(e.statePath)
+	if err != nil {
+		unlock = func() {}
+	}
+	e.readState()
+	return unlock
+}
+
+// saveState writes the learning state to disk
+func (e *TokenLimitEstimator) saveState() error {
+	if e.statePath == "" || e.state == nil {
+		return nil
+	}
+	data, err := json.MarshalIndent(e.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(e.statePath, data, 0o600)
+}
+
+// ObserveBlocks records completed blocks that look like limit hits: usage reached
+// LimitHitRatio of the estimate and then stopped well before the window reset.
+func (e *TokenLimitEstimator) ObserveBlocks(blocks []Block, estimatedLimit int) {
+	if e.state == nil || estimatedLimit <= 0 {
+		return
+	}
+	defer e.lockState()()
+
+	known := make(map[string]bool, len(e.state.LimitHits))
+	for _, hit := range e.state.LimitHits {
+		known[hit.StartTime] = true
+	}
+
+	changed := false
+	for _, block := range blocks {
+		if known[block.StartTime] || !isLimitHit(block, estimatedLimit) {
+			continue
+		}
+		e.state.LimitHits = append(e.state.LimitHits, LimitHit{
+			StartTime: block.StartTime,
+			Tokens:    block.TotalTokens,
+			Estimated: estimatedLimit,
+		})
+		changed = true
+	}
+
+	if len(e.state.LimitHits) > MaxRecordedLimitHits {
+		e.state.LimitHits = e.state.LimitHits[len(e.state.LimitHits)-MaxRecordedLimitHits:]
+	}
+	if changed {
+		_ = e.saveState()
+	}
+}
+
+// RecordReportedLimit records a limit derived from Claude Code's own usage report as an
+// observed limit hit for the session, replacing an earlier one for the same session
+func (e *TokenLimitEstimator) RecordReportedLimit(startTime string, limit, estimatedLimit int) {
+	if e.state == nil || limit <= 0 {
+		return
+	}
+	defer e.lockState()()
+
+	hit := LimitHit{StartTime: startTime, Tokens: limit, Estimated: estimatedLimit}
+	for i := range e.state.LimitHits {
+		if e.state.LimitHits[i].StartTime == startTime {
+			e.state.LimitHits[i] = hit
+			_ = e.saveState()
+			return
+		}
+	}
+	e.state.LimitHits = append(e.state.LimitHits, hit)
+	if len(e.state.LimitHits) > MaxRecordedLimitHits {
+		e.state.LimitHits = e.state.LimitHits[len(e.state.LimitHits)-MaxRecordedLimitHits:]
+	}
+	_ = e.saveState()
+}
+
+// isLimitHit reports whether a completed block stopped early after nearing the limit
+func isLimitHit(block Block, estimatedLimit int) bool {
+	if block.IsGap || block.IsActive || block.ActualEndTime == "" {
+		return false
+	}
+	if float64(block.TotalTokens) < float64(estimatedLimit)*LimitHitRatio {
+		return false
+	}
+
+	startTime, err := time.Parse(time.RFC3339, block.StartTime)
+	if err != nil {
+		return false
+	}
+	endTime, err := time.Parse(time.RFC3339, block.ActualEndTime)
+	if err != nil {
+		return false
+	}
+	return startTime.Add(SessionDuration).Sub(endTime) >= LimitHitIdleTime
+}
+
+// applyObservedLimits blends an estimate with the median of observed limit hits.
+// Trust in observations grows with each recorded hit.
+func (e *TokenLimitEstimator) applyObservedLimits(limit int) int {
+	if e.state == nil || len(e.state.LimitHits) == 0 {
+		return limit
+	}
+
+	tokens := make([]int, 0, len(e.state.LimitHits))
+	for _, hit := range e.state.LimitHits {
+		tokens = append(tokens, hit.Tokens)
+	}
+	observed := CalculateMedianTokens(tokens)
+
+	weight := WeightObservedBase + WeightObservedPerHit*float64(len(tokens))
+	if weight > WeightObservedMax {
+		weight = WeightObservedMax
+	}
+	adjusted := int(float64(observed)*weight + float64(limit)*(1-weight))
+	logger.Debugf("limit %d -> %d: median of %d observed limit hits %d weighted %.2f", limit, adjusted, len(tokens), observed, weight)
+	return adjusted
+}
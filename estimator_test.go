@@ -1,6 +1,10 @@
 package main
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -229,3 +233,267 @@ func TestGetAccuracyReport(t *testing.T) {
 		})
 	}
 }
+
+func TestEstimatorLearningState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "estimator.json")
+	est := NewTokenLimitEstimator()
+	est.LoadState(path)
+
+	blocks := []Block{
+		// Reached the estimate and stopped two hours before reset: a limit hit
+		{StartTime: "2025-06-20T00:00:00Z", ActualEndTime: "2025-06-20T03:00:00Z", TotalTokens: 9500},
+		// Used the whole window: not a hit
+		{StartTime: "2025-06-20T05:00:00Z", ActualEndTime: "2025-06-20T09:50:00Z", TotalTokens: 9800},
+		// Stopped early but well below the estimate: not a hit
+		{StartTime: "2025-06-20T10:00:00Z", ActualEndTime: "2025-06-20T11:00:00Z", TotalTokens: 2000},
+		// Active blocks are ignored
+		{StartTime: "2025-06-20T16:00:00Z", TotalTokens: 12000, IsActive: true},
+	}
+
+	est.ObserveBlocks(blocks, 10000)
+	est.ObserveBlocks(blocks, 10000) // Already recorded hits are not duplicated
+	if len(est.state.LimitHits) != 1 || est.state.LimitHits[0].Tokens != 9500 {
+		t.Fatalf("LimitHits = %+v, expected a single 9500 token hit", est.state.LimitHits)
+	}
+
+	// State survives a restart
+	restarted := NewTokenLimitEstimator()
+	restarted.LoadState(path)
+	if len(restarted.state.LimitHits) != 1 {
+		t.Fatalf("reloaded LimitHits = %+v, expected 1 hit", restarted.state.LimitHits)
+	}
+
+	// One hit: weight 0.4 towards the observed 9500
+	if result := restarted.applyObservedLimits(7000); result != 8000 {
+		t.Errorf("applyObservedLimits(7000) = %d, expected 8000", result)
+	}
+
+	// Without state the estimate is unchanged
+	if result := NewTokenLimitEstimator().applyObservedLimits(7000); result != 7000 {
+		t.Errorf("applyObservedLimits() without state = %d, expected 7000", result)
+	}
+}
+
+func TestEstimateColdStart(t *testing.T) {
+	tests := []struct {
+		name      string
+		blocks    []Block
+		expected  int
+		expectOK  bool
+		expectLow int
+		expectUp  int
+	}{
+		{
+			name:      "Single live session",
+			blocks:    []Block{{TotalTokens: 3000, Entries: 20, IsActive: true}},
+			expected:  6750, // 150 tokens/msg * 45 messages
+			expectOK:  true,
+			expectLow: 3375, // ±50% with one session
+			expectUp:  10125,
+		},
+		{
+			name: "Band narrows with more sessions",
+			blocks: []Block{
+				{TotalTokens: 4000, Entries: 40},
+				{TotalTokens: 2000, Entries: 20},
+				{TotalTokens: 3000, Entries: 20, IsGap: true}, // ignored
+				{TotalTokens: 2000, Entries: 20, IsActive: true},
+			},
+			expected:  4500, // 8000/80 = 100 tokens/msg * 45 messages
+			expectOK:  true,
+			expectLow: 3200, // ±0.5/sqrt(3) ≈ 28.9%
+			expectUp:  5799,
+		},
+		{
+			name:     "Too few messages",
+			blocks:   []Block{{TotalTokens: 600, Entries: 4, IsActive: true}},
+			expectOK: false,
+		},
+		{
+			name: "Enough history for statistics",
+			blocks: []Block{
+				{TotalTokens: 5000, Entries: 40},
+				{TotalTokens: 5000, Entries: 40},
+				{TotalTokens: 5000, Entries: 40},
+				{TotalTokens: 5000, Entries: 40},
+				{TotalTokens: 5000, Entries: 40},
+			},
+			expectOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			est := NewTokenLimitEstimator()
+			result, ok := est.estimateColdStart("pro", tt.blocks)
+			if ok != tt.expectOK {
+				t.Fatalf("estimateColdStart() ok = %v, expected %v", ok, tt.expectOK)
+			}
+			if !ok {
+				return
+			}
+			if result != tt.expected {
+				t.Errorf("estimateColdStart() = %d, expected %d", result, tt.expected)
+			}
+			info := est.GetEstimationInfo()
+			if !info.ColdStart || info.LowerBound != tt.expectLow || info.UpperBound != tt.expectUp {
+				t.Errorf("estimation info = %+v, expected cold start band %d-%d", info, tt.expectLow, tt.expectUp)
+			}
+		})
+	}
+}
+
+func TestColdStartUsesEstimator(t *testing.T) {
+	claudeDir := t.TempDir()
+	t.Setenv("CLAUDE_CONFIG_DIR", claudeDir)
+	project := filepath.Join(claudeDir, "projects", "-home-me-src")
+	if err := os.MkdirAll(project, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	var jsonl strings.Builder
+	for i, tokens := range []int{100, 100, 100, 100, 100, 1000} {
+		fmt.Fprintf(&jsonl, `{"timestamp":"2025-06-20T10:%02d:00Z","type":"assistant","message":{"usage":{"output_tokens":%d}}}`+"\n", 10+i, tokens)
+	}
+	if err := os.WriteFile(filepath.Join(project, "session.jsonl"), []byte(jsonl.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	est := NewTokenLimitEstimator()
+	est.SetEstimationMethod("mode")
+	blocks := []Block{{StartTime: "2025-06-20T10:00:00Z", ActualEndTime: "2025-06-20T12:00:00Z", TotalTokens: 1500, Entries: 6, IsActive: true}}
+	result, ok := est.estimateColdStart("pro", blocks)
+	if !ok {
+		t.Fatal("estimateColdStart() ok = false, expected a cold start estimate")
+	}
+	if expected := 45 * 100; result != expected {
+		t.Errorf("estimateColdStart() with --est mode = %d, expected %d", result, expected)
+	}
+	if info := est.GetEstimationInfo(); !strings.HasPrefix(info.Method, "cold start, ") {
+		t.Errorf("estimation method = %q, expected the cold start to name the estimator", info.Method)
+	}
+}
+
+func TestConfidence(t *testing.T) {
+	uniform := func(n, tokens int) []Block {
+		blocks := make([]Block, n)
+		for i := range blocks {
+			blocks[i] = Block{TotalTokens: tokens, Entries: 50}
+		}
+		return blocks
+	}
+
+	varied := uniform(14, 10000)
+	for i := range varied {
+		if i%2 == 0 {
+			varied[i].TotalTokens = 6000
+		}
+	}
+
+	tests := []struct {
+		name          string
+		blocks        []Block
+		expectLevel   string
+		expectMinUnc  float64
+		expectMaxUnc  float64
+		expectSession int
+	}{
+		{
+			name:          "No history",
+			blocks:        nil,
+			expectLevel:   ConfidenceNone,
+			expectMinUnc:  1,
+			expectMaxUnc:  1,
+			expectSession: 0,
+		},
+		{
+			name:          "Only the active session",
+			blocks:        []Block{{TotalTokens: 5000, Entries: 50, IsActive: true}},
+			expectLevel:   ConfidenceNone,
+			expectMinUnc:  1,
+			expectMaxUnc:  1,
+			expectSession: 1,
+		},
+		{
+			name:          "Cold start",
+			blocks:        uniform(4, 5000),
+			expectLevel:   ConfidenceLow,
+			expectMinUnc:  0.25,
+			expectMaxUnc:  0.25,
+			expectSession: 4,
+		},
+		{
+			name:          "Medium sample",
+			blocks:        varied,
+			expectLevel:   ConfidenceMedium,
+			expectMinUnc:  0.12, // cv 0.25 over 14 sessions
+			expectMaxUnc:  0.14,
+			expectSession: 14,
+		},
+		{
+			name:          "Large consistent sample",
+			blocks:        uniform(25, 8000),
+			expectLevel:   ConfidenceHigh,
+			expectMinUnc:  0,
+			expectMaxUnc:  0,
+			expectSession: 25,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewTokenLimitEstimator().Confidence(tt.blocks)
+			if result.Level != tt.expectLevel || result.Sessions != tt.expectSession {
+				t.Errorf("Confidence() = %+v, expected %s with %d sessions", result, tt.expectLevel, tt.expectSession)
+			}
+			if result.Uncertainty < tt.expectMinUnc || result.Uncertainty > tt.expectMaxUnc {
+				t.Errorf("Confidence().Uncertainty = %.3f, expected between %.3f and %.3f",
+					result.Uncertainty, tt.expectMinUnc, tt.expectMaxUnc)
+			}
+		})
+	}
+}
+
+func TestEstimationInfoCoarse(t *testing.T) {
+	block := Block{StartTime: "2025-06-20T10:00:00Z", ActualEndTime: "2025-06-20T12:00:00Z", TotalTokens: 9000, Entries: 30}
+	tests := []struct {
+		name         string
+		jsonl        string // Log of the block, none when empty
+		expectCoarse bool
+		expectTokens int
+	}{
+		{"JSONL logs cleaned up", "", true, 300}, // 9000 tokens / 30 entries
+		{"JSONL logs available", `{"timestamp":"2025-06-20T10:30:00Z","type":"assistant","message":{"usage":{"output_tokens":400}}}` + "\n", false, 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claudeDir := t.TempDir()
+			t.Setenv("CLAUDE_CONFIG_DIR", claudeDir)
+			if tt.jsonl != "" {
+				project := filepath.Join(claudeDir, "projects", "-home-me-src")
+				if err := os.MkdirAll(project, 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(project, "session.jsonl"), []byte(tt.jsonl), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			est := NewTokenLimitEstimator()
+			if got := est.calculateAvgTokensPerMessage([]Block{block}); got != tt.expectTokens {
+				t.Errorf("calculateAvgTokensPerMessage() = %d, expected %d", got, tt.expectTokens)
+			}
+			info := est.GetEstimationInfo()
+			if info.Coarse != tt.expectCoarse || info.IsFromJSONL == tt.expectCoarse {
+				t.Errorf("estimation info = %+v, expected coarse %v", info, tt.expectCoarse)
+			}
+
+			var buffer strings.Builder
+			NewDisplay("UTC").renderEstimationInfo(&buffer, est, nil, "pro")
+			line := string(stripANSI([]byte(buffer.String())))
+			if got := strings.Contains(line, "(coarse)"); got != tt.expectCoarse {
+				t.Errorf("estimation info line %q tagged coarse = %v, expected %v", line, got, tt.expectCoarse)
+			}
+		})
+	}
+}
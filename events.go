@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// StatusEvent records a status transition and why it happened
+type StatusEvent struct {
+	Time    time.Time `json:"time"`
+	Status  string    `json:"status"`
+	Reason  string    `json:"reason"`            // English, for scripts and logs
+	Message *Message  `json:"message,omitempty"` // Reason before formatting, missing from older files
+}
+
+// DisplayReason returns the reason in the display language
+func (e StatusEvent) DisplayReason() string {
+	if e.Message != nil {
+		return e.Message.String()
+	}
+	return e.Reason
+}
+
+// EventLog keeps the most recent status transitions.
+// Events are persisted so `cctop events` can show what a running monitor saw.
+type EventLog struct {
+	events []StatusEvent
+	path   string
+	mu     sync.Mutex
+}
+
+// defaultEventLogPath returns the events file next to the estimator state
+func defaultEventLogPath() string {
+	stateDir := cctopStateDir()
+	if stateDir == "" {
+		return ""
+	}
+	return filepath.Join(stateDir, "events.json")
+}
+
+// NewEventLog creates an event log backed by path; a missing or corrupt file starts empty
+func NewEventLog(path string) *EventLog {
+	log := &EventLog{path: path}
+	log.load()
+	return log
+}
+
+// load reads the events from disk; a missing or corrupt file leaves none
+func (l *EventLog) load() {
+	l.events = nil
+	if l.path == "" {
+		return
+	}
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return
+	}
+	if err := json.Unmarshal(data, &l.events); err != nil {
+		l.events = nil
+	}
+}
+
+// Record appends an event when the session's status differs from the last recorded one.
+// It returns the new event, or nil when the status is unchanged.
+func (l *EventLog) Record(session *Session, currentTime time.Time) *StatusEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Other monitors and the daemon append to the same file
+	if l.path != "" {
+		if unlock, err := lockStateFile(l.path); err == nil {
+			defer unlock()
+		}
+		l.load()
+	}
+
+	status := session.statusAt(currentTime)
+	if len(l.events) > 0 && l.events[len(l.events)-1].Status == status {
+		return nil
+	}
+
+	reason := session.statusReason(currentTime)
+	event := StatusEvent{
+		Time:    currentTime,
+		Status:  status,
+		Reason:  reason.English(),
+		Message: &reason,
+	}
+	l.events = append(l.events, event)
+	if len(l.events) > MaxStatusEvents {
+		l.events = l.events[len(l.events)-MaxStatusEvents:]
+	}
+	_ = l.save()
+	return &event
+}
+
+// Events returns the recorded events, oldest first
+func (l *EventLog) Events() []StatusEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]StatusEvent(nil), l.events...)
+}
+
+// save writes the events to disk
+func (l *EventLog) save() error {
+	if l.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(l.events, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(l.path, data, 0o600)
+}
+
+// StatusReason explains the session's current status in the display language
+func (s *Session) StatusReason(currentTime time.Time) string {
+	return s.statusReason(currentTime).String()
+}
+
+// statusReason explains the session's current status
+func (s *Session) statusReason(currentTime time.Time) Message {
+	if s.TracksSpend() {
+		return s.spendReason(currentTime)
+	}
+	tokens := s.Metrics.Tokens
+	status := s.statusAt(currentTime)
+	if status == "LIMIT EXCEEDED" {
+		return newMessage("tokens %s exceeded limit %s", formatNumber(tokens.Used), formatNumber(tokens.Limit))
+	}
+
+	sustainable := 0.0
+	if minutesLeft := s.EndTime.Sub(currentTime).Minutes(); minutesLeft > 0 {
+		sustainable = float64(tokens.Remaining) / minutesLeft
+	}
+	if status == "CRITICAL" && s.Alerts != nil {
+		return newMessage("tokens %s passed critical threshold %.0f%%", formatNumber(tokens.Used), s.Alerts.Critical)
+	}
+	if status == "WARNING" {
+		if s.Alerts != nil && !s.pessimisticEndTime(currentTime).Before(s.EndTime) {
+			return newMessage("tokens %s passed warning threshold %.0f%%", formatNumber(tokens.Used), s.Alerts.Warning)
+		}
+		if s.BurnRate <= sustainable && s.BurnBand != nil {
+			return newMessage("bursts of %.0f/min exceeded sustainable %.0f/min", s.BurnBand.P90, sustainable)
+		}
+		return newMessage("burn rate %.0f/min exceeded sustainable %.0f/min", s.BurnRate, sustainable)
+	}
+	return newMessage("burn rate %.0f/min within sustainable %.0f/min", s.BurnRate, sustainable)
+}
+
+// runEvents prints status transitions recorded by the monitor
+func runEvents(cmd *cobra.Command, args []string) {
+	fmt.Print(display.RenderEvents(eventLog.Events(), 0))
+}
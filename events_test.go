@@ -0,0 +1,123 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventLogRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.json")
+	log := NewEventLog(path)
+	now := time.Now()
+	start := now.Add(-time.Hour)
+
+	ok := newTestSession(start, 1000, 10000)
+	warning := newTestSession(start, 5000, 10000)
+	warning.BurnRate = 100 // 5,000 remaining tokens last 50 minutes, before the 4h reset
+	exceeded := newTestSession(start, 12000, 10000)
+
+	for _, session := range []*Session{ok, ok, warning, warning, exceeded} {
+		log.Record(session, now)
+	}
+
+	events := log.Events()
+	expected := []string{"OK", "WARNING", "LIMIT EXCEEDED"}
+	if len(events) != len(expected) {
+		t.Fatalf("len(events) = %d, expected %d", len(events), len(expected))
+	}
+	for i, status := range expected {
+		if events[i].Status != status {
+			t.Errorf("events[%d].Status = %s, expected %s", i, events[i].Status, status)
+		}
+	}
+
+	// Events survive a restart, and an unchanged status is not recorded again
+	restarted := NewEventLog(path)
+	if event := restarted.Record(exceeded, now); event != nil {
+		t.Errorf("Record() after restart = %+v, expected no event", event)
+	}
+	if len(restarted.Events()) != len(expected) {
+		t.Errorf("reloaded events = %d, expected %d", len(restarted.Events()), len(expected))
+	}
+
+	// An event recorded by another process in the meantime is kept
+	restarted.Record(ok, now)
+	log.Record(warning, now)
+	if events := NewEventLog(path).Events(); len(events) != 5 {
+		t.Errorf("events after two processes recorded = %d, expected 5", len(events))
+	}
+}
+
+func TestStatusReason(t *testing.T) {
+	now := time.Now()
+	start := now.Add(-time.Hour) // 240 minutes remaining
+
+	tests := []struct {
+		name     string
+		used     int
+		burnRate float64
+		expected string
+	}{
+		{name: "Within", used: 1000, burnRate: 10, expected: "burn rate 10/min within sustainable 38/min"},
+		{name: "Exceeding", used: 1000, burnRate: 820, expected: "burn rate 820/min exceeded sustainable 38/min"},
+		{name: "Limit", used: 12000, expected: "tokens 12,000 exceeded limit 10,000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := newTestSession(start, tt.used, 10000)
+			session.BurnRate = tt.burnRate
+			if result := session.StatusReason(now); result != tt.expected {
+				t.Errorf("StatusReason() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+
+	// Runs out 180 minutes from now, before the reset, but not from 90 minutes later:
+	// the status is taken at the time asked about, without alert levels to fall back on
+	session := newTestSession(start, 1000, 10000)
+	session.BurnRate = 50
+	if result := session.StatusReason(now.Add(90 * time.Minute)); result != "burn rate 50/min within sustainable 60/min" {
+		t.Errorf("StatusReason() later = %q, expected the burn rate within the sustainable rate", result)
+	}
+}
+
+func TestRenderEvents(t *testing.T) {
+	d := NewDisplay("UTC")
+	at := time.Date(2025, 6, 20, 12, 31, 0, 0, time.UTC)
+	events := []StatusEvent{
+		{Time: at, Status: "OK", Reason: "first"},
+		{Time: at.Add(time.Minute), Status: "WARNING", Reason: "second"},
+	}
+
+	output := d.RenderEvents(events, 1)
+	if !strings.Contains(output, "second") || strings.Contains(output, "first") {
+		t.Errorf("RenderEvents() = %q, expected only the newest event", output)
+	}
+}
+
+func TestEventReasonTranslatedWhenShown(t *testing.T) {
+	oldLang := lang
+	defer func() { lang = oldLang }()
+	lang = LangJapanese
+
+	path := filepath.Join(t.TempDir(), "events.json")
+	now := time.Now()
+	NewEventLog(path).Record(newTestSession(now.Add(-time.Hour), 12000, 10000), now)
+
+	// The file keeps English for scripts; the display language applies when rendering
+	event := NewEventLog(path).Events()[0]
+	if event.Reason != "tokens 12,000 exceeded limit 10,000" {
+		t.Errorf("Reason = %q, expected English", event.Reason)
+	}
+	if got := event.DisplayReason(); got != "トークン 12,000 が上限 10,000 を超過" {
+		t.Errorf("DisplayReason() = %q, expected the Japanese reason", got)
+	}
+
+	// Events from older files have no message and show the stored reason
+	if got := (StatusEvent{Reason: "old"}).DisplayReason(); got != "old" {
+		t.Errorf("DisplayReason() without a message = %q, expected old", got)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// RenderExitBanner renders the one-line summary left in scrollback when the monitor exits
+// Format: "● WARNING  63,000/140,000 tokens (45%)  today $12.34  reset 15:00 (in 2h 10m)"
+func (d *Display) RenderExitBanner(session *Session, err error, currentTime time.Time) string {
+	var idle *NoActiveSessionError
+	switch {
+	case session != nil:
+	case errors.As(err, &idle):
+		return fmt.Sprintf("%s  today %s", mutedString("● idle"), formatCost(idle.Idle.TodayCost))
+	case err != nil:
+		return mutedString("● no data  %s", err)
+	default:
+		return mutedString("● no data")
+	}
+
+	status := session.GetStatus()
+	tokens := session.Metrics.Tokens
+	usage := fmt.Sprintf("%s/%s tokens (%.0f%%)", formatNumber(tokens.Used), formatNumber(tokens.Limit), tokens.Percentage)
+	if session.NoLimit {
+		usage = fmt.Sprintf("%s tokens", formatNumber(tokens.Used))
+	}
+	return fmt.Sprintf("%s  %s  today %s  reset %s (in %s)",
+		colorizeStatus(status, "● %s", statusLabel(status)),
+		usage,
+		formatCost(session.TodayCost),
+		session.EndTime.In(d.timezone).Format(TimeFormatShort),
+		formatTime(session.EndTime.Sub(currentTime).Minutes()))
+}
+
+// RenderExitSummary renders the session totals printed under the exit banner
+func (d *Display) RenderExitSummary(session *Session, currentTime time.Time) string {
+	var buffer strings.Builder
+	elapsed := max(minTime(currentTime, session.EndTime).Sub(session.StartTime), 0)
+	tokens := session.Metrics.Tokens
+
+	fmt.Fprintf(&buffer, "  Session  %s-%s, %s of %s\n",
+		session.StartTime.In(d.timezone).Format(TimeFormatShort),
+		session.EndTime.In(d.timezone).Format(TimeFormatShort),
+		formatTime(elapsed.Minutes()),
+		formatTime(SessionDuration.Minutes()))
+	if session.NoLimit {
+		fmt.Fprintf(&buffer, "  Tokens   %s (no limit)\n", formatNumber(tokens.Used))
+	} else {
+		fmt.Fprintf(&buffer, "  Tokens   %s, %.1f%% of the predicted %s limit\n",
+			formatNumber(tokens.Used), tokens.Percentage, formatNumber(tokens.Limit))
+	}
+	if elapsed >= time.Minute {
+		average := float64(tokens.Used) / elapsed.Minutes()
+		fmt.Fprintf(&buffer, "  Burn     %s/min average (%s/h)\n",
+			formatNumber(int(average)), formatNumber(int(average*MinutesPerHour)))
+	}
+	fmt.Fprintf(&buffer, "  Cost     %s this session, %s today\n", formatCost(session.Block.CostUSD), formatCost(session.TodayCost))
+	if models := exitSummaryModels(session); models != "" {
+		fmt.Fprintf(&buffer, "  Models   %s\n", models)
+	}
+	return buffer.String()
+}
+
+// exitSummaryModels lists the session's models, with each family's share of the tokens
+// when per-model tokens were loaded
+func exitSummaryModels(session *Session) string {
+	var models []string
+	if shares := session.ModelShares(); len(shares) > 0 {
+		for _, share := range shares {
+			models = append(models, fmt.Sprintf("%s %.0f%%", share.Family, share.Percentage))
+		}
+	} else {
+		for _, model := range session.Block.Models {
+			models = append(models, formatModelName(model))
+		}
+	}
+	return strings.Join(models, ", ")
+}
+
+// RenderExit renders what the monitor leaves in scrollback: the exit banner, followed
+// by the session summary unless --exit-summary=false
+func (d *Display) RenderExit(session *Session, err error, currentTime time.Time) string {
+	banner := d.RenderExitBanner(session, err, currentTime)
+	if session == nil || !config.ExitSummary {
+		return banner
+	}
+	return banner + "\n" + mutedString("%s", strings.TrimSuffix(d.RenderExitSummary(session, currentTime), "\n"))
+}
+
+// exitState is the last refresh of the plain monitor, printed as the exit banner and summary on interrupt
+type exitState struct {
+	session *Session
+	err     error
+}
+
+// printBannerOnInterrupt prints the exit banner and summary of the latest state and exits when the
+// plain monitor is interrupted, since its refresh loop never returns
+func printBannerOnInterrupt(latest *atomic.Pointer[exitState]) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		if state := latest.Load(); state != nil {
+			fmt.Fprintln(os.Stdout, display.RenderExit(state.session, state.err, time.Now()))
+		}
+		shutdown()
+		os.Exit(130) // Conventional exit status after SIGINT
+	}()
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderExitBanner(t *testing.T) {
+	display := NewDisplay("UTC")
+	start := time.Date(2025, 6, 20, 10, 0, 0, 0, time.UTC)
+	now := start.Add(2*time.Hour + 50*time.Minute)
+
+	session := newTestSession(start, 63000, 140000)
+	session.TodayCost = 12.34
+	exceeded := newTestSession(start, 150000, 140000)
+	noLimit := newTestSession(start, 63000, 140000)
+	noLimit.NoLimit = true
+
+	tests := []struct {
+		name     string
+		session  *Session
+		err      error
+		expected string
+	}{
+		{"OK", session, nil, "● OK  63,000/140,000 tokens (45%)  today $12.34  reset 15:00 (in 2h 10m)"},
+		{"Limit exceeded", exceeded, nil, "● LIMIT EXCEEDED  150,000/140,000 tokens (107%)  today $0.00  reset 15:00 (in 2h 10m)"},
+		{"No limit", noLimit, nil, "● OK  63,000 tokens  today $0.00  reset 15:00 (in 2h 10m)"},
+		{"Idle", nil, &NoActiveSessionError{Idle: IdleSummary{TodayCost: 3.5}}, "● idle  today $3.50"},
+		{"Error", nil, errors.New("Failed to get usage data"), "● no data  Failed to get usage data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(stripANSI([]byte(display.RenderExitBanner(tt.session, tt.err, now))))
+			if got != tt.expected {
+				t.Errorf("RenderExitBanner() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderExitSummary(t *testing.T) {
+	display := NewDisplay("UTC")
+	start := time.Date(2025, 6, 20, 10, 0, 0, 0, time.UTC)
+	now := start.Add(2*time.Hour + 30*time.Minute)
+
+	session := newTestSession(start, 63000, 140000)
+	session.Block.CostUSD = 4.2
+	session.Block.Models = []string{"claude-opus-4-20250514", "claude-sonnet-4-20250514"}
+	session.TodayCost = 12.34
+
+	expected := `  Session  10:00-15:00, 2h 30m of 5h
+  Tokens   63,000, 45.0% of the predicted 140,000 limit
+  Burn     420/min average (25,200/h)
+  Cost     $4.20 this session, $12.34 today
+  Models   claude-opus-4-20250514, claude-sonnet-4-20250514
+`
+	if got := display.RenderExitSummary(session, now); got != expected {
+		t.Errorf("RenderExitSummary() = %q, expected %q", got, expected)
+	}
+
+	// With per-model tokens loaded, models show their share; after the reset the
+	// elapsed time stops at the session end
+	session.ModelTokens = map[string]int{"claude-opus-4-20250514": 47250, "claude-sonnet-4-20250514": 15750}
+	session.NoLimit = true
+	got := display.RenderExitSummary(session, start.Add(6*time.Hour))
+	for _, want := range []string{"5h of 5h", "63,000 (no limit)", "210/min average", "Opus 75%, Sonnet 25%"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("RenderExitSummary() = %q, expected it to contain %q", got, want)
+		}
+	}
+}
+
+func TestRenderExit(t *testing.T) {
+	oldConfig := config
+	defer func() { config = oldConfig }()
+	config = NewConfig()
+	display := NewDisplay("UTC")
+	start := time.Date(2025, 6, 20, 10, 0, 0, 0, time.UTC)
+	session := newTestSession(start, 63000, 140000)
+
+	if got := strings.Count(display.RenderExit(session, nil, start.Add(time.Hour)), "\n"); got != 4 {
+		t.Errorf("RenderExit() has %d line breaks, expected the banner and 4 summary lines", got)
+	}
+	if got := display.RenderExit(nil, errors.New("no data"), start); strings.Contains(got, "\n") {
+		t.Errorf("RenderExit() without a session = %q, expected only the banner", got)
+	}
+	config.ExitSummary = false
+	if got := display.RenderExit(session, nil, start.Add(time.Hour)); strings.Contains(got, "\n") {
+		t.Errorf("RenderExit() with --exit-summary=false = %q, expected only the banner", got)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Experiment names accepted by --experimental
+const (
+	ExperimentNativeSource = "native-source"
+	ExperimentForecastV2   = "forecast-v2"
+)
+
+// Experiment is an in-development subsystem that ships disabled until enabled with --experimental
+type Experiment struct {
+	Name        string
+	Description string
+}
+
+// experiments lists the subsystems that can be enabled; names leave the list once
+// their feature ships enabled or is dropped
+var experiments = []Experiment{
+	{ExperimentNativeSource, "Build blocks from the Claude logs directly instead of running ccusage"},
+	{ExperimentForecastV2, "Predict depletion from the smoothed burn rate instead of the burn window average"},
+}
+
+// experimentEnabled reports whether the experiment was enabled in the flags or config file
+func experimentEnabled(name string) bool {
+	return slices.Contains(config.Experimental, name)
+}
+
+// validExperiment rejects names that are not in experiments
+func validExperiment(value string) error {
+	for _, name := range strings.Split(value, ",") {
+		if !slices.ContainsFunc(experiments, func(e Experiment) bool { return e.Name == name }) {
+			return fmt.Errorf("unknown experiment %q, see 'cctop experiments'", name)
+		}
+	}
+	return nil
+}
+
+// runExperiments lists the experiments and whether each is enabled
+func runExperiments(cmd *cobra.Command, args []string) {
+	fmt.Println("Experiments for --experimental (or \"experimental\" in the config file):")
+	fmt.Println()
+	for _, experiment := range experiments {
+		state := " "
+		if experimentEnabled(experiment.Name) {
+			state = "*"
+		}
+		fmt.Printf("%s %-15s - %s\n", state, experiment.Name, experiment.Description)
+	}
+	fmt.Println()
+	fmt.Println("Enabled experiments are marked with *. They may change or go away between releases.")
+}
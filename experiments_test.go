@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidExperiment(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{ExperimentNativeSource, false},
+		{ExperimentForecastV2, false},
+		{"native-source,forecast-v2", false},
+		{"forecast-v3", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		if err := validExperiment(tt.value); (err != nil) != tt.wantErr {
+			t.Errorf("validExperiment(%q) error = %v, expected error %v", tt.value, err, tt.wantErr)
+		}
+	}
+}
+
+func TestExperimentalConfigFile(t *testing.T) {
+	if err := checkFlagValues(lookupFlag("experimental"), []string{ExperimentNativeSource, ExperimentForecastV2}); err != nil {
+		t.Errorf("checkFlagValues(known experiments) = %v, expected nil", err)
+	}
+	if err := checkFlagValues(lookupFlag("experimental"), []string{"bogus"}); err == nil {
+		t.Error("checkFlagValues(unknown experiment) = nil, expected an error")
+	}
+}
+
+func TestForecastV2(t *testing.T) {
+	oldConfig := config
+	defer func() { config = oldConfig }()
+	config = NewConfig()
+
+	now := time.Now()
+	session := newTestSession(now.Add(-time.Hour), 4000, 10000)
+	session.BurnRate = 10
+	session.RecentRates = &BurnRates{Instant: 200, Smoothed: 100}
+
+	// Disabled: the 6,000 remaining tokens last 600 minutes at the burn window average
+	if predicted, expected := session.GetPredictedEndTime(now), now.Add(10*time.Hour); !predicted.Equal(expected) {
+		t.Errorf("GetPredictedEndTime() = %v, expected %v", predicted, expected)
+	}
+
+	config.Experimental = []string{ExperimentForecastV2}
+	if predicted, expected := session.GetPredictedEndTime(now), now.Add(time.Hour); !predicted.Equal(expected) {
+		t.Errorf("GetPredictedEndTime() with forecast-v2 = %v, expected %v", predicted, expected)
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Export formats and record kinds
+const (
+	ExportCSV      = "csv"
+	ExportParquet  = "parquet"
+	ExportBlocks   = "blocks"
+	ExportMessages = "messages"
+)
+
+var (
+	exportFormat  string
+	exportSince   string
+	exportRecords string
+)
+
+// runExport writes session history records to stdout for spreadsheets, DuckDB or pandas
+func runExport(cmd *cobra.Command, args []string) {
+	if err := exportHistory(os.Stdout, exportFormat, exportRecords, exportSince, time.Now()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// exportHistory writes block or message records since the given date (YYYY-MM-DD, "" for all)
+func exportHistory(w io.Writer, format, records, since string, now time.Time) error {
+	var write func(io.Writer, exportTable) error
+	switch format {
+	case ExportCSV:
+		write = writeCSV
+	case ExportParquet:
+		write = writeParquet
+	default:
+		return fmt.Errorf("unknown format %q (csv, parquet)", format)
+	}
+
+	var sinceTime time.Time
+	if since != "" {
+		parsed, err := time.ParseInLocation(DateFormat, since, display.timezone)
+		if err != nil {
+			return fmt.Errorf("invalid --since %q, expected YYYY-MM-DD", since)
+		}
+		sinceTime = parsed
+	}
+
+	switch records {
+	case ExportBlocks:
+		data := fetchUsageData()
+		if data == nil {
+			return fmt.Errorf("Failed to get usage data")
+		}
+		return write(w, blockTable(data.Blocks, sinceTime))
+	case ExportMessages:
+		messages, err := NewMessageTokenReader().GetMessageRecords(sinceTime.Format(time.RFC3339), now.Format(time.RFC3339))
+		if err != nil {
+			return err
+		}
+		return write(w, messageTable(messages))
+	default:
+		return fmt.Errorf("unknown records %q (blocks, messages)", records)
+	}
+}
+
+// exportColumn is a named column with its Parquet physical type
+type exportColumn struct {
+	name string
+	kind int
+}
+
+// exportTable holds exported rows of string, int64, float64, bool or nil (missing) values
+type exportTable struct {
+	columns []exportColumn
+	rows    [][]any
+}
+
+// blockTable has one row per session block starting at or after since, skipping gaps
+func blockTable(blocks []Block, since time.Time) exportTable {
+	table := exportTable{columns: []exportColumn{
+		{"start", parquetByteArray}, {"end", parquetByteArray}, {"active", parquetBoolean},
+		{"models", parquetByteArray}, {"input_tokens", parquetInt64}, {"output_tokens", parquetInt64},
+		{"cache_creation_tokens", parquetInt64}, {"cache_read_tokens", parquetInt64},
+		{"total_tokens", parquetInt64}, {"entries", parquetInt64}, {"cost_usd", parquetDouble},
+	}}
+
+	for _, block := range blocks {
+		if block.IsGap {
+			continue
+		}
+		start, err := time.Parse(time.RFC3339, block.StartTime)
+		if err != nil || start.Before(since) {
+			continue
+		}
+		var end any
+		if block.ActualEndTime != "" {
+			end = block.ActualEndTime
+		}
+		counts := block.TokenCounts
+		table.rows = append(table.rows, []any{
+			block.StartTime,
+			end,
+			block.IsActive,
+			strings.Join(block.Models, " "),
+			int64(counts.InputTokens),
+			int64(counts.OutputTokens),
+			int64(counts.CacheCreationInputTokens),
+			int64(counts.CacheReadInputTokens),
+			int64(block.TotalTokens),
+			int64(block.Entries),
+			block.CostUSD,
+		})
+	}
+	return table
+}
+
+// messageTable has one row per assistant message; cost is missing when not logged
+func messageTable(messages []MessageRecord) exportTable {
+	table := exportTable{columns: []exportColumn{
+		{"time", parquetByteArray}, {"project", parquetByteArray}, {"model", parquetByteArray},
+		{"input_tokens", parquetInt64}, {"output_tokens", parquetInt64},
+		{"cache_creation_tokens", parquetInt64}, {"cache_read_tokens", parquetInt64}, {"cost_usd", parquetDouble},
+	}}
+
+	for _, message := range messages {
+		var cost any
+		if message.CostUSD > 0 {
+			cost = message.CostUSD
+		}
+		table.rows = append(table.rows, []any{
+			message.Time.UTC().Format(time.RFC3339),
+			message.Project,
+			message.Model,
+			int64(message.Usage.InputTokens),
+			int64(message.Usage.OutputTokens),
+			int64(message.Usage.CacheCreationInputTokens),
+			int64(message.Usage.CacheReadInputTokens),
+			cost,
+		})
+	}
+	return table
+}
+
+// writeCSV writes the table with a header row; missing values are empty
+func writeCSV(w io.Writer, table exportTable) error {
+	out := csv.NewWriter(w)
+	header := make([]string, len(table.columns))
+	for i, column := range table.columns {
+		header[i] = column.name
+	}
+	_ = out.Write(header)
+
+	for _, row := range table.rows {
+		record := make([]string, len(row))
+		for i, value := range row {
+			switch v := value.(type) {
+			case string:
+				record[i] = v
+			case bool:
+				record[i] = strconv.FormatBool(v)
+			case int64:
+				record[i] = strconv.FormatInt(v, 10)
+			case float64:
+				record[i] = strconv.FormatFloat(v, 'f', 6, 64)
+			}
+		}
+		_ = out.Write(record)
+	}
+	out.Flush()
+	return out.Error()
+}
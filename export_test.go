@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteBlockCSV(t *testing.T) {
+	blocks := []Block{
+		{StartTime: "2025-06-18T09:00:00Z", TotalTokens: 100},
+		{
+			StartTime: "2025-06-20T09:00:00Z", ActualEndTime: "2025-06-20T11:00:00Z",
+			Models:      []string{"claude-opus-4-20250514", "claude-sonnet-4-20250514"},
+			TokenCounts: TokenCounts{InputTokens: 10, OutputTokens: 20, CacheCreationInputTokens: 30, CacheReadInputTokens: 40},
+			TotalTokens: 100, Entries: 3, CostUSD: 1.25,
+		},
+		{StartTime: "2025-06-20T11:00:00Z", IsGap: true},
+	}
+
+	var buffer bytes.Buffer
+	if err := writeCSV(&buffer, blockTable(blocks, time.Date(2025, 6, 19, 0, 0, 0, 0, time.UTC))); err != nil {
+		t.Fatal(err)
+	}
+	expected := "start,end,active,models,input_tokens,output_tokens,cache_creation_tokens,cache_read_tokens,total_tokens,entries,cost_usd\n" +
+		"2025-06-20T09:00:00Z,2025-06-20T11:00:00Z,false,claude-opus-4-20250514 claude-sonnet-4-20250514,10,20,30,40,100,3,1.250000\n"
+	if buffer.String() != expected {
+		t.Errorf("writeCSV(blockTable()) =\n%s\nexpected\n%s", buffer.String(), expected)
+	}
+}
+
+func TestExportMessages(t *testing.T) {
+	projectsDir := t.TempDir()
+	dir := filepath.Join(projectsDir, "-home-me-src-cctop")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	lines := `{"timestamp":"2025-06-20T10:05:00Z","type":"assistant","cwd":"/home/me/src/cctop","costUSD":0.5,"message":{"model":"claude-opus-4-20250514","usage":{"input_tokens":1,"output_tokens":2,"cache_creation_input_tokens":3,"cache_read_input_tokens":4}}}
+{"timestamp":"2025-06-20T10:00:00Z","type":"assistant","cwd":"/home/me/src/cctop","message":{"model":"claude-sonnet-4-20250514","usage":{"output_tokens":7}}}
+{"timestamp":"2025-06-20T10:01:00Z","type":"user"}
+`
+	if err := os.WriteFile(filepath.Join(dir, "session.jsonl"), []byte(lines), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := &MessageTokenReader{claudeProjectsDirs: []string{projectsDir}}
+	records, err := reader.GetMessageRecords("2025-06-20T00:00:00Z", "2025-06-21T00:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buffer bytes.Buffer
+	if err := writeCSV(&buffer, messageTable(records)); err != nil {
+		t.Fatal(err)
+	}
+	expected := "time,project,model,input_tokens,output_tokens,cache_creation_tokens,cache_read_tokens,cost_usd\n" +
+		"2025-06-20T10:00:00Z,/home/me/src/cctop,claude-sonnet-4-20250514,0,7,0,0,\n" +
+		"2025-06-20T10:05:00Z,/home/me/src/cctop,claude-opus-4-20250514,1,2,3,4,0.500000\n"
+	if buffer.String() != expected {
+		t.Errorf("writeCSV(messageTable()) =\n%s\nexpected\n%s", buffer.String(), expected)
+	}
+}
+
+func TestExportHistoryErrors(t *testing.T) {
+	oldDisplay := display
+	defer func() { display = oldDisplay }()
+	display = NewDisplay("UTC")
+
+	tests := []struct {
+		name     string
+		format   string
+		records  string
+		since    string
+		expected string
+	}{
+		{name: "Unknown format", format: "xlsx", records: ExportBlocks, expected: "unknown format"},
+		{name: "Bad date", format: ExportCSV, records: ExportBlocks, since: "06/20/2025", expected: "YYYY-MM-DD"},
+		{name: "Unknown records", format: ExportCSV, records: "sessions", expected: "unknown records"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := exportHistory(&bytes.Buffer{}, tt.format, tt.records, tt.since, time.Now())
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("exportHistory() error = %v, expected one mentioning %q", err, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// FixtureOptions controls the shape of generated fixtures
+type FixtureOptions struct {
+	Sessions       int
+	Projects       int
+	MessagesMean   int     // Mean messages per session
+	TokensMean     float64 // Mean tokens per message
+	TokensStdDev   float64 // Standard deviation of tokens per message
+	Model          string
+	Seed           int64
+	Start          time.Time
+	CostPerMToken  float64 // USD per million tokens
+	LeaveLastOpen  bool    // Mark the final session as the active block
+	SessionSpacing time.Duration
+}
+
+// fixtureLine is a single assistant entry in a generated JSONL file
+type fixtureLine struct {
+	Timestamp string           `json:"timestamp"`
+	Type      string           `json:"type"`
+	SessionID string           `json:"sessionId"`
+	UUID      string           `json:"uuid"`
+	Message   AssistantMessage `json:"message"`
+}
+
+// Fixtures holds generated data before it is written to disk
+type Fixtures struct {
+	Blocks []Block
+	Daily  []DailyUsage
+	Files  map[string][]fixtureLine // Relative JSONL path to its lines
+}
+
+var fixtureOptions = DefaultFixtureOptions()
+
+var fixtureOutDir string
+
+// DefaultFixtureOptions returns options resembling a typical Pro user
+func DefaultFixtureOptions() FixtureOptions {
+	return FixtureOptions{
+		Sessions:       50,
+		Projects:       3,
+		MessagesMean:   45,
+		TokensMean:     150,
+		TokensStdDev:   50,
+		Model:          "claude-sonnet-4-20250514",
+		Seed:           1,
+		Start:          time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC),
+		CostPerMToken:  15,
+		LeaveLastOpen:  true,
+		SessionSpacing: 8 * time.Hour,
+	}
+}
+
+// runGenFixtures writes synthetic fixtures to the output directory
+func runGenFixtures(cmd *cobra.Command, args []string) {
+	fixtures := GenerateFixtures(fixtureOptions)
+	if err := fixtures.Write(fixtureOutDir); err != nil {
+		fmt.Println("Error writing fixtures:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Wrote %d sessions across %d projects to %s\n",
+		fixtureOptions.Sessions, fixtureOptions.Projects, fixtureOutDir)
+}
+
+// GenerateFixtures creates synthetic session blocks, daily totals and JSONL messages
+func GenerateFixtures(opts FixtureOptions) Fixtures {
+	rng := rand.New(rand.NewSource(opts.Seed)) // #nosec G404 -- deterministic test data
+	fixtures := Fixtures{Files: make(map[string][]fixtureLine)}
+	dailyIndex := make(map[string]int)
+	projects := max(opts.Projects, 1)
+
+	for i := 0; i < opts.Sessions; i++ {
+		start := opts.Start.Add(time.Duration(i) * opts.SessionSpacing)
+		messages := max(int(float64(opts.MessagesMean)*(0.5+rng.Float64())), 1)
+		// Spread messages over up to four hours of the five-hour window
+		step := 4 * time.Hour / time.Duration(messages)
+
+		path := filepath.Join(fmt.Sprintf("project-%d", i%projects), fmt.Sprintf("session-%04d.jsonl", i))
+		block := Block{
+			StartTime: start.Format(time.RFC3339),
+			Models:    []string{opts.Model},
+			Entries:   messages,
+		}
+
+		var last time.Time
+		for m := 0; m < messages; m++ {
+			last = start.Add(time.Duration(m) * step)
+			tokens := max(int(math.Round(opts.TokensMean+rng.NormFloat64()*opts.TokensStdDev)), 1)
+			output := tokens / 4
+			fixtures.Files[path] = append(fixtures.Files[path], fixtureLine{
+				Timestamp: last.Format(time.RFC3339),
+				Type:      "assistant",
+				SessionID: fmt.Sprintf("session-%04d", i),
+				UUID:      fmt.Sprintf("%04d-%04d", i, m),
+				Message: AssistantMessage{
+					Role:  "assistant",
+					Model: opts.Model,
+					Usage: TokenUsage{InputTokens: tokens - output, OutputTokens: output},
+				},
+			})
+			block.TotalTokens += tokens
+		}
+
+		block.CostUSD = float64(block.TotalTokens) * opts.CostPerMToken / 1e6
+		if opts.LeaveLastOpen && i == opts.Sessions-1 {
+			block.IsActive = true
+		} else {
+			block.ActualEndTime = last.Format(time.RFC3339)
+		}
+		fixtures.Blocks = append(fixtures.Blocks, block)
+
+		date := start.Format(DateFormat)
+		idx, ok := dailyIndex[date]
+		if !ok {
+			idx = len(fixtures.Daily)
+			dailyIndex[date] = idx
+			fixtures.Daily = append(fixtures.Daily, DailyUsage{Date: date})
+		}
+		fixtures.Daily[idx].TotalTokens += block.TotalTokens
+		fixtures.Daily[idx].TotalCost += block.CostUSD
+	}
+
+	return fixtures
+}
+
+// Write stores fixtures under dir as projects/<project>/*.jsonl, blocks.json and daily.json
+func (f Fixtures) Write(dir string) error {
+	for path, lines := range f.Files {
+		fullPath := filepath.Join(dir, "projects", path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+			return err
+		}
+		if err := writeJSONLines(fullPath, lines); err != nil {
+			return err
+		}
+	}
+
+	if err := writeJSONFile(filepath.Join(dir, "blocks.json"), CCUsageData{Blocks: f.Blocks}); err != nil {
+		return err
+	}
+	return writeJSONFile(filepath.Join(dir, "daily.json"), struct {
+		Daily []DailyUsage `json:"daily"`
+	}{Daily: f.Daily})
+}
+
+// writeJSONLines writes one JSON document per line
+func writeJSONLines(path string, lines []fixtureLine) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, line := range lines {
+		if err := encoder.Encode(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeJSONFile writes v as indented JSON
+func writeJSONFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateFixtures(t *testing.T) {
+	opts := DefaultFixtureOptions()
+	opts.Sessions = 10
+
+	fixtures := GenerateFixtures(opts)
+	if len(fixtures.Blocks) != 10 {
+		t.Fatalf("len(Blocks) = %d, expected 10", len(fixtures.Blocks))
+	}
+	if !fixtures.Blocks[9].IsActive || fixtures.Blocks[0].IsActive {
+		t.Error("expected only the last block to be active")
+	}
+
+	// Same seed, same data
+	again := GenerateFixtures(opts)
+	if again.Blocks[3].TotalTokens != fixtures.Blocks[3].TotalTokens {
+		t.Error("fixtures are not deterministic for a fixed seed")
+	}
+
+	dailyTokens := 0
+	for _, day := range fixtures.Daily {
+		dailyTokens += day.TotalTokens
+	}
+	blockTokens := 0
+	for _, block := range fixtures.Blocks {
+		blockTokens += block.TotalTokens
+	}
+	if dailyTokens != blockTokens {
+		t.Errorf("daily tokens %d != block tokens %d", dailyTokens, blockTokens)
+	}
+}
+
+func TestWriteFixturesReadable(t *testing.T) {
+	opts := DefaultFixtureOptions()
+	opts.Sessions = 5
+	fixtures := GenerateFixtures(opts)
+
+	dir := t.TempDir()
+	if err := fixtures.Write(dir); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "blocks.json"))
+	if err != nil {
+		t.Fatalf("reading blocks.json: %v", err)
+	}
+	var usage CCUsageData
+	if err := json.Unmarshal(data, &usage); err != nil || len(usage.Blocks) != 5 {
+		t.Fatalf("blocks.json parsed %d blocks (err %v), expected 5", len(usage.Blocks), err)
+	}
+
+	// The JSONL tree is readable by the real message reader
+	reader := &MessageTokenReader{claudeProjectsDirs: []string{filepath.Join(dir, "projects")}}
+	block := usage.Blocks[0]
+	tokens, err := reader.GetBlockTokens(block.StartTime, block.ActualEndTime)
+	if err != nil {
+		t.Fatalf("GetBlockTokens() error: %v", err)
+	}
+	if len(tokens) != block.Entries {
+		t.Errorf("read %d messages, expected %d", len(tokens), block.Entries)
+	}
+}
@@ -0,0 +1,53 @@
+{
+  description = "cctop - monitor Claude Code token usage in real time";
+
+  inputs = {
+    nixpkgs.url = "github:NixOS/nixpkgs/nixos-unstable";
+    flake-utils.url = "github:numtide/flake-utils";
+  };
+
+  outputs = { self, nixpkgs, flake-utils }:
+    flake-utils.lib.eachDefaultSystem (system:
+      let
+        pkgs = nixpkgs.legacyPackages.${system};
+        # Same metadata as goreleaser and make, see `cctop version --json`
+        commit = self.rev or self.dirtyRev or "none";
+        # lastModifiedDate is YYYYMMDDHHMMSS; written like the commit date goreleaser embeds
+        d = self.lastModifiedDate or "19700101000000";
+        date = with builtins;
+          "${substring 0 4 d}-${substring 4 2 d}-${substring 6 2 d}T${substring 8 2 d}:${substring 10 2 d}:${substring 12 2 d}Z";
+      in
+      {
+        packages.default = pkgs.buildGoModule {
+          pname = "cctop";
+          version = "0-unstable-${self.shortRev or self.dirtyShortRev or "dev"}";
+          src = self;
+
+          # Set by `make nix-vendor-hash`, which needs nix; run it after every change to go.mod.
+          # CI builds the flake, so a stale hash fails there with the expected value
+          vendorHash = pkgs.lib.fakeHash;
+
+          env.CGO_ENABLED = 0;
+          ldflags = [
+            "-s"
+            "-w"
+            "-X main.version=${self.shortRev or self.dirtyShortRev or "dev"}"
+            "-X main.commit=${commit}"
+            "-X main.date=${date}"
+            "-X main.builtBy=nix"
+          ];
+          subPackages = [ "." ];
+
+          meta = {
+            description = "Monitor Claude Code token usage with an htop-inspired terminal interface";
+            homepage = "https://github.com/Sixeight/cctop";
+            license = pkgs.lib.licenses.mit;
+            mainProgram = "cctop";
+          };
+        };
+
+        devShells.default = pkgs.mkShell {
+          packages = [ pkgs.go pkgs.gnumake pkgs.sqlite ];
+        };
+      });
+}
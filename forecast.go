@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// brailleCanvas plots dots on a grid of braille characters, 2 dots wide and 4 high each.
+// Every cell takes the color of the highest layer drawn in it.
+type brailleCanvas struct {
+	width, height int // In cells
+	dots          [][]rune
+	layers        [][]int
+}
+
+// brailleBits are the dot bits of a braille cell by [column][row], row 0 at the top
+var brailleBits = [2][4]rune{{0x01, 0x02, 0x04, 0x40}, {0x08, 0x10, 0x20, 0x80}}
+
+// newBrailleCanvas creates an empty canvas of width x height cells
+func newBrailleCanvas(width, height int) *brailleCanvas {
+	canvas := &brailleCanvas{width: width, height: height}
+	for range height {
+		canvas.dots = append(canvas.dots, make([]rune, width))
+		canvas.layers = append(canvas.layers, make([]int, width))
+	}
+	return canvas
+}
+
+// set draws the dot at x, y on layer (from 1), with y = 0 at the bottom
+func (c *brailleCanvas) set(x, y, layer int) {
+	if x < 0 || y < 0 || x >= c.width*2 || y >= c.height*4 {
+		return
+	}
+	row, column := c.height-1-y/4, x/2
+	c.dots[row][column] |= brailleBits[x%2][3-y%4]
+	c.layers[row][column] = max(c.layers[row][column], layer)
+}
+
+// rows renders each row of cells, painting runs of a layer with paint[layer-1]
+func (c *brailleCanvas) rows(paint []func(string) string) []string {
+	rows := make([]string, c.height)
+	for i := range c.height {
+		var row strings.Builder
+		for start := 0; start < c.width; {
+			end := start
+			var run strings.Builder
+			for end < c.width && c.layers[i][end] == c.layers[i][start] {
+				if c.dots[i][end] == 0 {
+					run.WriteRune(' ')
+				} else {
+					run.WriteRune(0x2800 + c.dots[i][end])
+				}
+				end++
+			}
+			if layer := c.layers[i][start]; layer > 0 {
+				row.WriteString(paint[layer-1](run.String()))
+			} else {
+				row.WriteString(run.String())
+			}
+			start = end
+		}
+		rows[i] = row.String()
+	}
+	return rows
+}
+
+// Forecast chart layers, later ones drawn over earlier ones
+const (
+	forecastLimitLayer = iota + 1
+	forecastProjectionLayer
+	forecastUsageLayer
+)
+
+// cumulativeTokensAt returns the tokens used by t from the session's messages, scaled so
+// that all messages add up to used (ccusage and the logs can differ slightly). Without
+// messages usage is assumed linear between start and now.
+func cumulativeTokensAt(timeline []TimedTokens, used int, start, now, t time.Time) int {
+	total, before := 0, 0
+	for _, message := range timeline {
+		total += message.Tokens
+		if !message.Time.After(t) {
+			before += message.Tokens
+		}
+	}
+	if total == 0 {
+		return blockTokensAt(used, now.Sub(start), t.Sub(start))
+	}
+	return int(float64(before) / float64(total) * float64(used))
+}
+
+// renderForecastChart plots the session's cumulative tokens so far and, dotted, where
+// the current burn rate takes them until the limit or the reset
+func (d *Display) renderForecastChart(buffer *strings.Builder, session *Session) {
+	width := d.barWidth() + 2 // As wide as a progress bar with its brackets
+	canvas := newBrailleCanvas(width, ForecastChartRows)
+	dotsX, dotsY := width*2, ForecastChartRows*4
+
+	currentTime := minTime(d.config.CurrentTime, session.EndTime)
+	used, limit := session.Metrics.Tokens.Used, session.Metrics.Tokens.Limit
+	rate := session.forecastRate()
+	exceeded := !session.NoLimit && used >= limit
+	depletion := session.EndTime
+	switch {
+	case exceeded:
+		depletion = currentTime
+	case !session.NoLimit:
+		depletion = minTime(session.GetPredictedEndTime(currentTime), session.EndTime)
+	}
+	projectedAt := func(t time.Time) int {
+		return used + int(rate*t.Sub(currentTime).Minutes())
+	}
+
+	top := max(used, projectedAt(session.EndTime), 1)
+	if !session.NoLimit {
+		top = max(limit, used, 1)
+	}
+	timeAt := func(x int) time.Time {
+		return session.StartTime.Add(time.Duration(float64(SessionDuration) * float64(x) / float64(dotsX-1)))
+	}
+	dotY := func(tokens int) int {
+		return clampInt(int(math.Round(float64(tokens)/float64(top)*float64(dotsY-1))), 0, dotsY-1)
+	}
+
+	if !session.NoLimit && limit >= used {
+		for x := 0; x < dotsX; x += 4 {
+			canvas.set(x, dotY(limit), forecastLimitLayer)
+		}
+	}
+	previous := -1
+	for x := range dotsX {
+		t := timeAt(x)
+		if t.After(currentTime) {
+			// Dotted: two dot columns on, two off
+			if t.After(depletion) {
+				break
+			}
+			if x%4 < 2 {
+				canvas.set(x, dotY(projectedAt(t)), forecastProjectionLayer)
+			}
+			continue
+		}
+		y := dotY(cumulativeTokensAt(session.Timeline, used, session.StartTime, currentTime, t))
+		if previous < 0 {
+			previous = y
+		}
+		for fill := min(previous, y); fill <= max(previous, y); fill++ {
+			canvas.set(x, fill, forecastUsageLayer)
+		}
+		previous = y
+	}
+
+	projection := okString
+	if depletion.Before(session.EndTime) {
+		projection = dangerString
+	}
+	rows := canvas.rows([]func(string) string{
+		func(s string) string { return mutedString("%s", s) },
+		func(s string) string { return projection("%s", s) },
+		func(s string) string { return infoString("%s", s) },
+	})
+	fmt.Fprintf(buffer, "Trend   %s %s\n", rows[0], mutedString("%s", formatNumber(top)))
+	for _, row := range rows[1:] {
+		fmt.Fprintf(buffer, "        %s\n", row)
+	}
+
+	start := session.StartTime.In(d.timezone).Format(TimeFormatShort)
+	end := session.EndTime.In(d.timezone).Format(TimeFormatShort)
+	fmt.Fprintf(buffer, "        %s\n", mutedString("%s%*s", start, width-len(start), end))
+
+	switch {
+	case exceeded:
+		fmt.Fprintf(buffer, "        %s\n", dangerString("%s", tr("limit reached, resets at %s", end)))
+	case session.NoLimit:
+		fmt.Fprintf(buffer, "        %s\n", mutedString("%s", tr("~%s tokens by the %s reset at this rate",
+			formatNumber(projectedAt(session.EndTime)), end)))
+	case depletion.Before(session.EndTime):
+		fmt.Fprintf(buffer, "        %s\n", dangerString("%s", tr("runs out at %s, %s before the %s reset",
+			depletion.In(d.timezone).Format(TimeFormatShort), formatTime(session.EndTime.Sub(depletion).Minutes()), end)))
+	default:
+		projected := projectedAt(session.EndTime)
+		fmt.Fprintf(buffer, "        %s\n", okString("%s", tr("~%s tokens (%.0f%%) by the %s reset at this rate",
+			formatNumber(projected), float64(projected)/float64(max(limit, 1))*100, end)))
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBrailleCanvas(t *testing.T) {
+	canvas := newBrailleCanvas(2, 2)
+	canvas.set(0, 0, 1) // Bottom left dot of the bottom left cell
+	canvas.set(1, 7, 1) // Top right dot of the top left cell
+	canvas.set(3, 4, 1) // Bottom right dot of the top right cell
+	canvas.set(4, 0, 1) // Outside, ignored
+
+	rows := canvas.rows([]func(string) string{func(s string) string { return s }})
+	expected := []string{"⠈⢀", "⡀ "}
+	for i := range expected {
+		if rows[i] != expected[i] {
+			t.Errorf("row %d = %q, expected %q", i, rows[i], expected[i])
+		}
+	}
+}
+
+func TestCumulativeTokensAt(t *testing.T) {
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	now := start.Add(2 * time.Hour)
+	timeline := []TimedTokens{
+		{Time: start.Add(10 * time.Minute), Tokens: 100},
+		{Time: start.Add(time.Hour), Tokens: 300},
+	}
+
+	tests := []struct {
+		name     string
+		timeline []TimedTokens
+		at       time.Duration
+		expected int
+	}{
+		{"before the first message", timeline, 5 * time.Minute, 0},
+		{"scaled to ccusage", timeline, 30 * time.Minute, 250},
+		{"all messages", timeline, 90 * time.Minute, 1000},
+		{"linear without logs", nil, time.Hour, 500},
+	}
+	for _, tt := range tests {
+		if got := cumulativeTokensAt(tt.timeline, 1000, start, now, start.Add(tt.at)); got != tt.expected {
+			t.Errorf("%s: cumulativeTokensAt() = %d, expected %d", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestRenderForecastChart(t *testing.T) {
+	oldDisplay, oldConfig := display, config
+	defer func() { display, config = oldDisplay, oldConfig }()
+	config = NewConfig()
+	display = NewDisplay("UTC")
+
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	now := start.Add(2 * time.Hour)
+	display.config = &DisplayConfig{CurrentTime: now, Timezone: time.UTC}
+
+	tests := []struct {
+		name     string
+		used     int
+		rate     float64
+		noLimit  bool
+		expected string
+	}{
+		{"runs out", 60000, 500, false, "runs out at 12:20, 1h 40m before the 14:00 reset"},
+		{"lasts", 20000, 100, false, "~38,000 tokens (38%) by the 14:00 reset at this rate"},
+		{"exceeded", 120000, 100, false, "limit reached, resets at 14:00"},
+		{"no limit", 20000, 100, true, "~38,000 tokens by the 14:00 reset at this rate"},
+	}
+	for _, tt := range tests {
+		session := newTestSession(start, tt.used, 100000)
+		session.BurnRate = tt.rate
+		session.NoLimit = tt.noLimit
+
+		var buffer strings.Builder
+		display.renderForecastChart(&buffer, session)
+		lines := strings.Split(strings.TrimSuffix(string(stripANSI([]byte(buffer.String()))), "\n"), "\n")
+		if len(lines) != ForecastChartRows+2 {
+			t.Fatalf("%s: rendered %d lines, expected %d", tt.name, len(lines), ForecastChartRows+2)
+		}
+		if !strings.HasPrefix(lines[0], "Trend") || !strings.Contains(lines[ForecastChartRows], "09:00") {
+			t.Errorf("%s: chart = %q, expected a Trend label and the session start", tt.name, lines)
+		}
+		if got := strings.TrimSpace(lines[len(lines)-1]); got != tt.expected {
+			t.Errorf("%s: summary = %q, expected %q", tt.name, got, tt.expected)
+		}
+	}
+}
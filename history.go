@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// HistoryRow summarizes a completed session block for the history table
+type HistoryRow struct {
+	Start        time.Time
+	End          time.Time
+	Tokens       int
+	Entries      int
+	TokensPerMsg int
+	Cost         float64
+	Exceeded     bool
+	Activity     *ActivityMetrics // Nil when the block's messages are no longer in the Claude logs
+}
+
+var historyRows int
+
+// runHistory prints completed session blocks annotated against the estimated limit
+func runHistory(cmd *cobra.Command, args []string) {
+	estimator.SetEstimationMethod(estimationMethod)
+
+	data := fetchUsageData()
+	if data == nil {
+		fmt.Println("Failed to get usage data")
+		return
+	}
+
+	limit := tokenLimitOf(config.Plan, data.Blocks)
+	rows := buildHistoryRows(data.Blocks, limit, historyRows)
+	addHistoryActivity(rows)
+	fmt.Print(display.RenderHistory(rows, limit))
+}
+
+// addHistoryActivity fills in each row's active time from the message timestamps in the JSONL logs
+func addHistoryActivity(rows []HistoryRow) {
+	if len(rows) == 0 {
+		return
+	}
+	// Rows are most recent first
+	timed, err := NewMessageTokenReader().GetBlockTimedTokens(
+		rows[len(rows)-1].Start.Format(time.RFC3339), rows[0].End.Format(time.RFC3339))
+	if err != nil {
+		return
+	}
+	times := make([]time.Time, 0, len(timed))
+	for _, message := range timed {
+		times = append(times, message.Time)
+	}
+	for i := range rows {
+		rows[i].Activity = historyActivity(times, rows[i].Start, rows[i].End)
+	}
+}
+
+// historyActivity returns the activity of a completed block, or nil if none of its messages were found
+func historyActivity(times []time.Time, start, end time.Time) *ActivityMetrics {
+	var blockTimes []time.Time
+	for _, t := range times {
+		if !t.Before(start) && !t.After(end) {
+			blockTimes = append(blockTimes, t)
+		}
+	}
+	if len(blockTimes) == 0 {
+		return nil
+	}
+	activity := calculateActivity(blockTimes, start, end, IdleThreshold)
+	return &activity
+}
+
+// buildHistoryRows converts completed blocks into history rows, most recent first.
+// A maxRows of 0 or less returns every completed block.
+func buildHistoryRows(blocks []Block, limit, maxRows int) []HistoryRow {
+	var rows []HistoryRow
+	for i := len(blocks) - 1; i >= 0; i-- {
+		if maxRows > 0 && len(rows) >= maxRows {
+			break
+		}
+
+		block := blocks[i]
+		if block.IsGap || block.IsActive {
+			continue
+		}
+
+		startTime, err := time.Parse(time.RFC3339, block.StartTime)
+		if err != nil {
+			continue
+		}
+		endTime, err := time.Parse(time.RFC3339, block.ActualEndTime)
+		if err != nil {
+			endTime = startTime.Add(SessionDuration)
+		}
+
+		row := HistoryRow{
+			Start:    startTime,
+			End:      endTime,
+			Tokens:   block.TotalTokens,
+			Entries:  block.Entries,
+			Cost:     block.CostUSD,
+			Exceeded: limit > 0 && block.TotalTokens > limit,
+		}
+		if block.Entries > 0 {
+			row.TokensPerMsg = block.TotalTokens / block.Entries
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestBuildHistoryRows(t *testing.T) {
+	blocks := []Block{
+		{StartTime: "2025-06-20T00:00:00Z", ActualEndTime: "2025-06-20T03:00:00Z", TotalTokens: 8000, Entries: 40, CostUSD: 2.5},
+		{StartTime: "2025-06-20T05:00:00Z", IsGap: true},
+		{StartTime: "2025-06-20T06:00:00Z", TotalTokens: 3000, Entries: 0},
+		{StartTime: "2025-06-20T11:00:00Z", TotalTokens: 1000, Entries: 10, IsActive: true},
+	}
+
+	rows := buildHistoryRows(blocks, 7000, 0)
+	if len(rows) != 2 {
+		t.Fatalf("len(rows) = %d, expected 2", len(rows))
+	}
+
+	// Most recent first, missing end time falls back to a full session
+	if got := rows[0].End.Sub(rows[0].Start); got != SessionDuration {
+		t.Errorf("rows[0] duration = %s, expected %s", got, SessionDuration)
+	}
+	if rows[0].TokensPerMsg != 0 || rows[0].Exceeded {
+		t.Errorf("rows[0] = %+v, expected no tokens/msg and not exceeded", rows[0])
+	}
+
+	if rows[1].TokensPerMsg != 200 {
+		t.Errorf("rows[1].TokensPerMsg = %d, expected 200", rows[1].TokensPerMsg)
+	}
+	if !rows[1].Exceeded {
+		t.Error("rows[1] should exceed the 7000 token limit")
+	}
+	if rows[1].Cost != 2.5 {
+		t.Errorf("rows[1].Cost = %.2f, expected 2.50", rows[1].Cost)
+	}
+
+	if rows := buildHistoryRows(blocks, 7000, 1); len(rows) != 1 {
+		t.Errorf("len(rows) with maxRows=1 = %d, expected 1", len(rows))
+	}
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Hook events
+const (
+	EventSessionStart  = "session_start"
+	EventSessionEnd    = "session_end"
+	EventModelSwitch   = "model_switch"
+	EventLimitExceeded = "limit_exceeded"
+	EventThreshold     = "threshold_" // Followed by a percentage, e.g. threshold_80
+)
+
+// HookPayload is the JSON written to a hook command's stdin
+type HookPayload struct {
+	Event  string        `json:"event"`
+	Status *StatusReport `json:"status,omitempty"`
+}
+
+// HookAction runs a user command for an event.
+// Event data is passed as CCTOP_* environment variables and as JSON on stdin.
+type HookAction struct {
+	Event   string
+	Command string
+}
+
+// Name describes the action
+func (a HookAction) Name() string {
+	return fmt.Sprintf("hook %s %q", a.Event, a.Command)
+}
+
+// Run executes the command through the shell, waiting at most HookTimeout
+func (a HookAction) Run(session *Session) error {
+	ctx, cancel := context.WithTimeout(context.Background(), HookTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", a.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", a.Command)
+	}
+	cmd.Env = append(os.Environ(), hookEnv(a.Event, session)...)
+
+	payload := HookPayload{Event: a.Event}
+	if session != nil {
+		report := NewStatusReport(session, session.Plan, time.Now())
+		payload.Status = &report
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	cmd.Stdin = bytes.NewReader(data)
+
+	return cmd.Run()
+}
+
+// backgroundAction runs an action on the background queue, so a slow command or
+// HTTP request does not hold up the refresh. Failures are logged instead of returned.
+type backgroundAction struct {
+	Action
+}
+
+// inBackground wraps an action to run on the background queue
+func inBackground(action Action) Action {
+	return backgroundAction{Action: action}
+}
+
+// Run queues the action and returns at once
+func (a backgroundAction) Run(session *Session) error {
+	backgroundActions.Queue(a.Action, session)
+	return nil
+}
+
+// actionQueue runs actions one at a time, in the order they were queued
+type actionQueue struct {
+	once    sync.Once
+	jobs    chan queuedAction
+	pending sync.WaitGroup
+}
+
+// queuedAction is an action waiting to run with the session it fired for
+type queuedAction struct {
+	action  Action
+	session *Session
+}
+
+// backgroundActions runs hooks and other actions off the refresh goroutine
+var backgroundActions = &actionQueue{}
+
+// Queue schedules an action, dropping it when HookQueueSize actions are already waiting
+func (q *actionQueue) Queue(action Action, session *Session) {
+	q.once.Do(func() {
+		q.jobs = make(chan queuedAction, HookQueueSize)
+		go q.run()
+	})
+	if session != nil {
+		// The TUI advances the session's countdowns while the action runs
+		snapshot := *session
+		session = &snapshot
+	}
+	q.pending.Add(1)
+	select {
+	case q.jobs <- queuedAction{action: action, session: session}:
+	default:
+		q.pending.Done()
+		logger.Warnf("%s: dropped, %d actions are already waiting", action.Name(), HookQueueSize)
+	}
+}
+
+// run executes queued actions until the process exits
+func (q *actionQueue) run() {
+	for job := range q.jobs {
+		if err := job.action.Run(job.session); err != nil {
+			logger.Errorf("%s: %v", job.action.Name(), err)
+		}
+		q.pending.Done()
+	}
+}
+
+// Wait blocks until the queued actions ran, or at most timeout
+func (q *actionQueue) Wait(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		q.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+// hookEnv returns the environment variables describing an event
+func hookEnv(event string, session *Session) []string {
+	env := []string{"CCTOP_EVENT=" + event}
+	if session == nil {
+		return env
+	}
+	tokens := session.Metrics.Tokens
+	return append(env,
+		fmt.Sprintf("CCTOP_TOKENS_USED=%d", tokens.Used),
+		fmt.Sprintf("CCTOP_TOKEN_LIMIT=%d", tokens.Limit),
+		fmt.Sprintf("CCTOP_PERCENTAGE=%.1f", tokens.Percentage),
+		"CCTOP_MODEL="+session.PrimaryModel,
+		"CCTOP_SESSION_START="+session.StartTime.Format(time.RFC3339),
+		"CCTOP_SESSION_END="+session.EndTime.Format(time.RFC3339),
+	)
+}
+
+// parseHooks parses --hook values of the form "event=command"
+func parseHooks(specs []string) ([]HookAction, error) {
+	hooks := make([]HookAction, 0, len(specs))
+	for _, spec := range specs {
+		event, command, found := strings.Cut(spec, "=")
+		if !found || command == "" {
+			return nil, fmt.Errorf("invalid hook %q, expected event=command", spec)
+		}
+		if _, ok := hookThreshold(event); !ok && !isLifecycleEvent(event) {
+			return nil, fmt.Errorf("unknown hook event %q", event)
+		}
+		hooks = append(hooks, HookAction{Event: event, Command: command})
+	}
+	return hooks, nil
+}
+
+// hookThreshold returns the token usage percentage a threshold event fires at
+func hookThreshold(event string) (float64, bool) {
+	if event == EventLimitExceeded {
+		return 100, true
+	}
+	if !strings.HasPrefix(event, EventThreshold) {
+		return 0, false
+	}
+	threshold, err := strconv.ParseFloat(strings.TrimPrefix(event, EventThreshold), 64)
+	if err != nil || threshold <= 0 {
+		return 0, false
+	}
+	return threshold, true
+}
+
+// isLifecycleEvent reports whether the event is detected by Hooks rather than the rules engine
+func isLifecycleEvent(event string) bool {
+	return event == EventSessionStart || event == EventSessionEnd || event == EventModelSwitch
+}
+
+// Hooks fires lifecycle hooks by comparing each refreshed session with the previous one.
+// Threshold hooks are run by the rules engine (see buildRules).
+type Hooks struct {
+	actions     map[string][]Action
+	lastSession *Session
+	observed    bool // A refresh was checked, so lastSession is known
+}
+
+// NewHooks creates a lifecycle hook runner for the lifecycle events among the hook commands
+func NewHooks(hookActions []HookAction) *Hooks {
+	h := &Hooks{actions: make(map[string][]Action)}
+	for _, action := range hookActions {
+		if isLifecycleEvent(action.Event) {
+			h.Add(action.Event, inBackground(action))
+		}
+	}
+	return h
+}
+
+// Add registers an action for a lifecycle event
+func (h *Hooks) Add(event string, action Action) {
+	h.actions[event] = append(h.actions[event], action)
+}
+
+// Check fires hooks for lifecycle changes. A nil session means no session is active.
+// A session seen at the first check does not fire session_start, as it may have started
+// earlier, but one starting after an idle stretch does.
+func (h *Hooks) Check(session *Session) []error {
+	last, observed := h.lastSession, h.observed
+	h.lastSession, h.observed = session, true
+	if !observed {
+		return nil
+	}
+
+	var errs []error
+	switch {
+	case last == nil && session == nil: // Still idle
+	case last == nil:
+		errs = append(errs, h.fire(EventSessionStart, session)...)
+	case session == nil:
+		errs = append(errs, h.fire(EventSessionEnd, last)...)
+	case !session.StartTime.Equal(last.StartTime):
+		errs = append(errs, h.fire(EventSessionEnd, last)...)
+		errs = append(errs, h.fire(EventSessionStart, session)...)
+	case session.PrimaryModel != last.PrimaryModel && last.PrimaryModel != "":
+		errs = append(errs, h.fire(EventModelSwitch, session)...)
+	}
+	return errs
+}
+
+// fire runs every action registered for the event
+func (h *Hooks) fire(event string, session *Session) []error {
+	var errs []error
+	for _, action := range h.actions[event] {
+		if err := action.Run(session); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", action.Name(), err))
+		}
+	}
+	return errs
+}
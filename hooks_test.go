@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseHooks(t *testing.T) {
+	tests := []struct {
+		name      string
+		specs     []string
+		expectErr bool
+	}{
+		{name: "Lifecycle events", specs: []string{"session_start=echo hi", "model_switch=echo =x"}},
+		{name: "Threshold events", specs: []string{"threshold_80=pause-ci", "limit_exceeded=notify"}},
+		{name: "Missing command", specs: []string{"session_end"}, expectErr: true},
+		{name: "Unknown event", specs: []string{"lunch=eat"}, expectErr: true},
+		{name: "Invalid threshold", specs: []string{"threshold_x=eat"}, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseHooks(tt.specs)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("parseHooks(%v) error = %v, expected error %v", tt.specs, err, tt.expectErr)
+			}
+		})
+	}
+
+	hooks, _ := parseHooks([]string{"model_switch=echo =x"})
+	if hooks[0].Command != "echo =x" {
+		t.Errorf("Command = %q, expected %q", hooks[0].Command, "echo =x")
+	}
+}
+
+func TestHookThreshold(t *testing.T) {
+	tests := []struct {
+		event    string
+		expected float64
+		ok       bool
+	}{
+		{event: "threshold_80", expected: 80, ok: true},
+		{event: "threshold_95.5", expected: 95.5, ok: true},
+		{event: "limit_exceeded", expected: 100, ok: true},
+		{event: "threshold_0", ok: false},
+		{event: "session_start", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.event, func(t *testing.T) {
+			result, ok := hookThreshold(tt.event)
+			if result != tt.expected || ok != tt.ok {
+				t.Errorf("hookThreshold(%s) = %v, %v, expected %v, %v", tt.event, result, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestHooksLifecycle(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands are run with sh")
+	}
+	oldConfig, oldEstimator := config, estimator
+	defer func() { config, estimator = oldConfig, oldEstimator }()
+	config = NewConfig()
+	estimator = NewTokenLimitEstimator()
+
+	out := filepath.Join(t.TempDir(), "events")
+	record := `echo "$CCTOP_EVENT $CCTOP_TOKENS_USED $(head -c 40)" >> ` + out
+	h := NewHooks([]HookAction{
+		{Event: EventSessionStart, Command: record},
+		{Event: EventSessionEnd, Command: record},
+		{Event: EventModelSwitch, Command: record},
+	})
+
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	first := newTestSession(start, 1000, 10000)
+	first.PrimaryModel = "sonnet"
+	switched := newTestSession(start, 2000, 10000)
+	switched.PrimaryModel = "opus"
+	next := newTestSession(start.Add(SessionDuration), 100, 10000)
+	afterIdle := newTestSession(start.Add(3*SessionDuration), 50, 10000)
+
+	for _, session := range []*Session{first, first, switched, next, nil, nil, afterIdle} {
+		if errs := h.Check(session); len(errs) != 0 {
+			t.Fatalf("Check() errors = %v", errs)
+		}
+	}
+	backgroundActions.Wait(HookTimeout)
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	expected := []string{"model_switch 2000", "session_end 2000", "session_start 100", "session_end 100", "session_start 50"}
+	if len(lines) != len(expected) {
+		t.Fatalf("events = %q, expected %q", lines, expected)
+	}
+	for i, prefix := range expected {
+		if !strings.HasPrefix(lines[i], prefix+` {"event":"`) {
+			t.Errorf("event %d = %q, expected prefix %q with JSON payload", i, lines[i], prefix)
+		}
+	}
+}
+
+func TestHookPayload(t *testing.T) {
+	data, err := json.Marshal(HookPayload{Event: EventSessionEnd})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"event":"session_end"}` {
+		t.Errorf("payload = %s, expected only the event without a session", data)
+	}
+}
+
+// blockingAction waits for release before returning
+type blockingAction struct {
+	release chan struct{}
+	runs    *int
+}
+
+func (a blockingAction) Name() string { return "blocking" }
+
+func (a blockingAction) Run(session *Session) error {
+	<-a.release
+	*a.runs++
+	return nil
+}
+
+func TestBackgroundAction(t *testing.T) {
+	var runs int
+	release := make(chan struct{})
+	action := inBackground(blockingAction{release: release, runs: &runs})
+
+	done := make(chan struct{})
+	go func() {
+		_ = action.Run(nil)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run() waited for the action, expected it to be queued")
+	}
+
+	close(release)
+	backgroundActions.Wait(time.Second)
+	if runs != 1 {
+		t.Errorf("runs = %d after waiting for the queue, expected 1", runs)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import "fmt"
+
+// Display languages
+const (
+	LangAuto     = "auto" // From LC_ALL, LC_MESSAGES or LANG
+	LangEnglish  = "en"
+	LangJapanese = "ja"
+)
+
+// catalogs translate display strings per language. Keys are the English format
+// strings; translations keep the same verbs in the same order. Strings missing
+// from a catalog are shown in English. JSON and --oneline output stay English
+// since scripts parse them.
+var catalogs = map[string]map[string]string{
+	LangJapanese: {
+		// Status words
+		"OK":             "正常",
+		"WARNING":        "警告",
+		"CRITICAL":       "危険",
+		"LIMIT EXCEEDED": "上限超過",
+
+		// Status bar
+		"Status: %s":                  "状態: %s",
+		"Tokens: %s/%s (%s)":          "トークン: %s/%s (%s)",
+		"Tokens: %s (no limit)":       "トークン: %s (上限なし)",
+		"Estimate: %s":                "枯渇予測: %s",
+		"Reset: %s":                   "リセット: %s",
+		"manual limit":                "手動設定の上限",
+		"tokens %s exceeded limit %s": "トークン %s が上限 %s を超過",
+		"tokens %s passed warning threshold %.0f%%":        "トークン %s が警告しきい値 %.0f%% を超過",
+		"tokens %s passed critical threshold %.0f%%":       "トークン %s が危険しきい値 %.0f%% を超過",
+		"bursts of %.0f/min exceeded sustainable %.0f/min": "瞬間的な消費 %.0f/分 が持続可能な %.0f/分 を超過",
+		"burn rate %.0f/min exceeded sustainable %.0f/min": "消費ペース %.0f/分 が持続可能な %.0f/分 を超過",
+		"burn rate %.0f/min within sustainable %.0f/min":   "消費ペース %.0f/分 は持続可能な %.0f/分 以内",
+		"Opus: switched to Sonnet at %.0f%%":               "Opus: %.0f%% で Sonnet に切り替え済み",
+		"Opus remaining: %s tokens":                        "Opus 残り: %s トークン",
+		"Opus remaining: ~%s":                              "Opus 残り: 約 %s",
+		"Spend: %s/%s per %s (%s)":                         "支出: %s/%s (%s ごと) (%s)",
+		"spent %s exceeded budget %s per %s":               "支出 %s が予算 %s (%s ごと) を超過",
+		"spent %s of budget %s per %s":                     "支出 %s / 予算 %s (%s ごと)",
+		"%s/h runs out of the %s budget at %s":             "%s/時 のペースで予算 %s は %s に尽きる見込み",
+
+		// Forecast
+		"limit reached, resets at %s":                      "上限に到達、%s にリセット",
+		"runs out at %s, %s before the %s reset":           "%s に枯渇 (%s 早く、リセットは %s)",
+		"~%s tokens by the %s reset at this rate":          "約 %s トークン (このペースで %s のリセットまで)",
+		"~%s tokens (%.0f%%) by the %s reset at this rate": "約 %s トークン (%.0f%%) (このペースで %s のリセットまで)",
+
+		// Warnings
+		"Warning: ccusage reported %d active blocks, showing the latest (also active: %s); run `cctop doctor`": "警告: ccusage が %d 個のアクティブなブロックを報告しました。最新のものを表示しています (他: %s)。`cctop doctor` を実行してください",
+		"Note: Auto-switched to auto plan (%s tokens)":                                                         "注意: auto プランに自動で切り替えました (%s トークン)",
+		"Warning: weekly usage at %.0f%%, resets %s":                                                           "警告: 週間使用量が %.0f%% です (リセット %s)",
+		"! soft limit %s exceeded by %s":                                                                       "! ソフトリミット %s を %s 超過",
+		"! soft limit %s: %.0f%% used, %s left":                                                                "! ソフトリミット %s: %.0f%% 使用、残り %s",
+		"Error: %s":                                                                                            "エラー: %s",
+		"PAUSED":                                                                                               "一時停止中",
+
+		// Notifications
+		"Token limit exceeded (%s/%s)":                                  "トークン上限を超過しました (%s/%s)",
+		"Token usage passed %.0f%% (%s/%s)":                             "トークン使用量が %.0f%% を超えました (%s/%s)",
+		"Token usage passed the warning threshold (%s/%s)":              "トークン使用量が警告しきい値を超えました (%s/%s)",
+		"Token usage passed the critical threshold (%s/%s)":             "トークン使用量が危険しきい値を超えました (%s/%s)",
+		"Spend passed the warning threshold (%s/%s per %s)":             "支出が警告しきい値を超えました (%s/%s、%s ごと)",
+		"Spend passed the critical threshold (%s/%s per %s)":            "支出が危険しきい値を超えました (%s/%s、%s ごと)",
+		"Soft limit reached (%s/%s)":                                    "ソフトリミットに達しました (%s/%s)",
+		"Soft limit usage passed %.0f%% (%s/%s)":                        "ソフトリミットの使用量が %.0f%% を超えました (%s/%s)",
+		"Weekly limit exceeded (%s/%s), resets %s":                      "週間上限を超過しました (%s/%s)、リセット %s",
+		"Weekly usage passed %.0f%% (%s/%s), resets %s":                 "週間使用量が %.0f%% を超えました (%s/%s)、リセット %s",
+		"%s exceeded their %.0f%% share of the session limit (%s/%s)":   "%s がセッション上限の割り当て %.0f%% を超えました (%s/%s)",
+		"Tokens predicted to run out at %s, before session reset at %s": "トークンは %s に尽きる見込みです (セッションのリセットは %s)",
+		"Budget exceeded (%s/%s per %s)":                                "予算を超過しました (%s/%s、%s ごと)",
+		"Spend passed %.0f%% of the budget (%s/%s per %s)":              "支出が予算の %.0f%% を超えました (%s/%s、%s ごと)",
+	},
+}
+
+// lang is the display language, set from --lang at startup
+var lang = LangEnglish
+
+// resolveLanguage returns the display language for a --lang value
+func resolveLanguage(value string) (string, error) {
+	switch value {
+	case LangEnglish, LangJapanese:
+		return value, nil
+	case LangAuto, "":
+		if _, ok := catalogs[localeLanguage("LC_MESSAGES")]; ok {
+			return localeLanguage("LC_MESSAGES"), nil
+		}
+		return LangEnglish, nil
+	default:
+		return "", fmt.Errorf("unknown language %q (%s, %s, %s)", value, LangAuto, LangEnglish, LangJapanese)
+	}
+}
+
+// translate returns the display language version of an English format string
+func translate(format string) string {
+	if translated, ok := catalogs[lang][format]; ok {
+		return translated
+	}
+	return format
+}
+
+// tr formats an English format string in the display language
+func tr(format string, a ...interface{}) string {
+	return fmt.Sprintf(translate(format), a...)
+}
+
+// Message is a display string kept in English with its arguments, so it can be
+// stored once and shown later in whichever display language is active
+type Message struct {
+	Format string `json:"format"`
+	Args   []any  `json:"args,omitempty"`
+}
+
+// newMessage creates a message from an English format string
+func newMessage(format string, a ...any) Message {
+	return Message{Format: format, Args: a}
+}
+
+// English returns the message in English, for logs and scripts
+func (m Message) English() string {
+	return fmt.Sprintf(m.Format, m.Args...)
+}
+
+// String returns the message in the display language
+func (m Message) String() string {
+	return tr(m.Format, m.Args...)
+}
+
+// statusLabel returns a status word (OK, WARNING, CRITICAL, LIMIT EXCEEDED) in the display language
+func statusLabel(status string) string {
+	return translate(status)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCatalogsKeepFormatVerbs(t *testing.T) {
+	for language, catalog := range catalogs {
+		for english, translated := range catalog {
+			if !slices.Equal(formatVerbs(english), formatVerbs(translated)) {
+				t.Errorf("%s translation of %q has verbs %v, expected %v", language, english, formatVerbs(translated), formatVerbs(english))
+			}
+		}
+	}
+}
+
+func TestResolveLanguage(t *testing.T) {
+	tests := []struct {
+		value    string
+		env      string
+		expected string
+		wantErr  bool
+	}{
+		{"ja", "en_US.UTF-8", "ja", false},
+		{"en", "ja_JP.UTF-8", "en", false},
+		{"auto", "ja_JP.UTF-8", "ja", false},
+		{"auto", "de_DE.UTF-8", "en", false},
+		{"auto", "", "en", false},
+		{"fr", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("LC_ALL", "")
+		t.Setenv("LC_MESSAGES", "")
+		t.Setenv("LANG", tt.env)
+		result, err := resolveLanguage(tt.value)
+		if (err != nil) != tt.wantErr || result != tt.expected {
+			t.Errorf("resolveLanguage(%q) with LANG=%q = %q, %v, expected %q", tt.value, tt.env, result, err, tt.expected)
+		}
+	}
+}
+
+func TestTr(t *testing.T) {
+	oldLang := lang
+	defer func() { lang = oldLang }()
+
+	lang = LangJapanese
+	if result := tr("Token usage passed %.0f%% (%s/%s)", 80.0, "5,600", "7,000"); result != "トークン使用量が 80% を超えました (5,600/7,000)" {
+		t.Errorf("tr() in ja = %q", result)
+	}
+	if result := statusLabel("LIMIT EXCEEDED"); result != "上限超過" {
+		t.Errorf("statusLabel() in ja = %q, expected 上限超過", result)
+	}
+	if result := tr("Untranslated %d", 3); result != "Untranslated 3" {
+		t.Errorf("tr() without a translation = %q, expected the English text", result)
+	}
+
+	lang = LangEnglish
+	if result := statusLabel("WARNING"); result != "WARNING" {
+		t.Errorf("statusLabel() in en = %q, expected WARNING", result)
+	}
+}
+
+func TestVisibleWidthCountsWideCharacters(t *testing.T) {
+	if width := visibleWidth("状態: 正常"); width != 10 {
+		t.Errorf("visibleWidth() = %d, expected 10", width)
+	}
+	if result := truncateANSI("状態: 上限超過", 8); visibleWidth(result) > 8 || !strings.HasSuffix(result, "…") {
+		t.Errorf("truncateANSI() = %q (%d columns), expected at most 8 columns ending in …", result, visibleWidth(result))
+	}
+}
+
+// formatVerbs returns the fmt verbs of a format string in order, e.g. [%s %.0f]
+func formatVerbs(format string) []string {
+	var verbs []string
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		end := i + 1
+		for end < len(format) && strings.IndexByte("+-# 0123456789.[]", format[end]) >= 0 {
+			end++
+		}
+		if end < len(format) {
+			if format[end] != '%' {
+				verbs = append(verbs, format[i:end+1])
+			}
+			i = end
+		}
+	}
+	return verbs
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// IdleSummary describes the state between sessions
+type IdleSummary struct {
+	LastBlock *Block    // Most recent completed block, nil without history
+	LastStart time.Time // Start of LastBlock
+	LastEnd   time.Time // Last activity in LastBlock
+	TodayCost float64   // Raw USD cost today
+}
+
+// NoActiveSessionError is returned by loadSession when no block is active.
+// It carries what the idle screen shows until activity resumes.
+type NoActiveSessionError struct {
+	Idle IdleSummary
+}
+
+func (e *NoActiveSessionError) Error() string {
+	return "No active session found"
+}
+
+// newIdleSummary finds the most recent completed block
+func newIdleSummary(blocks []Block, days []DailyUsage, currentTime time.Time) IdleSummary {
+	summary := IdleSummary{TodayCost: todayCost(days, currentTime)}
+	for i := len(blocks) - 1; i >= 0; i-- {
+		if blocks[i].IsGap || blocks[i].TotalTokens == 0 {
+			continue
+		}
+		summary.LastBlock = &blocks[i]
+		summary.LastStart, _ = time.Parse(time.RFC3339, blocks[i].StartTime)
+		summary.LastEnd, _ = time.Parse(time.RFC3339, blocks[i].ActualEndTime)
+		if summary.LastEnd.IsZero() {
+			summary.LastEnd = summary.LastStart.Add(SessionDuration)
+		}
+		break
+	}
+	return summary
+}
+
+// RenderIdle renders the screen shown while no session is active
+func (d *Display) RenderIdle(idle IdleSummary, currentTime time.Time) string {
+	var buffer strings.Builder
+	fmt.Fprintf(&buffer, "cctop - %s  %s  today: %s\n\n",
+		currentTime.In(d.timezone).Format(TimeFormat),
+		mutedString("idle"),
+		formatCost(idle.TodayCost))
+	buffer.WriteString("No active session. A new 5-hour block starts with your next message.\n")
+
+	if idle.LastBlock == nil {
+		return buffer.String()
+	}
+
+	block := idle.LastBlock
+	fmt.Fprintf(&buffer, "\nLast session  %s - %s  %s\n",
+		idle.LastStart.In(d.timezone).Format(TimeFormatShort),
+		idle.LastEnd.In(d.timezone).Format(TimeFormatShort),
+		mutedString("(ended %s ago)", formatTime(currentTime.Sub(idle.LastEnd).Minutes())))
+
+	models := make([]string, 0, len(block.Models))
+	for _, model := range block.Models {
+		models = append(models, formatModelName(model))
+	}
+	fmt.Fprintf(&buffer, "  tokens %s   cost %s   models %s\n",
+		formatNumber(block.TotalTokens),
+		formatCost(block.CostUSD),
+		strings.Join(models, ", "))
+	return buffer.String()
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewIdleSummary(t *testing.T) {
+	now := time.Date(2025, 6, 20, 15, 0, 0, 0, time.UTC)
+	blocks := []Block{
+		{StartTime: "2025-06-20T04:00:00Z", ActualEndTime: "2025-06-20T06:00:00Z", TotalTokens: 1000},
+		{StartTime: "2025-06-20T09:00:00Z", ActualEndTime: "2025-06-20T11:42:00Z", TotalTokens: 86800, CostUSD: 4.12},
+		{StartTime: "2025-06-20T11:42:00Z", IsGap: true},
+	}
+	days := []DailyUsage{{Date: "2025-06-20", TotalCost: 6.5}}
+
+	idle := newIdleSummary(blocks, days, now)
+	if idle.LastBlock == nil || idle.LastBlock.TotalTokens != 86800 {
+		t.Fatalf("LastBlock = %+v, expected the 09:00 block", idle.LastBlock)
+	}
+	if !idle.LastEnd.Equal(time.Date(2025, 6, 20, 11, 42, 0, 0, time.UTC)) || idle.TodayCost != 6.5 {
+		t.Errorf("idle = %+v, expected end 11:42 and today's cost 6.5", idle)
+	}
+
+	if empty := newIdleSummary(nil, nil, now); empty.LastBlock != nil {
+		t.Errorf("newIdleSummary(nil).LastBlock = %+v, expected nil", empty.LastBlock)
+	}
+}
+
+func TestRenderIdle(t *testing.T) {
+	now := time.Date(2025, 6, 20, 14, 0, 0, 0, time.UTC)
+	block := Block{TotalTokens: 86800, CostUSD: 4.12, Models: []string{"claude-opus-4-20250514"}}
+	idle := IdleSummary{
+		LastBlock: &block,
+		LastStart: time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC),
+		LastEnd:   time.Date(2025, 6, 20, 11, 42, 0, 0, time.UTC),
+	}
+
+	output := NewDisplay("UTC").RenderIdle(idle, now)
+	for _, expected := range []string{"No active session", "09:00 - 11:42", "ended 2h 18m ago", "86,800"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("RenderIdle() missing %q:\n%s", expected, output)
+		}
+	}
+}
+
+func TestModelClearsSessionWhenIdle(t *testing.T) {
+	m := NewModel("auto", 7000)
+	m.session = newTestSession(time.Now().Add(-time.Hour), 1000, 7000)
+
+	updated, _ := m.Update(usageMsg{plan: "auto", err: &NoActiveSessionError{}})
+	m = updated.(Model)
+	if m.session != nil {
+		t.Error("session kept after it ended, expected the idle screen")
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// BlockInspection is a raw ccusage block with fields cctop derives from it
+type BlockInspection struct {
+	Index   int             `json:"index"`
+	Block   json.RawMessage `json:"block"` // Exactly as returned by ccusage
+	Derived DerivedBlock    `json:"derived"`
+}
+
+// DerivedBlock holds values computed from a block and the Claude logs
+type DerivedBlock struct {
+	StartTime        time.Time      `json:"startTime"`
+	EndTime          time.Time      `json:"endTime"`   // Last activity, or now for the active block
+	WindowEnd        time.Time      `json:"windowEnd"` // When the 5-hour window resets
+	DurationMinutes  float64        `json:"durationMinutes"`
+	TokensPerMessage int            `json:"tokensPerMessage"`
+	PrimaryModel     string         `json:"primaryModel"`
+	LogMessages      int            `json:"logMessages"` // Assistant messages found in the JSONL logs
+	LogTokens        int            `json:"logTokens"`   // Their tokens, including all cache tokens
+	Hourly           []HourlyTokens `json:"hourly"`
+	Files            []FileTokens   `json:"files"`
+}
+
+// HourlyTokens is the usage within one hour of a block
+type HourlyTokens struct {
+	Hour     time.Time `json:"hour"`
+	Messages int       `json:"messages"`
+	Tokens   int       `json:"tokens"`
+}
+
+// FileTokens is a JSONL file's contribution to a block
+type FileTokens struct {
+	Path     string `json:"path"`
+	Messages int    `json:"messages"`
+	Tokens   int    `json:"tokens"`
+}
+
+// runInspectBlock prints a block selected by index, id or "active" as indented JSON
+func runInspectBlock(cmd *cobra.Command, args []string) {
+	output, err := runCCUsage("blocks", "--json")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to get usage data:", err)
+		os.Exit(1)
+	}
+	var raw struct {
+		Blocks []json.RawMessage `json:"blocks"`
+	}
+	var data CCUsageData
+	if err := json.Unmarshal(output, &raw); err != nil {
+		fmt.Fprintln(os.Stderr, "Error parsing JSON:", err)
+		os.Exit(1)
+	}
+	if err := json.Unmarshal(output, &data); err != nil {
+		fmt.Fprintln(os.Stderr, "Error parsing JSON:", err)
+		os.Exit(1)
+	}
+
+	index, err := findBlock(data.Blocks, args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	now := time.Now()
+	block := data.Blocks[index]
+	endTime := burnCalc.getBlockEndTime(block, now)
+	timed, _ := NewMessageTokenReader().GetBlockTimedTokens(block.StartTime, endTime.Format(time.RFC3339))
+
+	inspection := BlockInspection{
+		Index:   index,
+		Block:   raw.Blocks[index],
+		Derived: deriveBlock(block, timed, now),
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(inspection)
+}
+
+// findBlock resolves a block reference: an index into ccusage's block list (negative
+// counts from the end), a block id or start time, or "active"
+func findBlock(blocks []Block, ref string) (int, error) {
+	if index, err := strconv.Atoi(ref); err == nil {
+		if index < 0 {
+			index += len(blocks)
+		}
+		if index < 0 || index >= len(blocks) {
+			return 0, fmt.Errorf("block index %s out of range, %d blocks", ref, len(blocks))
+		}
+		return index, nil
+	}
+	for i, block := range blocks {
+		if (ref == "active" && block.IsActive) || block.ID == ref || block.StartTime == ref {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("no block %q", ref)
+}
+
+// deriveBlock computes timing, per-hour distribution and contributing files for a block
+func deriveBlock(block Block, timed []TimedTokens, currentTime time.Time) DerivedBlock {
+	startTime, _ := time.Parse(time.RFC3339, block.StartTime)
+	endTime := burnCalc.getBlockEndTime(block, currentTime)
+	derived := DerivedBlock{
+		StartTime:       startTime,
+		EndTime:         endTime,
+		WindowEnd:       startTime.Add(SessionDuration),
+		DurationMinutes: endTime.Sub(startTime).Minutes(),
+		PrimaryModel:    determinePrimaryModel(block.Models),
+		Hourly:          []HourlyTokens{},
+		Files:           []FileTokens{},
+	}
+	if block.Entries > 0 {
+		derived.TokensPerMessage = block.TotalTokens / block.Entries
+	}
+
+	hours := make(map[time.Time]*HourlyTokens)
+	files := make(map[string]*FileTokens)
+	for _, message := range timed {
+		derived.LogMessages++
+		derived.LogTokens += message.Tokens
+
+		hour := startTime.Add(message.Time.Sub(startTime).Truncate(time.Hour))
+		if hours[hour] == nil {
+			hours[hour] = &HourlyTokens{Hour: hour}
+		}
+		hours[hour].Messages++
+		hours[hour].Tokens += message.Tokens
+
+		if files[message.File] == nil {
+			files[message.File] = &FileTokens{Path: message.File}
+		}
+		files[message.File].Messages++
+		files[message.File].Tokens += message.Tokens
+	}
+
+	for _, hour := range hours {
+		derived.Hourly = append(derived.Hourly, *hour)
+	}
+	sort.Slice(derived.Hourly, func(i, j int) bool { return derived.Hourly[i].Hour.Before(derived.Hourly[j].Hour) })
+	for _, file := range files {
+		derived.Files = append(derived.Files, *file)
+	}
+	sort.Slice(derived.Files, func(i, j int) bool { return derived.Files[i].Tokens > derived.Files[j].Tokens })
+	return derived
+}
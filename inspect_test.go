@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFindBlock(t *testing.T) {
+	blocks := []Block{
+		{ID: "2025-06-20T04:00:00.000Z", StartTime: "2025-06-20T04:00:00Z"},
+		{IsGap: true},
+		{ID: "2025-06-20T10:00:00.000Z", StartTime: "2025-06-20T10:00:00Z", IsActive: true},
+	}
+
+	tests := []struct {
+		ref      string
+		expected int
+		wantErr  bool
+	}{
+		{"0", 0, false},
+		{"-1", 2, false},
+		{"3", 0, true},
+		{"-4", 0, true},
+		{"active", 2, false},
+		{"2025-06-20T04:00:00.000Z", 0, false},
+		{"2025-06-20T10:00:00Z", 2, false},
+		{"yesterday", 0, true},
+	}
+
+	for _, tt := range tests {
+		index, err := findBlock(blocks, tt.ref)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("findBlock(%q) error = %v, expected error %v", tt.ref, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && index != tt.expected {
+			t.Errorf("findBlock(%q) = %d, expected %d", tt.ref, index, tt.expected)
+		}
+	}
+}
+
+func TestDeriveBlock(t *testing.T) {
+	oldBurnCalc := burnCalc
+	burnCalc = NewBurnRateCalculator()
+	defer func() { burnCalc = oldBurnCalc }()
+
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	block := Block{
+		StartTime:     start.Format(time.RFC3339),
+		ActualEndTime: start.Add(150 * time.Minute).Format(time.RFC3339),
+		TotalTokens:   9000,
+		Entries:       3,
+		Models:        []string{"claude-opus-4-20250514"},
+	}
+	timed := []TimedTokens{
+		{Time: start.Add(10 * time.Minute), Tokens: 1000, File: "a.jsonl"},
+		{Time: start.Add(20 * time.Minute), Tokens: 2000, File: "b.jsonl"},
+		{Time: start.Add(140 * time.Minute), Tokens: 6000, File: "b.jsonl"},
+	}
+
+	derived := deriveBlock(block, timed, start.Add(4*time.Hour))
+	if derived.DurationMinutes != 150 || !derived.WindowEnd.Equal(start.Add(5*time.Hour)) {
+		t.Errorf("duration = %.0f window end = %v, expected 150 and 14:00", derived.DurationMinutes, derived.WindowEnd)
+	}
+	if derived.TokensPerMessage != 3000 || derived.LogMessages != 3 || derived.LogTokens != 9000 {
+		t.Errorf("derived = %+v, expected 3000 tokens/msg and 3 messages with 9000 tokens in the logs", derived)
+	}
+	if len(derived.Hourly) != 2 || derived.Hourly[0].Tokens != 3000 || !derived.Hourly[1].Hour.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("Hourly = %+v, expected 3000 tokens at 09:00 and 6000 at 11:00", derived.Hourly)
+	}
+	if len(derived.Files) != 2 || derived.Files[0].Path != "b.jsonl" || derived.Files[0].Messages != 2 {
+		t.Errorf("Files = %+v, expected b.jsonl first with 2 messages", derived.Files)
+	}
+}
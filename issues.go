@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// IssueConfig tags sessions with an issue (JIRA, Linear, ...) and where to post comments
+type IssueConfig struct {
+	ID         string // Issue key the current sessions are tagged with, e.g. ABC-123
+	WebhookURL string // Receives a comment payload when a tagged session ends
+}
+
+// IssueComment is the JSON payload posted to the issue webhook
+type IssueComment struct {
+	Issue     string    `json:"issue"`
+	Text      string    `json:"text"` // Ready-to-post comment body
+	Tokens    int       `json:"tokens"`
+	CostUSD   float64   `json:"costUsd"`
+	Model     string    `json:"model"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+}
+
+// IssueCommentAction posts the session's usage as a comment on the tagged issue
+type IssueCommentAction struct {
+	Issue      string
+	WebhookURL string
+	client     *http.Client
+}
+
+// NewIssueCommentAction creates an action posting to the configured webhook
+func NewIssueCommentAction(cfg IssueConfig) IssueCommentAction {
+	return IssueCommentAction{
+		Issue:      cfg.ID,
+		WebhookURL: cfg.WebhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name describes the action
+func (a IssueCommentAction) Name() string {
+	return fmt.Sprintf("comment on %s", a.Issue)
+}
+
+// Run posts the comment for the ended session
+func (a IssueCommentAction) Run(session *Session) error {
+	req, err := newJSONRequest(http.MethodPost, a.WebhookURL, newIssueComment(a.Issue, session))
+	if err != nil {
+		return err
+	}
+	return doJSONRequest(a.client, req, nil)
+}
+
+// newIssueComment summarizes a session's usage for an issue comment
+func newIssueComment(issue string, session *Session) IssueComment {
+	tokens := session.Metrics.Tokens.Used
+	cost := 0.0
+	if session.Block != nil {
+		cost = session.Block.CostUSD
+	}
+	return IssueComment{
+		Issue: issue,
+		Text: fmt.Sprintf("Claude session %s-%s used %s tokens (%s)",
+			session.StartTime.In(display.timezone).Format("2006-01-02 15:04"),
+			session.EndTime.In(display.timezone).Format("15:04"),
+			formatNumber(tokens),
+			formatCost(cost)),
+		Tokens:    tokens,
+		CostUSD:   cost,
+		Model:     session.PrimaryModel,
+		StartTime: session.StartTime,
+		EndTime:   session.EndTime,
+	}
+}
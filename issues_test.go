@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIssueCommentAction(t *testing.T) {
+	oldDisplay := display
+	defer func() { display = oldDisplay }()
+	display = NewDisplay("Asia/Tokyo")
+
+	var comment IssueComment
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&comment)
+	}))
+	defer server.Close()
+
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	session := newTestSession(start, 42000, 140000)
+	session.Block.CostUSD = 12.5
+	session.PrimaryModel = "claude-opus-4-20250514"
+
+	action := NewIssueCommentAction(IssueConfig{ID: "ABC-123", WebhookURL: server.URL})
+	if err := action.Run(session); err != nil {
+		t.Fatal(err)
+	}
+
+	if comment.Issue != "ABC-123" || comment.Tokens != 42000 || comment.CostUSD != 12.5 {
+		t.Errorf("comment = %+v, expected ABC-123 with 42000 tokens and $12.50", comment)
+	}
+	expected := "Claude session 2025-06-20 18:00-23:00 used 42,000 tokens ($12.50)" // In the display timezone
+	if comment.Text != expected {
+		t.Errorf("comment.Text = %q, expected %q", comment.Text, expected)
+	}
+}
+
+func TestHooksAdd(t *testing.T) {
+	var runs int
+	h := NewHooks(nil)
+	h.Add(EventSessionEnd, recordingAction{runs: &runs})
+
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	h.Check(newTestSession(start, 1000, 10000))
+	h.Check(nil)
+	if runs != 1 {
+		t.Errorf("runs = %d, expected 1 on session end", runs)
+	}
+}
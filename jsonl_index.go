@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileIndexEntry summarizes the assistant messages in a JSONL file
+type FileIndexEntry struct {
+	Size     int64     `json:"size"` // Bytes indexed, at a line boundary
+	Inode    uint64    `json:"inode,omitempty"`
+	ModTime  time.Time `json:"modTime"`
+	First    time.Time `json:"first"`
+	Last     time.Time `json:"last"`
+	Messages int       `json:"messages"`
+}
+
+// FileIndex caches per-file timestamp ranges so range queries can skip whole files.
+// Entries are extended when a file grows and rebuilt when it is replaced or rewritten.
+type FileIndex struct {
+	path    string
+	entries map[string]FileIndexEntry
+	dirty   bool
+	savedAt time.Time
+	mu      sync.Mutex
+}
+
+var (
+	sharedIndex     *FileIndex
+	sharedIndexOnce sync.Once
+)
+
+// defaultFileIndex returns the process-wide index stored in the user cache directory
+func defaultFileIndex() *FileIndex {
+	sharedIndexOnce.Do(func() {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			sharedIndex = NewFileIndex("")
+			return
+		}
+		sharedIndex = LoadFileIndex(filepath.Join(cacheDir, "cctop", "jsonl-index.json"))
+	})
+	return sharedIndex
+}
+
+// NewFileIndex creates an empty index persisted at path ("" keeps it in memory only)
+func NewFileIndex(path string) *FileIndex {
+	return &FileIndex{path: path, entries: make(map[string]FileIndexEntry)}
+}
+
+// LoadFileIndex reads an index from disk, starting empty if it is missing or corrupt
+func LoadFileIndex(path string) *FileIndex {
+	index := NewFileIndex(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return index
+	}
+	if err := json.Unmarshal(data, &index.entries); err != nil || index.entries == nil {
+		index.entries = make(map[string]FileIndexEntry)
+	}
+	index.prune()
+	return index
+}
+
+// MayContain reports whether the file can hold messages within [start, end].
+// Unindexed or changed files are scanned and indexed first.
+// Files that cannot be indexed are assumed to match so they are still read.
+func (x *FileIndex) MayContain(filename string, start, end time.Time) bool {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return true
+	}
+
+	x.mu.Lock()
+	entry, ok := x.entries[filename]
+	x.mu.Unlock()
+
+	if !ok || entry.Size != info.Size() || !entry.ModTime.Equal(info.ModTime()) {
+		previous := entry
+		if !ok || entry.Inode != fileInode(info) || info.Size() <= entry.Size {
+			// Replaced or rewritten in place, so index it again from the start
+			entry = FileIndexEntry{}
+		}
+		entry, err = buildIndexEntry(filename, info, entry)
+		if err != nil {
+			return true
+		}
+		// A partly written last line leaves the entry unchanged until it is complete
+		if !ok || entry != previous {
+			x.mu.Lock()
+			x.entries[filename] = entry
+			x.dirty = true
+			x.mu.Unlock()
+		}
+	}
+
+	if entry.Messages == 0 {
+		return false
+	}
+	return !entry.Last.Before(start) && !entry.First.After(end)
+}
+
+// SaveIfDue writes the index if it changed and FileIndexSaveInterval passed since the last write.
+// Entries lost by not saving are rebuilt on the next run.
+func (x *FileIndex) SaveIfDue(currentTime time.Time) error {
+	x.mu.Lock()
+	due := currentTime.Sub(x.savedAt) >= FileIndexSaveInterval
+	x.mu.Unlock()
+	if !due {
+		return nil
+	}
+	return x.Save()
+}
+
+// Save writes the index to disk if it changed, dropping entries of deleted files
+func (x *FileIndex) Save() error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if !x.dirty || x.path == "" {
+		return nil
+	}
+	x.pruneLocked()
+	data, err := json.Marshal(x.entries)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(x.path, data, 0o600); err != nil {
+		return err
+	}
+	x.dirty = false
+	x.savedAt = time.Now()
+	return nil
+}
+
+// prune drops the entries of files that no longer exist
+func (x *FileIndex) prune() {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.pruneLocked()
+}
+
+// pruneLocked is prune with x.mu held
+func (x *FileIndex) pruneLocked() {
+	for filename := range x.entries {
+		if _, err := os.Stat(filename); errors.Is(err, fs.ErrNotExist) {
+			delete(x.entries, filename)
+			x.dirty = true
+		}
+	}
+}
+
+// buildIndexEntry scans a file for the earliest and latest assistant message,
+// continuing from an existing entry so only appended lines are read.
+// Lines are not assumed to be in order, and malformed lines are ignored.
+func buildIndexEntry(filename string, info os.FileInfo, entry FileIndexEntry) (FileIndexEntry, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return FileIndexEntry{}, err
+	}
+	defer file.Close()
+
+	entry.Inode = fileInode(info)
+	entry.ModTime = info.ModTime()
+	entry.Size, _, err = readJSONLLines(file, entry.Size, 0, func(line []byte, _ int) {
+		var msg struct {
+			Timestamp string `json:"timestamp"`
+			Type      string `json:"type"`
+		}
+		if err := json.Unmarshal(line, &msg); err != nil || msg.Type != "assistant" {
+			return
+		}
+		msgTime, err := time.Parse(time.RFC3339, msg.Timestamp)
+		if err != nil {
+			return
+		}
+
+		if entry.Messages == 0 || msgTime.Before(entry.First) {
+			entry.First = msgTime
+		}
+		if entry.Messages == 0 || msgTime.After(entry.Last) {
+			entry.Last = msgTime
+		}
+		entry.Messages++
+	})
+
+	return entry, err
+}
+
+// newJSONLScanner creates a line scanner that tolerates very long JSONL lines
+func newJSONLScanner(file *os.File) *bufio.Scanner {
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), MaxJSONLLineSize)
+	return scanner
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileIndexMayContain(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "session.jsonl")
+	lines := `{"timestamp":"2025-06-20T12:00:00Z","type":"assistant"}
+not json at all
+{"timestamp":"garbage","type":"assistant"}
+{"timestamp":"2025-06-20T09:00:00Z","type":"assistant"}
+{"timestamp":"2025-06-19T00:00:00Z","type":"user"}
+{"timestamp":"2025-06-20T10:30:00Z","type":"assistant"}
+`
+	if err := os.WriteFile(file, []byte(lines), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	index := NewFileIndex(filepath.Join(dir, "index.json"))
+	at := func(hour int) time.Time { return time.Date(2025, 6, 20, hour, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		start    time.Time
+		end      time.Time
+		expected bool
+	}{
+		{name: "Overlapping", start: at(8), end: at(10), expected: true},
+		{name: "Inside", start: at(10), end: at(11), expected: true},
+		{name: "Before first", start: at(5), end: at(8), expected: false},
+		{name: "After last", start: at(13), end: at(18), expected: false},
+		{name: "Touching last", start: at(12), end: at(13), expected: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := index.MayContain(file, tt.start, tt.end); result != tt.expected {
+				t.Errorf("MayContain() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+
+	entry := index.entries[file]
+	if entry.Messages != 3 || !entry.First.Equal(at(9)) || !entry.Last.Equal(at(12)) {
+		t.Errorf("entry = %+v, expected 3 messages from 09:00 to 12:00", entry)
+	}
+
+	// Saved index is reused after reload
+	if err := index.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	reloaded := LoadFileIndex(filepath.Join(dir, "index.json"))
+	if got := reloaded.entries[file]; got.Messages != 3 {
+		t.Errorf("reloaded entry = %+v, expected 3 messages", got)
+	}
+
+	// Appending to the file invalidates the entry
+	appended := lines + `{"timestamp":"2025-06-20T16:00:00Z","type":"assistant"}` + "\n"
+	if err := os.WriteFile(file, []byte(appended), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if !reloaded.MayContain(file, at(15), at(17)) {
+		t.Error("MayContain() after append = false, expected true")
+	}
+}
+
+func TestLoadFileIndexCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.json")
+	if err := os.WriteFile(path, []byte("{corrupt"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if index := LoadFileIndex(path); index.entries == nil || len(index.entries) != 0 {
+		t.Errorf("LoadFileIndex() of corrupt file = %+v, expected empty index", index.entries)
+	}
+}
+
+func TestFileIndexExtendsOnAppend(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "session.jsonl")
+	first := `{"timestamp":"2025-06-20T09:00:00Z","type":"assistant"}` + "\n"
+	if err := os.WriteFile(file, []byte(first), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	index := NewFileIndex("")
+	at := func(hour int) time.Time { return time.Date(2025, 6, 20, hour, 0, 0, 0, time.UTC) }
+	index.MayContain(file, at(9), at(10))
+
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"timestamp":"2025-06-20T14:00:00Z","type":"assistant"}` + "\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	if !index.MayContain(file, at(13), at(15)) {
+		t.Error("MayContain() after append = false, expected true")
+	}
+	entry := index.entries[file]
+	if entry.Messages != 2 || !entry.First.Equal(at(9)) || !entry.Last.Equal(at(14)) {
+		t.Errorf("entry = %+v, expected 2 messages from 09:00 to 14:00", entry)
+	}
+}
+
+func TestFileIndexPrunesDeletedFiles(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "session.jsonl")
+	if err := os.WriteFile(file, []byte(`{"timestamp":"2025-06-20T09:00:00Z","type":"assistant"}`+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	index := NewFileIndex(filepath.Join(dir, "index.json"))
+	at := func(hour int) time.Time { return time.Date(2025, 6, 20, hour, 0, 0, 0, time.UTC) }
+	index.MayContain(file, at(9), at(10))
+	if err := index.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	if err := os.Remove(file); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded := LoadFileIndex(filepath.Join(dir, "index.json")); len(reloaded.entries) != 0 {
+		t.Errorf("reloaded entries = %+v, expected the deleted file to be dropped", reloaded.entries)
+	}
+}
+
+func TestFileIndexSaveIfDue(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "session.jsonl")
+	if err := os.WriteFile(file, []byte(`{"timestamp":"2025-06-20T09:00:00Z","type":"assistant"}`+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "index.json")
+	index := NewFileIndex(path)
+	index.savedAt = time.Now()
+	index.MayContain(file, time.Time{}, time.Now())
+
+	if err := index.SaveIfDue(time.Now()); err != nil {
+		t.Fatalf("SaveIfDue() error: %v", err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		t.Error("SaveIfDue() wrote the index right after the last write")
+	}
+	if err := index.SaveIfDue(time.Now().Add(FileIndexSaveInterval)); err != nil {
+		t.Fatalf("SaveIfDue() error: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("SaveIfDue() after the interval did not write the index: %v", err)
+	}
+}
+
+func FuzzBuildIndexEntry(f *testing.F) {
+	f.Add([]byte(`{"timestamp":"2025-06-20T09:00:00Z","type":"assistant"}` + "\n"))
+	f.Add([]byte(`{"timestamp":"2025-06-20T12:00:00Z","type":"assistant"}` + "\nnot json\n" + `{"timestamp":"garbage","type":"assistant"}`))
+	f.Add([]byte("\n\n{}\n"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		file := filepath.Join(t.TempDir(), "session.jsonl")
+		if err := os.WriteFile(file, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entry, err := buildIndexEntry(file, info, FileIndexEntry{})
+		if err != nil {
+			return
+		}
+		if entry.Size < 0 || entry.Size > int64(len(data)) {
+			t.Errorf("Size = %d, expected within the %d bytes of the file", entry.Size, len(data))
+		}
+		if entry.Messages > 0 && entry.Last.Before(entry.First) {
+			t.Errorf("Last %v before First %v", entry.Last, entry.First)
+		}
+		if entry.Messages == 0 && (!entry.First.IsZero() || !entry.Last.IsZero()) {
+			t.Errorf("entry without messages has a time range: %+v", entry)
+		}
+	})
+}
@@ -1,8 +1,6 @@
 package main
 
 import (
-	"bufio"
-	"encoding/json"
 	"os"
 	"path/filepath"
 	"sort"
@@ -19,32 +17,78 @@ type JSONLMessage struct {
 
 // AssistantMessage represents the message field in JSONL
 type AssistantMessage struct {
+	ID    string     `json:"id"`
 	Role  string     `json:"role"`
+	Model string     `json:"model"`
 	Usage TokenUsage `json:"usage"`
 }
 
+// jsonlEntry is an assistant message with the time and working directory it was sent from
+type jsonlEntry struct {
+	Time      time.Time
+	Cwd       string
+	RequestID string
+	CostUSD   float64 // Only logged by older Claude Code versions
+	Message   AssistantMessage
+}
+
+// dedupKey identifies an API response that Claude may log more than once, as ccusage
+// does; entries without IDs are never treated as duplicates
+func (e jsonlEntry) dedupKey() string {
+	if e.Message.ID == "" || e.RequestID == "" {
+		return ""
+	}
+	return e.Message.ID + ":" + e.RequestID
+}
+
+// TimedTokens is a message's tokens, including all cache tokens, when it was sent
+// and the log file it was read from
+type TimedTokens struct {
+	Time   time.Time
+	Tokens int
+	File   string
+}
+
 // TokenUsage represents token usage in a message
 type TokenUsage struct {
-	InputTokens  int `json:"input_tokens"`
-	OutputTokens int `json:"output_tokens"`
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
 }
 
 // MessageTokenReader reads token data from JSONL files
 type MessageTokenReader struct {
-	claudeProjectsDir string
+	claudeProjectsDirs []string
+	index              *FileIndex // Optional, skips files outside the requested range
+	tail               *JSONLTail // Optional, parses only lines appended since the last read
 }
 
-// NewMessageTokenReader creates a new reader
+// NewMessageTokenReader creates a new reader for the active profiles, or the default directory
 func NewMessageTokenReader() *MessageTokenReader {
-	homeDir, _ := os.UserHomeDir()
-	claudeProjectsDir := filepath.Join(homeDir, ".config", "claude", "projects")
-
 	return &MessageTokenReader{
-		claudeProjectsDir: claudeProjectsDir,
+		claudeProjectsDirs: defaultProjectsDirs(),
+		index:              defaultFileIndex(),
+		tail:               defaultJSONLTail(),
+	}
+}
+
+// defaultProjectsDirs returns the projects directories of the active profiles, or of the
+// Claude config directories found on this machine
+func defaultProjectsDirs() []string {
+	if dirs := profileProjectsDirs(); len(dirs) > 0 {
+		return dirs
+	}
+
+	var dirs []string
+	for _, dir := range defaultClaudeDirs() {
+		dirs = append(dirs, filepath.Join(dir, "projects"))
 	}
+	return dirs
 }
 
-// GetBlockTokens retrieves all message tokens for a specific time range across all projects
+// GetBlockTokens retrieves all message tokens for a specific time range across all
+// projects, oldest first so order-sensitive estimators like EWMA see the real sequence
 func (r *MessageTokenReader) GetBlockTokens(startTime, endTime string) ([]int, error) {
 	// Get all project directories
 	projectDirs, err := r.getAllProjectDirs()
@@ -52,7 +96,7 @@ func (r *MessageTokenReader) GetBlockTokens(startTime, endTime string) ([]int, e
 		return nil, err
 	}
 
-	var allTokens []int
+	var entries []jsonlEntry
 
 	// Search through all project directories
 	for _, projectDir := range projectDirs {
@@ -63,88 +107,301 @@ func (r *MessageTokenReader) GetBlockTokens(startTime, endTime string) ([]int, e
 		}
 
 		// Read tokens from each file
-		for _, file := range files {
-			tokens, err := r.readBlockTokensFromFile(file, startTime, endTime)
+		for _, file := range r.filterFiles(files, startTime, endTime) {
+			fileEntries, err := r.readBlockEntriesFromFile(file, startTime, endTime)
 			if err != nil {
+				logger.Warnf("skipping %s: %v", file, err)
 				continue // Skip files with errors
 			}
-			allTokens = append(allTokens, tokens...)
+			entries = append(entries, fileEntries...)
 		}
 	}
 
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+	var allTokens []int
+	for _, entry := range entries {
+		if tokens := entry.Message.Usage.Total(); tokens > 0 {
+			allTokens = append(allTokens, tokens)
+		}
+	}
 	return allTokens, nil
 }
 
-// getAllProjectDirs returns all project directories under ~/.config/claude/projects/
+// getAllProjectDirs returns all project directories under each projects directory.
+// It fails only when none of the projects directories can be read.
 func (r *MessageTokenReader) getAllProjectDirs() ([]string, error) {
-	entries, err := os.ReadDir(r.claudeProjectsDir)
-	if err != nil {
-		return nil, err
-	}
-
 	var dirs []string
-	for _, entry := range entries {
-		if entry.IsDir() {
-			dirs = append(dirs, filepath.Join(r.claudeProjectsDir, entry.Name()))
+	var lastErr error
+	readAny := false
+
+	for _, projectsDir := range r.claudeProjectsDirs {
+		entries, err := os.ReadDir(projectsDir)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		readAny = true
+
+		for _, entry := range entries {
+			if entry.IsDir() {
+				dirs = append(dirs, filepath.Join(projectsDir, entry.Name()))
+			}
 		}
 	}
 
+	if !readAny && lastErr != nil {
+		return nil, lastErr
+	}
 	return dirs, nil
 }
 
-// readBlockTokensFromFile reads tokens for messages within a time range from a file
-func (r *MessageTokenReader) readBlockTokensFromFile(filename, startTime, endTime string) ([]int, error) {
-	file, err := os.Open(filename)
+// filterFiles drops files the index knows hold no messages in the time range
+func (r *MessageTokenReader) filterFiles(files []string, startTime, endTime string) []string {
+	if r.index == nil {
+		return files
+	}
+	start, err := time.Parse(time.RFC3339, startTime)
 	if err != nil {
-		return nil, err
+		return files
+	}
+	end, err := time.Parse(time.RFC3339, endTime)
+	if err != nil {
+		return files
 	}
-	defer file.Close()
 
-	var tokens []int
-	scanner := bufio.NewScanner(file)
+	filtered := files[:0:0]
+	for _, file := range files {
+		if r.index.MayContain(file, start, end) {
+			filtered = append(filtered, file)
+		}
+	}
+	_ = r.index.SaveIfDue(time.Now())
+	return filtered
+}
 
-	// Parse time boundaries
-	start, err := time.Parse(time.RFC3339, startTime)
+// GetBlockModelTokens sums message tokens per model for a time range across all projects
+func (r *MessageTokenReader) GetBlockModelTokens(startTime, endTime string) (map[string]int, error) {
+	projectDirs, err := r.getAllProjectDirs()
 	if err != nil {
 		return nil, err
 	}
-	end, err := time.Parse(time.RFC3339, endTime)
+
+	modelTokens := make(map[string]int)
+	for _, projectDir := range projectDirs {
+		files, err := filepath.Glob(filepath.Join(projectDir, "*.jsonl"))
+		if err != nil {
+			continue // Skip this project on error
+		}
+
+		for _, file := range r.filterFiles(files, startTime, endTime) {
+			messages, err := r.readBlockMessagesFromFile(file, startTime, endTime)
+			if err != nil {
+				logger.Warnf("skipping %s: %v", file, err)
+				continue // Skip files with errors
+			}
+			for _, msg := range messages {
+				if msg.Model == "" || msg.Model == "<synthetic>" {
+					continue
+				}
+				modelTokens[msg.Model] += msg.Usage.Total()
+			}
+		}
+	}
+
+	return modelTokens, nil
+}
+
+// GetBlockProjectTokens sums message tokens per project for a time range.
+// Projects are keyed by the working directory recorded in the logs (see projectPath).
+func (r *MessageTokenReader) GetBlockProjectTokens(startTime, endTime string) (map[string]int, error) {
+	projectDirs, err := r.getAllProjectDirs()
 	if err != nil {
 		return nil, err
 	}
 
-	for scanner.Scan() {
-		var msg struct {
-			Timestamp string           `json:"timestamp"`
-			Type      string           `json:"type"`
-			Message   AssistantMessage `json:"message"`
+	projectTokens := make(map[string]int)
+	for _, projectDir := range projectDirs {
+		files, err := filepath.Glob(filepath.Join(projectDir, "*.jsonl"))
+		if err != nil {
+			continue // Skip this project on error
+		}
+
+		for _, file := range r.filterFiles(files, startTime, endTime) {
+			entries, err := r.readBlockEntriesFromFile(file, startTime, endTime)
+			if err != nil {
+				logger.Warnf("skipping %s: %v", file, err)
+				continue // Skip files with errors
+			}
+			for _, entry := range entries {
+				tokens := entry.Message.Usage.Total()
+				if tokens > 0 {
+					projectTokens[projectPath(projectDir, entry.Cwd)] += tokens
+				}
+			}
 		}
+	}
+
+	return projectTokens, nil
+}
+
+// GetBlockTimedTokens returns every message's tokens with its timestamp for a time
+// range across all projects, oldest first. Cache tokens count fully and responses
+// logged more than once count once, as in ccusage totals.
+func (r *MessageTokenReader) GetBlockTimedTokens(startTime, endTime string) ([]TimedTokens, error) {
+	projectDirs, err := r.getAllProjectDirs()
+	if err != nil {
+		return nil, err
+	}
 
-		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
-			continue // Skip malformed lines
+	var timed []TimedTokens
+	seen := make(map[string]bool)
+	for _, projectDir := range projectDirs {
+		files, err := filepath.Glob(filepath.Join(projectDir, "*.jsonl"))
+		if err != nil {
+			continue // Skip this project on error
 		}
 
-		// Only process assistant messages
-		if msg.Type != "assistant" {
-			continue
+		for _, file := range r.filterFiles(files, startTime, endTime) {
+			entries, err := r.readBlockEntriesFromFile(file, startTime, endTime)
+			if err != nil {
+				logger.Warnf("skipping %s: %v", file, err)
+				continue // Skip files with errors
+			}
+			for _, entry := range entries {
+				if key := entry.dedupKey(); key != "" {
+					if seen[key] {
+						continue
+					}
+					seen[key] = true
+				}
+				usage := entry.Message.Usage
+				tokens := usage.InputTokens + usage.OutputTokens + usage.CacheCreationInputTokens + usage.CacheReadInputTokens
+				if tokens > 0 {
+					timed = append(timed, TimedTokens{Time: entry.Time, Tokens: tokens, File: file})
+				}
+			}
 		}
+	}
+
+	sort.Slice(timed, func(i, j int) bool { return timed[i].Time.Before(timed[j].Time) })
+	return timed, nil
+}
+
+// MessageRecord is an assistant message as exported for analysis
+type MessageRecord struct {
+	Time    time.Time
+	Project string
+	Model   string
+	Usage   TokenUsage
+	CostUSD float64 // 0 unless the log recorded it
+}
+
+// GetMessageRecords returns every assistant message in a time range across all
+// projects, oldest first, counting responses logged more than once only once
+func (r *MessageTokenReader) GetMessageRecords(startTime, endTime string) ([]MessageRecord, error) {
+	projectDirs, err := r.getAllProjectDirs()
+	if err != nil {
+		return nil, err
+	}
 
-		// Check if message is within time range
-		msgTime, err := time.Parse(time.RFC3339, msg.Timestamp)
+	var records []MessageRecord
+	seen := make(map[string]bool)
+	for _, projectDir := range projectDirs {
+		files, err := filepath.Glob(filepath.Join(projectDir, "*.jsonl"))
 		if err != nil {
-			continue
+			continue // Skip this project on error
 		}
 
-		// Check if message is within time range (inclusive)
-		if (msgTime.Equal(start) || msgTime.After(start)) && (msgTime.Before(end) || msgTime.Equal(end)) {
-			totalTokens := msg.Message.Usage.InputTokens + msg.Message.Usage.OutputTokens
-			if totalTokens > 0 {
-				tokens = append(tokens, totalTokens)
+		for _, file := range r.filterFiles(files, startTime, endTime) {
+			entries, err := r.readBlockEntriesFromFile(file, startTime, endTime)
+			if err != nil {
+				logger.Warnf("skipping %s: %v", file, err)
+				continue // Skip files with errors
+			}
+			for _, entry := range entries {
+				if key := entry.dedupKey(); key != "" {
+					if seen[key] {
+						continue
+					}
+					seen[key] = true
+				}
+				records = append(records, MessageRecord{
+					Time:    entry.Time,
+					Project: projectPath(projectDir, entry.Cwd),
+					Model:   entry.Message.Model,
+					Usage:   entry.Message.Usage,
+					CostUSD: entry.CostUSD,
+				})
 			}
 		}
 	}
 
-	return tokens, scanner.Err()
+	sort.Slice(records, func(i, j int) bool { return records[i].Time.Before(records[j].Time) })
+	return records, nil
+}
+
+// readBlockMessagesFromFile reads assistant messages within a time range from a file
+func (r *MessageTokenReader) readBlockMessagesFromFile(filename, startTime, endTime string) ([]AssistantMessage, error) {
+	entries, err := r.readBlockEntriesFromFile(filename, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]AssistantMessage, len(entries))
+	for i, entry := range entries {
+		messages[i] = entry.Message
+	}
+	return messages, nil
+}
+
+// readBlockEntriesFromFile reads assistant log entries within a time range from a file.
+// With a tail only lines appended since the last read are parsed.
+func (r *MessageTokenReader) readBlockEntriesFromFile(filename, startTime, endTime string) ([]jsonlEntry, error) {
+	// Parse time boundaries
+	start, err := time.Parse(time.RFC3339, startTime)
+	if err != nil {
+		return nil, err
+	}
+	end, err := time.Parse(time.RFC3339, endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	var all []jsonlEntry
+	if r.tail != nil {
+		all, err = r.tail.Entries(filename)
+	} else {
+		all, err = readJSONLEntries(filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []jsonlEntry
+	for _, entry := range all {
+		// Check if message is within time range (inclusive)
+		if !entry.Time.Before(start) && !entry.Time.After(end) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// readJSONLEntries reads every assistant log entry in a file
+func readJSONLEntries(filename string) ([]jsonlEntry, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []jsonlEntry
+	_, _, err = readJSONLLines(file, 0, 0, func(line []byte, number int) {
+		if entry, ok := parseJSONLLine(filename, number, line); ok {
+			entries = append(entries, entry)
+		}
+	})
+	return entries, err
 }
 
 // CalculateMedianTokens calculates the median of token values
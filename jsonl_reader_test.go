@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGetBlockTimedTokensDedup(t *testing.T) {
+	projectsDir := t.TempDir()
+	dir := filepath.Join(projectsDir, "-home-me-src-cctop")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// A resumed session copies earlier responses into a new log file
+	first := `{"timestamp":"2025-06-20T10:00:00Z","type":"assistant","requestId":"req_1","message":{"id":"msg_1","usage":{"input_tokens":100,"output_tokens":200}}}
+{"timestamp":"2025-06-20T10:10:00Z","type":"assistant","message":{"usage":{"output_tokens":50}}}
+`
+	resumed := `{"timestamp":"2025-06-20T10:00:00Z","type":"assistant","requestId":"req_1","message":{"id":"msg_1","usage":{"input_tokens":100,"output_tokens":200}}}
+{"timestamp":"2025-06-20T10:10:00Z","type":"assistant","message":{"usage":{"output_tokens":50}}}
+{"timestamp":"2025-06-20T10:20:00Z","type":"assistant","requestId":"req_2","message":{"id":"msg_2","usage":{"output_tokens":400}}}
+`
+	for name, lines := range map[string]string{"first.jsonl": first, "resumed.jsonl": resumed} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(lines), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reader := &MessageTokenReader{claudeProjectsDirs: []string{projectsDir}}
+	timed, err := reader.GetBlockTimedTokens("2025-06-20T09:00:00Z", "2025-06-20T14:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// msg_1 counts once; entries without IDs cannot be matched and count each time
+	total := 0
+	for _, message := range timed {
+		total += message.Tokens
+	}
+	if len(timed) != 4 || total != 800 {
+		t.Errorf("GetBlockTimedTokens() = %d messages with %d tokens, expected 4 with 800", len(timed), total)
+	}
+}
+
+func TestGetBlockTokensOldestFirst(t *testing.T) {
+	projectsDir := t.TempDir()
+	// Files are read project by project, so two projects interleave in time
+	logs := map[string]string{
+		"-home-me-src-a": `{"timestamp":"2025-06-20T10:00:00Z","type":"assistant","message":{"usage":{"output_tokens":1}}}
+{"timestamp":"2025-06-20T10:20:00Z","type":"assistant","message":{"usage":{"output_tokens":3}}}
+`,
+		"-home-me-src-b": `{"timestamp":"2025-06-20T10:10:00Z","type":"assistant","message":{"usage":{"output_tokens":2}}}
+{"timestamp":"2025-06-20T10:30:00Z","type":"assistant","message":{"usage":{"output_tokens":4}}}
+`,
+	}
+	for project, lines := range logs {
+		dir := filepath.Join(projectsDir, project)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "session.jsonl"), []byte(lines), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reader := &MessageTokenReader{claudeProjectsDirs: []string{projectsDir}}
+	tokens, err := reader.GetBlockTokens("2025-06-20T09:00:00Z", "2025-06-20T14:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tokens, []int{1, 2, 3, 4}) {
+		t.Errorf("GetBlockTokens() = %v, expected [1 2 3 4]", tokens)
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// tailedFile holds the assistant entries parsed so far from one JSONL file
+type tailedFile struct {
+	inode   uint64
+	offset  int64  // Bytes parsed, always at a line boundary
+	lines   int    // Lines parsed
+	used    uint64 // Read counter value at the last read, for evicting the least recently used
+	entries []jsonlEntry
+}
+
+// JSONLTail remembers how far each JSONL file has been read so later reads only
+// parse lines appended since. A file is read again from the start when its inode
+// changes or it shrinks below the remembered offset, as after rotation or truncation.
+// At most MaxTailedFiles files are kept; the least recently read is dropped first.
+type JSONLTail struct {
+	files map[string]*tailedFile
+	reads uint64
+	mu    sync.Mutex
+}
+
+var (
+	sharedTail     *JSONLTail
+	sharedTailOnce sync.Once
+)
+
+// defaultJSONLTail returns the process-wide tail shared by all readers
+func defaultJSONLTail() *JSONLTail {
+	sharedTailOnce.Do(func() {
+		sharedTail = NewJSONLTail()
+	})
+	return sharedTail
+}
+
+// NewJSONLTail creates an empty tail
+func NewJSONLTail() *JSONLTail {
+	return &JSONLTail{files: make(map[string]*tailedFile)}
+}
+
+// Entries returns every assistant entry in the file, parsing only lines appended
+// since the previous call. The returned slice must not be modified.
+func (t *JSONLTail) Entries(filename string) ([]jsonlEntry, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	inode := fileInode(info)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tailed, ok := t.files[filename]
+	if !ok || tailed.inode != inode || info.Size() < tailed.offset {
+		tailed = &tailedFile{inode: inode}
+		t.files[filename] = tailed
+	}
+	t.reads++
+	tailed.used = t.reads
+	t.evictLocked()
+	if info.Size() == tailed.offset {
+		return tailed.entries, nil
+	}
+
+	offset, lines, err := readJSONLLines(file, tailed.offset, tailed.lines, func(line []byte, number int) {
+		if entry, ok := parseJSONLLine(filename, number, line); ok {
+			tailed.entries = append(tailed.entries, entry)
+		}
+	})
+	tailed.offset, tailed.lines = offset, lines
+	return tailed.entries, err
+}
+
+// evictLocked drops the least recently read files beyond MaxTailedFiles
+func (t *JSONLTail) evictLocked() {
+	for len(t.files) > MaxTailedFiles {
+		oldest := ""
+		for name, tailed := range t.files {
+			if oldest == "" || tailed.used < t.files[oldest].used {
+				oldest = name
+			}
+		}
+		delete(t.files, oldest)
+	}
+}
+
+// readJSONLLines calls fn for each line from offset onwards, numbered on from the lines
+// before offset, and returns the offset just past the last line consumed and the lines
+// consumed in total. A final line without a newline is only consumed when it is
+// complete JSON, since Claude may still be writing it.
+// Lines longer than MaxJSONLLineSize are skipped.
+func readJSONLLines(file *os.File, offset int64, lines int, fn func(line []byte, number int)) (int64, int, error) {
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return offset, lines, err
+	}
+
+	reader := bufio.NewReaderSize(file, 64*1024)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(line) > 0 && json.Valid(line) {
+				lines++
+				fn(line, lines)
+				offset += int64(len(line))
+			}
+			return offset, lines, nil
+		}
+		if err != nil {
+			return offset, lines, err
+		}
+
+		offset += int64(len(line))
+		lines++
+		if len(line) <= MaxJSONLLineSize {
+			fn(line, lines)
+		} else {
+			logger.Debugf("%s:%d: skipped line of %d bytes", file.Name(), lines, len(line))
+		}
+	}
+}
+
+// parseJSONLLine parses line number of a log file like parseJSONLEntry, logging why
+// malformed lines are skipped
+func parseJSONLLine(filename string, number int, line []byte) (jsonlEntry, bool) {
+	entry, ok, err := parseJSONLEntry(line)
+	if err != nil {
+		logger.Debugf("%s:%d: %v", filename, number, err)
+	}
+	return entry, ok
+}
+
+// parseJSONLEntry parses an assistant log line, reporting false for any other line
+// and an error for lines that are not valid log entries
+func parseJSONLEntry(line []byte) (jsonlEntry, bool, error) {
+	var msg struct {
+		Timestamp string           `json:"timestamp"`
+		Type      string           `json:"type"`
+		Cwd       string           `json:"cwd"`
+		RequestID string           `json:"requestId"`
+		CostUSD   float64          `json:"costUSD"`
+		Message   AssistantMessage `json:"message"`
+	}
+	if err := json.Unmarshal(line, &msg); err != nil {
+		return jsonlEntry{}, false, err
+	}
+	if msg.Type != "assistant" {
+		return jsonlEntry{}, false, nil
+	}
+
+	msgTime, err := time.Parse(time.RFC3339, msg.Timestamp)
+	if err != nil {
+		return jsonlEntry{}, false, fmt.Errorf("invalid timestamp: %w", err)
+	}
+	return jsonlEntry{Time: msgTime, Cwd: msg.Cwd, RequestID: msg.RequestID, CostUSD: msg.CostUSD, Message: msg.Message}, true, nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJSONLTailEntries(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "session.jsonl")
+	line := func(ts string, tokens int) string {
+		return fmt.Sprintf(`{"timestamp":"%s","type":"assistant","message":{"usage":{"output_tokens":%d}}}`, ts, tokens) + "\n"
+	}
+	write := func(content string, flag int) {
+		f, err := os.OpenFile(file, flag|os.O_WRONLY, 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.WriteString(content); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+	tokens := func(entries []jsonlEntry) []int {
+		var result []int
+		for _, e := range entries {
+			result = append(result, e.Message.Usage.OutputTokens)
+		}
+		return result
+	}
+
+	tail := NewJSONLTail()
+	write(line("2025-06-20T09:00:00Z", 100)+`{"type":"user"}`+"\n", os.O_CREATE|os.O_TRUNC)
+	entries, err := tail.Entries(file)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Entries() = %v, %v, expected 1 entry", tokens(entries), err)
+	}
+
+	// Appended lines are parsed, a partly written line waits until it is complete
+	partial := line("2025-06-20T09:10:00Z", 300)
+	write(line("2025-06-20T09:05:00Z", 200)+partial[:20], os.O_APPEND)
+	entries, _ = tail.Entries(file)
+	if got := tokens(entries); len(got) != 2 || got[1] != 200 {
+		t.Errorf("Entries() after append = %v, expected [100 200]", got)
+	}
+	offset := tail.files[file].offset
+
+	write(partial[20:], os.O_APPEND)
+	entries, _ = tail.Entries(file)
+	if got := tokens(entries); len(got) != 3 || got[2] != 300 {
+		t.Errorf("Entries() after completing line = %v, expected [100 200 300]", got)
+	}
+	if tail.files[file].offset <= offset {
+		t.Errorf("offset = %d, expected it to advance past %d", tail.files[file].offset, offset)
+	}
+
+	// Truncation starts over from the beginning
+	write(line("2025-06-20T10:00:00Z", 50), os.O_TRUNC)
+	entries, _ = tail.Entries(file)
+	if got := tokens(entries); len(got) != 1 || got[0] != 50 {
+		t.Errorf("Entries() after truncation = %v, expected [50]", got)
+	}
+}
+
+func TestReadJSONLLinesFinalLine(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "session.jsonl")
+	content := `{"a":1}` + "\n" + `{"b":2}`
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var lines int
+	offset, _, err := readJSONLLines(f, 0, 0, func([]byte, int) { lines++ })
+	if err != nil || lines != 2 || offset != int64(len(content)) {
+		t.Errorf("readJSONLLines() = %d lines to offset %d (%v), expected 2 lines to %d", lines, offset, err, len(content))
+	}
+}
+
+func TestJSONLParseErrorsLogged(t *testing.T) {
+	oldLogger := logger
+	defer func() { logger = oldLogger }()
+	logPath := filepath.Join(t.TempDir(), "cctop.log")
+	logger, _ = NewLogger(LogConfig{Level: "debug", File: logPath})
+
+	file := filepath.Join(t.TempDir(), "session.jsonl")
+	valid := `{"timestamp":"2025-06-20T10:00:00Z","type":"assistant","message":{"id":"msg_1","usage":{"input_tokens":1}}}` + "\n"
+	content := valid + "{not json\n" + `{"timestamp":"yesterday","type":"assistant"}` + "\n" + `{"type":"user"}` + "\n"
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tail := NewJSONLTail()
+	if entries, _ := tail.Entries(file); len(entries) != 1 {
+		t.Errorf("Entries() = %d entries, expected 1", len(entries))
+	}
+	// Appended lines keep counting from the lines already read
+	appended, _ := os.OpenFile(file, os.O_APPEND|os.O_WRONLY, 0o600)
+	appended.WriteString(valid + "[]\n")
+	appended.Close()
+	tail.Entries(file)
+	logger.Close()
+
+	data, _ := os.ReadFile(logPath)
+	log := string(data)
+	for _, expected := range []string{file + ":2: invalid character", file + ":3: invalid timestamp", file + ":6: json: cannot unmarshal"} {
+		if !strings.Contains(log, expected) {
+			t.Errorf("log = %q, expected %q", log, expected)
+		}
+	}
+	if strings.Contains(log, file+":4:") {
+		t.Errorf("log = %q, expected the user message not to be logged", log)
+	}
+}
+
+func TestJSONLTailEvictsLeastRecentlyRead(t *testing.T) {
+	dir := t.TempDir()
+	tail := NewJSONLTail()
+	names := make([]string, MaxTailedFiles+1)
+	for i := range names {
+		names[i] = filepath.Join(dir, fmt.Sprintf("%d.jsonl", i))
+		if err := os.WriteFile(names[i], nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range names[:MaxTailedFiles] {
+		if _, err := tail.Entries(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Reading the first file again leaves the second as the least recently read
+	if _, err := tail.Entries(names[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tail.Entries(names[MaxTailedFiles]); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tail.files) != MaxTailedFiles {
+		t.Errorf("tail holds %d files, expected %d", len(tail.files), MaxTailedFiles)
+	}
+	if _, ok := tail.files[names[1]]; ok {
+		t.Error("least recently read file was kept")
+	}
+	if _, ok := tail.files[names[0]]; !ok {
+		t.Error("recently read file was evicted")
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Key binding actions of the interactive monitor
+const (
+	ActionSessionView = "session-view"
+	ActionHistoryView = "history-view"
+	ActionDailyView   = "daily-view"
+	ActionEventsView  = "events-view"
+	ActionNextView    = "next-view"
+	ActionPrevView    = "prev-view"
+	ActionPause       = "pause"
+	ActionPlan        = "plan"
+	ActionProfile     = "profile"
+	ActionQuit        = "quit" // ctrl+c always quits as well
+)
+
+// KeyAction describes an action and its default keys
+type KeyAction struct {
+	Name        string
+	Description string
+	Defaults    []string
+}
+
+// keyActions lists the actions in the order they are documented
+var keyActions = []KeyAction{
+	{ActionSessionView, "Session view", []string{"1"}},
+	{ActionHistoryView, "History view", []string{"2"}},
+	{ActionDailyView, "Daily view", []string{"3"}},
+	{ActionEventsView, "Events view", []string{"4"}},
+	{ActionNextView, "Next view", []string{"tab"}},
+	{ActionPrevView, "Previous view", []string{"shift+tab"}},
+	{ActionPause, "Pause/resume refresh", []string{"space"}},
+	{ActionPlan, "Cycle plan", []string{"p"}},
+	{ActionProfile, "Cycle profile", []string{"o"}},
+	{ActionQuit, "Quit", []string{"q"}},
+}
+
+// KeyMap binds key names, as reported by bubbletea (e.g. "j", "ctrl+n", "tab"), to actions
+type KeyMap struct {
+	keys    map[string][]string // Action to keys, first key shown in the footer
+	actions map[string]string   // Key to action
+}
+
+// keyMap is the active key map, set from --keys at startup
+var keyMap = mustKeyMap(nil)
+
+// NewKeyMap builds the key map from the defaults with overrides of action to space
+// separated keys, e.g. next-view="l tab". A key bound to two actions is an error.
+func NewKeyMap(overrides map[string]string) (KeyMap, error) {
+	keyMap := KeyMap{keys: make(map[string][]string), actions: make(map[string]string)}
+	for _, action := range keyActions {
+		keyMap.keys[action.Name] = action.Defaults
+	}
+	for action, keys := range overrides {
+		if !slices.ContainsFunc(keyActions, func(a KeyAction) bool { return a.Name == action }) {
+			return KeyMap{}, fmt.Errorf("unknown key action %q (see 'cctop keys')", action)
+		}
+		keyMap.keys[action] = strings.Fields(keys)
+	}
+
+	for _, action := range keyActions {
+		for _, key := range keyMap.keys[action.Name] {
+			if other, ok := keyMap.actions[key]; ok {
+				return KeyMap{}, fmt.Errorf("key %q is bound to both %s and %s", key, other, action.Name)
+			}
+			keyMap.actions[key] = action.Name
+		}
+	}
+	return keyMap, nil
+}
+
+// mustKeyMap builds a key map, panicking on invalid overrides
+func mustKeyMap(overrides map[string]string) KeyMap {
+	keyMap, err := NewKeyMap(overrides)
+	if err != nil {
+		panic(err)
+	}
+	return keyMap
+}
+
+// validKeyBindings checks --keys values given as action=keys pairs
+func validKeyBindings(value string) error {
+	overrides := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		action, keys, _ := strings.Cut(pair, "=")
+		overrides[action] = keys
+	}
+	_, err := NewKeyMap(overrides)
+	return err
+}
+
+// Action returns the action bound to a key as reported by bubbletea, or ""
+func (k KeyMap) Action(key string) string {
+	if key == " " {
+		key = "space"
+	}
+	return k.actions[key]
+}
+
+// Keys returns the keys bound to an action
+func (k KeyMap) Keys(action string) []string {
+	return k.keys[action]
+}
+
+// Hint returns the first key of an action for the footer, e.g. "[q]", or "" when unbound
+func (k KeyMap) Hint(action string) string {
+	keys := k.keys[action]
+	if len(keys) == 0 {
+		return ""
+	}
+	return "[" + keys[0] + "]"
+}
+
+// runKeys prints the active key map
+func runKeys(cmd *cobra.Command, args []string) {
+	for _, action := range keyActions {
+		bound := strings.Join(keyMap.Keys(action.Name), " ")
+		if bound == "" {
+			bound = "(unbound)"
+		}
+		fmt.Printf("%-14s %-20s %s\n", action.Name, bound, action.Description)
+	}
+}
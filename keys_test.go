@@ -0,0 +1,75 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestNewKeyMap(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string]string
+		key       string
+		expected  string
+		wantErr   bool
+	}{
+		{"default", nil, "tab", ActionNextView, false},
+		{"space alias", nil, " ", ActionPause, false},
+		{"vim navigation", map[string]string{ActionNextView: "l tab", ActionPrevView: "h"}, "l", ActionNextView, false},
+		{"override frees default", map[string]string{ActionQuit: "Q"}, "q", "", false},
+		{"unknown action", map[string]string{"explode": "x"}, "", "", true},
+		{"conflict", map[string]string{ActionPlan: "q"}, "", "", true},
+	}
+
+	for _, tt := range tests {
+		keyMap, err := NewKeyMap(tt.overrides)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: NewKeyMap() error = %v, expected error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && keyMap.Action(tt.key) != tt.expected {
+			t.Errorf("%s: Action(%q) = %q, expected %q", tt.name, tt.key, keyMap.Action(tt.key), tt.expected)
+		}
+	}
+}
+
+func TestValidKeyBindings(t *testing.T) {
+	if err := validKeyBindings("next-view=l tab,prev-view=h"); err != nil {
+		t.Errorf("validKeyBindings() error = %v, expected nil", err)
+	}
+	if err := validKeyBindings("pause=p"); err == nil {
+		t.Errorf("validKeyBindings(%q) error = nil, expected a conflict with plan", "pause=p")
+	}
+}
+
+func TestRemappedKeys(t *testing.T) {
+	oldKeyMap := keyMap
+	defer func() { keyMap = oldKeyMap }()
+	keyMap = mustKeyMap(map[string]string{ActionNextView: "l", ActionPrevView: "h", ActionQuit: "Q"})
+
+	m := NewModel("auto", 7000)
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")})
+	m = updated.(Model)
+	if m.view != ViewHistory {
+		t.Errorf("view after l = %d, expected ViewHistory", m.view)
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")})
+	m = updated.(Model)
+	if m.view != ViewSession {
+		t.Errorf("view after h = %d, expected ViewSession", m.view)
+	}
+
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}); cmd != nil {
+		t.Errorf("q still quits after remapping quit to Q")
+	}
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC}); cmd == nil {
+		t.Errorf("ctrl+c does not quit")
+	}
+
+	footer := string(stripANSI([]byte(display.RenderFooter(ViewSession, false, nil))))
+	if !strings.Contains(footer, "[l] next") || !strings.Contains(footer, "[Q] quit") {
+		t.Errorf("RenderFooter() = %q, expected the remapped keys", footer)
+	}
+}
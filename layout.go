@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// LayoutData is the data available to a --format-file template
+type LayoutData struct {
+	Session    *Session
+	Tokens     TokenMetrics
+	Time       TimeMetrics
+	Plan       string // Resolved plan, e.g. max5 for auto
+	Status     string
+	Confidence Confidence
+	Now        time.Time
+}
+
+// LoadLayout replaces the built-in session view with a template file
+func (d *Display) LoadLayout(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	layout, err := template.New(filepath.Base(path)).Funcs(d.layoutFuncs()).Parse(string(data))
+	if err != nil {
+		return fmt.Errorf("invalid layout: %w", err)
+	}
+	d.layout = layout
+	return nil
+}
+
+// renderLayout executes the layout template for a session
+func (d *Display) renderLayout(session *Session, estimator *TokenLimitEstimator, plan string) string {
+	data := LayoutData{
+		Session:    session,
+		Tokens:     session.Metrics.Tokens,
+		Time:       session.Metrics.Time,
+		Plan:       estimator.GetActualPlan(plan, session.AllBlocks),
+		Status:     session.GetStatus(),
+		Confidence: limitConfidence(session.AllBlocks),
+		Now:        d.config.CurrentTime,
+	}
+
+	var buffer strings.Builder
+	if err := d.layout.Execute(&buffer, data); err != nil {
+		return dangerString("layout error: %v", err) + "\n"
+	}
+	return buffer.String()
+}
+
+// layoutFuncs returns the helper functions available to layout templates
+func (d *Display) layoutFuncs() template.FuncMap {
+	return template.FuncMap{
+		"red":    dangerString,
+		"green":  okString,
+		"yellow": warningString,
+		"cyan":   infoString,
+		"gray":   mutedString,
+		"bold":   color.New(color.Bold).Sprint,
+		// statusColor colors text like the status, e.g. {{statusColor .Status .Status}}
+		"statusColor": func(status, text string) string { return colorizeStatus(status, "%s", text) },
+		// usageColor colors text by token percentage, like the token bar
+		"usageColor": func(percentage float64, text string) string {
+			switch d.alertLevels().Color(percentage) {
+			case "red":
+				return dangerString("%s", text)
+			case "yellow":
+				return warningString("%s", text)
+			}
+			return okString("%s", text)
+		},
+		"bar":      func(percentage float64) string { return d.createProgressBar(percentage, false, "") },
+		"timeBar":  func(percentage float64) string { return d.createProgressBar(percentage, true, "") },
+		"number":   formatNumber,
+		"cost":     formatCost,
+		"duration": formatTime,
+		"clock":    func(t time.Time) string { return t.In(d.timezone).Format(TimeFormatShort) },
+		"percent":  func(percentage float64) string { return fmt.Sprintf("%.1f%%", percentage) },
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDisplayLayout(t *testing.T) {
+	oldConfig, oldEstimator := config, estimator
+	defer func() { config, estimator = oldConfig, oldEstimator }()
+	config = NewConfig()
+	estimator = NewTokenLimitEstimator()
+
+	path := filepath.Join(t.TempDir(), "layout.tmpl")
+	layout := `{{number .Tokens.Used}}/{{number .Tokens.Limit}} {{percent .Tokens.Percentage}}` +
+		`{{if gt .Tokens.Percentage 50.0}} {{red "high"}}{{end}} resets {{clock .Session.EndTime}} {{.Status}}`
+	if err := os.WriteFile(path, []byte(layout), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewDisplay("UTC")
+	if err := d.LoadLayout(path); err != nil {
+		t.Fatalf("LoadLayout() error = %v", err)
+	}
+
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	output := d.Render(newTestSession(start, 6000, 10000), estimator, "pro")
+	if expected := "6,000/10,000 60.0% high resets 14:00 OK"; output != expected {
+		t.Errorf("Render() = %q, expected %q", output, expected)
+	}
+}
+
+func TestDisplayLayoutErrors(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.tmpl")
+	if err := os.WriteFile(invalid, []byte("{{if .Status}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewDisplay("UTC")
+	if err := d.LoadLayout(invalid); err == nil {
+		t.Error("LoadLayout(unterminated if) succeeded, expected an error")
+	}
+	if err := d.LoadLayout(filepath.Join(dir, "missing.tmpl")); err == nil {
+		t.Error("LoadLayout(missing) succeeded, expected an error")
+	}
+	if d.layout != nil {
+		t.Error("failed LoadLayout() replaced the layout")
+	}
+}
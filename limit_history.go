@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// LimitChange records a recomputed token limit and the inputs it was estimated from
+type LimitChange struct {
+	Time         time.Time `json:"time"`
+	Limit        int       `json:"limit"`
+	Plan         string    `json:"plan"`
+	Method       string    `json:"method"`
+	Sessions     int       `json:"sessions"`        // Completed sessions in the history
+	TokensPerMsg int       `json:"tokensPerMsg"`    // From the reference session
+	Reference    int       `json:"referenceTokens"` // Tokens of the reference session
+	ColdStart    bool      `json:"coldStart,omitempty"`
+	Coarse       bool      `json:"coarse,omitempty"`    // Tokens/msg from entry counts, without JSONL logs
+	LimitHits    int       `json:"limitHits,omitempty"` // Observed limit hits pulling the estimate
+}
+
+// LimitLog keeps every change of the estimated limit, so `cctop limit-history`
+// can show whether the learning converges
+type LimitLog struct {
+	changes []LimitChange
+	path    string
+	mu      sync.Mutex
+}
+
+var limitHistoryWeeks int
+
+// defaultLimitLogPath returns the limit changelog next to the estimator state
+func defaultLimitLogPath() string {
+	stateDir := cctopStateDir()
+	if stateDir == "" {
+		return ""
+	}
+	return filepath.Join(stateDir, "limits.json")
+}
+
+// NewLimitLog creates a limit changelog backed by path; a missing or corrupt file starts empty
+func NewLimitLog(path string) *LimitLog {
+	log := &LimitLog{path: path}
+	log.load()
+	return log
+}
+
+// load reads the changes from disk; a missing or corrupt file leaves none
+func (l *LimitLog) load() {
+	l.changes = nil
+	if l.path == "" {
+		return
+	}
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return
+	}
+	if err := json.Unmarshal(data, &l.changes); err != nil {
+		l.changes = nil
+	}
+}
+
+// Record appends the change when the limit, plan or method differs from the last one.
+// It reports whether the change was recorded.
+func (l *LimitLog) Record(change LimitChange) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Other monitors and the daemon append to the same file
+	if l.path != "" {
+		if unlock, err := lockStateFile(l.path); err == nil {
+			defer unlock()
+		}
+		l.load()
+	}
+
+	if len(l.changes) > 0 {
+		last := l.changes[len(l.changes)-1]
+		if last.Limit == change.Limit && last.Plan == change.Plan && last.Method == change.Method {
+			return false
+		}
+	}
+
+	l.changes = append(l.changes, change)
+	if len(l.changes) > MaxLimitChanges {
+		l.changes = l.changes[len(l.changes)-MaxLimitChanges:]
+	}
+	_ = l.save()
+	return true
+}
+
+// Changes returns the recorded changes, oldest first
+func (l *LimitLog) Changes() []LimitChange {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]LimitChange(nil), l.changes...)
+}
+
+// save writes the changes to disk
+func (l *LimitLog) save() error {
+	if l.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(l.changes, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(l.path, data, 0o600)
+}
+
+// newLimitChange describes a limit just estimated from blocks
+func newLimitChange(e *TokenLimitEstimator, plan string, blocks []Block, limit int, currentTime time.Time) LimitChange {
+	info := e.GetEstimationInfo()
+	change := LimitChange{
+		Time:         currentTime,
+		Limit:        limit,
+		Plan:         e.GetActualPlan(plan, blocks),
+		Method:       e.GetEstimationMethod(),
+		Sessions:     len(completedBlocks(blocks)),
+		TokensPerMsg: info.TokensPerMsg,
+		Reference:    info.TotalTokens,
+		ColdStart:    info.ColdStart,
+		Coarse:       info.Coarse,
+	}
+	if e.state != nil {
+		change.LimitHits = len(e.state.LimitHits)
+	}
+	return change
+}
+
+// recordLimit adds a freshly estimated limit to the changelog
+func recordLimit(plan string, blocks []Block, limit int) {
+	if limitLog == nil || limit <= 0 {
+		return
+	}
+	change := newLimitChange(estimator, plan, blocks, limit, time.Now())
+	if limitLog.Record(change) {
+		logger.Infof("estimated limit %s (%s, %d sessions)", formatNumber(limit), change.Method, change.Sessions)
+	}
+}
+
+// dailyLimits returns the limit in effect at the end of each of the last days, oldest
+// first, with 0 for days before the first recorded change
+func dailyLimits(changes []LimitChange, currentTime time.Time, days int) []int {
+	limits := make([]int, days)
+	for i := range limits {
+		dayEnd := currentTime.AddDate(0, 0, i-days+1)
+		for _, change := range changes {
+			if change.Time.After(dayEnd) {
+				break
+			}
+			limits[i] = change.Limit
+		}
+	}
+	return limits
+}
+
+// limitTrend summarizes whether the estimate settled over the last week:
+// oscillating (changed direction repeatedly), converging (moved less than the
+// week before), stable (moved less than LimitStableSpread) or still moving
+func limitTrend(changes []LimitChange, currentTime time.Time) string {
+	weekAgo := currentTime.Add(-7 * 24 * time.Hour)
+	recent := limitsSince(changes, weekAgo, currentTime)
+	if len(recent) < 2 {
+		return "stable"
+	}
+	if limitReversals(recent) >= LimitOscillationReversals {
+		return "oscillating"
+	}
+
+	spread := limitSpread(recent)
+	if previous := limitsSince(changes, weekAgo.Add(-7*24*time.Hour), weekAgo); len(previous) >= 2 && spread < limitSpread(previous) {
+		return "converging"
+	}
+	if spread <= LimitStableSpread {
+		return "stable"
+	}
+	return "still moving"
+}
+
+// limitsSince returns the limits in effect from start until end, including the one
+// already in effect at start
+func limitsSince(changes []LimitChange, start, end time.Time) []int {
+	var limits []int
+	for i, change := range changes {
+		if change.Time.After(end) {
+			break
+		}
+		next := i + 1
+		if change.Time.Before(start) && next < len(changes) && !changes[next].Time.After(start) {
+			continue
+		}
+		limits = append(limits, change.Limit)
+	}
+	return limits
+}
+
+// limitReversals counts how often consecutive changes switched between rising and falling
+func limitReversals(limits []int) int {
+	reversals, direction := 0, 0
+	for i := 1; i < len(limits); i++ {
+		step := limits[i] - limits[i-1]
+		if step == 0 {
+			continue
+		}
+		current := 1
+		if step < 0 {
+			current = -1
+		}
+		if direction != 0 && current != direction {
+			reversals++
+		}
+		direction = current
+	}
+	return reversals
+}
+
+// limitSpread returns the range of limits relative to their mean, in percent
+func limitSpread(limits []int) float64 {
+	if len(limits) == 0 {
+		return 0
+	}
+	lowest, highest, total := limits[0], limits[0], 0
+	for _, limit := range limits {
+		lowest = min(lowest, limit)
+		highest = max(highest, limit)
+		total += limit
+	}
+	mean := float64(total) / float64(len(limits))
+	if mean == 0 {
+		return 0
+	}
+	return math.Round(float64(highest-lowest)/mean*1000) / 10
+}
+
+// runLimitHistory prints how the estimated limit evolved
+func runLimitHistory(cmd *cobra.Command, args []string) {
+	if limitHistoryWeeks < 1 {
+		fmt.Fprintf(os.Stderr, "--weeks %d: must be at least 1\n", limitHistoryWeeks)
+		os.Exit(1)
+	}
+	fmt.Print(display.RenderLimitHistory(limitLog.Changes(), time.Now(), limitHistoryWeeks*7))
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLimitLogRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "limits.json")
+	log := NewLimitLog(path)
+	now := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+
+	if !log.Record(LimitChange{Time: now, Limit: 100000, Plan: "max5", Method: "p40"}) {
+		t.Error("Record() = false for the first limit, expected true")
+	}
+	if log.Record(LimitChange{Time: now.Add(time.Hour), Limit: 100000, Plan: "max5", Method: "p40"}) {
+		t.Error("Record() = true for an unchanged limit, expected false")
+	}
+	if !log.Record(LimitChange{Time: now.Add(2 * time.Hour), Limit: 100000, Plan: "max5", Method: "trim10"}) {
+		t.Error("Record() = false after a method change, expected true")
+	}
+
+	reloaded := NewLimitLog(path)
+	if len(reloaded.Changes()) != 2 {
+		t.Errorf("reloaded %d changes, expected 2", len(reloaded.Changes()))
+	}
+}
+
+func TestDailyLimits(t *testing.T) {
+	now := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+	changes := []LimitChange{
+		{Time: now.AddDate(0, 0, -2), Limit: 100},
+		{Time: now.AddDate(0, 0, -1).Add(-time.Hour), Limit: 120},
+		{Time: now.AddDate(0, 0, -1).Add(time.Hour), Limit: 130},
+	}
+
+	got := dailyLimits(changes, now, 4)
+	expected := []int{0, 100, 120, 130}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("dailyLimits() = %v, expected %v", got, expected)
+			break
+		}
+	}
+}
+
+func TestLimitTrend(t *testing.T) {
+	now := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+	series := func(start time.Time, limits ...int) []LimitChange {
+		var changes []LimitChange
+		for i, limit := range limits {
+			changes = append(changes, LimitChange{Time: start.Add(time.Duration(i) * 24 * time.Hour), Limit: limit})
+		}
+		return changes
+	}
+
+	tests := []struct {
+		name     string
+		changes  []LimitChange
+		expected string
+	}{
+		{"Single estimate", series(now.AddDate(0, 0, -3), 100000), "stable"},
+		{"Up and down", series(now.AddDate(0, 0, -5), 100000, 120000, 100000, 120000), "oscillating"},
+		{"Narrowing", series(now.AddDate(0, 0, -13), 80000, 120000, 90000, 100000, 100000, 100000, 100000, 100000, 100000, 101000, 102000), "converging"},
+		{"Small drift", series(now.AddDate(0, 0, -3), 100000, 101000, 102000), "stable"},
+		{"Large drift", series(now.AddDate(0, 0, -3), 100000, 120000, 140000), "still moving"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := limitTrend(tt.changes, now); got != tt.expected {
+				t.Errorf("limitTrend() = %s, expected %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderLimitHistory(t *testing.T) {
+	d := NewDisplay("UTC")
+	now := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+	if output := d.RenderLimitHistory(nil, now, 14); !strings.Contains(output, "No estimated limits") {
+		t.Errorf("RenderLimitHistory(nil) = %q, expected no limits message", output)
+	}
+
+	changes := []LimitChange{
+		{Time: now.AddDate(0, 0, -3), Limit: 100000, Plan: "max5", Method: "p40", Sessions: 10, TokensPerMsg: 440},
+		{Time: now.AddDate(0, 0, -1), Limit: 110000, Plan: "max5", Method: "p40", Sessions: 11, TokensPerMsg: 480},
+	}
+	output := d.RenderLimitHistory(changes, now, 14)
+	for _, expected := range []string{"100,000 -> 110,000", "+10.0%", "06-19 12:00"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("RenderLimitHistory() missing %q:\n%s", expected, output)
+		}
+	}
+	if output := d.RenderLimitHistory(changes, now, 0); !strings.Contains(output, "No estimated limits") {
+		t.Errorf("RenderLimitHistory(0 days) = %q, expected no limits message", output)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// LimitTransition is a change of the token limit while the monitor runs
+type LimitTransition struct {
+	From   int       `json:"from"`
+	To     int       `json:"to"`
+	At     time.Time `json:"at"`
+	Reason string    `json:"reason"`
+}
+
+// limitState tracks re-estimates of the token limit. Refreshes run on their own
+// goroutines, so it is guarded by a mutex.
+type limitState struct {
+	estimatedAt time.Time        // When the limit was last estimated
+	checkedOver int              // Limit already re-estimated because usage exceeded it
+	transition  *LimitTransition // Most recent limit change, never modified once set
+	mu          sync.Mutex
+}
+
+var limitRefresh = &limitState{}
+
+// markEstimated records that the limit was estimated at currentTime
+func (s *limitState) markEstimated(currentTime time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.estimatedAt = currentTime
+}
+
+// LimitAt returns the limit to show at currentTime, moving from From to To over LimitAnimationDuration
+func (t LimitTransition) LimitAt(currentTime time.Time) int {
+	elapsed := currentTime.Sub(t.At)
+	if elapsed >= LimitAnimationDuration {
+		return t.To
+	}
+	if elapsed <= 0 {
+		return t.From
+	}
+	progress := float64(elapsed) / float64(LimitAnimationDuration)
+	return t.From + int(float64(t.To-t.From)*progress)
+}
+
+// Animate returns the token metrics with the limit and percentage shown at currentTime
+func (t LimitTransition) Animate(tokens TokenMetrics, currentTime time.Time) TokenMetrics {
+	tokens.Limit = t.LimitAt(currentTime)
+	if tokens.Limit > 0 {
+		tokens.Percentage = float64(tokens.Used) / float64(tokens.Limit) * 100
+	}
+	return tokens
+}
+
+// refreshTokenLimit re-estimates the limit every config.LimitRefresh, and at once when
+// usage exceeds it for any plan, so plan upgrades and revised estimates apply without a
+// restart. Exceeding the limit only ever raises it, switching to the detected plan if needed.
+// A --token-limit is never re-estimated, and the API plan has no limit.
+func refreshTokenLimit(plan string, blocks []Block, used, limit int, currentTime time.Time) int {
+	if config.TokenLimit > 0 || config.APIPlan() {
+		return limit
+	}
+	s := limitRefresh
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	exceeded := config.ShouldAutoSwitch(plan, used, limit) && limit != s.checkedOver
+	due := config.LimitRefresh > 0 && currentTime.Sub(s.estimatedAt) >= config.LimitRefresh
+	if !exceeded && !due {
+		return limit
+	}
+	s.estimatedAt = currentTime
+
+	newLimit := estimator.EstimateLimit(plan, blocks)
+	estimatedPlan := plan
+	reason := "re-estimated"
+	if exceeded {
+		s.checkedOver = limit
+		if auto := estimator.EstimateLimit("auto", blocks); auto > newLimit {
+			newLimit, estimatedPlan = auto, "auto"
+		}
+		if newLimit <= limit {
+			return limit
+		}
+		reason = fmt.Sprintf("usage exceeded %s", formatNumber(limit))
+	}
+	if newLimit <= 0 || newLimit == limit {
+		return limit
+	}
+
+	recordLimit(estimatedPlan, blocks, newLimit)
+	s.transition = &LimitTransition{From: limit, To: newLimit, At: currentTime, Reason: reason}
+	logger.Infof("token limit %s -> %s (%s)", formatNumber(limit), formatNumber(newLimit), reason)
+	return newLimit
+}
+
+// recentLimitTransition returns the last limit change if it is recent enough to highlight
+func recentLimitTransition(currentTime time.Time) *LimitTransition {
+	limitRefresh.mu.Lock()
+	defer limitRefresh.mu.Unlock()
+	transition := limitRefresh.transition
+	if transition == nil || currentTime.Sub(transition.At) >= LimitChangeHighlight {
+		return nil
+	}
+	return transition
+}
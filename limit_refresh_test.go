@@ -0,0 +1,145 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLimitTransitionAnimates(t *testing.T) {
+	at := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+	change := LimitTransition{From: 7000, To: 35000, At: at}
+
+	tests := []struct {
+		offset   time.Duration
+		expected int
+	}{
+		{-time.Second, 7000},
+		{0, 7000},
+		{LimitAnimationDuration / 2, 21000},
+		{LimitAnimationDuration, 35000},
+		{time.Minute, 35000},
+	}
+	for _, tt := range tests {
+		if got := change.LimitAt(at.Add(tt.offset)); got != tt.expected {
+			t.Errorf("LimitAt(+%v) = %d, expected %d", tt.offset, got, tt.expected)
+		}
+	}
+
+	tokens := change.Animate(TokenMetrics{Used: 7000, Limit: 35000, Percentage: 20}, at)
+	if tokens.Limit != 7000 || tokens.Percentage != 100 {
+		t.Errorf("Animate() at the change = %+v, expected the old limit at 100%%", tokens)
+	}
+}
+
+func TestRefreshTokenLimit(t *testing.T) {
+	oldConfig, oldEstimator := config, estimator
+	oldLimitRefresh := limitRefresh
+	defer func() {
+		config, estimator = oldConfig, oldEstimator
+		limitRefresh = oldLimitRefresh
+	}()
+	config = NewConfig()
+	estimator = NewTokenLimitEstimator()
+	now := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+	limitRefresh = &limitState{estimatedAt: now.Add(-time.Minute)}
+
+	// Neither due nor exceeded: the limit is kept without estimating
+	if got := refreshTokenLimit("max5", nil, 0, 1, now); got != 1 || limitRefresh.transition != nil {
+		t.Errorf("refreshTokenLimit() before the interval = %d, expected the current limit 1", got)
+	}
+
+	// Due: the estimate replaces the stale limit and the change is recorded
+	got := refreshTokenLimit("max5", nil, 0, 1, now.Add(config.LimitRefresh))
+	if got <= 1 || limitRefresh.transition == nil || limitRefresh.transition.From != 1 || limitRefresh.transition.To != got {
+		t.Errorf("refreshTokenLimit() after the interval = %d with change %+v, expected a new limit", got, limitRefresh.transition)
+	}
+	if recent := recentLimitTransition(now.Add(config.LimitRefresh + LimitChangeHighlight)); recent != nil {
+		t.Errorf("recentLimitTransition() after the highlight = %+v, expected nil", recent)
+	}
+
+	// Exceeded but already re-estimated for this limit: not estimated again every refresh
+	limitRefresh.checkedOver = 50
+	limitRefresh.transition = nil
+	if got := refreshTokenLimit("max5", nil, 100, 50, now.Add(config.LimitRefresh+time.Minute)); got != 50 || limitRefresh.transition != nil {
+		t.Errorf("refreshTokenLimit() for a checked limit = %d, expected 50", got)
+	}
+}
+
+func TestManualTokenLimit(t *testing.T) {
+	oldConfig, oldEstimator := config, estimator
+	oldLimitRefresh := limitRefresh
+	defer func() {
+		config, estimator = oldConfig, oldEstimator
+		limitRefresh = oldLimitRefresh
+	}()
+	config = NewConfig()
+	config.TokenLimit = 250000
+	estimator = NewTokenLimitEstimator()
+	limitRefresh = &limitState{}
+	now := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+
+	// Neither an overdue refresh nor usage over the limit re-estimates it
+	if got := refreshTokenLimit("pro", nil, 300000, 250000, now.Add(24*time.Hour)); got != 250000 || limitRefresh.transition != nil {
+		t.Errorf("refreshTokenLimit() with --token-limit = %d, expected 250000", got)
+	}
+
+	// Without completed sessions the manual limit is still trusted
+	blocks := []Block{{StartTime: now.Format(time.RFC3339), TotalTokens: 1000, IsActive: true}}
+	if got := limitConfidence(blocks); got.Level != ConfidenceManual {
+		t.Errorf("limitConfidence() with --token-limit = %+v, expected %s", got, ConfidenceManual)
+	}
+	var buffer strings.Builder
+	NewDisplay("UTC").renderConfidence(&buffer, 250000, limitConfidence(blocks))
+	if got := string(stripANSI([]byte(buffer.String()))); !strings.Contains(got, "limit 250,000 · manual limit") {
+		t.Errorf("renderConfidence() with --token-limit = %q, expected a manual limit note", got)
+	}
+
+	// History and the blocks timeline use it as well
+	if got := tokenLimitOf("pro", blocks); got != 250000 {
+		t.Errorf("tokenLimitOf() with --token-limit = %d, expected 250000", got)
+	}
+	config.TokenLimit = 0
+	config.Plan = PlanAPI
+	if got := tokenLimitOf(PlanAPI, blocks); got != 0 {
+		t.Errorf("tokenLimitOf() of the API plan = %d, expected no limit", got)
+	}
+}
+
+func TestShouldAutoSwitch(t *testing.T) {
+	cfg := NewConfig()
+	tests := []struct {
+		plan     string
+		used     int
+		limit    int
+		expected bool
+	}{
+		{"pro", 8000, 50000, true},
+		{"max5", 40000, 35000, true},
+		{"auto", 1000, 35000, false},
+		{"max20", 1000, 0, false},
+	}
+	for _, tt := range tests {
+		if got := cfg.ShouldAutoSwitch(tt.plan, tt.used, tt.limit); got != tt.expected {
+			t.Errorf("ShouldAutoSwitch(%s, %d, %d) = %v, expected %v", tt.plan, tt.used, tt.limit, got, tt.expected)
+		}
+	}
+}
+
+func TestRenderLimitChange(t *testing.T) {
+	tests := []struct {
+		change   LimitTransition
+		expected string
+	}{
+		{LimitTransition{From: 100000, To: 120000, Reason: "re-estimated"}, "Limit   ↑ 100,000 → 120,000 (re-estimated)\n"},
+		{LimitTransition{From: 120000, To: 90000, Reason: "re-estimated"}, "Limit   ↓ 120,000 → 90,000 (re-estimated)\n"},
+	}
+
+	for _, tt := range tests {
+		var buffer strings.Builder
+		NewDisplay("UTC").renderLimitChange(&buffer, tt.change)
+		if got := string(stripANSI([]byte(buffer.String()))); got != tt.expected {
+			t.Errorf("renderLimitChange(%+v) = %q, expected %q", tt.change, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogLevel is the severity of a log message
+type LogLevel int
+
+// Log levels, from least to most verbose
+const (
+	LogOff LogLevel = iota
+	LogError
+	LogWarn
+	LogInfo
+	LogDebug
+	LogTrace
+)
+
+// logLevelNames maps levels to their names
+var logLevelNames = []string{"off", "error", "warn", "info", "debug", "trace"}
+
+// String returns the level name
+func (l LogLevel) String() string {
+	if l < 0 || int(l) >= len(logLevelNames) {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return logLevelNames[l]
+}
+
+// parseLogLevel parses a level name
+func parseLogLevel(name string) (LogLevel, error) {
+	for i, levelName := range logLevelNames {
+		if strings.EqualFold(name, levelName) {
+			return LogLevel(i), nil
+		}
+	}
+	return LogOff, fmt.Errorf("unknown log level %q (%s)", name, strings.Join(logLevelNames, ", "))
+}
+
+// LogConfig holds log file configuration
+type LogConfig struct {
+	Level   string
+	Debug   bool // Log at least at debug level, whatever Level says
+	File    string
+	MaxSize int64 // Bytes before the file is rotated
+	Backups int   // Rotated files kept (file.1 ... file.N)
+}
+
+// Logger writes leveled messages to a size-rotated file. A nil Logger discards everything.
+type Logger struct {
+	mu      sync.Mutex
+	level   LogLevel
+	path    string
+	maxSize int64
+	backups int
+	file    *os.File
+	size    int64
+}
+
+// NewLogger creates a logger; the file is opened on the first message
+func NewLogger(cfg LogConfig) (*Logger, error) {
+	level, err := parseLogLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Debug {
+		level = max(level, LogDebug)
+	}
+	if cfg.File == "" {
+		return nil, fmt.Errorf("no log file")
+	}
+	return &Logger{level: level, path: cfg.File, maxSize: cfg.MaxSize, backups: cfg.Backups}, nil
+}
+
+// defaultLogPath returns the log file in the cctop state directory
+func defaultLogPath() string {
+	stateDir := cctopStateDir()
+	if stateDir == "" {
+		return ""
+	}
+	return filepath.Join(stateDir, "cctop.log")
+}
+
+// Errorf logs at error level
+func (l *Logger) Errorf(format string, args ...interface{}) { l.logf(LogError, format, args...) }
+
+// Warnf logs at warn level
+func (l *Logger) Warnf(format string, args ...interface{}) { l.logf(LogWarn, format, args...) }
+
+// Infof logs at info level
+func (l *Logger) Infof(format string, args ...interface{}) { l.logf(LogInfo, format, args...) }
+
+// Debugf logs at debug level
+func (l *Logger) Debugf(format string, args ...interface{}) { l.logf(LogDebug, format, args...) }
+
+// Tracef logs at trace level
+func (l *Logger) Tracef(format string, args ...interface{}) { l.logf(LogTrace, format, args...) }
+
+// Enabled reports whether messages at level are written
+func (l *Logger) Enabled(level LogLevel) bool {
+	return l != nil && level <= l.level && level != LogOff
+}
+
+// logf writes a message when the level is enabled; write failures are ignored
+func (l *Logger) logf(level LogLevel, format string, args ...interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+	line := fmt.Sprintf("%s %-5s %s\n", time.Now().Format(time.RFC3339), strings.ToUpper(level.String()), fmt.Sprintf(format, args...))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.maxSize > 0 && l.size+int64(len(line)) > l.maxSize {
+		l.rotate()
+	}
+	if l.file == nil && l.open() != nil {
+		return
+	}
+	n, _ := l.file.WriteString(line)
+	l.size += int64(n)
+}
+
+// open opens the log file for appending
+func (l *Logger) open() error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	l.file = file
+	l.size = info.Size()
+	return nil
+}
+
+// rotate shifts file -> file.1 -> ... -> file.N, dropping the oldest
+func (l *Logger) rotate() {
+	if l.file != nil {
+		l.file.Close()
+		l.file = nil
+	}
+	l.size = 0
+
+	if l.backups <= 0 {
+		_ = os.Remove(l.path)
+		return
+	}
+	for i := l.backups - 1; i >= 1; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", l.path, i), fmt.Sprintf("%s.%d", l.path, i+1))
+	}
+	_ = os.Rename(l.path, l.path+".1")
+}
+
+// Close closes the log file
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	tests := []struct {
+		name      string
+		expected  LogLevel
+		expectErr bool
+	}{
+		{name: "off", expected: LogOff},
+		{name: "WARN", expected: LogWarn},
+		{name: "trace", expected: LogTrace},
+		{name: "verbose", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseLogLevel(tt.name)
+			if (err != nil) != tt.expectErr || result != tt.expected {
+				t.Errorf("parseLogLevel(%s) = %v, %v, expected %v", tt.name, result, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoggerLevelsAndRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "cctop.log")
+	l, err := NewLogger(LogConfig{Level: "info", File: path, MaxSize: 200, Backups: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	l.Debugf("hidden")
+	l.Infof("first %d", 1)
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "hidden") || !strings.Contains(string(data), "INFO  first 1") {
+		t.Errorf("log = %q, expected only the info message", data)
+	}
+
+	// Each line is about 50 bytes, so 20 lines rotate several times
+	for i := 0; i < 20; i++ {
+		l.Warnf("message %02d", i)
+	}
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("expected %s to exist: %v", name, err)
+		}
+		if info.Size() > 200 {
+			t.Errorf("%s size = %d, expected at most 200", name, info.Size())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 backups, found %s.3", path)
+	}
+
+	lines, err := tailLines(path, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 1 || !strings.HasSuffix(lines[0], "message 19") {
+		t.Errorf("tailLines() = %q, expected the last message", lines)
+	}
+}
+
+func TestNilLogger(t *testing.T) {
+	var l *Logger
+	l.Errorf("ignored")
+	if l.Enabled(LogError) {
+		t.Error("nil logger should not be enabled")
+	}
+}
+
+func TestLoggerDebug(t *testing.T) {
+	tests := []struct {
+		level    string
+		expected LogLevel
+	}{
+		{"off", LogDebug},
+		{"warn", LogDebug},
+		{"trace", LogTrace}, // --debug does not make a more verbose level quieter
+	}
+
+	for _, tt := range tests {
+		l, err := NewLogger(LogConfig{Level: tt.level, Debug: true, File: filepath.Join(t.TempDir(), "cctop.log")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !l.Enabled(tt.expected) || l.Enabled(tt.expected+1) {
+			t.Errorf("--debug with level %s logs up to %v, expected %v", tt.level, l.level, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	logsLines  int
+	logsFollow bool
+)
+
+// runLogsTail prints the end of the log file, optionally following new lines
+func runLogsTail(cmd *cobra.Command, args []string) {
+	path := config.Log.File
+	lines, err := tailLines(path, logsLines)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	if logsFollow {
+		if err := followFile(path, os.Stdout); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+}
+
+// tailLines returns the last n lines of a file
+func tailLines(path string, n int) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lines := make([]string, 0, n)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if n <= 0 {
+			continue
+		}
+		if len(lines) == n {
+			lines = lines[1:]
+		}
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// followFile copies data appended to path to w until interrupted, reopening the file after rotation
+func followFile(path string, w io.Writer) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	offset, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	for {
+		time.Sleep(LogFollowInterval)
+
+		if info, err := os.Stat(path); err == nil && info.Size() < offset {
+			// Rotated: continue from the start of the new file
+			file.Close()
+			if file, err = os.Open(path); err != nil {
+				return err
+			}
+			offset = 0
+		}
+
+		n, err := io.Copy(w, file)
+		if err != nil {
+			file.Close()
+			return err
+		}
+		offset += n
+	}
+}
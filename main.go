@@ -4,30 +4,30 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
-	"os/signal"
 	"strings"
-	"syscall"
 	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // Moved constants to constants.go
 
 // TokenMetrics holds calculated token usage information
 type TokenMetrics struct {
-	Used       int
-	Limit      int
-	Percentage float64
-	Remaining  int
+	Used       int     `json:"used"`
+	Limit      int     `json:"limit"`
+	Percentage float64 `json:"percentage"`
+	Remaining  int     `json:"remaining"`
 }
 
 // TimeMetrics holds calculated time information
 type TimeMetrics struct {
-	SessionEndTime     time.Time
-	MinutesRemaining   float64
-	ProgressPercentage float64
+	SessionEndTime     time.Time `json:"sessionEndTime"`
+	MinutesRemaining   float64   `json:"minutesRemaining"`
+	ProgressPercentage float64   `json:"progressPercentage"`
 }
 
 // DisplayConfig holds display configuration
@@ -35,17 +35,22 @@ type DisplayConfig struct {
 	CurrentTime time.Time
 	Timezone    *time.Location
 	BurnRate    float64
+	Alerts      *AlertLevels // Configured alert levels of the session, nil for the bar color defaults
 }
 
 // Block represents a usage block from ccusage
 type Block struct {
-	StartTime     string   `json:"startTime"`
-	ActualEndTime string   `json:"actualEndTime"`
-	Models        []string `json:"models"`
-	TotalTokens   int      `json:"totalTokens"`
-	Entries       int      `json:"entries"`
-	IsActive      bool     `json:"isActive"`
-	IsGap         bool     `json:"isGap"`
+	ID            string      `json:"id"`
+	StartTime     string      `json:"startTime"`
+	EndTime       string      `json:"endTime"` // End of the 5-hour window, or of a gap
+	ActualEndTime string      `json:"actualEndTime"`
+	Models        []string    `json:"models"`
+	TotalTokens   int         `json:"totalTokens"`
+	TokenCounts   TokenCounts `json:"tokenCounts"`
+	CostUSD       float64     `json:"costUSD"`
+	Entries       int         `json:"entries"`
+	IsActive      bool        `json:"isActive"`
+	IsGap         bool        `json:"isGap"`
 }
 
 // CCUsageData represents the JSON response from ccusage
@@ -55,8 +60,9 @@ type CCUsageData struct {
 
 // DailyUsage represents daily usage data from ccusage
 type DailyUsage struct {
-	Date      string  `json:"date"`
-	TotalCost float64 `json:"totalCost"`
+	Date        string  `json:"date"`
+	TotalTokens int     `json:"totalTokens"`
+	TotalCost   float64 `json:"totalCost"`
 }
 
 // SessionData represents session data from ccusage session command
@@ -87,10 +93,21 @@ type ModelBreakdown struct {
 // Moved to session.go and display.go
 
 var (
-	config    *Config
-	estimator *TokenLimitEstimator
-	display   *Display
-	burnCalc  *BurnRateCalculator
+	config       *Config
+	estimator    *TokenLimitEstimator
+	display      *Display
+	burnCalc     *BurnRateCalculator
+	currency     *CurrencyConverter
+	notifier     *Notifier
+	breaks       *BreakReminder
+	bell         *Bell
+	rules        *RulesEngine
+	hooks        *Hooks
+	eventLog     *EventLog
+	limitLog     *LimitLog
+	usageHistory *UsageHistory
+	systemLog    *SystemLog
+	logger       *Logger
 )
 
 var rootCmd = &cobra.Command{
@@ -104,18 +121,596 @@ var estimationMethod string
 
 func init() {
 	config = NewConfig()
+	cobra.OnInitialize(func() {
+		// Applied first so every setting below sees values from the file
+		configFile = loadConfigFile(configPath, lookupFlag)
+		var err error
+		if numbers, err = newNumberFormat(numberFormatStyle(config), config.ThousandsSeparator); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		// Built after flag parsing so --currency and --currency-rate apply
+		if err := validCurrency(config.Currency); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		currency = NewCurrencyConverter(config.Currency, config.CurrencyRate)
+		if config.Log.Level != "off" || config.Log.Debug {
+			if logger, err = NewLogger(config.Log); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		}
+		if colorDisabled() {
+			color.NoColor = true
+		}
+		if lang, err = resolveLanguage(config.Lang); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if keyMap, err = NewKeyMap(config.Keys); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if err := applyTheme(config.Theme, config.Colors); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if config.FormatFile != "" {
+			if err := display.LoadLayout(config.FormatFile); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		}
+		for _, problem := range configFile.Problems {
+			logger.Warnf("config file %s: %s", configFile.Path, problem)
+		}
+		if replayPath != "" {
+			// Before anything reads state or logs, so all of it comes from the sandbox
+			if replayer, err = startReplay(replayPath, replaySpeed); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		}
+		estimator.LoadState(defaultEstimatorStatePath())
+		eventLog = NewEventLog(defaultEventLogPath())
+		if config.Archive {
+			if archive, err = NewArchive(config.ArchivePath); err != nil {
+				fmt.Fprintf(os.Stderr, "--archive: %v\n", err)
+				exit(1)
+			}
+		}
+		limitLog = NewLimitLog(defaultLimitLogPath())
+		config.Profiles = parseProfiles(config.ClaudeDirs)
+		if remotes, err := loadRemotes(defaultRemotesPath()); err != nil {
+			logger.Warnf("remotes ignored: %v", err)
+		} else {
+			config.Profiles = withRemoteProfiles(config.Profiles, remotes, defaultRemotesPath(), defaultClaudeDirs())
+		}
+		if err := validProfile(config.Profile, config.Profiles); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit(1)
+		}
+		burnCalc.SetWindow(config.BurnWindow)
+		if config.MessageBurnRate {
+			burnCalc.UseMessages(NewMessageTokenReader())
+		}
+		hookActions, err := parseHooks(config.HookSpecs)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit(1)
+		}
+		for _, name := range config.Experimental {
+			if err := validExperiment(name); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				exit(1)
+			}
+		}
+		if _, err := ParseEstimator(estimationMethod); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit(1)
+		}
+		if config.ModelWeights, err = parseModelWeights(config.ModelWeightSpecs); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit(1)
+		}
+		if config.APIPlan() {
+			config.WeeklyBar = false // The API has no weekly token limit
+		}
+		if config.TokenLimit < 0 {
+			fmt.Fprintln(os.Stderr, "--token-limit must not be negative")
+			exit(1)
+		}
+		if config.TokenLimit > 0 && config.NoLimit {
+			fmt.Fprintln(os.Stderr, "--token-limit and --no-limit cannot be combined")
+			exit(1)
+		}
+		// A zero interval would refresh in a busy loop
+		for _, name := range []string{"interval", "frame-interval", "idle-interval", "daily-interval", "burn-half-life", "burn-window"} {
+			if err := positiveDuration(lookupFlag(name).Value.String()); err != nil {
+				fmt.Fprintf(os.Stderr, "--%s %v\n", name, err)
+				exit(1)
+			}
+		}
+		if config.AlertThresholds, err = parseAlertThresholds(config.AlertSpecs); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit(1)
+		}
+		if config.TeamShares, err = parseTeamShares(config.TeamShareSpecs, config.Profiles); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit(1)
+		}
+		config.Hooks = hookActions
+		hooks = NewHooks(hookActions)
+		if config.Issue.ID != "" && config.Issue.WebhookURL != "" {
+			hooks.Add(EventSessionEnd, inBackground(NewIssueCommentAction(config.Issue)))
+		}
+		if config.ChatWebhook.URL != "" {
+			hooks.Add(EventSessionEnd, inBackground(NewChatWebhookAction(config.ChatWebhook.URL, 0)))
+		}
+		if config.TimeTracker.Service != "" {
+			if config.Tracker, err = NewTimeTracker(config.TimeTracker); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				exit(1)
+			}
+		}
+		if config.SystemLog != "" {
+			if systemLog, err = NewSystemLog(config.SystemLog); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				exit(1)
+			}
+		}
+		if config.Notify.Enabled {
+			notifier = NewNotifier(config.Notify)
+			if systemLog != nil {
+				notifier.OnAlert(func(message string, session *Session) {
+					_ = systemLog.LogAlert(message, session)
+				})
+			}
+		}
+		rules = NewRulesEngine(buildRules(config)...)
+		if config.Breaks.Enabled {
+			breaks = NewBreakReminder(config.Breaks, config.Notify.Enabled)
+		}
+		if config.Bell.Enabled {
+			bell = NewBell(config.Bell)
+		}
+	})
 
-	rootCmd.Flags().StringVar(&config.Plan, "plan", config.Plan, "Claude plan type (auto, pro, max5, max20)")
-	rootCmd.Flags().StringVar(&config.Timezone, "timezone", config.Timezone, "Timezone for display")
-	rootCmd.Flags().StringVar(&estimationMethod, "est", "p40", "Estimation method (see 'cctop list-est' for all options)")
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", defaultConfigPath(), "JSON config file of flag names to values")
+	rootCmd.PersistentFlags().StringVar(&config.Plan, "plan", config.Plan, "Claude plan type (auto, pro, max5, max20, or api for pay-as-you-go spend against --budget)")
+	rootCmd.PersistentFlags().StringVar(&config.Timezone, "timezone", config.Timezone, "Timezone for display")
+	rootCmd.PersistentFlags().StringVar(&estimationMethod, "estimator", DefaultEstimationMethod, "Estimation strategy (see 'cctop list-est' for all options)")
+	rootCmd.PersistentFlags().StringVar(&estimationMethod, "est", DefaultEstimationMethod, "Alias for --estimator")
+	rootCmd.Flags().IntVar(&config.BillingAnchorDay, "billing-day", config.BillingAnchorDay, "Day of month your subscription renews (1-31)")
+	rootCmd.PersistentFlags().StringVar(&config.Currency, "currency", config.Currency, "Currency code for displayed costs (e.g. EUR, JPY)")
+	rootCmd.PersistentFlags().Float64Var(&config.CurrencyRate, "currency-rate", config.CurrencyRate, "Static exchange rate per USD (default: fetch daily ECB rates)")
+	rootCmd.PersistentFlags().Float64Var(&config.CostMultiplier, "cost-multiplier", config.CostMultiplier, "Multiplier applied to displayed costs for tax or markup (e.g. 1.2)")
+	rootCmd.PersistentFlags().DurationVar(&config.RemoteSync, "remote-sync", config.RemoteSync, "Interval between mirroring the logs of 'cctop remote' machines while monitoring (0: only 'cctop remote sync')")
+	rootCmd.PersistentFlags().StringArrayVar(&config.ClaudeDirs, "claude-dir", config.ClaudeDirs, "Claude config directory to monitor, as path or name=path (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&config.Profile, "profile", config.Profile, "Only monitor the named --claude-dir profile (default: aggregate all)")
+	rootCmd.PersistentFlags().DurationVar(&config.UpdateInterval, "interval", config.UpdateInterval, "Refresh interval while a session is active")
+	rootCmd.PersistentFlags().DurationVar(&config.FrameInterval, "frame-interval", config.FrameInterval, "Redraw interval of the clock and countdowns between refreshes")
+	rootCmd.PersistentFlags().BoolVar(&config.MessageBurnRate, "message-burn-rate", config.MessageBurnRate, "Compute the burn rate from message timestamps in the Claude logs (false: spread block tokens evenly)")
+	rootCmd.PersistentFlags().DurationVar(&config.BurnWindow, "burn-window", config.BurnWindow, "Window of the burn rate used for depletion predictions")
+	rootCmd.PersistentFlags().DurationSliceVar(&config.BurnWindows, "burn-windows", config.BurnWindows, "Windows whose burn rates are shown side by side, e.g. 5m,1h (empty hides them)")
+	rootCmd.PersistentFlags().DurationVar(&config.BurnHalfLife, "burn-half-life", config.BurnHalfLife, "Half-life of the smoothed burn rate shown next to the instantaneous (last 5m) rate; shorter reacts faster")
+	rootCmd.PersistentFlags().BoolVar(&config.Watch, "watch", config.Watch, "Refresh as soon as Claude logs a message, using filesystem notifications (false: poll every --interval)")
+	rootCmd.PersistentFlags().BoolVar(&config.Archive, "archive", config.Archive, "Archive every observed block and daily cost in SQLite (needs the sqlite3 command), keeping history after logs are pruned")
+	rootCmd.PersistentFlags().StringVar(&config.ArchivePath, "archive-db", config.ArchivePath, "SQLite database of the archive")
+	rootCmd.PersistentFlags().DurationVar(&config.IdleInterval, "idle-interval", config.IdleInterval, "Refresh interval when no session is active")
+	rootCmd.PersistentFlags().DurationVar(&config.DailyInterval, "daily-interval", config.DailyInterval, "Minimum time between daily cost fetches")
+	rootCmd.PersistentFlags().DurationVar(&config.LimitRefresh, "limit-refresh", config.LimitRefresh, "Re-estimate the token limit this often while monitoring (0: only when usage exceeds it)")
+	rootCmd.PersistentFlags().StringSliceVar(&config.Experimental, "experimental", config.Experimental, "Enable an in-development subsystem (see 'cctop experiments'; repeatable)")
+	rootCmd.PersistentFlags().IntVar(&config.TokenLimit, "token-limit", config.TokenLimit, "Known session token limit (e.g. API or Team plans) used instead of estimating it")
+	rootCmd.PersistentFlags().BoolVar(&config.NoLimit, "no-limit", config.NoLimit, "No token limit applies (e.g. API key accounts): show absolute usage instead of percentages")
+	rootCmd.PersistentFlags().Float64Var(&config.CacheWeight, "cache-weight", config.CacheWeight, "Weight of cache read/write tokens in JSONL based limit estimation (0 excludes them, 1 counts them fully); ccusage session totals are not weighted")
+	rootCmd.PersistentFlags().StringVar(&config.Log.Level, "log-level", config.Log.Level, "Log level (off, error, warn, info, debug, trace)")
+	rootCmd.PersistentFlags().BoolVar(&config.Log.Debug, "debug", config.Log.Debug, "Log at debug level: ccusage runs and failures, skipped JSONL files and lines, and limit estimation")
+	rootCmd.PersistentFlags().StringVar(&config.Log.File, "log-file", config.Log.File, "Log file, rotated by size")
+	rootCmd.PersistentFlags().Int64Var(&config.Log.MaxSize, "log-max-size", config.Log.MaxSize, "Log file size in bytes before rotation")
+	rootCmd.PersistentFlags().IntVar(&config.Log.Backups, "log-backups", config.Log.Backups, "Rotated log files to keep")
+	rootCmd.PersistentFlags().StringVar(&snapshotPath, "snapshot", defaultSnapshotPath(), "Snapshot file written by daemon and read by status")
+	rootCmd.PersistentFlags().StringVar(&config.NumberFormat, "number-format", config.NumberFormat, "Token count format: comma (1,234,567), locale (from LANG, e.g. 1.234.567) or si (1.23M)")
+	rootCmd.PersistentFlags().BoolVar(&config.ShortNumbers, "short-numbers", config.ShortNumbers, "Abbreviate token counts in bars and status lines (1,234,567 as 1.23M); JSON keeps full precision")
+	rootCmd.PersistentFlags().StringVar(&config.ThousandsSeparator, "thousands-separator", config.ThousandsSeparator, "Custom thousands separator, e.g. \"'\" or \" \"")
+	rootCmd.PersistentFlags().StringVar(&config.FormatFile, "format-file", config.FormatFile, "Go template file replacing the session view layout (see README)")
+	rootCmd.PersistentFlags().StringVar(&config.Lang, "lang", config.Lang, "Display language: auto (from LC_ALL, LC_MESSAGES or LANG), en or ja")
+	rootCmd.PersistentFlags().StringVar(&config.Theme, "theme", config.Theme, "Color theme: default, solarized, monochrome or high-contrast (see 'cctop themes'); NO_COLOR disables colors")
+	rootCmd.PersistentFlags().StringToStringVar(&config.Colors, "colors", config.Colors, "Color overrides per element, e.g. bar-high=magenta,warning=\"bold 208\" (see 'cctop themes')")
+	rootCmd.PersistentFlags().StringToStringVar(&config.AlertSpecs, "alert-thresholds", config.AlertSpecs, "Warning/critical levels per plan as percentages or tokens for bar colors, status, notifications and exit codes, e.g. max5=70%/90%,pro=5000/6500,default=60%/85%")
+	rootCmd.PersistentFlags().StringVar(&config.Output, "output", config.Output, "Output format (tui, plain, json)")
+	rootCmd.Flags().BoolVar(&config.ModelBars, "model-bars", config.ModelBars, "Show per-model token share bars (Opus, Sonnet, ...)")
+	rootCmd.Flags().StringToStringVar(&config.ModelWeightSpecs, "model-weights", config.ModelWeightSpecs, "Limit weight per model family for --model-bars, e.g. opus=5,sonnet=1")
+	rootCmd.Flags().BoolVar(&config.ProjectsPanel, "projects", config.ProjectsPanel, "Show which projects used the session's tokens")
+	rootCmd.Flags().BoolVar(&config.TypicalShape, "typical", config.TypicalShape, "Mark typical historical usage for this point in the session on the token bar")
+	rootCmd.Flags().BoolVar(&config.IdleSegments, "idle-segments", config.IdleSegments, "Dim the parts of the session bar where no messages were sent for over 5 minutes")
+	rootCmd.PersistentFlags().StringToStringVar(&config.Keys, "keys", config.Keys, "Key bindings of the monitor as action=keys, e.g. next-view=\"l tab\",prev-view=h,quit=Q (see 'cctop keys')")
+	rootCmd.Flags().BoolVar(&config.WhatsNew, "whats-new", config.WhatsNew, "Show what's new once after an upgrade (see 'cctop whatsnew')")
+	rootCmd.Flags().BoolVar(&config.ExitSummary, "exit-summary", config.ExitSummary, "Print the session's tokens, burn rate, cost and models when the monitor exits")
+	rootCmd.Flags().StringVar(&recordPath, "record", "", "Record every frame of the monitor to an asciinema cast file, e.g. out.cast (replay with 'asciinema play')")
+	rootCmd.Flags().StringVar(&replayPath, "replay", "", "Monitor a recording from 'cctop record' instead of live usage, in a sandbox that leaves cctop's state untouched")
+	rootCmd.Flags().Float64Var(&replaySpeed, "replay-speed", 60, "Recorded seconds replayed per second (at least 1)")
+	rootCmd.Flags().BoolVar(&config.ForecastChart, "forecast", config.ForecastChart, "Chart the session's cumulative tokens with a dotted projection to the limit or reset")
+	rootCmd.Flags().BoolVar(&config.ActiveTime, "active-time", config.ActiveTime, "Show active time (messages less than 5 minutes apart) against the elapsed session time")
+	rootCmd.Flags().BoolVar(&config.DailyBar, "daily-bar", config.DailyBar, "Show today's tokens across all sessions against a daily budget")
+	rootCmd.Flags().IntVar(&config.DailyBudget, "daily-budget", config.DailyBudget, "Daily token budget for --daily-bar (default: median of past days)")
+	rootCmd.PersistentFlags().BoolVar(&config.WeeklyBar, "weekly-bar", config.WeeklyBar, "Show tokens over the rolling 7-day window against the weekly limit (a rough estimate unless --weekly-limit is set)")
+	rootCmd.PersistentFlags().IntVar(&config.SoftLimit, "soft-limit", config.SoftLimit, "Personal per-session token cap with its own marker (!) and alerts, independent of the plan limit")
+	rootCmd.Flags().StringToStringVar(&config.TeamShareSpecs, "team-share", config.TeamShareSpecs, "Share of the session limit per --claude-dir profile on a shared account, e.g. alice=60,bob=40")
+	rootCmd.Flags().StringVar(&config.TeamMember, "team-member", config.TeamMember, "Your --claude-dir profile in the team panel")
+	rootCmd.PersistentFlags().IntVar(&config.WeeklyLimit, "weekly-limit", config.WeeklyLimit, "Weekly token limit for --weekly-bar (default: estimated from the plan)")
+	rootCmd.Flags().BoolVar(&config.Breaks.Enabled, "break-reminder", config.Breaks.Enabled, "Remind you to take breaks as the session progresses")
+	rootCmd.Flags().Float64SliceVar(&config.Breaks.Points, "break-points", config.Breaks.Points, "Session progress percentages that trigger a break reminder")
+	rootCmd.PersistentFlags().Float64Var(&config.Budget, "budget", config.Budget, "Cost budget in USD per budget period (shows a cost bar)")
+	rootCmd.PersistentFlags().StringVar(&config.BudgetPeriod, "budget-period", config.BudgetPeriod, "Budget period (day, week, session)")
+	rootCmd.Flags().StringVar(&config.Focus.OnShortcut, "focus-shortcut", config.Focus.OnShortcut, "macOS Shortcut to run when usage crosses --focus-threshold (e.g. one enabling a Focus mode)")
+	rootCmd.Flags().StringVar(&config.Focus.OffShortcut, "focus-off-shortcut", config.Focus.OffShortcut, "macOS Shortcut to run when usage drops back below --focus-threshold")
+	rootCmd.Flags().Float64Var(&config.Focus.Threshold, "focus-threshold", config.Focus.Threshold, "Token usage percentage that triggers --focus-shortcut")
+	rootCmd.PersistentFlags().StringVar(&config.SystemLog, "syslog", config.SystemLog, "Write status changes and alerts to the system log (auto, journald, syslog, oslog)")
+	rootCmd.PersistentFlags().StringArrayVar(&config.HookSpecs, "hook", config.HookSpecs, "Run a command on an event, as event=command (session_start, session_end, model_switch, threshold_NN, limit_exceeded; repeatable)")
+	rootCmd.Flags().StringVar(&config.TimeTracker.Service, "time-tracker", config.TimeTracker.Service, "Track active sessions in a time tracker (toggl, clockify)")
+	rootCmd.Flags().StringVar(&config.TimeTracker.Workspace, "time-tracker-workspace", config.TimeTracker.Workspace, "Time tracker workspace ID")
+	rootCmd.Flags().StringVar(&config.TimeTracker.Token, "time-tracker-token", config.TimeTracker.Token, "Time tracker API token (default: $CCTOP_TIME_TRACKER_TOKEN)")
+	rootCmd.Flags().StringVar(&config.Issue.ID, "issue", os.Getenv("CCTOP_ISSUE"), "Issue the sessions are worked on, e.g. ABC-123 (default: $CCTOP_ISSUE)")
+	rootCmd.Flags().StringVar(&config.Issue.WebhookURL, "issue-webhook", config.Issue.WebhookURL, "Webhook that receives a comment for --issue when a session ends")
+	rootCmd.Flags().BoolVar(&config.Bell.Enabled, "bell", config.Bell.Enabled, "Ring the terminal bell when usage crosses the warning threshold or the limit")
+	rootCmd.Flags().StringVar(&config.Bell.Command, "bell-command", config.Bell.Command, "Sound command played instead of the terminal bell (e.g. \"afplay /System/Library/Sounds/Ping.aiff\")")
+	rootCmd.Flags().Float64Var(&config.Bell.Warning, "bell-warning", config.Bell.Warning, "Token usage percentage that rings the warning bell")
+	rootCmd.Flags().DurationVar(&config.Bell.Cooldown, "bell-cooldown", config.Bell.Cooldown, "Minimum time before the same bell rings again")
+	rootCmd.Flags().StringVar(&config.ChatWebhook.URL, "webhook", os.Getenv("CCTOP_WEBHOOK_URL"), "Slack or Discord incoming webhook for threshold alerts and session summaries (default: $CCTOP_WEBHOOK_URL)")
+	rootCmd.Flags().Float64SliceVar(&config.ChatWebhook.Thresholds, "webhook-thresholds", config.ChatWebhook.Thresholds, "Token usage percentages that post to --webhook")
+	rootCmd.Flags().BoolVar(&config.Notify.Enabled, "notify", config.Notify.Enabled, "Send desktop notifications when usage crosses thresholds")
+	rootCmd.Flags().Float64SliceVar(&config.Notify.Thresholds, "notify-thresholds", config.Notify.Thresholds, "Token usage percentages that trigger a notification")
+	rootCmd.Flags().DurationVar(&config.Notify.Cooldown, "notify-cooldown", config.Notify.Cooldown, "Minimum time before the same notification repeats")
 
 	// Add analyze command for testing
-	rootCmd.AddCommand(&cobra.Command{
+	analyzeCmd := &cobra.Command{
 		Use:   "analyze",
 		Short: "Analyze token limit estimation accuracy",
 		Run: func(cmd *cobra.Command, args []string) {
 			testAccuracy()
 		},
+	}
+	backtestCmd := &cobra.Command{
+		Use:   "backtest",
+		Short: "Replay history and report how well each estimation method predicted past sessions",
+		Run:   runBacktest,
+	}
+	backtestCmd.Flags().StringSliceVar(&backtestMethods, "methods", BuiltinEstimators, "Estimation methods to compare")
+	backtestCmd.Flags().IntVar(&backtestRows, "rows", HistoryViewRows, "Number of sessions to show (0 for all)")
+	analyzeCmd.AddCommand(backtestCmd)
+	rootCmd.AddCommand(analyzeCmd)
+
+	// Add history command to list completed session blocks
+	historyCmd := &cobra.Command{
+		Use:   "history",
+		Short: "List completed session blocks",
+		Run:   runHistory,
+	}
+	historyCmd.Flags().IntVar(&historyRows, "rows", HistoryViewRows, "Number of blocks to show (0 for all)")
+	rootCmd.AddCommand(historyCmd)
+
+	// Add blocks command to show a timeline of blocks and gaps
+	blocksCmd := &cobra.Command{
+		Use:   "blocks",
+		Short: "Show a timeline of recent blocks and gaps against the estimated limit",
+		Run:   runBlocks,
+	}
+	blocksCmd.Flags().IntVar(&blocksDays, "days", TimelineDays, "Days covered by the timeline")
+	rootCmd.AddCommand(blocksCmd)
+
+	// Add export command to dump history for spreadsheets or DuckDB
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export per-block or per-message history as CSV",
+		Run:   runExport,
+	}
+	exportCmd.Flags().StringVar(&exportFormat, "format", ExportCSV, "Output format (csv, parquet)")
+	exportCmd.Flags().StringVar(&exportSince, "since", "", "Only export records from this date on (YYYY-MM-DD)")
+	exportCmd.Flags().StringVar(&exportRecords, "records", ExportBlocks, "Records to export (blocks, messages)")
+	rootCmd.AddCommand(exportCmd)
+
+	// Add inspect command for debugging raw data
+	inspectCmd := &cobra.Command{
+		Use:   "inspect",
+		Short: "Dump raw usage data with derived fields",
+	}
+	inspectCmd.AddCommand(&cobra.Command{
+		Use:   "block <index|id|active>",
+		Short: "Print a ccusage block as JSON with end time, hourly distribution and contributing log files",
+		Args:  cobra.ExactArgs(1),
+		Run:   runInspectBlock,
+	})
+	rootCmd.AddCommand(inspectCmd)
+
+	// Add discrepancy command to compare Claude Code's own usage report with the estimate
+	discrepancyCmd := &cobra.Command{
+		Use:   "discrepancy [text]",
+		Short: "Compare Claude Code's /status or limit warning text (argument or stdin) with cctop's estimate",
+		Run:   runDiscrepancy,
+	}
+	discrepancyCmd.Flags().BoolVar(&discrepancyRecalibrate, "recalibrate", false, "Record the limit implied by Claude's percentage as an observed limit")
+	rootCmd.AddCommand(discrepancyCmd)
+
+	// Add limit-history command to show how the estimated limit evolved
+	limitHistoryCmd := &cobra.Command{
+		Use:   "limit-history",
+		Short: "Show how the estimated limit changed over time",
+		Run:   runLimitHistory,
+	}
+	limitHistoryCmd.Flags().IntVar(&limitHistoryWeeks, "weeks", 8, "Weeks covered by the chart")
+	rootCmd.AddCommand(limitHistoryCmd)
+
+	// Add status command for one-shot output
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Print the current session once and exit",
+		Run:   runStatus,
+	}
+	statusCmd.Flags().BoolVar(&statusJSON, "json", false, "Output as JSON")
+	statusCmd.Flags().BoolVar(&statusOneline, "oneline", false, "Print one compact line for shell prompts; exit 0 OK, 1 warning, 2 limit exceeded, 3 no data")
+	statusCmd.Flags().BoolVar(&statusFresh, "fresh", false, "Query ccusage even when a recent daemon snapshot exists")
+	rootCmd.AddCommand(statusCmd)
+
+	// Add daemon command for headless snapshots
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "daemon",
+		Short: "Run headless and write a JSON snapshot every interval",
+		Run:   runDaemon,
+	})
+
+	// Add tmux command for status-right
+	tmuxCmd := &cobra.Command{
+		Use:   "tmux",
+		Short: "Print a one-line tmux status, e.g. set -g status-right '#(cctop tmux)'",
+		Run:   runTmux,
+	}
+	tmuxCmd.Flags().StringVar(&tmuxFormat, "format", DefaultTmuxFormat, "Go template for the line (fields: Percent, Used, Limit, Remaining, Reset, Cost, BurnRate, Status, Model, Plan, Color, NoLimit)")
+	rootCmd.AddCommand(tmuxCmd)
+
+	// Add serve command for the web dashboard, the headless JSON API and metrics export
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run headless and serve a live web dashboard (/), JSON (/v1/session, /v1/blocks, /v1/burnrate, /v1/estimate, /v1/events) and Prometheus metrics (/metrics)",
+		Run:   runServe,
+	}
+	serveCmd.Flags().StringVar(&serveAddr, "addr", DefaultServeAddr, "Address to serve the dashboard, the API and /metrics on, e.g. 127.0.0.1:8787 or :8787 for all interfaces")
+	// Old name of --addr, sharing its value so only --addr carries a default
+	serveCmd.Flags().Var(serveCmd.Flags().Lookup("addr").Value, "metrics-addr", "Address to serve the API and /metrics on")
+	if err := serveCmd.Flags().MarkDeprecated("metrics-addr", "use --addr"); err != nil {
+		panic(err)
+	}
+	rootCmd.AddCommand(serveCmd)
+
+	// Add mcp command so Claude Code can query its own usage
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "mcp",
+		Short: "Serve usage over stdio as an MCP server (get_usage, get_burn_rate, get_predicted_depletion)",
+		Run:   runMCP,
+	})
+
+	// Add projects command for the per-project breakdown
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "projects",
+		Short: "Show tokens used per project in the current session",
+		Run:   runProjects,
+	})
+
+	// Add session command to drill down into one Claude Code conversation
+	sessionCmd := &cobra.Command{
+		Use:   "session <session-id>",
+		Short: "Show a Claude Code session's tokens message by message, with cumulative totals, models and the largest messages",
+		Args:  cobra.ExactArgs(1),
+		Run:   runConversation,
+	}
+	sessionCmd.Flags().IntVar(&conversationTop, "top", 5, "Largest messages to list (0 hides them)")
+	sessionCmd.Flags().BoolVar(&conversationJSON, "json", false, "Print the session as JSON")
+	rootCmd.AddCommand(sessionCmd)
+
+	// Add record command to capture ccusage snapshots for --replay
+	recordCmd := &cobra.Command{
+		Use:   "record <file>",
+		Short: "Record ccusage snapshots and the Claude log messages of the session for 'cctop --replay'",
+		Args:  cobra.ExactArgs(1),
+		Run:   runRecord,
+	}
+	recordCmd.Flags().DurationVar(&recordInterval, "interval", time.Minute, "Time between snapshots")
+	recordCmd.Flags().DurationVar(&recordDuration, "duration", 0, "Stop recording after this long (0 = until interrupted)")
+	rootCmd.AddCommand(recordCmd)
+
+	// Add events command to show why the status changed
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "events",
+		Short: "Show recent status changes and their reasons",
+		Run:   runEvents,
+	})
+
+	// Add doctor command to diagnose the environment
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "doctor",
+		Short: "Check ccusage, Claude logs and terminal setup",
+		Run:   runDoctor,
+	})
+
+	// Add version command with the embedded build metadata
+	versionCmd := &cobra.Command{
+		Use:   "version",
+		Short: "Show version and build metadata",
+		Run:   runVersion,
+	}
+	versionCmd.Flags().BoolVar(&versionJSON, "json", false, "Print build metadata as JSON (version, commit, date, builder, Go version, path)")
+	rootCmd.AddCommand(versionCmd)
+
+	// Add about command for version and a shareable setup report
+	aboutCmd := &cobra.Command{
+		Use:   "about",
+		Short: "Show version information",
+		Run:   runAbout,
+	}
+	aboutCmd.Flags().BoolVar(&aboutReport, "report", false, "Print an anonymized setup summary for bug reports (offline, no usage data)")
+	rootCmd.AddCommand(aboutCmd)
+
+	// Add support-bundle command to collect diagnostics for issue reports
+	supportBundleCmd := &cobra.Command{
+		Use:   "support-bundle",
+		Short: "Zip version info, settings, logs and anonymized block statistics for a bug report",
+		Run:   runSupportBundle,
+	}
+	supportBundleCmd.Flags().StringVarP(&supportBundleOut, "out", "o", "", "Zip file to write (default cctop-support-<time>.zip)")
+	supportBundleCmd.Flags().BoolVarP(&supportBundleYes, "yes", "y", false, "Include every item without asking")
+	rootCmd.AddCommand(supportBundleCmd)
+
+	// Add config commands to check and explain settings
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration",
+	}
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "validate",
+		Short: "Check the config file and effective settings",
+		Run:   runConfigValidate,
+	})
+	configShowCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Print the config file",
+		Run:   runConfigShow,
+	}
+	configShowCmd.Flags().BoolVar(&configShowEffective, "effective", false, "Print every effective setting and its source (default, file, env, flag)")
+	configCmd.AddCommand(configShowCmd)
+	rootCmd.AddCommand(configCmd)
+
+	// Add logs command to inspect the log file
+	logsCmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Inspect the cctop log file",
+	}
+	logsTailCmd := &cobra.Command{
+		Use:   "tail",
+		Short: "Print the last lines of the log file",
+		Run:   runLogsTail,
+	}
+	logsTailCmd.Flags().IntVarP(&logsLines, "lines", "n", 50, "Number of lines to print")
+	logsTailCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Keep printing new lines")
+	logsCmd.AddCommand(logsTailCmd)
+	rootCmd.AddCommand(logsCmd)
+
+	// Add report command for weekly and monthly summaries
+	reportCmd := &cobra.Command{
+		Use:   "report",
+		Short: "Summarize usage over the last week or month",
+		Run:   runReport,
+	}
+	reportCmd.Flags().StringVar(&reportPeriod, "period", ReportPeriodWeek, "Report period (week, month)")
+	rootCmd.AddCommand(reportCmd)
+
+	// Add devtools commands for development and testing
+	devtoolsCmd := &cobra.Command{
+		Use:   "devtools",
+		Short: "Development and testing utilities",
+	}
+	genFixturesCmd := &cobra.Command{
+		Use:   "gen-fixtures",
+		Short: "Generate synthetic JSONL projects and ccusage-style JSON",
+		Run:   runGenFixtures,
+	}
+	genFixturesCmd.Flags().StringVar(&fixtureOutDir, "out", "fixtures", "Output directory")
+	genFixturesCmd.Flags().IntVar(&fixtureOptions.Sessions, "sessions", fixtureOptions.Sessions, "Number of sessions")
+	genFixturesCmd.Flags().IntVar(&fixtureOptions.Projects, "projects", fixtureOptions.Projects, "Number of project directories")
+	genFixturesCmd.Flags().IntVar(&fixtureOptions.MessagesMean, "messages-mean", fixtureOptions.MessagesMean, "Mean messages per session")
+	genFixturesCmd.Flags().Float64Var(&fixtureOptions.TokensMean, "tokens-mean", fixtureOptions.TokensMean, "Mean tokens per message")
+	genFixturesCmd.Flags().Float64Var(&fixtureOptions.TokensStdDev, "tokens-stddev", fixtureOptions.TokensStdDev, "Standard deviation of tokens per message")
+	genFixturesCmd.Flags().StringVar(&fixtureOptions.Model, "model", fixtureOptions.Model, "Model name recorded on messages")
+	genFixturesCmd.Flags().Int64Var(&fixtureOptions.Seed, "seed", fixtureOptions.Seed, "Random seed")
+	devtoolsCmd.AddCommand(genFixturesCmd)
+	rootCmd.AddCommand(devtoolsCmd)
+
+	// Add baseline command to compare sessions with a shared profile
+	baselineCmd := &cobra.Command{
+		Use:   "baseline [file]",
+		Short: "Compare your sessions with a baseline profile (default: the imported one)",
+		Args:  cobra.MaximumNArgs(1),
+		Run:   runBaseline,
+	}
+	baselineExportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Print your sessions as an anonymized baseline profile for sharing",
+		Run:   runBaselineExport,
+	}
+	baselineExportCmd.Flags().StringVar(&baselineName, "name", "baseline", "Name of the profile, e.g. your team")
+	baselineExportCmd.Flags().StringVarP(&baselineOut, "out", "o", "", "Write the profile to a file instead of stdout")
+	baselineCmd.AddCommand(baselineExportCmd)
+	baselineCmd.AddCommand(&cobra.Command{
+		Use:   "import <file>",
+		Short: "Keep a baseline profile as the default for 'cctop baseline'",
+		Args:  cobra.ExactArgs(1),
+		Run:   runBaselineImport,
+	})
+	rootCmd.AddCommand(baselineCmd)
+
+	// Add experiments command to list in-development subsystems
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "experiments",
+		Short: "List experimental subsystems that --experimental can enable",
+		Run:   runExperiments,
+	})
+
+	// Add whatsnew command to show the embedded release notes
+	whatsNewCmd := &cobra.Command{
+		Use:   "whatsnew",
+		Short: "Show what's new in this release",
+		Run:   runWhatsNew,
+	}
+	whatsNewCmd.Flags().BoolVar(&whatsNewAll, "all", false, "Show the notes of this and every earlier release")
+	rootCmd.AddCommand(whatsNewCmd)
+
+	// Add remote command to merge usage from other machines
+	remoteCmd := &cobra.Command{
+		Use:   "remote",
+		Short: "Merge usage of other machines on the same account, mirrored over SSH",
+		Run:   runRemoteList,
+	}
+	remoteAddCmd := &cobra.Command{
+		Use:   "add user@host",
+		Short: "Mirror the Claude logs of a machine with rsync over SSH",
+		Args:  cobra.ExactArgs(1),
+		Run:   runRemoteAdd,
+	}
+	remoteAddCmd.Flags().StringVar(&remoteName, "name", "", "Name of the remote (default: the host name)")
+	remoteAddCmd.Flags().StringVar(&remoteDir, "dir", "", "Claude config directory on the remote (default: .config/claude or .claude in its home)")
+	remoteCmd.AddCommand(remoteAddCmd)
+	remoteCmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List remotes and when they were last mirrored",
+		Run:   runRemoteList,
+	})
+	remoteCmd.AddCommand(&cobra.Command{
+		Use:   "remove <name>",
+		Short: "Forget a remote and delete its mirrored logs",
+		Args:  cobra.ExactArgs(1),
+		Run:   runRemoteRemove,
+	})
+	remoteCmd.AddCommand(&cobra.Command{
+		Use:   "sync",
+		Short: "Mirror the logs of every remote now",
+		Run:   runRemoteSync,
+	})
+	rootCmd.AddCommand(remoteCmd)
+
+	// Add keys command to print the active key bindings
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "keys",
+		Short: "Show the key bindings of the monitor (remap them with --keys)",
+		Run:   runKeys,
+	})
+
+	// Add themes command to preview the color themes
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "themes",
+		Short: "Preview the color themes and the elements --colors can override",
+		Run: func(cmd *cobra.Command, args []string) {
+			runThemes()
+		},
 	})
 
 	// Add list-est command to show available estimation methods
@@ -132,113 +727,229 @@ func main() {
 	estimator = NewTokenLimitEstimator()
 	display = NewDisplay(config.Timezone)
 	burnCalc = NewBurnRateCalculator()
+	usageHistory = NewUsageHistory(UsageHistorySize)
 
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	shutdown()
+	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 }
 
-// Terminal control functions moved to utils.go
+// shutdown finishes outstanding work before the process exits: the hooks fired by the
+// last refresh, and stopping the time entry the tracker started
+func shutdown() {
+	if config.Tracker != nil {
+		backgroundActions.Queue(TimeTrackerAction{Tracker: config.Tracker, Stop: true}, nil)
+	}
+	backgroundActions.Wait(HookTimeout)
+	_ = replayer.Close()
+}
+
+// exit shuts down and exits with code, for failures once the monitor may have started
+// work that shutdown finishes, such as the replay sandbox
+func exit(code int) {
+	shutdown()
+	os.Exit(code)
+}
 
 func runMonitor(cmd *cobra.Command, args []string) {
-	hideCursor()
-	defer showCursor()
+	if config.Output == OutputJSON {
+		runStatus(cmd, args)
+		return
+	}
 
 	// Set estimation method
 	estimator.SetEstimationMethod(estimationMethod)
 
-	setupSignalHandler()
-	tokenLimit := getInitialTokenLimit()
-	clearScreen()
+	startProjectWatcher()
+	startRemoteSync()
 
-	for {
-		if err := updateDisplay(&tokenLimit); err != nil {
-			displayError(err.Error())
-			time.Sleep(config.UpdateInterval)
-			continue
+	if recordPath != "" {
+		width, height := terminalSize()
+		recorder, err := NewCastRecorder(recordPath, width, height, time.Now())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit(1)
 		}
-		time.Sleep(config.UpdateInterval)
+		castRecorder = recorder
+		defer castRecorder.Close()
+	}
+
+	// Escape sequences would end up in pipes and log files
+	if config.Output == OutputPlain || !NewTerminal(os.Stdout).Color() {
+		runPlainMonitor()
+		return
+	}
+
+	model := NewModel(config.Plan, getInitialTokenLimit(config.Plan))
+	if config.WhatsNew {
+		model.whatsNew = pendingWhatsNew(defaultWhatsNewPath())
+	}
+	program := tea.NewProgram(model, tea.WithAltScreen(), tea.WithFPS(MaxFramesPerSecond))
+	final, err := program.Run()
+	if err != nil {
+		fmt.Println(err)
+		exit(1)
+	}
+	// The alternate screen is gone, so leave the last state in scrollback
+	if model, ok := final.(Model); ok {
+		banner := display.RenderExit(model.session, model.err, time.Now())
+		fmt.Fprintln(NewTerminal(os.Stdout), banner)
+		castRecorder.Screen(banner+"\n", time.Now())
 	}
 }
 
-func setupSignalHandler() {
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		showCursor()
-		fmt.Print("\n")
-		os.Exit(0)
-	}()
+// lookupFlag finds a root command flag by name, local or persistent
+func lookupFlag(name string) *pflag.Flag {
+	if flag := rootCmd.Flags().Lookup(name); flag != nil {
+		return flag
+	}
+	return rootCmd.PersistentFlags().Lookup(name)
 }
 
-func updateDisplay(tokenLimit *int) error {
-	usageData := fetchUsageData()
-	if usageData == nil {
-		return fmt.Errorf("Failed to get usage data")
+// loadSession fetches usage data of a profile ("" for all) and builds the active session
+func loadSession(plan, profile string, tokenLimit *int) (*Session, error) {
+	selectProfile(profile)
+	usageData, err := loadUsageData()
+	recordUsageFetch(err)
+	if err != nil {
+		return nil, err
 	}
 
 	activeBlock := findActiveBlock(usageData.Blocks)
 	if activeBlock == nil {
-		return fmt.Errorf("No active session found")
+		logger.Debugf("no active block among %d blocks", len(usageData.Blocks))
+		if hooks != nil {
+			logErrors(hooks.Check(nil))
+		}
+		if rules != nil {
+			logErrors(rules.Evaluate(nil, time.Now()))
+		}
+		return nil, &NoActiveSessionError{Idle: newIdleSummary(usageData.Blocks, cachedDailyUsage(time.Now()), time.Now())}
 	}
 
+	currency.Refresh(time.Now())
+
+	if config.TokenLimit == 0 {
+		estimator.ObserveBlocks(usageData.Blocks, *tokenLimit)
+	}
+	*tokenLimit = refreshTokenLimit(plan, usageData.Blocks, activeBlock.TotalTokens, *tokenLimit, time.Now())
+
 	// Create session with all metrics
-	session := NewSession(activeBlock, usageData.Blocks, *tokenLimit, time.Now())
+	session := NewSession(activeBlock, usageData.Blocks, plan, *tokenLimit, time.Now())
+	session.Profile = profile
+	session.LimitChange = recentLimitTransition(time.Now())
+	session.ConflictingBlocks = conflictingActiveBlocks(usageData.Blocks, activeBlock)
 
-	// Auto-switch plan if needed
-	if config.ShouldAutoSwitch(config.Plan, session.Block.TotalTokens) {
-		newLimit := estimator.EstimateLimit("auto", usageData.Blocks)
-		if newLimit > *tokenLimit {
-			*tokenLimit = newLimit
-			session.Metrics.Tokens = session.calculateTokenMetrics(*tokenLimit)
+	if notifier != nil {
+		notifier.Check(session, time.Now())
+	}
+	if rules != nil {
+		logErrors(rules.Evaluate(session, time.Now()))
+	}
+	if bell != nil {
+		if err := bell.Check(session, time.Now()); err != nil {
+			logger.Errorf("bell: %v", err)
+		}
+	}
+	if usageHistory != nil {
+		usageHistory.Record(session, time.Now())
+		session.RecentUsage = usageHistory.Buckets(time.Now(), SparklineWindow, SparklineBuckets)
+		if rates, ok := usageHistory.BurnRates(EWMABurnRate{HalfLife: config.BurnHalfLife}, time.Now()); ok {
+			session.RecentRates = &rates
+			useInstantRate(session.WindowRates, rates.Instant)
 		}
 	}
 
-	// Render display
-	output := display.Render(session, estimator, config.Plan)
-	clearAndHome()
-	fmt.Print(output)
-	return nil
+	if eventLog != nil {
+		if event := eventLog.Record(session, time.Now()); event != nil {
+			logger.Infof("status %s: %s", event.Status, event.Reason)
+			if systemLog != nil {
+				_ = systemLog.LogStatus(*event, session)
+			}
+		}
+	}
+	if hooks != nil {
+		logErrors(hooks.Check(session))
+	}
+	if breaks != nil {
+		session.BreakReminder = breaks.Check(session, time.Now())
+	}
+
+	logger.Debugf("refreshed: %d/%d tokens, burn rate %.1f/min", session.Metrics.Tokens.Used, *tokenLimit, session.BurnRate)
+	return session, nil
 }
 
-func displayError(message string) {
-	clearAndHome()
-	fmt.Print(display.RenderError(message))
+// logErrors logs action failures from rules and hooks
+func logErrors(errs []error) {
+	for _, err := range errs {
+		logger.Errorf("%v", err)
+	}
 }
 
+// fetchUsageData fetches usage blocks, nil when that fails
 func fetchUsageData() *CCUsageData {
-	cmd := exec.Command("ccusage", "blocks", "--json")
-	output, err := cmd.Output()
+	data, _ := loadUsageData()
+	return data
+}
+
+// loadUsageData fetches usage blocks from ccusage, or from the Claude logs with the
+// native-source experiment
+func loadUsageData() (*CCUsageData, error) {
+	if replayer != nil {
+		return replayer.UsageData(time.Now())
+	}
+	if experimentEnabled(ExperimentNativeSource) {
+		if data := fetchNativeUsageData(time.Now()); data != nil {
+			return data, nil
+		}
+		return nil, fmt.Errorf("reading the Claude logs failed")
+	}
+	output, err := runCCUsage("blocks", "--json")
 	if err != nil {
-		return nil
+		logger.Warnf("%v", err)
+		return nil, err
 	}
 
 	var data CCUsageData
 	if err := json.Unmarshal(output, &data); err != nil {
-		return nil
+		logger.Warnf("ccusage blocks returned invalid JSON: %v", err)
+		return nil, &CCUsageError{Args: []string{"blocks", "--json"}, Err: fmt.Errorf("invalid JSON: %w", err)}
+	}
+	if archive != nil {
+		data.Blocks = archive.ArchiveBlocks(data.Blocks)
 	}
 
-	return &data
+	return &data, nil
 }
 
-func findActiveBlock(blocks []Block) *Block {
-	for i := range blocks {
-		if blocks[i].IsActive {
-			return &blocks[i]
-		}
+func getInitialTokenLimit(plan string) int {
+	if config.TokenLimit > 0 || config.APIPlan() {
+		return tokenLimitOf(plan, nil)
 	}
-	return nil
-}
-
-func getInitialTokenLimit() int {
 	data := fetchUsageData()
 	if data != nil {
-		return estimator.EstimateLimit(config.Plan, data.Blocks)
+		return tokenLimitOf(plan, data.Blocks)
 	}
 	// Fallback to default limits if no data available
-	return config.GetTokenLimit(config.Plan)
+	return config.GetTokenLimit(plan)
+}
+
+// tokenLimitOf returns the session token limit of already fetched blocks: --token-limit
+// when set, none for the API plan, and the estimate otherwise
+func tokenLimitOf(plan string, blocks []Block) int {
+	if config.TokenLimit > 0 {
+		return config.TokenLimit
+	}
+	if config.APIPlan() {
+		return 0
+	}
+	limit := estimator.EstimateLimit(plan, blocks)
+	recordLimit(plan, blocks, limit)
+	limitRefresh.markEstimated(time.Now())
+	return limit
 }
 
 // Removed getTokenLimit - now using config.GetTokenLimit and estimator directly
@@ -253,15 +964,16 @@ func getInitialTokenLimit() int {
 
 // Removed calculatePredictedEnd - now in session.go
 
-func fetchTodayTotalCost(currentTime time.Time) float64 {
-	// Get today's date in YYYY-MM-DD format
-	todayStr := currentTime.Format("2006-01-02")
-
+// fetchDailyUsage fetches per-day usage from ccusage
+func fetchDailyUsage() []DailyUsage {
+	if replayer != nil {
+		return replayer.Daily(time.Now())
+	}
 	// Run ccusage daily command
-	cmd := exec.Command("ccusage", "daily", "--json")
-	output, err := cmd.Output()
+	output, err := runCCUsage("daily", "--json")
 	if err != nil {
-		return 0.0
+		logger.Warnf("ccusage daily failed: %v", err)
+		return nil
 	}
 
 	// Parse JSON response
@@ -269,17 +981,20 @@ func fetchTodayTotalCost(currentTime time.Time) float64 {
 		Daily []DailyUsage `json:"daily"`
 	}
 	if err := json.Unmarshal(output, &response); err != nil {
-		return 0.0
+		logger.Warnf("ccusage daily returned invalid JSON: %v", err)
+		return nil
 	}
-
-	// Find today's entry
-	for _, day := range response.Daily {
-		if day.Date == todayStr {
-			return day.TotalCost
-		}
+	if archive != nil {
+		return archive.ArchiveDaily(response.Daily)
 	}
 
-	return 0.0
+	return response.Daily
+}
+
+// todayCost finds today's total cost in the daily usage entries
+func todayCost(days []DailyUsage, currentTime time.Time) float64 {
+	day, _ := findDay(days, currentTime)
+	return day.TotalCost
 }
 
 // Removed buildHeader - now in display.go
@@ -306,7 +1021,7 @@ func fetchTodayTotalCost(currentTime time.Time) float64 {
 
 // listEstimationMethods displays available estimation methods
 func listEstimationMethods() {
-	fmt.Println("Available estimation methods for --est flag:")
+	fmt.Println("Available estimation methods for --estimator (or --est):")
 	fmt.Println()
 	fmt.Println("Percentile-based methods:")
 	fmt.Println("  pNN           - Percentile (1-99, e.g., p25, p40, p50)")
@@ -318,27 +1033,33 @@ func listEstimationMethods() {
 	fmt.Println()
 	fmt.Println("Other methods:")
 	fmt.Println("  mode          - Most frequent value")
+	fmt.Println("  ewma          - Exponentially weighted average, recent messages count more (half-life 20 msgs)")
+	fmt.Println("  ewmaNN        - EWMA with a half-life of NN messages (e.g., ewma50)")
+	fmt.Println("  bayes         - Average shrunk towards 150 tokens/msg, stable with few messages")
 	fmt.Println()
 	fmt.Println("Default: p40 (40th percentile)")
 	fmt.Println()
 	fmt.Println("Example usage:")
-	fmt.Println("  cctop --est p40           # Use 40th percentile (default)")
-	fmt.Println("  cctop --est p25           # Use 25th percentile (conservative)")
-	fmt.Println("  cctop --est median        # Use median (50th percentile)")
-	fmt.Println("  cctop --est trim10        # Use 10% trimmed mean")
-	fmt.Println("  cctop --est avg           # Use simple average")
+	fmt.Println("  cctop --estimator p40           # Use 40th percentile (default)")
+	fmt.Println("  cctop --estimator p25           # Use 25th percentile (conservative)")
+	fmt.Println("  cctop --estimator median        # Use median (50th percentile)")
+	fmt.Println("  cctop --estimator trim10        # Use 10% trimmed mean")
+	fmt.Println("  cctop --estimator avg           # Use simple average")
+	fmt.Println()
+	fmt.Println("Compare all methods with 'cctop analyze' and 'cctop analyze backtest'")
 }
 
 // fetchCurrentSessionData fetches session data from ccusage
 func fetchCurrentSessionData() *SessionData {
-	cmd := exec.Command("ccusage", "session", "--json")
-	output, err := cmd.Output()
+	output, err := runCCUsage("session", "--json")
 	if err != nil {
+		logger.Warnf("ccusage session failed: %v", err)
 		return nil
 	}
 
 	var data SessionData
 	if err := json.Unmarshal(output, &data); err != nil {
+		logger.Warnf("ccusage session returned invalid JSON: %v", err)
 		return nil
 	}
 
@@ -202,3 +202,59 @@ func TestCreateProgressBars(t *testing.T) {
 		t.Error("createProgressBar returned empty string for time bar")
 	}
 }
+
+func TestModelShares(t *testing.T) {
+	session := &Session{
+		ModelTokens: map[string]int{
+			"claude-opus-4-20250514":   6000,
+			"claude-opus-4-1-20250805": 2000,
+			"claude-sonnet-4-20250514": 2000,
+		},
+	}
+
+	shares := session.ModelShares()
+	if len(shares) != 2 {
+		t.Fatalf("len(shares) = %d, expected 2", len(shares))
+	}
+	if shares[0].Family != "Opus" || shares[0].Tokens != 8000 || shares[0].Percentage != 80 {
+		t.Errorf("shares[0] = %+v, expected Opus 8000 (80%%)", shares[0])
+	}
+	if shares[1].Family != "Sonnet" || shares[1].Percentage != 20 {
+		t.Errorf("shares[1] = %+v, expected Sonnet (20%%)", shares[1])
+	}
+
+	if shares := (&Session{}).ModelShares(); shares != nil {
+		t.Errorf("ModelShares() without data = %+v, expected nil", shares)
+	}
+}
+
+func TestModelSharesWeighted(t *testing.T) {
+	oldConfig := config
+	defer func() { config = oldConfig }()
+	config = NewConfig()
+
+	weights, err := parseModelWeights(map[string]string{"Opus": "5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.ModelWeights = weights
+
+	session := &Session{
+		ModelTokens: map[string]int{
+			"claude-opus-4-20250514":   1000,
+			"claude-sonnet-4-20250514": 5000,
+		},
+	}
+
+	shares := session.ModelShares()
+	if shares[0].Family != "Opus" || shares[0].WeightedTokens != 5000 || shares[0].Percentage != 50 {
+		t.Errorf("shares[0] = %+v, expected Opus 5000 weighted tokens (50%%)", shares[0])
+	}
+	if shares[1].Family != "Sonnet" || shares[1].Weight != 1 || shares[1].Percentage != 50 {
+		t.Errorf("shares[1] = %+v, expected Sonnet weight 1 (50%% weighted)", shares[1])
+	}
+
+	if _, err := parseModelWeights(map[string]string{"opus": "heavy"}); err == nil {
+		t.Error("expected error for non-numeric weight")
+	}
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// mcpProtocolVersions are the MCP revisions the server speaks, latest first
+var mcpProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// JSON-RPC error codes
+const (
+	rpcParseError     = -32700
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+)
+
+// rpcRequest is a JSON-RPC 2.0 request, or a notification when ID is absent
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// rpcResponse is a JSON-RPC 2.0 response
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is a JSON-RPC 2.0 error
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// MCPTool is a tool offered to MCP clients. The tools take no arguments and answer
+// from the current status report.
+type MCPTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+	answer      func(report *StatusReport, loc *time.Location) any
+}
+
+// MCPUsage is the get_usage result
+type MCPUsage struct {
+	Summary         string    `json:"summary"`
+	Plan            string    `json:"plan"`
+	Status          string    `json:"status"`
+	TokensUsed      int       `json:"tokensUsed"`
+	TokenLimit      int       `json:"tokenLimit"`
+	TokensRemaining int       `json:"tokensRemaining"`
+	PercentUsed     float64   `json:"percentUsed"`
+	NoLimit         bool      `json:"noLimit"` // Limit and percentage are guesses
+	ResetAt         time.Time `json:"resetAt"`
+	MinutesToReset  float64   `json:"minutesToReset"`
+	CostUSD         float64   `json:"costUsd"` // Cost of the session so far
+}
+
+// MCPBurnRate is the get_burn_rate result
+type MCPBurnRate struct {
+	Summary         string           `json:"summary"`
+	TokensPerMinute float64          `json:"tokensPerMinute"`
+	Sustainable     float64          `json:"sustainableTokensPerMinute"` // Rate using up the remaining tokens exactly at reset
+	CostPerHourUSD  float64          `json:"costPerHourUsd"`
+	Recent          *BurnRates       `json:"recent,omitempty"`
+	Windows         []WindowBurnRate `json:"windows,omitempty"`
+}
+
+// MCPDepletion is the get_predicted_depletion result
+type MCPDepletion struct {
+	Summary            string     `json:"summary"`
+	PredictedEnd       time.Time  `json:"predictedEnd"`
+	Earliest           *time.Time `json:"predictedEndEarliest,omitempty"`
+	Latest             *time.Time `json:"predictedEndLatest,omitempty"`
+	ResetAt            time.Time  `json:"resetAt"`
+	RunsOutBeforeReset bool       `json:"runsOutBeforeReset"`
+	MinutesLeft        float64    `json:"minutesLeft"` // Until tokens run out or the session resets, whichever comes first
+}
+
+// mcpNoArguments is the input schema of tools without arguments
+var mcpNoArguments = map[string]any{"type": "object", "properties": map[string]any{}}
+
+// mcpTools lists the tools in the order clients show them
+var mcpTools = []MCPTool{
+	{
+		Name:        "get_usage",
+		Description: "Tokens used and left in the current 5-hour Claude Code session, the estimated limit, and when it resets",
+		InputSchema: mcpNoArguments,
+		answer:      mcpUsage,
+	},
+	{
+		Name:        "get_burn_rate",
+		Description: "How fast the current session uses tokens (tokens/minute, USD/hour) and the rate that would last until the reset",
+		InputSchema: mcpNoArguments,
+		answer:      mcpBurnRate,
+	},
+	{
+		Name:        "get_predicted_depletion",
+		Description: "When tokens are predicted to run out at the current burn rate, and whether that is before the session resets",
+		InputSchema: mcpNoArguments,
+		answer:      mcpDepletion,
+	},
+}
+
+// MCPServer answers MCP requests over the stdio transport: one JSON-RPC message per line
+type MCPServer struct {
+	report   func(currentTime time.Time) (*StatusReport, error)
+	loc      *time.Location
+	mu       sync.Mutex
+	cached   *StatusReport
+	cachedAt time.Time
+}
+
+// NewMCPServer creates a server answering from report, reused for MCPReportMaxAge
+func NewMCPServer(report func(currentTime time.Time) (*StatusReport, error), loc *time.Location) *MCPServer {
+	return &MCPServer{report: report, loc: loc}
+}
+
+// Serve handles messages from r until it is closed, writing responses to w
+func (s *MCPServer) Serve(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), MaxJSONLLineSize)
+	encoder := json.NewEncoder(w)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		if response := s.handle(scanner.Bytes()); response != nil {
+			if err := encoder.Encode(response); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// handle answers one message, returning nil for notifications
+func (s *MCPServer) handle(message []byte) *rpcResponse {
+	var request rpcRequest
+	if err := json.Unmarshal(message, &request); err != nil {
+		return &rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{rpcParseError, err.Error()}}
+	}
+	if request.ID == nil {
+		return nil // Notifications, e.g. notifications/initialized, need no answer
+	}
+
+	response := &rpcResponse{JSONRPC: "2.0", ID: request.ID}
+	switch request.Method {
+	case "initialize":
+		response.Result = s.initialize(request.Params)
+	case "ping":
+		response.Result = struct{}{}
+	case "tools/list":
+		response.Result = map[string][]MCPTool{"tools": mcpTools}
+	case "tools/call":
+		response.Result, response.Error = s.callTool(request.Params)
+	default:
+		response.Error = &rpcError{rpcMethodNotFound, "method not found: " + request.Method}
+	}
+	return response
+}
+
+// initialize agrees on the protocol version: the client's if supported, else the latest
+func (s *MCPServer) initialize(params json.RawMessage) any {
+	var request struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	_ = json.Unmarshal(params, &request)
+	protocolVersion := mcpProtocolVersions[0]
+	if slices.Contains(mcpProtocolVersions, request.ProtocolVersion) {
+		protocolVersion = request.ProtocolVersion
+	}
+	return map[string]any{
+		"protocolVersion": protocolVersion,
+		"capabilities":    map[string]any{"tools": map[string]any{}},
+		"serverInfo":      map[string]string{"name": "cctop", "version": version},
+		"instructions":    "Live Claude Code usage of the current 5-hour session. Check get_usage before long tasks and slow down when get_predicted_depletion says tokens run out before the reset.",
+	}
+}
+
+// callTool runs a tool. Failing to load usage, e.g. with no active session, is a tool
+// error the model can read rather than a protocol error.
+func (s *MCPServer) callTool(params json.RawMessage) (any, *rpcError) {
+	var request struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(params, &request); err != nil {
+		return nil, &rpcError{rpcInvalidParams, err.Error()}
+	}
+	index := slices.IndexFunc(mcpTools, func(tool MCPTool) bool { return tool.Name == request.Name })
+	if index < 0 {
+		return nil, &rpcError{rpcInvalidParams, "unknown tool: " + request.Name}
+	}
+
+	report, err := s.currentReport(time.Now())
+	if err != nil {
+		return mcpToolResult(err.Error(), nil, true), nil
+	}
+	answer := mcpTools[index].answer(report, s.loc)
+	data, _ := json.MarshalIndent(answer, "", "  ")
+	return mcpToolResult(string(data), answer, false), nil
+}
+
+// currentReport returns the status report, refreshing it after MCPReportMaxAge
+func (s *MCPServer) currentReport(currentTime time.Time) (*StatusReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cached != nil && currentTime.Sub(s.cachedAt) < MCPReportMaxAge {
+		return s.cached, nil
+	}
+	report, err := s.report(currentTime)
+	if err != nil {
+		return nil, err
+	}
+	s.cached, s.cachedAt = report, currentTime
+	return report, nil
+}
+
+// mcpToolResult wraps a tool answer as text content, plus structured content for
+// clients of protocol revisions that read it
+func mcpToolResult(text string, structured any, isError bool) map[string]any {
+	result := map[string]any{
+		"content": []map[string]string{{"type": "text", "text": text}},
+		"isError": isError,
+	}
+	if structured != nil {
+		result["structuredContent"] = structured
+	}
+	return result
+}
+
+// mcpUsage answers get_usage
+func mcpUsage(report *StatusReport, loc *time.Location) any {
+	usage := MCPUsage{
+		Plan:            report.Plan,
+		Status:          report.Status,
+		TokensUsed:      report.Tokens.Used,
+		TokenLimit:      report.Tokens.Limit,
+		TokensRemaining: report.Tokens.Remaining,
+		PercentUsed:     report.Tokens.Percentage,
+		NoLimit:         report.NoLimit,
+		ResetAt:         report.EndTime,
+		MinutesToReset:  report.Time.MinutesRemaining,
+		CostUSD:         report.Cost.BlockUSD,
+	}
+	usage.Summary = fmt.Sprintf("%d of about %d tokens left (%.0f%% used), the session resets at %s",
+		usage.TokensRemaining, usage.TokenLimit, usage.PercentUsed,
+		report.EndTime.In(loc).Format(TimeFormatShort+" MST"))
+	if report.NoLimit {
+		usage.Summary = fmt.Sprintf("%d tokens used, no limit estimated yet, the session resets at %s",
+			usage.TokensUsed, report.EndTime.In(loc).Format(TimeFormatShort+" MST"))
+	}
+	return usage
+}
+
+// mcpBurnRate answers get_burn_rate
+func mcpBurnRate(report *StatusReport, loc *time.Location) any {
+	rate := MCPBurnRate{
+		TokensPerMinute: report.BurnRate,
+		CostPerHourUSD:  report.CostBurnRate,
+		Recent:          report.BurnRates,
+		Windows:         report.WindowRates,
+	}
+	if report.Time.MinutesRemaining > 0 && !report.NoLimit {
+		rate.Sustainable = float64(max(report.Tokens.Remaining, 0)) / report.Time.MinutesRemaining
+	}
+	rate.Summary = fmt.Sprintf("Using %.0f tokens/min (%s/hour); %.0f tokens/min would last until the reset",
+		rate.TokensPerMinute, formatCost(rate.CostPerHourUSD), rate.Sustainable)
+	return rate
+}
+
+// mcpDepletion answers get_predicted_depletion
+func mcpDepletion(report *StatusReport, loc *time.Location) any {
+	depletion := MCPDepletion{
+		PredictedEnd:       report.PredictedEnd,
+		Earliest:           report.PredictedMin,
+		Latest:             report.PredictedMax,
+		ResetAt:            report.EndTime,
+		RunsOutBeforeReset: !report.NoLimit && report.PredictedEnd.Before(report.EndTime),
+	}
+	end := report.EndTime
+	if depletion.RunsOutBeforeReset {
+		end = report.PredictedEnd
+	}
+	depletion.MinutesLeft = max(end.Sub(report.GeneratedAt).Minutes(), 0)
+
+	if depletion.RunsOutBeforeReset {
+		depletion.Summary = fmt.Sprintf("At the current rate tokens run out at %s, %s before the session resets at %s",
+			report.PredictedEnd.In(loc).Format(TimeFormatShort+" MST"),
+			formatTime(report.EndTime.Sub(report.PredictedEnd).Minutes()),
+			report.EndTime.In(loc).Format(TimeFormatShort+" MST"))
+	} else {
+		depletion.Summary = fmt.Sprintf("At the current rate tokens last until the session resets at %s",
+			report.EndTime.In(loc).Format(TimeFormatShort+" MST"))
+	}
+	return depletion
+}
+
+// runMCP serves usage to Claude Code over stdin and stdout
+func runMCP(cmd *cobra.Command, args []string) {
+	bell = nil // BEL or a sound command output would corrupt the protocol on stdout
+	server := NewMCPServer(currentStatusReport, display.timezone)
+	if err := server.Serve(os.Stdin, os.Stdout); err != nil {
+		logger.Errorf("mcp: %v", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testReport returns a report of 3,000 of 10,000 tokens, running out an hour before the reset
+func testReport(currentTime time.Time) (*StatusReport, error) {
+	return &StatusReport{
+		GeneratedAt:  currentTime,
+		Plan:         "pro",
+		Status:       "WARNING",
+		EndTime:      currentTime.Add(3 * time.Hour),
+		Tokens:       TokenMetrics{Used: 3000, Limit: 10000, Percentage: 30, Remaining: 7000},
+		Time:         TimeMetrics{MinutesRemaining: 180},
+		BurnRate:     70,
+		CostBurnRate: 2.5,
+		PredictedEnd: currentTime.Add(2 * time.Hour),
+		Cost:         CostReport{BlockUSD: 1.5, CycleUSD: 42},
+	}, nil
+}
+
+// mcpResponse is a decoded MCP server response
+type mcpResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+// callMCP sends messages to an MCP server and returns its responses by ID
+func callMCP(t *testing.T, server *MCPServer, messages ...string) map[string]mcpResponse {
+	t.Helper()
+	var output strings.Builder
+	if err := server.Serve(strings.NewReader(strings.Join(messages, "\n")), &output); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+	responses := make(map[string]mcpResponse)
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		var response mcpResponse
+		if err := json.Unmarshal([]byte(line), &response); err != nil {
+			t.Fatalf("invalid response %q: %v", line, err)
+		}
+		responses[string(response.ID)] = response
+	}
+	return responses
+}
+
+func TestMCPServer(t *testing.T) {
+	server := NewMCPServer(testReport, time.UTC)
+	responses := callMCP(t, server,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"get_usage","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"get_tokens"}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"resources/list"}`,
+	)
+	if len(responses) != 5 {
+		t.Errorf("got %d responses, expected 5 (none for the notification)", len(responses))
+	}
+
+	var initialized struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	json.Unmarshal(responses["1"].Result, &initialized)
+	if initialized.ProtocolVersion != "2024-11-05" {
+		t.Errorf("protocolVersion = %q, expected the client's 2024-11-05", initialized.ProtocolVersion)
+	}
+
+	var tools struct {
+		Tools []MCPTool `json:"tools"`
+	}
+	json.Unmarshal(responses["2"].Result, &tools)
+	if len(tools.Tools) != 3 || tools.Tools[0].Name != "get_usage" || tools.Tools[0].InputSchema["type"] != "object" {
+		t.Errorf("tools/list = %+v, expected the three tools", tools.Tools)
+	}
+
+	var result struct {
+		Content           []map[string]string `json:"content"`
+		IsError           bool                `json:"isError"`
+		StructuredContent MCPUsage            `json:"structuredContent"`
+	}
+	json.Unmarshal(responses["3"].Result, &result)
+	if result.IsError || result.StructuredContent.TokensRemaining != 7000 || len(result.Content) != 1 ||
+		!strings.Contains(result.Content[0]["text"], `"tokensRemaining": 7000`) {
+		t.Errorf("get_usage = %+v, expected 7000 tokens remaining as text and structured content", result)
+	}
+
+	for id, code := range map[string]int{"4": rpcInvalidParams, "5": rpcMethodNotFound} {
+		if responses[id].Error == nil || responses[id].Error.Code != code {
+			t.Errorf("response %s error = %+v, expected code %d", id, responses[id].Error, code)
+		}
+	}
+}
+
+func TestMCPToolError(t *testing.T) {
+	server := NewMCPServer(func(time.Time) (*StatusReport, error) {
+		return nil, errors.New("no active session")
+	}, time.UTC)
+	responses := callMCP(t, server, `{"jsonrpc":"2.0","id":"a","method":"tools/call","params":{"name":"get_burn_rate"}}`)
+
+	var result struct {
+		Content []map[string]string `json:"content"`
+		IsError bool                `json:"isError"`
+	}
+	json.Unmarshal(responses[`"a"`].Result, &result)
+	if !result.IsError || result.Content[0]["text"] != "no active session" {
+		t.Errorf("tool result without a session = %+v, expected a tool error", result)
+	}
+}
+
+func TestMCPReportCached(t *testing.T) {
+	calls := 0
+	server := NewMCPServer(func(currentTime time.Time) (*StatusReport, error) {
+		calls++
+		return testReport(currentTime)
+	}, time.UTC)
+	now := time.Date(2025, 6, 20, 10, 0, 0, 0, time.UTC)
+	for _, offset := range []time.Duration{0, MCPReportMaxAge / 2, MCPReportMaxAge} {
+		server.currentReport(now.Add(offset))
+	}
+	if calls != 2 {
+		t.Errorf("loaded the report %d times, expected 2 (once more after MCPReportMaxAge)", calls)
+	}
+}
+
+func TestMCPAnswers(t *testing.T) {
+	now := time.Date(2025, 6, 20, 10, 0, 0, 0, time.UTC)
+	report, _ := testReport(now)
+
+	if usage := mcpUsage(report, time.UTC).(MCPUsage); usage.CostUSD != 1.5 {
+		t.Errorf("mcpUsage().CostUSD = %v, expected the session's 1.5", usage.CostUSD)
+	}
+
+	rate := mcpBurnRate(report, time.UTC).(MCPBurnRate)
+	if rate.TokensPerMinute != 70 || int(rate.Sustainable) != 38 { // 7000 tokens / 180 minutes
+		t.Errorf("mcpBurnRate() = %+v, expected 70/min with 38/min sustainable", rate)
+	}
+
+	tests := []struct {
+		name          string
+		predictedEnd  time.Time
+		expectBefore  bool
+		expectMinutes float64
+		expectSummary string
+	}{
+		{"runs out first", now.Add(2 * time.Hour), true, 120, "tokens run out at 12:00 UTC, 1h before the session resets at 13:00 UTC"},
+		{"lasts until reset", now.Add(4 * time.Hour), false, 180, "tokens last until the session resets at 13:00 UTC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report.PredictedEnd = tt.predictedEnd
+			depletion := mcpDepletion(report, time.UTC).(MCPDepletion)
+			if depletion.RunsOutBeforeReset != tt.expectBefore || depletion.MinutesLeft != tt.expectMinutes {
+				t.Errorf("mcpDepletion() = %+v, expected runs out before reset %v with %v minutes left",
+					depletion, tt.expectBefore, tt.expectMinutes)
+			}
+			if !strings.Contains(depletion.Summary, tt.expectSummary) {
+				t.Errorf("summary = %q, expected it to contain %q", depletion.Summary, tt.expectSummary)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// MetricsExporter exposes the latest session metrics in Prometheus text format
+type MetricsExporter struct {
+	mu      sync.RWMutex
+	session *Session
+	up      bool
+	now     time.Time
+}
+
+var serveAddr string
+
+// DefaultServeAddr binds to loopback only; pass --addr :9185 to expose the server on the network
+const DefaultServeAddr = "127.0.0.1:9185"
+
+// NewMetricsExporter creates an exporter with no data yet
+func NewMetricsExporter() *MetricsExporter {
+	return &MetricsExporter{}
+}
+
+// Update records the latest refresh result
+func (m *MetricsExporter) Update(session *Session, err error, currentTime time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.up = err == nil
+	m.now = currentTime
+	if err == nil {
+		m.session = session
+	}
+}
+
+// ServeHTTP writes the gauges for the last successful refresh
+func (m *MetricsExporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprint(w, m.render())
+}
+
+// render builds the Prometheus exposition text
+func (m *MetricsExporter) render() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var buffer strings.Builder
+	up := 0.0
+	if m.up {
+		up = 1
+	}
+	writeGauge(&buffer, "cctop_up", "Whether the last ccusage refresh succeeded", up)
+
+	if m.session == nil {
+		return buffer.String()
+	}
+
+	tokens := m.session.Metrics.Tokens
+	writeGauge(&buffer, "cctop_tokens_used", "Tokens used in the active session", float64(tokens.Used))
+	writeGauge(&buffer, "cctop_token_limit", "Estimated token limit for the active session", float64(tokens.Limit))
+	writeGauge(&buffer, "cctop_burn_rate", "Token burn rate in tokens per minute", m.session.BurnRate)
+	writeGauge(&buffer, "cctop_session_seconds_remaining", "Seconds until the active session resets",
+		m.session.Metrics.Time.MinutesRemaining*60)
+	writeGauge(&buffer, "cctop_cache_hit_ratio", "Cache reads as a fraction of input tokens in the active session", m.session.Cache.HitRatio)
+	writeGauge(&buffer, "cctop_today_cost_usd", "Total cost today in USD", m.session.TodayCost)
+	writeGauge(&buffer, "cctop_cost_burn_rate_usd", "Cost burn rate in USD per hour", m.session.CostBurnRate)
+	return buffer.String()
+}
+
+// writeGauge writes a single gauge with its HELP and TYPE lines
+func writeGauge(buffer *strings.Builder, name, help string, value float64) {
+	fmt.Fprintf(buffer, "# HELP %s %s\n# TYPE %s gauge\n%s %g\n", name, help, name, name, value)
+}
+
+// runServe runs the monitoring loop headless and serves the JSON API and metrics over HTTP
+func runServe(cmd *cobra.Command, args []string) {
+	estimator.SetEstimationMethod(estimationMethod)
+	exporter := NewMetricsExporter()
+	api := NewAPIServer()
+
+	mux := api.Handler()
+	mux.Handle("/metrics", exporter)
+	mux.HandleFunc("GET /{$}", serveDashboard)
+	server := &http.Server{
+		Addr:              serveAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+	fmt.Printf("Serving the dashboard on %s/, the API on %s/v1/ and metrics on %s/metrics\n", serveAddr, serveAddr, serveAddr)
+
+	tokenLimit := getInitialTokenLimit(config.Plan)
+
+	for {
+		session, err := loadSession(config.Plan, config.Profile, &tokenLimit)
+		exporter.Update(session, err, time.Now())
+		api.Update(session, err, config.Plan, time.Now())
+
+		select {
+		case err := <-errCh:
+			fmt.Println(err)
+			os.Exit(1)
+		case <-time.After(pollInterval(session, time.Now())):
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsExporter(t *testing.T) {
+	exporter := NewMetricsExporter()
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	now := start.Add(time.Hour)
+
+	session := newTestSession(start, 3500, 7000)
+	session.BurnRate = 12.5
+	session.TodayCost = 4.2
+	session.Metrics.Time = session.calculateTimeMetrics(now)
+	exporter.Update(session, nil, now)
+
+	recorder := httptest.NewRecorder()
+	exporter.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body := recorder.Body.String()
+
+	for _, expected := range []string{
+		"cctop_up 1",
+		"cctop_tokens_used 3500",
+		"cctop_token_limit 7000",
+		"cctop_burn_rate 12.5",
+		"cctop_session_seconds_remaining 14400",
+		"cctop_today_cost_usd 4.2",
+		"# TYPE cctop_tokens_used gauge",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("metrics output missing %q:\n%s", expected, body)
+		}
+	}
+
+	// A failed refresh keeps the last values but reports down
+	exporter.Update(nil, errors.New("ccusage failed"), now)
+	body = exporter.render()
+	if !strings.Contains(body, "cctop_up 0") || !strings.Contains(body, "cctop_tokens_used 3500") {
+		t.Errorf("unexpected metrics after failed refresh:\n%s", body)
+	}
+}
+
+func TestServeAddrFlags(t *testing.T) {
+	oldAddr := serveAddr
+	defer func() { serveAddr = oldAddr }()
+
+	serve, _, err := rootCmd.Find([]string{"serve"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	flags := serve.Flags()
+	if addr := flags.Lookup("addr"); addr.DefValue != DefaultServeAddr {
+		t.Errorf("--addr default = %q, expected %q", addr.DefValue, DefaultServeAddr)
+	}
+	old := flags.Lookup("metrics-addr")
+	if !old.Hidden || old.Deprecated == "" {
+		t.Errorf("--metrics-addr hidden %v, deprecated %q, expected a hidden deprecated alias", old.Hidden, old.Deprecated)
+	}
+	if err := old.Value.Set(":9100"); err != nil || serveAddr != ":9100" {
+		t.Errorf("--metrics-addr :9100 set serveAddr = %q (%v), expected :9100", serveAddr, err)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"time"
+)
+
+// ModelSwitch is where a Max plan session moves from Opus to Sonnet
+type ModelSwitch struct {
+	Percent   float64   `json:"percent"`     // Token usage percentage of the switch
+	Tokens    int       `json:"tokens"`      // Token usage of the switch
+	Remaining int       `json:"remaining"`   // Tokens left before the switch
+	Switched  bool      `json:"switched"`    // Usage is past the switch
+	At        time.Time `json:"at,omitzero"` // Predicted switch at the burn rate, zero when switched or idle
+}
+
+// opusSwitchPercent returns the token usage percentage where the plan switches from Opus to Sonnet
+func opusSwitchPercent(plan string) (float64, bool) {
+	switch plan {
+	case "max5":
+		return Max5OpusSwitchPercent, true
+	case "max20":
+		return Max20OpusSwitchPercent, true
+	default:
+		return 0, false
+	}
+}
+
+// predictModelSwitch locates the session relative to the plan's Opus switch point and
+// predicts when usage reaches it at burnRate tokens per minute. It returns nil for
+// plans without a switch and for sessions that never used Opus.
+func predictModelSwitch(plan string, tokens TokenMetrics, models []string, modelTokens map[string]int, burnRate float64, currentTime time.Time) *ModelSwitch {
+	percent, ok := opusSwitchPercent(plan)
+	if !ok || tokens.Limit <= 0 || !usedOpus(models, modelTokens) {
+		return nil
+	}
+
+	modelSwitch := &ModelSwitch{Percent: percent, Tokens: int(float64(tokens.Limit) * percent / 100)}
+	modelSwitch.Remaining = modelSwitch.Tokens - tokens.Used
+	if modelSwitch.Remaining <= 0 {
+		modelSwitch.Remaining = 0
+		modelSwitch.Switched = true
+		return modelSwitch
+	}
+	if burnRate > 0 {
+		minutes := float64(modelSwitch.Remaining) / burnRate
+		modelSwitch.At = currentTime.Add(time.Duration(minutes * float64(time.Minute)))
+	}
+	return modelSwitch
+}
+
+// usedOpus reports whether the session used Opus, from the per-model breakdown when loaded
+func usedOpus(models []string, modelTokens map[string]int) bool {
+	if len(modelTokens) > 0 {
+		for model, tokens := range modelTokens {
+			if tokens > 0 && modelFamily(model) == "Opus" {
+				return true
+			}
+		}
+		return false
+	}
+	for _, model := range models {
+		if modelFamily(model) == "Opus" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPredictModelSwitch(t *testing.T) {
+	now := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+	opus := []string{"claude-opus-4-20250514"}
+	tokens := func(used int) TokenMetrics { return TokenMetrics{Used: used, Limit: 100000} }
+
+	tests := []struct {
+		name        string
+		plan        string
+		tokens      TokenMetrics
+		models      []string
+		modelTokens map[string]int
+		burnRate    float64
+		expected    *ModelSwitch
+	}{
+		{"Pro has no switch", "pro", tokens(5000), opus, nil, 100, nil},
+		{"Sonnet only", "max5", tokens(5000), []string{"claude-sonnet-4"}, nil, 100, nil},
+		{"Breakdown without Opus tokens", "max5", tokens(5000), opus, map[string]int{"claude-sonnet-4": 5000}, 100, nil},
+		{
+			name: "Before the Max5 switch", plan: "max5", tokens: tokens(16500), models: opus, burnRate: 100,
+			expected: &ModelSwitch{Percent: 20, Tokens: 20000, Remaining: 3500, At: now.Add(35 * time.Minute)},
+		},
+		{
+			name: "Idle before the Max20 switch", plan: "max20", tokens: tokens(10000), models: opus,
+			expected: &ModelSwitch{Percent: 50, Tokens: 50000, Remaining: 40000},
+		},
+		{
+			name: "Past the switch", plan: "max5", tokens: tokens(25000), models: opus, burnRate: 100,
+			modelTokens: map[string]int{"claude-opus-4": 20000, "claude-sonnet-4": 5000},
+			expected:    &ModelSwitch{Percent: 20, Tokens: 20000, Switched: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := predictModelSwitch(tt.plan, tt.tokens, tt.models, tt.modelTokens, tt.burnRate, now)
+			if (got == nil) != (tt.expected == nil) || got != nil && *got != *tt.expected {
+				t.Errorf("predictModelSwitch() = %+v, expected %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatModelSwitch(t *testing.T) {
+	display := NewDisplay("UTC")
+	now := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+	display.config = &DisplayConfig{CurrentTime: now, Timezone: time.UTC}
+
+	tests := []struct {
+		modelSwitch ModelSwitch
+		expected    string
+	}{
+		{ModelSwitch{Percent: 20, Remaining: 3500, At: now.Add(35 * time.Minute)}, "Opus remaining: ~35m"},
+		{ModelSwitch{Percent: 50, Remaining: 40000}, "Opus remaining: 40,000 tokens"},
+		{ModelSwitch{Percent: 20, Switched: true}, "Opus: switched to Sonnet at 20%"},
+	}
+	for _, tt := range tests {
+		if got := string(stripANSI([]byte(display.formatModelSwitch(tt.modelSwitch)))); !strings.Contains(got, tt.expected) {
+			t.Errorf("formatModelSwitch(%+v) = %q, expected %q", tt.modelSwitch, got, tt.expected)
+		}
+	}
+}
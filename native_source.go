@@ -0,0 +1,63 @@
+package main
+
+import (
+	"slices"
+	"time"
+)
+
+// fetchNativeUsageData builds the blocks ccusage would report from the Claude logs,
+// for the native-source experiment
+func fetchNativeUsageData(currentTime time.Time) *CCUsageData {
+	since := currentTime.Add(-NativeSourceLookback)
+	records, err := NewMessageTokenReader().GetMessageRecords(since.Format(time.RFC3339), currentTime.Format(time.RFC3339))
+	if err != nil {
+		logger.Warnf("native source: reading Claude logs failed: %v", err)
+		return nil
+	}
+	logger.Tracef("native source: %d messages since %s", len(records), since.Format(DateFormat))
+
+	data := CCUsageData{Blocks: buildNativeBlocks(records, currentTime)}
+	if archive != nil {
+		data.Blocks = archive.ArchiveBlocks(data.Blocks)
+	}
+	return &data
+}
+
+// buildNativeBlocks groups messages, oldest first, into 5-hour blocks the way ccusage
+// does: a block starts at the hour of its first message and ends after SessionDuration,
+// or earlier when no message was sent for SessionDuration. Gaps are not reported.
+func buildNativeBlocks(records []MessageRecord, currentTime time.Time) []Block {
+	var blocks []Block
+	var start, last time.Time
+	for _, record := range records {
+		if len(blocks) == 0 || record.Time.Sub(start) >= SessionDuration || record.Time.Sub(last) >= SessionDuration {
+			start = record.Time.UTC().Truncate(time.Hour)
+			blocks = append(blocks, Block{
+				ID:        start.Format(time.RFC3339),
+				StartTime: start.Format(time.RFC3339),
+				EndTime:   start.Add(SessionDuration).Format(time.RFC3339),
+			})
+		}
+		last = record.Time
+
+		block := &blocks[len(blocks)-1]
+		usage := record.Usage
+		block.TokenCounts.InputTokens += usage.InputTokens
+		block.TokenCounts.OutputTokens += usage.OutputTokens
+		block.TokenCounts.CacheCreationInputTokens += usage.CacheCreationInputTokens
+		block.TokenCounts.CacheReadInputTokens += usage.CacheReadInputTokens
+		block.TotalTokens += usage.InputTokens + usage.OutputTokens + usage.CacheCreationInputTokens + usage.CacheReadInputTokens
+		block.CostUSD += record.CostUSD // Only what the logs recorded
+		block.Entries++
+		block.ActualEndTime = record.Time.UTC().Format(time.RFC3339)
+		if record.Model != "" && !slices.Contains(block.Models, record.Model) {
+			block.Models = append(block.Models, record.Model)
+		}
+	}
+
+	if len(blocks) > 0 {
+		active := &blocks[len(blocks)-1]
+		active.IsActive = currentTime.Before(start.Add(SessionDuration)) && currentTime.Sub(last) < SessionDuration
+	}
+	return blocks
+}
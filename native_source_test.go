@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildNativeBlocks(t *testing.T) {
+	day := time.Date(2025, 6, 20, 0, 0, 0, 0, time.UTC)
+	message := func(at time.Duration, model string, tokens int) MessageRecord {
+		return MessageRecord{Time: day.Add(at), Model: model, Usage: TokenUsage{InputTokens: tokens / 2, OutputTokens: tokens - tokens/2}}
+	}
+	records := []MessageRecord{
+		message(9*time.Hour+20*time.Minute, "claude-opus-4", 1000),
+		message(11*time.Hour, "claude-sonnet-4", 500),
+		message(13*time.Hour+59*time.Minute, "claude-opus-4", 500),
+		message(14*time.Hour+5*time.Minute, "claude-sonnet-4", 300), // 5 hours after the block start
+		message(16*time.Hour, "claude-sonnet-4", 200),
+	}
+
+	blocks := buildNativeBlocks(records, day.Add(17*time.Hour))
+	if len(blocks) != 2 {
+		t.Fatalf("buildNativeBlocks() returned %d blocks, expected 2", len(blocks))
+	}
+
+	expected := []struct {
+		start, actualEnd string
+		tokens, entries  int
+		models           int
+		active           bool
+	}{
+		{"2025-06-20T09:00:00Z", "2025-06-20T13:59:00Z", 2000, 3, 2, false},
+		{"2025-06-20T14:00:00Z", "2025-06-20T16:00:00Z", 500, 2, 1, true},
+	}
+	for i, want := range expected {
+		block := blocks[i]
+		if block.StartTime != want.start || block.ActualEndTime != want.actualEnd {
+			t.Errorf("block %d = %s to %s, expected %s to %s", i, block.StartTime, block.ActualEndTime, want.start, want.actualEnd)
+		}
+		if block.TotalTokens != want.tokens || block.Entries != want.entries || len(block.Models) != want.models {
+			t.Errorf("block %d = %d tokens, %d entries, %d models, expected %d, %d, %d",
+				i, block.TotalTokens, block.Entries, len(block.Models), want.tokens, want.entries, want.models)
+		}
+		if block.IsActive != want.active {
+			t.Errorf("block %d IsActive = %v, expected %v", i, block.IsActive, want.active)
+		}
+	}
+
+	// Five idle hours end the block early
+	blocks = buildNativeBlocks(records[:1], day.Add(14*time.Hour+30*time.Minute))
+	if blocks[0].IsActive {
+		t.Error("block after five idle hours is active, expected inactive")
+	}
+	if blocks := buildNativeBlocks(nil, day); len(blocks) != 0 {
+		t.Errorf("buildNativeBlocks(nil) = %v, expected no blocks", blocks)
+	}
+}
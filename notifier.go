@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// NotifyConfig holds desktop notification configuration
+type NotifyConfig struct {
+	Enabled    bool
+	Thresholds []float64     // Token usage percentages that trigger a notification
+	Cooldown   time.Duration // Minimum time between repeats of the same notification
+}
+
+// Notifier fires desktop notifications when usage crosses thresholds
+type Notifier struct {
+	thresholds     []float64
+	cooldown       time.Duration
+	lastFired      map[string]time.Time
+	lastPercentage float64
+	lastWeekly     float64         // Weekly usage percentage, tracked across sessions
+	lastSoft       float64         // Soft limit usage percentage
+	lastSpend      float64         // Budget spend percentage of the API plan, tracked across sessions
+	overShare      map[string]bool // Team members over their share this session
+	depleting      bool
+	sessionStart   time.Time
+	send           func(title, message string) error
+	onAlert        func(message string, session *Session) // Optional, called for every alert
+}
+
+// NewNotifier creates a notifier using the native notification command for this OS
+func NewNotifier(cfg NotifyConfig) *Notifier {
+	return &Notifier{
+		thresholds: cfg.Thresholds,
+		cooldown:   cfg.Cooldown,
+		lastFired:  make(map[string]time.Time),
+		send:       sendDesktopNotification,
+	}
+}
+
+// OnAlert registers a function called for every alert, whether or not the notification could be shown
+func (n *Notifier) OnAlert(fn func(message string, session *Session)) {
+	n.onAlert = fn
+}
+
+// Check compares the session against thresholds and notifies on upward crossings
+func (n *Notifier) Check(session *Session, currentTime time.Time) {
+	// New session: start tracking crossings from scratch
+	if !session.StartTime.Equal(n.sessionStart) {
+		n.sessionStart = session.StartTime
+		n.lastPercentage = 0
+		n.lastSoft = 0
+		n.overShare = make(map[string]bool)
+		n.depleting = false
+	}
+
+	percentage := session.Metrics.Tokens.Percentage
+	if session.NoLimit {
+		// Percentages of a guessed limit would only cause false alarms
+		percentage = 0
+	}
+	for _, threshold := range n.thresholds {
+		if n.lastPercentage < threshold && percentage >= threshold {
+			n.notify(thresholdKey(threshold), currentTime, thresholdMessage(threshold, session), session)
+		}
+	}
+	if alerts := session.Alerts; alerts != nil && !session.NoLimit {
+		if n.lastPercentage < alerts.Warning && percentage >= alerts.Warning && percentage < alerts.Critical {
+			n.notify("alert-warning", currentTime, tr("Token usage passed the warning threshold (%s/%s)",
+				formatNumber(session.Metrics.Tokens.Used), formatNumber(session.Metrics.Tokens.Limit)), session)
+		}
+		if n.lastPercentage < alerts.Critical && percentage >= alerts.Critical {
+			n.notify("alert-critical", currentTime, tr("Token usage passed the critical threshold (%s/%s)",
+				formatNumber(session.Metrics.Tokens.Used), formatNumber(session.Metrics.Tokens.Limit)), session)
+		}
+	}
+	n.lastPercentage = percentage
+
+	// The soft limit is known exactly, so it alerts even without a plan limit
+	soft := session.SoftLimit.Percentage
+	for _, threshold := range n.thresholds {
+		if n.lastSoft < threshold && soft >= threshold {
+			n.notify("soft-"+thresholdKey(threshold), currentTime, softLimitMessage(threshold, session.SoftLimit), session)
+		}
+	}
+	n.lastSoft = soft
+
+	for _, member := range session.Team {
+		over := member.Tokens.Percentage >= 100
+		if over && !n.overShare[member.Name] {
+			n.notify("team-"+member.Name, currentTime, teamShareMessage(member), session)
+		}
+		n.overShare[member.Name] = over
+	}
+
+	// Spend resets with its budget period rather than the session, so drops re-arm the thresholds
+	spend := 0.0
+	if session.TracksSpend() {
+		spend = session.Cost.Percentage
+	}
+	for _, threshold := range n.thresholds {
+		if n.lastSpend < threshold && spend >= threshold {
+			n.notify("spend-"+thresholdKey(threshold), currentTime, spendMessage(threshold, session.Cost), session)
+		}
+	}
+	// Alert levels of the API plan are percentages of the budget
+	if alerts := session.Alerts; alerts != nil && session.TracksSpend() {
+		cost := session.Cost
+		if n.lastSpend < alerts.Warning && spend >= alerts.Warning && spend < alerts.Critical {
+			n.notify("spend-alert-warning", currentTime, tr("Spend passed the warning threshold (%s/%s per %s)",
+				formatCost(cost.Spent), formatCost(cost.Budget), cost.Period), session)
+		}
+		if n.lastSpend < alerts.Critical && spend >= alerts.Critical {
+			n.notify("spend-alert-critical", currentTime, tr("Spend passed the critical threshold (%s/%s per %s)",
+				formatCost(cost.Spent), formatCost(cost.Budget), cost.Period), session)
+		}
+	}
+	n.lastSpend = spend
+
+	weekly := session.Weekly.Tokens.Percentage
+	for _, threshold := range n.thresholds {
+		if n.lastWeekly < threshold && weekly >= threshold {
+			n.notify("weekly-"+thresholdKey(threshold), currentTime, weeklyThresholdMessage(threshold, session.Weekly), session)
+		}
+	}
+	n.lastWeekly = weekly
+
+	depleting := !session.NoLimit && session.GetPredictedEndTime(currentTime).Before(session.EndTime)
+	if depleting && !n.depleting {
+		n.notify("depletion", currentTime, tr("Tokens predicted to run out at %s, before session reset at %s",
+			session.GetPredictedEndTime(currentTime).Format(TimeFormatShort),
+			session.EndTime.Format(TimeFormatShort)), session)
+	}
+	n.depleting = depleting
+}
+
+// notify sends a notification unless the same key fired within the cooldown
+func (n *Notifier) notify(key string, currentTime time.Time, message string, session *Session) {
+	if last, ok := n.lastFired[key]; ok && currentTime.Sub(last) < n.cooldown {
+		return
+	}
+	if n.onAlert != nil {
+		n.onAlert(message, session)
+	}
+	if err := n.send("cctop", message); err != nil {
+		return
+	}
+	n.lastFired[key] = currentTime
+}
+
+// thresholdKey identifies a threshold for cooldown tracking
+func thresholdKey(threshold float64) string {
+	return fmt.Sprintf("threshold-%.0f", threshold)
+}
+
+// thresholdMessage builds the notification text for a crossed threshold
+func thresholdMessage(threshold float64, session *Session) string {
+	tokens := session.Metrics.Tokens
+	if threshold >= 100 {
+		return tr("Token limit exceeded (%s/%s)", formatNumber(tokens.Used), formatNumber(tokens.Limit))
+	}
+	return tr("Token usage passed %.0f%% (%s/%s)", threshold, formatNumber(tokens.Used), formatNumber(tokens.Limit))
+}
+
+// softLimitMessage builds the notification text for a crossed soft limit threshold
+func softLimitMessage(threshold float64, soft TokenMetrics) string {
+	if threshold >= 100 {
+		return tr("Soft limit reached (%s/%s)", formatNumber(soft.Used), formatNumber(soft.Limit))
+	}
+	return tr("Soft limit usage passed %.0f%% (%s/%s)", threshold, formatNumber(soft.Used), formatNumber(soft.Limit))
+}
+
+// weeklyThresholdMessage builds the notification text for a crossed weekly threshold
+func weeklyThresholdMessage(threshold float64, weekly WeeklyMetrics) string {
+	tokens := weekly.Tokens
+	if threshold >= 100 {
+		return tr("Weekly limit exceeded (%s/%s), resets %s",
+			formatNumber(tokens.Used), formatNumber(tokens.Limit), weekly.Reset.Format("Mon 15:04"))
+	}
+	return tr("Weekly usage passed %.0f%% (%s/%s), resets %s",
+		threshold, formatNumber(tokens.Used), formatNumber(tokens.Limit), weekly.Reset.Format("Mon 15:04"))
+}
+
+// sendDesktopNotification shows a native notification via osascript or notify-send
+func sendDesktopNotification(title, message string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptQuote(message), appleScriptQuote(title))
+		cmd = exec.Command("osascript", "-e", script)
+	case "linux":
+		cmd = exec.Command("notify-send", title, message)
+	default:
+		return fmt.Errorf("desktop notifications not supported on %s", runtime.GOOS)
+	}
+	return cmd.Run()
+}
+
+// appleScriptQuote quotes a string for use in an AppleScript literal
+func appleScriptQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
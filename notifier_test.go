@@ -0,0 +1,160 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestNotifier(sent *[]string) *Notifier {
+	n := NewNotifier(NotifyConfig{
+		Enabled:    true,
+		Thresholds: []float64{80, 95, 100},
+		Cooldown:   30 * time.Minute,
+	})
+	n.send = func(title, message string) error {
+		*sent = append(*sent, message)
+		return nil
+	}
+	return n
+}
+
+func newTestSession(start time.Time, used, limit int) *Session {
+	block := &Block{StartTime: start.Format(time.RFC3339), TotalTokens: used}
+	session := &Session{
+		Block:     block,
+		StartTime: start,
+		EndTime:   start.Add(SessionDuration),
+	}
+	session.Metrics.Tokens = session.calculateTokenMetrics(limit)
+	return session
+}
+
+func TestNotifierThresholdCrossing(t *testing.T) {
+	var sent []string
+	n := newTestNotifier(&sent)
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	now := start.Add(time.Hour)
+
+	n.Check(newTestSession(start, 5000, 10000), now)
+	if len(sent) != 0 {
+		t.Fatalf("sent %d notifications below thresholds, expected 0", len(sent))
+	}
+
+	n.Check(newTestSession(start, 8500, 10000), now)
+	if len(sent) != 1 {
+		t.Fatalf("sent %d notifications after crossing 80%%, expected 1", len(sent))
+	}
+
+	// Staying above the threshold does not repeat the notification
+	n.Check(newTestSession(start, 8600, 10000), now)
+	if len(sent) != 1 {
+		t.Errorf("sent %d notifications without a new crossing, expected 1", len(sent))
+	}
+
+	// Jumping past two thresholds fires both
+	n.Check(newTestSession(start, 10500, 10000), now)
+	if len(sent) != 3 {
+		t.Errorf("sent %d notifications after crossing 95%% and 100%%, expected 3", len(sent))
+	}
+}
+
+func TestNotifierCooldown(t *testing.T) {
+	var sent []string
+	n := newTestNotifier(&sent)
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	now := start.Add(time.Hour)
+
+	n.Check(newTestSession(start, 8500, 10000), now)
+
+	// A new session re-arms thresholds, but the cooldown still applies
+	nextStart := start.Add(10 * time.Minute)
+	n.Check(newTestSession(nextStart, 8500, 10000), now.Add(10*time.Minute))
+	if len(sent) != 1 {
+		t.Errorf("sent %d notifications within cooldown, expected 1", len(sent))
+	}
+
+	laterStart := start.Add(time.Hour)
+	n.Check(newTestSession(laterStart, 8500, 10000), now.Add(time.Hour))
+	if len(sent) != 2 {
+		t.Errorf("sent %d notifications after cooldown, expected 2", len(sent))
+	}
+}
+
+func TestAppleScriptQuote(t *testing.T) {
+	if result := appleScriptQuote(`say "hi" \ bye`); result != `"say \"hi\" \\ bye"` {
+		t.Errorf("appleScriptQuote() = %s", result)
+	}
+}
+
+func TestNotifierOnAlert(t *testing.T) {
+	var sent, alerts []string
+	n := newTestNotifier(&sent)
+	n.OnAlert(func(message string, session *Session) {
+		alerts = append(alerts, message)
+	})
+
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	n.Check(newTestSession(start, 8500, 10000), start.Add(time.Hour))
+	if len(alerts) != 1 || alerts[0] != sent[0] {
+		t.Errorf("alerts = %v, expected the sent notification %v", alerts, sent)
+	}
+}
+
+func TestNotifierWeeklyThreshold(t *testing.T) {
+	var sent []string
+	n := newTestNotifier(&sent)
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+
+	session := newTestSession(start, 1000, 10000)
+	session.Weekly = WeeklyMetrics{
+		Tokens: TokenMetrics{Used: 85000, Limit: 100000, Percentage: 85},
+		Reset:  start.Add(48 * time.Hour),
+	}
+	n.Check(session, start.Add(time.Hour))
+	if len(sent) != 1 || !strings.Contains(sent[0], "Weekly usage passed 80%") {
+		t.Fatalf("sent = %v, expected one weekly 80%% notification", sent)
+	}
+
+	// A new session does not reset weekly tracking
+	next := newTestSession(start.Add(5*time.Hour), 1000, 10000)
+	next.Weekly = session.Weekly
+	n.Check(next, start.Add(6*time.Hour))
+	if len(sent) != 1 {
+		t.Errorf("sent %d notifications in a new session, expected 1", len(sent))
+	}
+}
+
+func TestNotifierNoLimit(t *testing.T) {
+	var sent []string
+	n := newTestNotifier(&sent)
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+
+	session := newTestSession(start, 12000, 10000)
+	session.NoLimit = true
+	session.BurnRate = 500
+	n.Check(session, start.Add(time.Hour))
+	if len(sent) != 0 {
+		t.Errorf("sent %v without a known limit, expected nothing", sent)
+	}
+	if status := session.GetStatus(); status != "OK" {
+		t.Errorf("GetStatus() = %q without a known limit, expected OK", status)
+	}
+}
+
+func TestNotifierSoftLimit(t *testing.T) {
+	var sent []string
+	n := newTestNotifier(&sent)
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+
+	// Soft limit alerts work without a plan limit
+	session := newTestSession(start, 61000, 200000)
+	session.NoLimit = true
+	session.SoftLimit = session.calculateTokenMetrics(60000)
+	n.Check(session, start.Add(time.Hour))
+
+	expected := []string{"Soft limit usage passed 80% (61,000/60,000)", "Soft limit usage passed 95% (61,000/60,000)", "Soft limit reached (61,000/60,000)"}
+	if strings.Join(sent, "|") != strings.Join(expected, "|") {
+		t.Errorf("sent = %v, expected %v", sent, expected)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Number formats
+const (
+	NumberFormatComma  = "comma"  // 1,234,567
+	NumberFormatLocale = "locale" // Grouping of the LC_ALL, LC_NUMERIC or LANG locale, e.g. 1.234.567 for de
+	NumberFormatSI     = "si"     // 1.23M
+)
+
+// NumberFormat controls how token counts are displayed
+type NumberFormat struct {
+	Style     string // comma, locale or si
+	Separator string // Thousands separator for grouped numbers
+	Decimal   string // Decimal separator for abbreviated numbers
+}
+
+// numbers is the active number format, set from the configuration at startup
+var numbers = NumberFormat{Style: NumberFormatComma, Separator: ",", Decimal: "."}
+
+// localeSeparators maps languages to their thousands and decimal separators
+var localeSeparators = map[string][2]string{
+	"de": {".", ","}, "es": {".", ","}, "it": {".", ","}, "nl": {".", ","}, "pt": {".", ","},
+	"id": {".", ","}, "tr": {".", ","}, "da": {".", ","},
+	"fr": {" ", ","}, "ru": {" ", ","}, "pl": {" ", ","}, "cs": {" ", ","}, "sv": {" ", ","},
+	"fi": {" ", ","}, "nb": {" ", ","}, "uk": {" ", ","},
+}
+
+// newNumberFormat resolves a style and optional custom thousands separator
+func newNumberFormat(style, separator string) (NumberFormat, error) {
+	format := NumberFormat{Style: style, Separator: ",", Decimal: "."}
+	switch style {
+	case NumberFormatComma, NumberFormatSI:
+	case NumberFormatLocale:
+		if separators, ok := localeSeparators[localeLanguage("LC_NUMERIC")]; ok {
+			format.Separator, format.Decimal = separators[0], separators[1]
+		}
+	default:
+		return format, fmt.Errorf("unknown number format %q (comma, locale, si)", style)
+	}
+	if separator != "" {
+		format.Separator = separator
+	}
+	return format, nil
+}
+
+// numberFormatStyle returns the configured style, with --short-numbers selecting si
+func numberFormatStyle(cfg *Config) string {
+	if cfg.ShortNumbers {
+		return NumberFormatSI
+	}
+	return cfg.NumberFormat
+}
+
+// localeLanguage returns the language of a locale category such as LC_NUMERIC,
+// e.g. "de" for de_DE.UTF-8
+func localeLanguage(category string) string {
+	for _, name := range []string{"LC_ALL", category, "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			language, _, _ := strings.Cut(value, "_")
+			language, _, _ = strings.Cut(language, ".")
+			return strings.ToLower(language)
+		}
+	}
+	return ""
+}
+
+// Format formats a token count in this number format
+func (f NumberFormat) Format(n int) string {
+	if f.Style == NumberFormatSI {
+		return f.abbreviate(n)
+	}
+	return groupDigits(n, f.Separator)
+}
+
+// groupDigits inserts a separator every three digits
+func groupDigits(n int, separator string) string {
+	s := strconv.Itoa(n)
+	sign := ""
+	if n < 0 {
+		sign, s = "-", s[1:]
+	}
+	if len(s) <= 3 {
+		return sign + s
+	}
+
+	var result strings.Builder
+	result.WriteString(sign)
+	for i, digit := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			result.WriteString(separator)
+		}
+		result.WriteRune(digit)
+	}
+	return result.String()
+}
+
+// abbreviate formats n with three significant digits and an SI suffix, e.g. 63.4k or 1.23M
+func (f NumberFormat) abbreviate(n int) string {
+	value := math.Abs(float64(n))
+	sign := ""
+	if n < 0 {
+		sign = "-"
+	}
+	if value < 1000 {
+		return strconv.Itoa(n)
+	}
+
+	for _, unit := range []struct {
+		suffix string
+		size   float64
+	}{{"k", 1e3}, {"M", 1e6}, {"B", 1e9}} {
+		scaled := value / unit.size
+		decimals := 2
+		if scaled >= 100 {
+			decimals = 0
+		} else if scaled >= 10 {
+			decimals = 1
+		}
+		text := strconv.FormatFloat(scaled, 'f', decimals, 64)
+		// Rounding up to 1000 moves to the next unit, e.g. 999,999 is 1M rather than 1000k
+		if rounded, _ := strconv.ParseFloat(text, 64); rounded >= 1000 && unit.suffix != "B" {
+			continue
+		}
+		if strings.Contains(text, ".") {
+			text = strings.TrimRight(strings.TrimRight(text, "0"), ".")
+		}
+		return sign + strings.Replace(text, ".", f.Decimal, 1) + unit.suffix
+	}
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,71 @@
+package main
+
+import "testing"
+
+func TestNumberFormat(t *testing.T) {
+	comma := NumberFormat{Style: NumberFormatComma, Separator: ",", Decimal: "."}
+	german := NumberFormat{Style: NumberFormatLocale, Separator: ".", Decimal: ","}
+	si := NumberFormat{Style: NumberFormatSI, Separator: ",", Decimal: "."}
+	siGerman := NumberFormat{Style: NumberFormatSI, Separator: ".", Decimal: ","}
+
+	tests := []struct {
+		format   NumberFormat
+		n        int
+		expected string
+	}{
+		{comma, 0, "0"},
+		{comma, 999, "999"},
+		{comma, 1000, "1,000"},
+		{comma, 1234567, "1,234,567"},
+		{comma, -1234567, "-1,234,567"},
+		{german, 1234567, "1.234.567"},
+		{si, 999, "999"},
+		{si, 1234, "1.23k"},
+		{si, 63400, "63.4k"},
+		{si, 140000, "140k"},
+		{si, 999999, "1M"},
+		{si, 1200000, "1.2M"},
+		{si, 1234567, "1.23M"},
+		{si, -63400, "-63.4k"},
+		{siGerman, 1234567, "1,23M"},
+	}
+
+	for _, tt := range tests {
+		if result := tt.format.Format(tt.n); result != tt.expected {
+			t.Errorf("%s Format(%d) = %q, expected %q", tt.format.Style, tt.n, result, tt.expected)
+		}
+	}
+}
+
+func TestNewNumberFormat(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_NUMERIC", "")
+	t.Setenv("LANG", "de_DE.UTF-8")
+
+	format, err := newNumberFormat(NumberFormatLocale, "")
+	if err != nil || format.Separator != "." || format.Decimal != "," {
+		t.Errorf("newNumberFormat(locale) = %+v, %v, expected . and , for de", format, err)
+	}
+
+	format, err = newNumberFormat(NumberFormatComma, "'")
+	if err != nil || format.Format(1234567) != "1'234'567" {
+		t.Errorf("newNumberFormat(comma, ') formats %q, expected 1'234'567", format.Format(1234567))
+	}
+
+	if _, err := newNumberFormat("roman", ""); err == nil {
+		t.Error("newNumberFormat(roman) succeeded, expected an error")
+	}
+}
+
+func TestNumberFormatStyle(t *testing.T) {
+	cfg := NewConfig()
+	if style := numberFormatStyle(cfg); style != NumberFormatComma {
+		t.Errorf("numberFormatStyle(defaults) = %q, expected %q", style, NumberFormatComma)
+	}
+
+	cfg.NumberFormat = NumberFormatLocale
+	cfg.ShortNumbers = true
+	if style := numberFormatStyle(cfg); style != NumberFormatSI {
+		t.Errorf("numberFormatStyle(--short-numbers) = %q, expected %q", style, NumberFormatSI)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Exit codes of status --oneline, following the Nagios plugin convention
+const (
+	ExitOK            = 0
+	ExitWarning       = 1
+	ExitLimitExceeded = 2
+	ExitUnknown       = 3 // Usage data could not be read
+)
+
+var statusOneline bool
+
+// runStatusOneline prints a single compact line and exits with the status as exit code,
+// for shell prompts and cron checks
+func runStatusOneline() {
+	report, err := onelineStatusReport(time.Now())
+	line, code := formatOneline(report, err, display.timezone)
+	fmt.Println(line)
+	shutdown()
+	os.Exit(code)
+}
+
+// onelineStatusReport returns the status from a recent daemon snapshot unless --fresh,
+// or refreshes it via ccusage
+func onelineStatusReport(currentTime time.Time) (*StatusReport, error) {
+	if !statusFresh {
+		if snapshot, ok := readSnapshot(snapshotPath, currentTime); ok {
+			if snapshot.Status == nil {
+				if snapshot.Error == (&NoActiveSessionError{}).Error() {
+					return nil, &NoActiveSessionError{}
+				}
+				return nil, errors.New(snapshot.Error)
+			}
+			return snapshot.Status, nil
+		}
+	}
+
+	estimator.SetEstimationMethod(estimationMethod)
+	tokenLimit := getInitialTokenLimit(config.Plan)
+	session, err := loadSession(config.Plan, config.Profile, &tokenLimit)
+	if err != nil {
+		return nil, err
+	}
+	report := NewStatusReport(session, config.Plan, currentTime)
+	return &report, nil
+}
+
+// formatOneline formats the status as one uncolored line with its exit code, e.g.
+// "CC 45% 63,000/140,000 · reset 15:00 (2h 10m) · 120/min · OK"
+func formatOneline(report *StatusReport, err error, loc *time.Location) (string, int) {
+	var noActive *NoActiveSessionError
+	switch {
+	case errors.As(err, &noActive):
+		return "CC idle", ExitOK
+	case err != nil:
+		return "CC -- " + err.Error(), ExitUnknown
+	}
+
+	var parts []string
+	if report.Plan == PlanAPI && report.Budget.Budget > 0 {
+		parts = append(parts, fmt.Sprintf("CC %d%% %s/%s per %s", int(report.Budget.Percentage), formatCost(report.Budget.Spent), formatCost(report.Budget.Budget), report.Budget.Period))
+	} else if report.NoLimit {
+		parts = append(parts, fmt.Sprintf("CC %s tokens", formatNumber(report.Tokens.Used)))
+	} else {
+		parts = append(parts, fmt.Sprintf("CC %d%% %s/%s", int(report.Tokens.Percentage), formatNumber(report.Tokens.Used), formatNumber(report.Tokens.Limit)))
+	}
+	parts = append(parts,
+		fmt.Sprintf("reset %s (%s)", report.EndTime.In(loc).Format(TimeFormatShort), formatTime(report.Time.MinutesRemaining)),
+		fmt.Sprintf("%.0f/min", report.BurnRate),
+		report.Status,
+	)
+	return strings.Join(parts, " · "), statusExitCode(report.Status)
+}
+
+// statusExitCode maps a session status to the exit code of status --oneline
+func statusExitCode(status string) int {
+	switch status {
+	case "WARNING":
+		return ExitWarning
+	case "CRITICAL", "LIMIT EXCEEDED":
+		return ExitLimitExceeded
+	default:
+		return ExitOK
+	}
+}
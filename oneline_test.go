@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFormatOneline(t *testing.T) {
+	end := time.Date(2025, 6, 20, 15, 0, 0, 0, time.UTC)
+	report := func(status string, noLimit bool) *StatusReport {
+		return &StatusReport{
+			EndTime:  end,
+			Tokens:   TokenMetrics{Used: 63000, Limit: 140000, Percentage: 45},
+			Time:     TimeMetrics{MinutesRemaining: 130},
+			BurnRate: 120,
+			Status:   status,
+			NoLimit:  noLimit,
+		}
+	}
+	apiReport := report("OK", true)
+	apiReport.Plan = PlanAPI
+	apiReport.Budget = CostMetrics{Spent: 4.2, Budget: 10, Percentage: 42, Period: BudgetPeriodDay}
+
+	tests := []struct {
+		name         string
+		report       *StatusReport
+		err          error
+		expected     string
+		expectedCode int
+	}{
+		{"OK", report("OK", false), nil, "CC 45% 63,000/140,000 · reset 15:00 (2h 10m) · 120/min · OK", ExitOK},
+		{"Warning", report("WARNING", false), nil, "CC 45% 63,000/140,000 · reset 15:00 (2h 10m) · 120/min · WARNING", ExitWarning},
+		{"Limit exceeded", report("LIMIT EXCEEDED", false), nil, "CC 45% 63,000/140,000 · reset 15:00 (2h 10m) · 120/min · LIMIT EXCEEDED", ExitLimitExceeded},
+		{"No limit", report("OK", true), nil, "CC 63,000 tokens · reset 15:00 (2h 10m) · 120/min · OK", ExitOK},
+		{"Critical", report("CRITICAL", false), nil, "CC 45% 63,000/140,000 · reset 15:00 (2h 10m) · 120/min · CRITICAL", ExitLimitExceeded},
+		{"API plan", apiReport, nil, "CC 42% $4.20/$10.00 per day · reset 15:00 (2h 10m) · 120/min · OK", ExitOK},
+		{"No active session", nil, &NoActiveSessionError{}, "CC idle", ExitOK},
+		{"No data", nil, errors.New("Failed to get usage data"), "CC -- Failed to get usage data", ExitUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, code := formatOneline(tt.report, tt.err, time.UTC)
+			if line != tt.expected || code != tt.expectedCode {
+				t.Errorf("formatOneline() = %q, %d, expected %q, %d", line, code, tt.expected, tt.expectedCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Output formats for one-shot status
+const (
+	OutputTUI   = "tui"
+	OutputPlain = "plain" // Appended frames without colors, chosen automatically when stdout is not a terminal
+	OutputJSON  = "json"
+)
+
+// StatusReport is the machine-readable snapshot of the active session
+type StatusReport struct {
+	GeneratedAt  time.Time         `json:"generatedAt"`
+	Plan         string            `json:"plan"`
+	StartTime    time.Time         `json:"startTime"`
+	BlockStart   string            `json:"blockStart"` // ccusage's start time as recorded in the estimator state
+	EndTime      time.Time         `json:"endTime"`
+	PrimaryModel string            `json:"primaryModel"`
+	Models       []string          `json:"models"`
+	Tokens       TokenMetrics      `json:"tokens"`
+	Confidence   Confidence        `json:"confidence"`
+	LimitChange  *LimitTransition  `json:"limitChange,omitempty"` // Recent change of the estimated limit
+	ModelSwitch  *ModelSwitch      `json:"modelSwitch,omitempty"` // Opus to Sonnet switch of Max plans
+	Cache        CacheMetrics      `json:"cache"`
+	Time         TimeMetrics       `json:"time"`
+	Activity     *ActivityMetrics  `json:"activity,omitempty"` // Only when message times were loaded
+	BurnRate     float64           `json:"burnRate"`
+	BurnRates    *BurnRates        `json:"burnRates,omitempty"` // Short-term rates, only from a running monitor
+	CostBurnRate float64           `json:"costBurnRate"`        // Raw USD per hour
+	WindowRates  []WindowBurnRate  `json:"windowRates,omitempty"`
+	Budget       CostMetrics       `json:"budget"`
+	Weekly       *WeeklyMetrics    `json:"weekly,omitempty"`
+	SoftLimit    *TokenMetrics     `json:"softLimit,omitempty"`
+	Team         []TeamMemberUsage `json:"team,omitempty"`
+	PredictedEnd time.Time         `json:"predictedEnd"`
+	BurnBand     *BurnBand         `json:"burnBand,omitempty"`
+	PredictedMin *time.Time        `json:"predictedEndEarliest,omitempty"` // Depletion at the band's P90 rate
+	PredictedMax *time.Time        `json:"predictedEndLatest,omitempty"`   // Depletion at the band's P10 rate
+	Status       string            `json:"status"`
+	Alerts       AlertLevels       `json:"alerts"`  // Levels coloring the token bar, in percent
+	NoLimit      bool              `json:"noLimit"` // Limit, percentage and predictedEnd are guesses
+	Cost         CostReport        `json:"cost"`
+}
+
+// CostReport holds raw USD costs alongside display-adjusted values
+type CostReport struct {
+	BlockUSD   float64 `json:"blockUsd"` // Raw cost of the active session block
+	TodayUSD   float64 `json:"todayUsd"` // Raw cost from ccusage
+	CycleUSD   float64 `json:"cycleUsd"` // Raw cost from ccusage
+	Today      float64 `json:"today"`    // After multiplier and currency conversion
+	Cycle      float64 `json:"cycle"`    // After multiplier and currency conversion
+	Currency   string  `json:"currency"`
+	Multiplier float64 `json:"multiplier"`
+}
+
+var statusJSON bool
+
+// NewStatusReport builds a status report from a session
+func NewStatusReport(session *Session, plan string, currentTime time.Time) StatusReport {
+	report := StatusReport{
+		GeneratedAt:  currentTime,
+		Plan:         estimator.GetActualPlan(plan, session.AllBlocks),
+		StartTime:    session.StartTime,
+		BlockStart:   session.Block.StartTime,
+		EndTime:      session.EndTime,
+		PrimaryModel: session.PrimaryModel,
+		Models:       session.CurrentModels,
+		Tokens:       session.Metrics.Tokens,
+		Confidence:   limitConfidence(session.AllBlocks),
+		Cache:        session.Cache,
+		Time:         session.Metrics.Time,
+		BurnRate:     session.BurnRate,
+		BurnRates:    session.RecentRates,
+		CostBurnRate: session.CostBurnRate,
+		WindowRates:  session.WindowRates,
+		Budget:       session.Cost,
+		PredictedEnd: session.GetPredictedEndTime(currentTime),
+		Status:       session.GetStatus(),
+		NoLimit:      session.NoLimit,
+		Cost:         newCostReport(session),
+	}
+	report.Alerts = config.barAlertLevels()
+	if session.Alerts != nil {
+		report.Alerts = *session.Alerts
+	}
+	report.Team = session.Team
+	report.LimitChange = session.LimitChange
+	report.ModelSwitch = session.ModelSwitch
+	if session.BurnBand != nil {
+		predicted := session.PredictedEndRange(currentTime)
+		report.BurnBand = session.BurnBand
+		report.PredictedMin = &predicted.Start
+		report.PredictedMax = &predicted.End
+	}
+	report.Activity = session.Activity(currentTime)
+	if session.SoftLimit.Limit > 0 {
+		report.SoftLimit = &session.SoftLimit
+	}
+	if config.WeeklyBar {
+		report.Weekly = &session.Weekly
+	}
+	return report
+}
+
+// newCostReport collects raw and display-adjusted costs for a session
+func newCostReport(session *Session) CostReport {
+	report := CostReport{
+		BlockUSD:   session.Block.CostUSD,
+		TodayUSD:   session.TodayCost,
+		CycleUSD:   session.Cycle.Cost,
+		Today:      adjustCost(session.TodayCost),
+		Cycle:      adjustCost(session.Cycle.Cost),
+		Currency:   "USD",
+		Multiplier: config.CostMultiplier,
+	}
+	if currency != nil {
+		report.Today = currency.Convert(report.Today)
+		report.Cycle = currency.Convert(report.Cycle)
+		report.Currency = currency.Code()
+	}
+	return report
+}
+
+// runStatus prints the current session once, as text or JSON
+func runStatus(cmd *cobra.Command, args []string) {
+	if statusJSON {
+		config.Output = OutputJSON
+	}
+	fmt.Fprint(os.Stderr, display.RenderSafeModeBanner(configFile))
+	if statusOneline {
+		runStatusOneline()
+		return
+	}
+
+	// Prefer the daemon's snapshot, which avoids running ccusage
+	if !statusFresh {
+		if snapshot, ok := readSnapshot(snapshotPath, time.Now()); ok {
+			printSnapshot(snapshot)
+			return
+		}
+	}
+
+	estimator.SetEstimationMethod(estimationMethod)
+	tokenLimit := getInitialTokenLimit(config.Plan)
+	session, err := loadSession(config.Plan, config.Profile, &tokenLimit)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if config.Output != OutputJSON {
+		terminal := NewTerminal(os.Stdout)
+		display.SetWidth(terminal.Width())
+		fmt.Fprintln(terminal, display.Render(session, estimator, config.Plan))
+		return
+	}
+
+	output, err := json.MarshalIndent(NewStatusReport(session, config.Plan, time.Now()), "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println(string(output))
+}
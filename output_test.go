@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewStatusReport(t *testing.T) {
+	oldConfig, oldCurrency, oldEstimator := config, currency, estimator
+	defer func() { config, currency, estimator = oldConfig, oldCurrency, oldEstimator }()
+
+	config = NewConfig()
+	config.CostMultiplier = 1.5
+	currency = NewCurrencyConverter("EUR", 0.5)
+	estimator = NewTokenLimitEstimator()
+
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	session := newTestSession(start, 3500, 7000)
+	session.Block.StartTime = "2025-06-20T09:00:00.000Z"
+	session.TodayCost = 10
+	session.Cycle.Cost = 40
+
+	report := NewStatusReport(session, "pro", start.Add(time.Hour))
+	if report.Cost.TodayUSD != 10 || report.Cost.CycleUSD != 40 {
+		t.Errorf("raw costs = %.2f/%.2f, expected 10.00/40.00", report.Cost.TodayUSD, report.Cost.CycleUSD)
+	}
+	if report.Cost.Today != 7.5 || report.Cost.Cycle != 30 {
+		t.Errorf("adjusted costs = %.2f/%.2f, expected 7.50/30.00", report.Cost.Today, report.Cost.Cycle)
+	}
+	if report.Cost.Currency != "EUR" {
+		t.Errorf("Currency = %s, expected EUR", report.Cost.Currency)
+	}
+	if report.Tokens.Percentage != 50 || report.Status != "OK" {
+		t.Errorf("tokens = %+v status = %s, expected 50%% OK", report.Tokens, report.Status)
+	}
+	// Limit hits are keyed by ccusage's start time, which the estimator state stores as is
+	if report.BlockStart != session.Block.StartTime {
+		t.Errorf("BlockStart = %s, expected %s", report.BlockStart, session.Block.StartTime)
+	}
+
+	output, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+	if !strings.Contains(string(output), `"todayUsd":10`) {
+		t.Errorf("JSON output missing raw cost: %s", output)
+	}
+}
+
+func TestStatusReportShortNumbers(t *testing.T) {
+	oldConfig, oldEstimator, oldNumbers := config, estimator, numbers
+	defer func() { config, estimator, numbers = oldConfig, oldEstimator, oldNumbers }()
+
+	config = NewConfig()
+	estimator = NewTokenLimitEstimator()
+	numbers = NumberFormat{Style: NumberFormatSI, Separator: ",", Decimal: "."}
+
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	output, err := json.Marshal(NewStatusReport(newTestSession(start, 1234567, 2000000), "max20", start.Add(time.Hour)))
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+	if !strings.Contains(string(output), `"used":1234567`) {
+		t.Errorf("JSON output lost precision with short numbers: %s", output)
+	}
+	if formatNumber(1234567) != "1.23M" {
+		t.Errorf("formatNumber(1234567) = %q, expected 1.23M", formatNumber(1234567))
+	}
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// Minimal Parquet writer for export: one row group with a single uncompressed, PLAIN-encoded
+// data page per optional column. DuckDB, pandas and Spark read it without extra dependencies.
+
+// Parquet physical types, repetitions, converted types and encodings
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+
+	parquetOptional = 1
+	parquetUTF8     = 0
+	parquetPlain    = 0
+	parquetRLE      = 3
+)
+
+// Thrift compact protocol field types
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// parquetColumn is the written location of a column chunk
+type parquetColumn struct {
+	offset int64
+	size   int64
+}
+
+// writeParquet writes the table as a Parquet file; values must match their column's type or be nil
+func writeParquet(w io.Writer, table exportTable) error {
+	var file bytes.Buffer
+	file.WriteString("PAR1")
+
+	chunks := make([]parquetColumn, len(table.columns))
+	if len(table.rows) > 0 {
+		for i, column := range table.columns {
+			page := parquetPage(column.kind, table.rows, i)
+
+			header := &thriftWriter{}
+			header.begin()
+			header.i32(1, 0) // DATA_PAGE
+			header.i32(2, int32(len(page)))
+			header.i32(3, int32(len(page)))
+			header.structField(5)
+			header.i32(1, int32(len(table.rows)))
+			header.i32(2, parquetPlain)
+			header.i32(3, parquetRLE)
+			header.i32(4, parquetRLE)
+			header.end()
+			header.end()
+
+			chunks[i] = parquetColumn{offset: int64(file.Len()), size: int64(header.buf.Len() + len(page))}
+			file.Write(header.buf.Bytes())
+			file.Write(page)
+		}
+	}
+
+	footer := parquetFooter(table, chunks)
+	file.Write(footer)
+	_ = binary.Write(&file, binary.LittleEndian, uint32(len(footer)))
+	file.WriteString("PAR1")
+
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+// parquetPage encodes the definition levels and present values of one column
+func parquetPage(kind int, rows [][]any, index int) []byte {
+	var levels, values bytes.Buffer
+	var bools []bool
+	run, runValue := 0, false
+
+	flush := func() {
+		if run == 0 {
+			return
+		}
+		writeUvarint(&levels, uint64(run)<<1)
+		if runValue {
+			levels.WriteByte(1)
+		} else {
+			levels.WriteByte(0)
+		}
+	}
+
+	for _, row := range rows {
+		value := row[index]
+		present := value != nil
+		if run > 0 && present != runValue {
+			flush()
+			run = 0
+		}
+		run, runValue = run+1, present
+
+		switch v := value.(type) {
+		case bool:
+			bools = append(bools, v)
+		case int64:
+			_ = binary.Write(&values, binary.LittleEndian, v)
+		case float64:
+			_ = binary.Write(&values, binary.LittleEndian, math.Float64bits(v))
+		case string:
+			_ = binary.Write(&values, binary.LittleEndian, uint32(len(v)))
+			values.WriteString(v)
+		}
+	}
+	flush()
+
+	if kind == parquetBoolean {
+		packed := make([]byte, (len(bools)+7)/8)
+		for i, v := range bools {
+			if v {
+				packed[i/8] |= 1 << (i % 8)
+			}
+		}
+		values.Write(packed)
+	}
+
+	var page bytes.Buffer
+	_ = binary.Write(&page, binary.LittleEndian, uint32(levels.Len()))
+	page.Write(levels.Bytes())
+	page.Write(values.Bytes())
+	return page.Bytes()
+}
+
+// parquetFooter encodes the FileMetaData with the schema and the single row group
+func parquetFooter(table exportTable, chunks []parquetColumn) []byte {
+	rows := int64(len(table.rows))
+	meta := &thriftWriter{}
+	meta.begin()
+	meta.i32(1, 1) // Format version
+
+	meta.list(2, thriftStruct, len(table.columns)+1)
+	meta.begin()
+	meta.binary(4, "schema")
+	meta.i32(5, int32(len(table.columns)))
+	meta.end()
+	for _, column := range table.columns {
+		meta.begin()
+		meta.i32(1, int32(column.kind))
+		meta.i32(3, parquetOptional)
+		meta.binary(4, column.name)
+		if column.kind == parquetByteArray {
+			meta.i32(6, parquetUTF8)
+		}
+		meta.end()
+	}
+
+	meta.i64(3, rows)
+
+	if rows == 0 {
+		meta.list(4, thriftStruct, 0)
+	} else {
+		var total int64
+		meta.list(4, thriftStruct, 1)
+		meta.begin()
+		meta.list(1, thriftStruct, len(table.columns))
+		for i, column := range table.columns {
+			chunk := chunks[i]
+			total += chunk.size
+			meta.begin()
+			meta.i64(2, chunk.offset)
+			meta.structField(3)
+			meta.i32(1, int32(column.kind))
+			meta.list(2, thriftI32, 2)
+			meta.varint(parquetPlain)
+			meta.varint(parquetRLE)
+			meta.list(3, thriftBinary, 1)
+			meta.bytes(column.name)
+			meta.i32(4, 0) // UNCOMPRESSED
+			meta.i64(5, rows)
+			meta.i64(6, chunk.size)
+			meta.i64(7, chunk.size)
+			meta.i64(9, chunk.offset)
+			meta.end()
+			meta.end()
+		}
+		meta.i64(2, total)
+		meta.i64(3, rows)
+		meta.end()
+	}
+
+	meta.binary(6, "cctop version "+version)
+	meta.end()
+	return meta.buf.Bytes()
+}
+
+// thriftWriter encodes structs with the Thrift compact protocol used by Parquet metadata
+type thriftWriter struct {
+	buf  bytes.Buffer
+	last []int16 // Last field id of each open struct
+}
+
+func (t *thriftWriter) begin() { t.last = append(t.last, 0) }
+
+func (t *thriftWriter) end() {
+	t.buf.WriteByte(0)
+	t.last = t.last[:len(t.last)-1]
+}
+
+func (t *thriftWriter) field(id int16, kind byte) {
+	last := &t.last[len(t.last)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | kind)
+	} else {
+		t.buf.WriteByte(kind)
+		t.varint(int64(id))
+	}
+	*last = id
+}
+
+// varint writes a zigzag-encoded integer, also used for i32 list elements
+func (t *thriftWriter) varint(v int64) { writeUvarint(&t.buf, uint64(v<<1^v>>63)) }
+
+// bytes writes a length-prefixed string, also used for binary list elements
+func (t *thriftWriter) bytes(s string) {
+	writeUvarint(&t.buf, uint64(len(s)))
+	t.buf.WriteString(s)
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.varint(v)
+}
+
+func (t *thriftWriter) binary(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.bytes(s)
+}
+
+func (t *thriftWriter) list(id int16, kind byte, size int) {
+	t.field(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | kind)
+	} else {
+		t.buf.WriteByte(0xf0 | kind)
+		writeUvarint(&t.buf, uint64(size))
+	}
+}
+
+func (t *thriftWriter) structField(id int16) {
+	t.field(id, thriftStruct)
+	t.begin()
+}
+
+func writeUvarint(buf *bytes.Buffer, v uint64) {
+	buf.Write(binary.AppendUvarint(nil, v))
+}
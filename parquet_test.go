@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// thriftReader decodes compact protocol structs into maps of field id to value
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) varint() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) value(kind byte) any {
+	switch kind {
+	case thriftI32, thriftI64:
+		return r.varint()
+	case thriftBinary:
+		n := int(r.uvarint())
+		r.pos += n
+		return string(r.data[r.pos-n : r.pos])
+	case thriftList:
+		header := r.data[r.pos]
+		r.pos++
+		size, elem := int(header>>4), header&0x0f
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		list := make([]any, size)
+		for i := range list {
+			list[i] = r.value(elem)
+		}
+		return list
+	case thriftStruct:
+		fields := map[int16]any{}
+		var last int16
+		for {
+			header := r.data[r.pos]
+			r.pos++
+			if header == 0 {
+				return fields
+			}
+			id := last + int16(header>>4)
+			if header>>4 == 0 {
+				id = int16(r.varint())
+			}
+			fields[id] = r.value(header & 0x0f)
+			last = id
+		}
+	}
+	panic("unsupported thrift type")
+}
+
+// readParquet decodes a file written by writeParquet back into column names and rows
+func readParquet(t *testing.T, data []byte) ([]string, [][]any) {
+	t.Helper()
+	if !bytes.HasPrefix(data, []byte("PAR1")) || !bytes.HasSuffix(data, []byte("PAR1")) {
+		t.Fatalf("missing PAR1 magic")
+	}
+	length := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := &thriftReader{data: data[len(data)-8-length : len(data)-8]}
+	meta := footer.value(thriftStruct).(map[int16]any)
+
+	schema := meta[2].([]any)
+	var names []string
+	var kinds []int64
+	for _, element := range schema[1:] {
+		fields := element.(map[int16]any)
+		names = append(names, fields[4].(string))
+		kinds = append(kinds, fields[1].(int64))
+	}
+
+	rows := make([][]any, meta[3].(int64))
+	for i := range rows {
+		rows[i] = make([]any, len(names))
+	}
+	for _, group := range meta[4].([]any) {
+		for c, chunk := range group.(map[int16]any)[1].([]any) {
+			offset := chunk.(map[int16]any)[3].(map[int16]any)[9].(int64)
+			page := &thriftReader{data: data, pos: int(offset)}
+			header := page.value(thriftStruct).(map[int16]any)
+			body := data[page.pos : page.pos+int(header[3].(int64))]
+
+			levelsLength := int(binary.LittleEndian.Uint32(body))
+			levels := &thriftReader{data: body[4 : 4+levelsLength]}
+			var present []bool
+			for levels.pos < len(levels.data) {
+				run := int(levels.uvarint() >> 1)
+				value := levels.data[levels.pos] == 1
+				levels.pos++
+				for range run {
+					present = append(present, value)
+				}
+			}
+
+			values := body[4+levelsLength:]
+			bit := 0
+			for r, ok := range present {
+				if !ok {
+					continue
+				}
+				switch kinds[c] {
+				case parquetBoolean:
+					rows[r][c] = values[bit/8]&(1<<(bit%8)) != 0
+					bit++
+				case parquetInt64:
+					rows[r][c] = int64(binary.LittleEndian.Uint64(values))
+					values = values[8:]
+				case parquetDouble:
+					rows[r][c] = math.Float64frombits(binary.LittleEndian.Uint64(values))
+					values = values[8:]
+				case parquetByteArray:
+					n := int(binary.LittleEndian.Uint32(values))
+					rows[r][c] = string(values[4 : 4+n])
+					values = values[4+n:]
+				}
+			}
+		}
+	}
+	return names, rows
+}
+
+func TestWriteParquet(t *testing.T) {
+	table := exportTable{
+		columns: []exportColumn{
+			{"start", parquetByteArray}, {"active", parquetBoolean}, {"tokens", parquetInt64}, {"cost_usd", parquetDouble},
+		},
+		rows: [][]any{
+			{"2025-06-20T09:00:00Z", true, int64(100), 1.25},
+			{"2025-06-20T14:00:00Z", false, int64(-3), nil},
+			{nil, true, int64(1 << 40), nil},
+		},
+	}
+	for i := range 20 {
+		table.rows = append(table.rows, []any{"", i%3 == 0, int64(i), float64(i) / 2})
+	}
+
+	tests := []struct {
+		name  string
+		table exportTable
+	}{
+		{name: "Rows", table: table},
+		{name: "Empty", table: exportTable{columns: table.columns}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			if err := writeParquet(&buffer, tt.table); err != nil {
+				t.Fatal(err)
+			}
+			names, rows := readParquet(t, buffer.Bytes())
+
+			expectedNames := []string{"start", "active", "tokens", "cost_usd"}
+			if !reflect.DeepEqual(names, expectedNames) {
+				t.Errorf("columns = %v, expected %v", names, expectedNames)
+			}
+			if len(rows) != len(tt.table.rows) {
+				t.Fatalf("rows = %d, expected %d", len(rows), len(tt.table.rows))
+			}
+			for i := range rows {
+				if !reflect.DeepEqual(rows[i], tt.table.rows[i]) {
+					t.Errorf("row %d = %v, expected %v", i, rows[i], tt.table.rows[i])
+				}
+			}
+		})
+	}
+}
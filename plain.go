@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// runPlainMonitor refreshes like the TUI but appends each frame to stdout without
+// clearing the screen or colors, so `cctop | tee log.txt` and CI logs stay readable
+func runPlainMonitor() {
+	color.NoColor = true
+	out := &plainTerminal{out: os.Stdout}
+
+	var latest atomic.Pointer[exitState]
+	printBannerOnInterrupt(&latest)
+
+	tokenLimit := getInitialTokenLimit(config.Plan)
+	for {
+		session, err := loadSession(config.Plan, config.Profile, &tokenLimit)
+		latest.Store(&exitState{session: session, err: err})
+		frame := renderPlainFrame(session, err, time.Now())
+		fmt.Fprint(out, frame)
+		castRecorder.Output(frame, time.Now())
+		waitForRefresh(refreshInterval(session, time.Now()), projectWatcher.Changes(), nil)
+	}
+}
+
+// renderPlainFrame renders one refresh headed by a timestamped separator
+func renderPlainFrame(session *Session, err error, currentTime time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s ---\n", currentTime.In(display.timezone).Format("2006-01-02 15:04:05"))
+
+	var idle *NoActiveSessionError
+	switch {
+	case errors.As(err, &idle):
+		b.WriteString(display.RenderIdle(idle.Idle, currentTime))
+	case err != nil:
+		b.WriteString(display.RenderError(err))
+	default:
+		b.WriteString(display.Render(session, estimator, config.Plan))
+	}
+
+	if !strings.HasSuffix(b.String(), "\n") {
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
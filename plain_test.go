@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderPlainFrame(t *testing.T) {
+	oldDisplay := display
+	defer func() { display = oldDisplay }()
+	display = NewDisplay("UTC")
+
+	now := time.Date(2025, 6, 20, 14, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{name: "Idle", err: &NoActiveSessionError{}, expected: "No active session"},
+		{name: "Error", err: errors.New("Failed to get usage data"), expected: "Failed to get usage data"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame := renderPlainFrame(nil, tt.err, now)
+			if !strings.HasPrefix(frame, "--- 2025-06-20 14:00:00 ---\n") {
+				t.Errorf("renderPlainFrame() header = %q, expected the timestamp separator", strings.SplitN(frame, "\n", 2)[0])
+			}
+			if !strings.Contains(frame, tt.expected) {
+				t.Errorf("renderPlainFrame() missing %q:\n%s", tt.expected, frame)
+			}
+			if !strings.HasSuffix(frame, "\n\n") {
+				t.Errorf("renderPlainFrame() = %q, expected a blank line between frames", frame)
+			}
+		})
+	}
+}
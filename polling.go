@@ -0,0 +1,63 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// dailyCache holds the last ccusage daily result so it can be fetched less often than blocks
+var dailyCache struct {
+	mu        sync.Mutex
+	days      []DailyUsage
+	fetchedAt time.Time
+}
+
+// pollInterval returns how long to wait before the next refresh.
+// Sessions with recent activity refresh at UpdateInterval, idle ones back off to IdleInterval.
+// After failed refreshes the wait doubles each time instead.
+func pollInterval(session *Session, currentTime time.Time) time.Duration {
+	if backoff, failing := failureBackoff(); failing {
+		return backoff
+	}
+	if session == nil || !isSessionActive(session.Block, currentTime) {
+		return maxDuration(config.IdleInterval, config.UpdateInterval)
+	}
+	return config.UpdateInterval
+}
+
+// isSessionActive reports whether the block saw activity within IdleThreshold
+func isSessionActive(block *Block, currentTime time.Time) bool {
+	if block == nil || !block.IsActive {
+		return false
+	}
+	lastActivity, err := time.Parse(time.RFC3339, block.ActualEndTime)
+	if err != nil {
+		// Without an activity timestamp assume the session is in use
+		return true
+	}
+	return currentTime.Sub(lastActivity) < IdleThreshold
+}
+
+// cachedDailyUsage returns daily usage, refetching at most once per DailyInterval
+func cachedDailyUsage(currentTime time.Time) []DailyUsage {
+	dailyCache.mu.Lock()
+	defer dailyCache.mu.Unlock()
+
+	if dailyCache.days != nil && currentTime.Sub(dailyCache.fetchedAt) < config.DailyInterval {
+		return dailyCache.days
+	}
+
+	if days := fetchDailyUsage(); days != nil {
+		dailyCache.days = days
+		dailyCache.fetchedAt = currentTime
+	}
+	return dailyCache.days
+}
+
+// maxDuration returns the longer of two durations
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Profile is a named Claude config directory (e.g. work, personal)
+type Profile struct {
+	Name string
+	Dir  string
+}
+
+// ProfileUsage holds a profile's token usage in the active session window
+type ProfileUsage struct {
+	Name   string
+	Tokens int
+}
+
+// parseProfiles parses --claude-dir values of the form "name=path" or "path".
+// Without a name, the directory's base name is used, extended with parent
+// directories while it collides with another profile (a/.claude, b/.claude).
+func parseProfiles(specs []string) []Profile {
+	profiles := make([]Profile, 0, len(specs))
+	var unnamed []int
+	var named, paths []string
+	for _, spec := range specs {
+		name, dir, found := strings.Cut(spec, "=")
+		if found {
+			named = append(named, name)
+		} else {
+			dir = spec
+			unnamed = append(unnamed, len(profiles))
+			paths = append(paths, spec)
+		}
+		profiles = append(profiles, Profile{Name: name, Dir: expandHome(dir)})
+	}
+
+	for i, name := range uniquePathNames(paths, named) {
+		profiles[unnamed[i]].Name = name
+	}
+	return profiles
+}
+
+// uniquePathNames names each path after its last element, extended with parent
+// directories while the name collides with another path's or a reserved name
+func uniquePathNames(paths, reserved []string) []string {
+	names := make([]string, len(paths))
+	pending := make([]int, len(paths))
+	for i := range paths {
+		pending[i] = i
+	}
+	for depth := 1; len(pending) > 0; depth++ {
+		for _, i := range pending {
+			names[i] = trailingPath(paths[i], depth)
+		}
+		counts := make(map[string]int, len(names)+len(reserved))
+		for _, name := range append(names, reserved...) {
+			counts[name]++
+		}
+		var colliding []int
+		for _, i := range pending {
+			if counts[names[i]] > 1 && trailingPath(paths[i], depth+1) != names[i] {
+				colliding = append(colliding, i)
+			}
+		}
+		pending = colliding
+	}
+	return names
+}
+
+// trailingPath returns the last depth elements of a path joined with /, e.g. b/.claude
+func trailingPath(path string, depth int) string {
+	elements := strings.Split(filepath.ToSlash(filepath.Clean(path)), "/")
+	return strings.Join(elements[max(len(elements)-depth, 0):], "/")
+}
+
+// validProfile checks that --profile names one of the profiles
+func validProfile(name string, profiles []Profile) error {
+	if name == "" {
+		return nil
+	}
+	names := make([]string, 0, len(profiles))
+	for _, profile := range profiles {
+		if profile.Name == name {
+			return nil
+		}
+		names = append(names, profile.Name)
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("unknown profile %q, name profiles with --claude-dir name=path", name)
+	}
+	return fmt.Errorf("unknown profile %q, expected one of %s", name, strings.Join(names, ", "))
+}
+
+// expandHome replaces a leading ~ with the user's home directory.
+// Both ~/ and, on Windows, ~\ are accepted.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
+}
+
+// profileSelection is the profile the last loadSession was scoped to. The TUI switches
+// profiles with a key, so refreshes read it here rather than from config.Profile.
+var profileSelection struct {
+	sync.Mutex
+	name     string
+	selected bool
+}
+
+// selectProfile scopes usage loading to a profile, "" meaning all profiles
+func selectProfile(name string) {
+	profileSelection.Lock()
+	defer profileSelection.Unlock()
+	profileSelection.name, profileSelection.selected = name, true
+}
+
+// selectedProfile returns the profile usage loading is scoped to, --profile until one is selected
+func selectedProfile() string {
+	profileSelection.Lock()
+	defer profileSelection.Unlock()
+	if !profileSelection.selected {
+		return config.Profile
+	}
+	return profileSelection.name
+}
+
+// activeProfiles returns the profiles currently being monitored.
+// With a selected profile only that one is returned, otherwise all are aggregated.
+func activeProfiles() []Profile {
+	name := selectedProfile()
+	if name == "" {
+		return config.Profiles
+	}
+	for _, profile := range config.Profiles {
+		if profile.Name == name {
+			return []Profile{profile}
+		}
+	}
+	return config.Profiles
+}
+
+// profileNames returns the names that can be selected, with "" meaning all profiles
+func profileNames() []string {
+	names := []string{""}
+	for _, profile := range config.Profiles {
+		names = append(names, profile.Name)
+	}
+	return names
+}
+
+// ccusageCommand builds a ccusage command scoped to the active profiles
+func ccusageCommand(args ...string) *exec.Cmd {
+	cmd := exec.Command("ccusage", args...)
+	profiles := activeProfiles()
+	if len(profiles) == 0 {
+		return cmd
+	}
+
+	dirs := make([]string, 0, len(profiles))
+	for _, profile := range profiles {
+		dirs = append(dirs, profile.Dir)
+	}
+	// ccusage accepts a comma-separated list of config directories
+	cmd.Env = append(os.Environ(), "CLAUDE_CONFIG_DIR="+strings.Join(dirs, ","))
+	return cmd
+}
+
+// profileProjectsDirs returns the JSONL project directories for the active profiles
+func profileProjectsDirs() []string {
+	profiles := activeProfiles()
+	dirs := make([]string, 0, len(profiles))
+	for _, profile := range profiles {
+		dirs = append(dirs, filepath.Join(profile.Dir, "projects"))
+	}
+	return dirs
+}
+
+// calculateProfileUsage sums each profile's tokens within the block's time range
+func calculateProfileUsage(block *Block, currentTime time.Time) []ProfileUsage {
+	endTime := block.ActualEndTime
+	if endTime == "" {
+		endTime = currentTime.Format(time.RFC3339)
+	}
+
+	usage := make([]ProfileUsage, 0, len(config.Profiles))
+	for _, profile := range config.Profiles {
+		reader := &MessageTokenReader{claudeProjectsDirs: []string{filepath.Join(profile.Dir, "projects")}, tail: defaultJSONLTail()}
+		tokens, err := reader.GetBlockTokens(block.StartTime, endTime)
+		if err != nil {
+			continue
+		}
+		total := 0
+		for _, t := range tokens {
+			total += t
+		}
+		usage = append(usage, ProfileUsage{Name: profile.Name, Tokens: total})
+	}
+	return usage
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseProfiles(t *testing.T) {
+	homeDir, _ := os.UserHomeDir()
+	profiles := parseProfiles([]string{"work=/data/claude-work", "/home/me/.config/claude", "alt=~/claude-alt"})
+
+	expected := []Profile{
+		{Name: "work", Dir: "/data/claude-work"},
+		{Name: "claude", Dir: "/home/me/.config/claude"},
+		{Name: "alt", Dir: filepath.Join(homeDir, "claude-alt")},
+	}
+	if len(profiles) != len(expected) {
+		t.Fatalf("len(profiles) = %d, expected %d", len(profiles), len(expected))
+	}
+	for i := range expected {
+		if profiles[i] != expected[i] {
+			t.Errorf("profiles[%d] = %+v, expected %+v", i, profiles[i], expected[i])
+		}
+	}
+}
+
+func TestParseProfilesCollidingNames(t *testing.T) {
+	profiles := parseProfiles([]string{"/home/me/a/.claude", "/home/me/b/.claude", "/srv/claude"})
+
+	expected := []string{"a/.claude", "b/.claude", "claude"}
+	for i, name := range expected {
+		if profiles[i].Name != name {
+			t.Errorf("profiles[%d].Name = %q, expected %q", i, profiles[i].Name, name)
+		}
+	}
+}
+
+func TestValidProfile(t *testing.T) {
+	profiles := []Profile{{Name: "work", Dir: "/w"}, {Name: "home", Dir: "/h"}}
+	if err := validProfile("", profiles); err != nil {
+		t.Errorf("validProfile(all) error = %v", err)
+	}
+	if err := validProfile("home", profiles); err != nil {
+		t.Errorf("validProfile(home) error = %v", err)
+	}
+	if err := validProfile("hme", profiles); err == nil {
+		t.Error("validProfile(hme) accepted an unknown profile")
+	}
+}
+
+func TestProfileSelection(t *testing.T) {
+	oldConfig := config
+	defer func() { config = oldConfig }()
+
+	config = NewConfig()
+	config.Profiles = []Profile{{Name: "work", Dir: "/w"}, {Name: "home", Dir: "/h"}}
+
+	cmd := ccusageCommand("blocks", "--json")
+	if !containsEnv(cmd.Env, "CLAUDE_CONFIG_DIR=/w,/h") {
+		t.Error("expected all profile directories when no profile is selected")
+	}
+
+	config.Profile = "home"
+	cmd = ccusageCommand("blocks", "--json")
+	if !containsEnv(cmd.Env, "CLAUDE_CONFIG_DIR=/h") {
+		t.Error("expected only the selected profile directory")
+	}
+
+	if next := nextProfile("home"); next != "" {
+		t.Errorf("nextProfile(home) = %q, expected all profiles", next)
+	}
+	if next := nextProfile(""); next != "work" {
+		t.Errorf("nextProfile(all) = %q, expected work", next)
+	}
+}
+
+func TestCalculateProfileUsage(t *testing.T) {
+	oldConfig := config
+	defer func() { config = oldConfig }()
+
+	opts := DefaultFixtureOptions()
+	opts.Sessions = 1
+	opts.LeaveLastOpen = false
+	fixtures := GenerateFixtures(opts)
+
+	dir := t.TempDir()
+	if err := fixtures.Write(filepath.Join(dir, "work")); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+
+	config = NewConfig()
+	config.Profiles = []Profile{
+		{Name: "work", Dir: filepath.Join(dir, "work")},
+		{Name: "empty", Dir: filepath.Join(dir, "missing")},
+	}
+
+	usage := calculateProfileUsage(&fixtures.Blocks[0], time.Now())
+	if len(usage) != 1 || usage[0].Name != "work" || usage[0].Tokens != fixtures.Blocks[0].TotalTokens {
+		t.Errorf("usage = %+v, expected work with %d tokens", usage, fixtures.Blocks[0].TotalTokens)
+	}
+}
+
+func containsEnv(env []string, entry string) bool {
+	for _, e := range env {
+		if e == entry {
+			return true
+		}
+	}
+	return false
+}
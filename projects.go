@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// ProjectUsage is a project's share of the active block's tokens
+type ProjectUsage struct {
+	Name       string
+	Tokens     int
+	Percentage float64
+}
+
+// projectName names a project after its working directory, falling back to
+// the encoded project directory name when the logs do not record one
+func projectName(projectDir, cwd string) string {
+	return filepath.Base(projectPath(projectDir, cwd))
+}
+
+// projectPath identifies a project by its working directory, so projects in
+// directories with the same name (work/api, oss/api) are kept apart
+func projectPath(projectDir, cwd string) string {
+	if cwd != "" {
+		return filepath.Clean(cwd)
+	}
+	return filepath.Base(projectDir)
+}
+
+// summarizeProjects converts per-project tokens, keyed by projectPath, into shares
+// sorted by usage. Projects are named after their directory, with parent directories
+// added where two share a name.
+func summarizeProjects(projectTokens map[string]int) []ProjectUsage {
+	total := 0
+	for _, tokens := range projectTokens {
+		total += tokens
+	}
+	if total == 0 {
+		return nil
+	}
+
+	paths := make([]string, 0, len(projectTokens))
+	for path := range projectTokens {
+		paths = append(paths, path)
+	}
+	names := uniquePathNames(paths, nil)
+
+	projects := make([]ProjectUsage, 0, len(projectTokens))
+	for i, path := range paths {
+		tokens := projectTokens[path]
+		projects = append(projects, ProjectUsage{
+			Name:       names[i],
+			Tokens:     tokens,
+			Percentage: float64(tokens) / float64(total) * 100,
+		})
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i].Tokens == projects[j].Tokens {
+			return projects[i].Name < projects[j].Name
+		}
+		return projects[i].Tokens > projects[j].Tokens
+	})
+	return projects
+}
+
+// loadProjectUsage reads per-project token usage for the block from JSONL logs
+func loadProjectUsage(block *Block, currentTime time.Time) []ProjectUsage {
+	endTime := block.ActualEndTime
+	if endTime == "" {
+		endTime = currentTime.Format(time.RFC3339)
+	}
+	projectTokens, err := NewMessageTokenReader().GetBlockProjectTokens(block.StartTime, endTime)
+	if err != nil {
+		return nil
+	}
+	return summarizeProjects(projectTokens)
+}
+
+// runProjects prints the per-project token breakdown of the active block
+func runProjects(cmd *cobra.Command, args []string) {
+	data := fetchUsageData()
+	if data == nil {
+		fmt.Println("Failed to get usage data")
+		return
+	}
+
+	block := findActiveBlock(data.Blocks)
+	if block == nil {
+		fmt.Println("No active session found")
+		return
+	}
+
+	fmt.Print(display.RenderProjects(loadProjectUsage(block, time.Now())))
+}
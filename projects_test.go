@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetBlockProjectTokens(t *testing.T) {
+	projectsDir := t.TempDir()
+	write := func(project, lines string) {
+		dir := filepath.Join(projectsDir, project)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "session.jsonl"), []byte(lines), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("-home-me-src-cctop", `{"timestamp":"2025-06-20T10:00:00Z","type":"assistant","cwd":"/home/me/src/cctop","message":{"usage":{"input_tokens":100,"output_tokens":200}}}
+{"timestamp":"2025-06-20T10:05:00Z","type":"user","cwd":"/home/me/src/cctop","message":{"usage":{"input_tokens":999}}}
+{"timestamp":"2025-06-20T11:00:00Z","type":"assistant","cwd":"/home/me/src/cctop","message":{"usage":{"input_tokens":50,"output_tokens":50}}}
+`)
+	write("-home-me-src-other", `{"timestamp":"2025-06-20T10:30:00Z","type":"assistant","message":{"usage":{"input_tokens":100,"output_tokens":0}}}
+{"timestamp":"2025-06-20T16:00:00Z","type":"assistant","message":{"usage":{"input_tokens":500,"output_tokens":0}}}
+`)
+
+	reader := &MessageTokenReader{claudeProjectsDirs: []string{projectsDir}}
+	result, err := reader.GetBlockProjectTokens("2025-06-20T09:00:00Z", "2025-06-20T14:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]int{"/home/me/src/cctop": 400, "-home-me-src-other": 100}
+	if len(result) != len(expected) {
+		t.Fatalf("GetBlockProjectTokens() = %v, expected %v", result, expected)
+	}
+	for name, tokens := range expected {
+		if result[name] != tokens {
+			t.Errorf("tokens[%s] = %d, expected %d", name, result[name], tokens)
+		}
+	}
+}
+
+func TestSummarizeProjects(t *testing.T) {
+	projects := summarizeProjects(map[string]int{"b": 100, "a": 100, "c": 200})
+	expected := []ProjectUsage{
+		{Name: "c", Tokens: 200, Percentage: 50},
+		{Name: "a", Tokens: 100, Percentage: 25},
+		{Name: "b", Tokens: 100, Percentage: 25},
+	}
+	if len(projects) != len(expected) {
+		t.Fatalf("summarizeProjects() = %v, expected %v", projects, expected)
+	}
+	for i := range expected {
+		if projects[i] != expected[i] {
+			t.Errorf("projects[%d] = %+v, expected %+v", i, projects[i], expected[i])
+		}
+	}
+
+	if result := summarizeProjects(nil); result != nil {
+		t.Errorf("summarizeProjects(nil) = %v, expected nil", result)
+	}
+}
+
+func TestSummarizeProjectsSameName(t *testing.T) {
+	projects := summarizeProjects(map[string]int{"/home/me/work/api": 200, "/home/me/oss/api": 100, "/home/me/cctop": 100})
+	expected := []ProjectUsage{
+		{Name: "work/api", Tokens: 200, Percentage: 50},
+		{Name: "cctop", Tokens: 100, Percentage: 25},
+		{Name: "oss/api", Tokens: 100, Percentage: 25},
+	}
+	if len(projects) != len(expected) {
+		t.Fatalf("summarizeProjects() = %v, expected %v", projects, expected)
+	}
+	for i := range expected {
+		if projects[i] != expected[i] {
+			t.Errorf("projects[%d] = %+v, expected %+v", i, projects[i], expected[i])
+		}
+	}
+}
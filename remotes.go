@@ -0,0 +1,350 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Remote is another machine running Claude Code against the same account. Its
+// Claude logs are mirrored into the state directory and merged like a --claude-dir.
+type Remote struct {
+	Name      string    `json:"name"`
+	Host      string    `json:"host"` // SSH destination, e.g. user@host
+	Dir       string    `json:"dir"`  // Claude config directory on the remote machine
+	LastSync  time.Time `json:"lastSync,omitzero"`
+	LastError string    `json:"lastError,omitempty"`
+}
+
+var (
+	remoteName string
+	remoteDir  string
+)
+
+// remoteNamePattern matches characters not allowed in remote names, which are also directory names
+var remoteNamePattern = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// defaultRemotesPath returns the list of remotes in the cctop state directory
+func defaultRemotesPath() string {
+	stateDir := cctopStateDir()
+	if stateDir == "" {
+		return ""
+	}
+	return filepath.Join(stateDir, "remotes.json")
+}
+
+// remoteMirrorDir returns where a remote's Claude logs are mirrored
+func remoteMirrorDir(remotesPath, name string) string {
+	return filepath.Join(filepath.Dir(remotesPath), "remotes", name)
+}
+
+// remoteNameFromHost derives a remote name from an SSH destination, e.g. "build" for user@build.example.com
+func remoteNameFromHost(host string) string {
+	if _, after, found := strings.Cut(host, "@"); found {
+		host = after
+	}
+	host, _, _ = strings.Cut(host, ".")
+	host, _, _ = strings.Cut(host, ":")
+	return strings.Trim(remoteNamePattern.ReplaceAllString(host, "-"), "-")
+}
+
+// loadRemotes reads the configured remotes; a missing file means none
+func loadRemotes(path string) ([]Remote, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var remotes []Remote
+	if err := json.Unmarshal(data, &remotes); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return remotes, nil
+}
+
+// saveRemotes writes the configured remotes
+func saveRemotes(path string, remotes []Remote) error {
+	if path == "" {
+		return fmt.Errorf("no state directory for remotes")
+	}
+	data, err := json.MarshalIndent(remotes, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'), 0o644)
+}
+
+// updateRemotes loads the remotes, applies change and saves the result while holding
+// the remotes file lock, so concurrent adds, removes and syncs don't overwrite each other
+func updateRemotes(path string, change func(remotes []Remote) ([]Remote, error)) error {
+	if path == "" {
+		return fmt.Errorf("no state directory for remotes")
+	}
+	unlock, err := lockStateFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	remotes, err := loadRemotes(path)
+	if err != nil {
+		return err
+	}
+	if remotes, err = change(remotes); err != nil {
+		return err
+	}
+	return saveRemotes(path, remotes)
+}
+
+// withRemoteProfiles adds a profile per remote mirror. Without --claude-dir the local
+// directories are added as well, named "local", since ccusage then only reads the
+// directories it is given.
+func withRemoteProfiles(profiles []Profile, remotes []Remote, remotesPath string, localDirs []string) []Profile {
+	if len(remotes) == 0 {
+		return profiles
+	}
+	if len(profiles) == 0 {
+		for i, dir := range localDirs {
+			name := "local"
+			if i > 0 {
+				name = "local-" + filepath.Base(dir)
+			}
+			profiles = append(profiles, Profile{Name: name, Dir: dir})
+		}
+	}
+	for _, remote := range remotes {
+		profiles = append(profiles, Profile{Name: remote.Name, Dir: remoteMirrorDir(remotesPath, remote.Name)})
+	}
+	return profiles
+}
+
+// rsyncArgs returns the arguments copying a remote's JSONL logs into its mirror.
+// Only *.jsonl files are transferred and files deleted remotely are kept, since
+// ccusage may still need their blocks.
+func rsyncArgs(remote Remote, mirror string) []string {
+	return []string{
+		"-az", "--timeout=30", "-e", "ssh -o BatchMode=yes -o ConnectTimeout=10",
+		"--include=*/", "--include=*.jsonl", "--exclude=*", "--prune-empty-dirs",
+		remote.Host + ":" + strings.TrimSuffix(remote.Dir, "/") + "/projects/",
+		filepath.Join(mirror, "projects") + string(filepath.Separator),
+	}
+}
+
+// validHost rejects SSH destinations that ssh or rsync would read as an option
+func validHost(host string) error {
+	if host == "" || strings.HasPrefix(host, "-") {
+		return fmt.Errorf("invalid remote host %q", host)
+	}
+	return nil
+}
+
+// syncRemote mirrors one remote's Claude logs with rsync over SSH
+func syncRemote(remote Remote, mirror string) error {
+	if err := validHost(remote.Host); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(mirror, "projects"), 0o755); err != nil {
+		return err
+	}
+	output, err := exec.Command("rsync", rsyncArgs(remote, mirror)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("rsync from %s: %v: %s", remote.Host, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// syncRemotes mirrors every remote and records the outcome in the remotes file
+func syncRemotes(path string, currentTime time.Time) []error {
+	remotes, err := loadRemotes(path)
+	if err != nil {
+		return []error{err}
+	}
+	var errs []error
+	for i, remote := range remotes {
+		if err := syncRemote(remote, remoteMirrorDir(path, remote.Name)); err != nil {
+			remotes[i].LastError = err.Error()
+			errs = append(errs, err)
+			continue
+		}
+		remotes[i].LastSync = currentTime
+		remotes[i].LastError = ""
+	}
+	if len(remotes) > 0 {
+		if err := recordSyncs(path, remotes); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// recordSyncs stores the outcome of syncing remotes. Syncing takes a while, so the
+// file is read again and only remotes still there with the same host are updated:
+// remotes added or removed meanwhile by another cctop are kept as they are.
+func recordSyncs(path string, synced []Remote) error {
+	return updateRemotes(path, func(current []Remote) ([]Remote, error) {
+		for i := range current {
+			for _, remote := range synced {
+				if remote.Name == current[i].Name && remote.Host == current[i].Host {
+					current[i].LastSync, current[i].LastError = remote.LastSync, remote.LastError
+				}
+			}
+		}
+		return current, nil
+	})
+}
+
+// startRemoteSync mirrors the remotes in the background every --remote-sync while
+// monitoring. New files trigger a refresh through the project watcher.
+func startRemoteSync() {
+	remotes, _ := loadRemotes(defaultRemotesPath())
+	if len(remotes) == 0 || config.RemoteSync <= 0 {
+		return
+	}
+	go func() {
+		for {
+			for _, err := range syncRemotes(defaultRemotesPath(), time.Now()) {
+				logger.Warnf("remote sync: %v", err)
+			}
+			time.Sleep(config.RemoteSync)
+		}
+	}()
+}
+
+// findRemoteClaudeDir asks the remote machine where Claude Code keeps its logs
+func findRemoteClaudeDir(host string) (string, error) {
+	script := `for d in .config/claude .claude; do [ -d "$HOME/$d/projects" ] && echo "$d" && exit 0; done; exit 1`
+	output, err := exec.Command("ssh", "-o", "BatchMode=yes", "-o", "ConnectTimeout=10", host, script).Output()
+	if err != nil {
+		return "", fmt.Errorf("no Claude logs found on %s (use --dir): %v", host, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// runRemoteAdd registers a remote machine and mirrors its logs once
+func runRemoteAdd(cmd *cobra.Command, args []string) {
+	if _, err := exec.LookPath("rsync"); err != nil {
+		fmt.Fprintln(os.Stderr, "rsync is required to mirror remote Claude logs")
+		os.Exit(1)
+	}
+	host := args[0]
+	if err := validHost(host); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	remote := Remote{Name: remoteName, Host: host, Dir: remoteDir}
+	if remote.Name == "" {
+		remote.Name = remoteNameFromHost(host)
+	}
+	if remote.Name == "" || remote.Name == "local" || remoteNamePattern.MatchString(remote.Name) {
+		fmt.Fprintf(os.Stderr, "invalid remote name %q, choose one with --name\n", remote.Name)
+		os.Exit(1)
+	}
+
+	path := defaultRemotesPath()
+	remotes, err := loadRemotes(path)
+	if err == nil {
+		err = checkNewRemote(remotes, remote)
+	}
+	if err == nil && remote.Dir == "" {
+		remote.Dir, err = findRemoteClaudeDir(host)
+	}
+	if err == nil {
+		err = syncRemote(remote, remoteMirrorDir(path, remote.Name))
+	}
+	if err == nil {
+		remote.LastSync = time.Now()
+		// Checked again: another cctop may have added it while mirroring
+		err = updateRemotes(path, func(remotes []Remote) ([]Remote, error) {
+			if err := checkNewRemote(remotes, remote); err != nil {
+				return nil, err
+			}
+			return append(remotes, remote), nil
+		})
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Added remote %s (%s:%s)\n", remote.Name, remote.Host, remote.Dir)
+}
+
+// checkNewRemote fails when a remote with the same name is already configured
+func checkNewRemote(remotes []Remote, remote Remote) error {
+	for _, existing := range remotes {
+		if existing.Name == remote.Name {
+			return fmt.Errorf("remote %q already exists", remote.Name)
+		}
+	}
+	return nil
+}
+
+// runRemoteList prints the remotes and when they were last mirrored
+func runRemoteList(cmd *cobra.Command, args []string) {
+	remotes, err := loadRemotes(defaultRemotesPath())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if len(remotes) == 0 {
+		fmt.Println("No remotes, add one with 'cctop remote add user@host'")
+		return
+	}
+	for _, remote := range remotes {
+		synced := "never synced"
+		if !remote.LastSync.IsZero() {
+			synced = "synced " + remote.LastSync.In(display.timezone).Format("2006-01-02 15:04")
+		}
+		fmt.Printf("%-12s %s:%s  %s\n", remote.Name, remote.Host, remote.Dir, synced)
+		if remote.LastError != "" {
+			fmt.Printf("%-12s %s\n", "", dangerString("last sync failed: %s", remote.LastError))
+		}
+	}
+}
+
+// runRemoteRemove forgets a remote and deletes its mirror
+func runRemoteRemove(cmd *cobra.Command, args []string) {
+	path := defaultRemotesPath()
+	err := updateRemotes(path, func(remotes []Remote) ([]Remote, error) {
+		kept := remotes[:0]
+		for _, remote := range remotes {
+			if remote.Name != args[0] {
+				kept = append(kept, remote)
+			}
+		}
+		if len(kept) == len(remotes) {
+			return nil, fmt.Errorf("no remote named %q", args[0])
+		}
+		return kept, nil
+	})
+	if err == nil {
+		err = os.RemoveAll(remoteMirrorDir(path, args[0]))
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Removed remote %s\n", args[0])
+}
+
+// runRemoteSync mirrors every remote now
+func runRemoteSync(cmd *cobra.Command, args []string) {
+	errs := syncRemotes(defaultRemotesPath(), time.Now())
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	if len(errs) > 0 {
+		os.Exit(1)
+	}
+}
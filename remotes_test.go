@@ -0,0 +1,121 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRemoteNameFromHost(t *testing.T) {
+	tests := []struct {
+		host     string
+		expected string
+	}{
+		{"build", "build"},
+		{"me@build.example.com", "build"},
+		{"me@10.0.0.5", "10"},
+		{"laptop:2222", "laptop"},
+		{"me@My Mac", "My-Mac"},
+	}
+
+	for _, tt := range tests {
+		if result := remoteNameFromHost(tt.host); result != tt.expected {
+			t.Errorf("remoteNameFromHost(%q) = %q, expected %q", tt.host, result, tt.expected)
+		}
+	}
+}
+
+func TestSaveAndLoadRemotes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "remotes.json")
+	if remotes, err := loadRemotes(path); err != nil || remotes != nil {
+		t.Fatalf("loadRemotes() without a file = %v, %v, expected none", remotes, err)
+	}
+
+	synced := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	saved := []Remote{{Name: "build", Host: "me@build", Dir: ".claude", LastSync: synced}}
+	if err := saveRemotes(path, saved); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadRemotes(path)
+	if err != nil || len(loaded) != 1 || loaded[0].Host != "me@build" || !loaded[0].LastSync.Equal(synced) {
+		t.Errorf("loadRemotes() = %+v, %v, expected %+v", loaded, err, saved)
+	}
+}
+
+func TestRecordSyncsKeepsConcurrentChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "remotes.json")
+	synced := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	// Read before syncing: build and old
+	before := []Remote{{Name: "build", Host: "me@build"}, {Name: "old", Host: "me@old"}}
+	// Meanwhile another cctop removed old, re-added build on a new host and added laptop
+	now := []Remote{{Name: "build", Host: "me@build2"}, {Name: "laptop", Host: "me@laptop"}}
+	if err := saveRemotes(path, now); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range before {
+		before[i].LastSync = synced
+	}
+	if err := recordSyncs(path, before); err != nil {
+		t.Fatal(err)
+	}
+	loaded, _ := loadRemotes(path)
+	if len(loaded) != 2 || loaded[0].Host != "me@build2" || loaded[1].Name != "laptop" {
+		t.Fatalf("remotes = %+v, expected the concurrent changes kept", loaded)
+	}
+	if !loaded[0].LastSync.IsZero() {
+		t.Errorf("build on a new host marked synced at %v", loaded[0].LastSync)
+	}
+}
+
+func TestValidHost(t *testing.T) {
+	for host, valid := range map[string]bool{
+		"me@build":               true,
+		"build.example.com":      true,
+		"-oProxyCommand=touch x": false,
+		"":                       false,
+	} {
+		if err := validHost(host); (err == nil) != valid {
+			t.Errorf("validHost(%q) = %v, expected valid %v", host, err, valid)
+		}
+	}
+}
+
+func TestWithRemoteProfiles(t *testing.T) {
+	remotesPath := filepath.Join("state", "remotes.json")
+	remotes := []Remote{{Name: "build"}}
+	mirror := filepath.Join("state", "remotes", "build")
+
+	tests := []struct {
+		name     string
+		profiles []Profile
+		remotes  []Remote
+		expected []Profile
+	}{
+		{"no remotes", nil, nil, nil},
+		{"local directories added", nil, remotes, []Profile{{"local", "/home/me/.config/claude"}, {"local-.claude", "/home/me/.claude"}, {"build", mirror}}},
+		{"claude dirs kept", []Profile{{"work", "/work"}}, remotes, []Profile{{"work", "/work"}, {"build", mirror}}},
+	}
+
+	for _, tt := range tests {
+		result := withRemoteProfiles(tt.profiles, tt.remotes, remotesPath, []string{"/home/me/.config/claude", "/home/me/.claude"})
+		if !slices.Equal(result, tt.expected) {
+			t.Errorf("%s: withRemoteProfiles() = %v, expected %v", tt.name, result, tt.expected)
+		}
+	}
+}
+
+func TestRsyncArgs(t *testing.T) {
+	args := rsyncArgs(Remote{Host: "me@build", Dir: ".config/claude/"}, filepath.Join("mirror", "build"))
+	source, destination := args[len(args)-2], args[len(args)-1]
+	if source != "me@build:.config/claude/projects/" {
+		t.Errorf("rsync source = %q, expected me@build:.config/claude/projects/", source)
+	}
+	if destination != filepath.Join("mirror", "build", "projects")+string(filepath.Separator) {
+		t.Errorf("rsync destination = %q, expected the mirror's projects directory", destination)
+	}
+	if !slices.Contains(args, "--include=*.jsonl") || slices.Contains(args, "--delete") {
+		t.Errorf("rsyncArgs() = %v, expected only JSONL files and no deletions", args)
+	}
+}
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// RecordingVersion is the format version of recordings written by cctop record
+const RecordingVersion = 1
+
+// Recording is a series of ccusage snapshots with the Claude log messages of the
+// sessions they cover, written by cctop record and played back by --replay
+type Recording struct {
+	Version  int               `json:"version"`
+	Frames   []RecordingFrame  `json:"frames"`
+	Messages []RecordedMessage `json:"messages"` // Sorted by time
+}
+
+// RecordingFrame is what ccusage reported at one point in time
+type RecordingFrame struct {
+	Time   time.Time    `json:"time"`
+	Blocks []Block      `json:"blocks"`
+	Daily  []DailyUsage `json:"daily"`
+}
+
+// RecordedMessage is an assistant message from the Claude logs
+type RecordedMessage struct {
+	File      string           `json:"file"` // Relative to the projects directory
+	Time      time.Time        `json:"time"`
+	Cwd       string           `json:"cwd,omitempty"`
+	RequestID string           `json:"requestId,omitempty"`
+	Message   AssistantMessage `json:"message"`
+}
+
+// replayLine is a recorded message written back as a Claude log line
+type replayLine struct {
+	Timestamp string           `json:"timestamp"`
+	Type      string           `json:"type"`
+	Cwd       string           `json:"cwd,omitempty"`
+	RequestID string           `json:"requestId,omitempty"`
+	Message   AssistantMessage `json:"message"`
+}
+
+var (
+	replayPath     string
+	replaySpeed    float64
+	recordInterval time.Duration
+	recordDuration time.Duration
+	replayer       *Replayer // Nil unless --replay is set
+)
+
+// loadRecording reads a recording file
+func loadRecording(path string) (*Recording, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var recording Recording
+	if err := json.Unmarshal(data, &recording); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if recording.Version != RecordingVersion {
+		return nil, fmt.Errorf("%s: unsupported recording version %d", path, recording.Version)
+	}
+	if len(recording.Frames) == 0 {
+		return nil, fmt.Errorf("%s: no frames recorded", path)
+	}
+	return &recording, nil
+}
+
+// Replayer plays a recording back at speed times real time. Recorded times are shifted
+// so the current frame happens now, and its messages are written as Claude logs to a
+// sandbox directory that also holds cctop's state, so replays leave no trace.
+type Replayer struct {
+	mu        sync.Mutex
+	recording *Recording
+	speed     float64
+	start     time.Time // Real time the replay started
+	dir       string    // Sandbox directory
+}
+
+// NewReplayer starts replaying a recording at currentTime with a sandbox in dir
+func NewReplayer(recording *Recording, speed float64, dir string, currentTime time.Time) (*Replayer, error) {
+	if speed < 1 {
+		return nil, fmt.Errorf("--replay-speed %v: must be at least 1", speed)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "claude", "projects"), 0o755); err != nil {
+		return nil, err
+	}
+	return &Replayer{recording: recording, speed: speed, start: currentTime, dir: dir}, nil
+}
+
+// startReplay loads a recording and redirects cctop's state and the Claude logs to a
+// new sandbox, so everything set up afterwards only sees the replay
+func startReplay(path string, speed float64) (*Replayer, error) {
+	recording, err := loadRecording(path)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "cctop-replay-")
+	if err != nil {
+		return nil, err
+	}
+	replay, err := NewReplayer(recording, speed, dir, time.Now())
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	for name, value := range map[string]string{
+		"XDG_STATE_HOME":    filepath.Join(dir, "state"),
+		"XDG_CACHE_HOME":    filepath.Join(dir, "cache"),
+		"CLAUDE_CONFIG_DIR": filepath.Join(dir, "claude"),
+	} {
+		if err := os.Setenv(name, value); err != nil {
+			replay.Close()
+			return nil, err
+		}
+	}
+	sandboxConfig(config)
+	return replay, nil
+}
+
+// sandboxConfig turns off everything that would act on the replayed usage outside
+// cctop: hooks, webhooks, the time tracker, notifications, the system log, the bell,
+// Focus shortcuts and remote syncs, besides the archive and other local state
+func sandboxConfig(cfg *Config) {
+	cfg.ClaudeDirs = nil
+	cfg.Archive = false
+	cfg.WhatsNew = false
+	cfg.Watch = false // The replay writes the logs it would be notified of
+	cfg.RemoteSync = 0
+	cfg.HookSpecs = nil
+	cfg.ChatWebhook.URL = ""
+	cfg.Issue.WebhookURL = ""
+	cfg.TimeTracker.Service = ""
+	cfg.Notify.Enabled = false
+	cfg.Breaks.Enabled = false
+	cfg.SystemLog = ""
+	cfg.Bell.Enabled = false
+	cfg.Focus.OnShortcut = ""
+	cfg.Focus.OffShortcut = ""
+}
+
+// position returns the recorded time being replayed at currentTime, from the first
+// frame onwards and holding at the last
+func (r *Replayer) position(currentTime time.Time) time.Time {
+	first := r.recording.Frames[0].Time
+	last := r.recording.Frames[len(r.recording.Frames)-1].Time
+	elapsed := time.Duration(float64(currentTime.Sub(r.start)) * r.speed)
+	return minTime(first.Add(max(elapsed, 0)), last)
+}
+
+// frame returns the latest frame recorded by position
+func (r *Replayer) frame(position time.Time) RecordingFrame {
+	i := sort.Search(len(r.recording.Frames), func(i int) bool { return r.recording.Frames[i].Time.After(position) })
+	return r.recording.Frames[max(i-1, 0)]
+}
+
+// UsageData returns the blocks of the frame being replayed, shifted to currentTime,
+// after writing the messages sent by then to the sandbox logs
+func (r *Replayer) UsageData(currentTime time.Time) (*CCUsageData, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	position := r.position(currentTime)
+	shift := currentTime.Sub(position)
+	if err := r.writeLogs(position, shift); err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+
+	frame := r.frame(position)
+	blocks := make([]Block, len(frame.Blocks))
+	for i, block := range frame.Blocks {
+		block.StartTime = shiftTimestamp(block.StartTime, shift)
+		block.EndTime = shiftTimestamp(block.EndTime, shift)
+		block.ActualEndTime = shiftTimestamp(block.ActualEndTime, shift)
+		blocks[i] = block
+	}
+	return &CCUsageData{Blocks: blocks}, nil
+}
+
+// Daily returns the daily usage of the frame being replayed, with dates moved by the
+// days between the recorded and the current time
+func (r *Replayer) Daily(currentTime time.Time) []DailyUsage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	position := r.position(currentTime)
+	days := calendarDays(position.In(display.timezone), currentTime.In(display.timezone))
+	frame := r.frame(position)
+	daily := make([]DailyUsage, len(frame.Daily))
+	for i, day := range frame.Daily {
+		if date, err := time.Parse(DateFormat, day.Date); err == nil {
+			day.Date = date.AddDate(0, 0, days).Format(DateFormat)
+		}
+		daily[i] = day
+	}
+	return daily
+}
+
+// writeLogs rewrites the sandbox logs with the messages sent by position, shifted
+func (r *Replayer) writeLogs(position time.Time, shift time.Duration) error {
+	files := make(map[string][]byte)
+	for _, message := range r.recording.Messages {
+		if message.Time.After(position) {
+			break
+		}
+		line, err := json.Marshal(replayLine{
+			Timestamp: message.Time.Add(shift).Format(time.RFC3339),
+			Type:      "assistant",
+			Cwd:       message.Cwd,
+			RequestID: message.RequestID,
+			Message:   message.Message,
+		})
+		if err != nil {
+			return err
+		}
+		files[message.File] = append(append(files[message.File], line...), '\n')
+	}
+	projectsDir := filepath.Join(r.dir, "claude", "projects")
+	for file, data := range files {
+		path := filepath.Join(projectsDir, filepath.FromSlash(file))
+		if !strings.HasPrefix(path, projectsDir+string(filepath.Separator)) {
+			return fmt.Errorf("invalid log file %q", file)
+		}
+		if err := writeFileAtomic(path, data, 0o600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close removes the sandbox
+func (r *Replayer) Close() error {
+	if r == nil {
+		return nil
+	}
+	return os.RemoveAll(r.dir)
+}
+
+// shiftTimestamp moves an RFC 3339 timestamp, leaving empty or invalid ones as they are
+func shiftTimestamp(timestamp string, shift time.Duration) string {
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return timestamp
+	}
+	return t.Add(shift).Format(time.RFC3339)
+}
+
+// calendarDays returns the calendar days from one time to another in their location
+func calendarDays(from, to time.Time) int {
+	fromDate := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDate := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toDate.Sub(fromDate).Hours() / 24)
+}
+
+// recordMessages returns the assistant messages sent since a time in the projects
+// directories, with their log file relative to its projects directory
+func recordMessages(projectsDirs []string, since time.Time) []RecordedMessage {
+	var messages []RecordedMessage
+	seen := make(map[string]bool)
+	for _, projectsDir := range projectsDirs {
+		files, _ := filepath.Glob(filepath.Join(projectsDir, "*", "*.jsonl"))
+		for _, file := range files {
+			if info, err := os.Stat(file); err != nil || info.ModTime().Before(since) {
+				continue
+			}
+			entries, err := readJSONLEntries(file)
+			if err != nil {
+				logger.Warnf("record: skipping %s: %v", file, err)
+				continue
+			}
+			relative, _ := filepath.Rel(projectsDir, file)
+			for _, entry := range entries {
+				if entry.Time.Before(since) {
+					continue
+				}
+				if key := entry.dedupKey(); key != "" {
+					if seen[key] {
+						continue
+					}
+					seen[key] = true
+				}
+				messages = append(messages, RecordedMessage{
+					File:      filepath.ToSlash(relative),
+					Time:      entry.Time,
+					Cwd:       entry.Cwd,
+					RequestID: entry.RequestID,
+					Message:   entry.Message,
+				})
+			}
+		}
+	}
+	sort.SliceStable(messages, func(i, j int) bool { return messages[i].Time.Before(messages[j].Time) })
+	return messages
+}
+
+// recordingStart returns when the messages of a recording start: the start of the
+// first frame's active block, or the first frame without one
+func recordingStart(frame RecordingFrame) time.Time {
+	if block := findActiveBlock(frame.Blocks); block != nil {
+		if start, err := time.Parse(time.RFC3339, block.StartTime); err == nil {
+			return start
+		}
+	}
+	return frame.Time
+}
+
+// runRecord snapshots ccusage every --interval until --duration passes or it is
+// interrupted, rewriting the recording after each frame so it is always playable
+func runRecord(cmd *cobra.Command, args []string) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if recordDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, recordDuration)
+		defer cancel()
+	}
+
+	recording := Recording{Version: RecordingVersion}
+	for {
+		data, err := loadUsageData()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		} else {
+			frame := RecordingFrame{Time: time.Now(), Blocks: data.Blocks, Daily: fetchDailyUsage()}
+			recording.Frames = append(recording.Frames, frame)
+			recording.Messages = recordMessages(defaultProjectsDirs(), recordingStart(recording.Frames[0]))
+
+			output, err := json.Marshal(recording)
+			if err == nil {
+				err = writeFileAtomic(args[0], output, 0o600)
+			}
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			fmt.Fprintf(os.Stderr, "%s frame %d: %d blocks, %d messages\n",
+				frame.Time.In(display.timezone).Format(TimeFormat), len(recording.Frames), len(frame.Blocks), len(recording.Messages))
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Fprintf(os.Stderr, "Recorded %d frames to %s, replay with 'cctop --replay %s'\n", len(recording.Frames), args[0], args[0])
+			return
+		case <-time.After(recordInterval):
+		}
+	}
+}
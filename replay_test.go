@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testRecording returns a recording of a session started at 09:00, with frames at
+// 10:00, 10:10 and 10:20
+func testRecording() *Recording {
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	frame := func(minutes, tokens int) RecordingFrame {
+		at := start.Add(time.Hour + time.Duration(minutes)*time.Minute)
+		return RecordingFrame{
+			Time: at,
+			Blocks: []Block{
+				{StartTime: "2025-06-19T09:00:00Z", ActualEndTime: "2025-06-19T12:00:00Z", TotalTokens: 90000, Entries: 400},
+				{StartTime: start.Format(time.RFC3339), EndTime: start.Add(SessionDuration).Format(time.RFC3339),
+					ActualEndTime: at.Format(time.RFC3339), TotalTokens: tokens, Entries: 10, IsActive: true},
+			},
+			Daily: []DailyUsage{{Date: "2025-06-19", TotalTokens: 90000}, {Date: "2025-06-20", TotalTokens: tokens}},
+		}
+	}
+	message := func(minutes, tokens int) RecordedMessage {
+		return RecordedMessage{
+			File:    "-home-me-src-cctop/3f2a9c.jsonl",
+			Time:    start.Add(time.Duration(minutes) * time.Minute),
+			Message: AssistantMessage{Model: "claude-sonnet-4-20250514", Usage: TokenUsage{InputTokens: tokens}},
+		}
+	}
+	return &Recording{
+		Version:  RecordingVersion,
+		Frames:   []RecordingFrame{frame(0, 10000), frame(10, 15000), frame(20, 30000)},
+		Messages: []RecordedMessage{message(30, 10000), message(65, 5000), message(75, 15000)},
+	}
+}
+
+func TestReplayerUsageData(t *testing.T) {
+	oldDisplay := display
+	defer func() { display = oldDisplay }()
+	display = NewDisplay("UTC")
+
+	dir := t.TempDir()
+	now := time.Date(2025, 7, 1, 15, 0, 0, 0, time.UTC)
+	replay, err := NewReplayer(testRecording(), 60, dir, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logFile := filepath.Join(dir, "claude", "projects", "-home-me-src-cctop", "3f2a9c.jsonl")
+
+	tests := []struct {
+		name     string
+		elapsed  time.Duration // Real time since the replay started
+		tokens   int
+		start    string // Start of the active block, shifted so the replayed time is now
+		messages int
+		today    int // Tokens on the current day
+	}{
+		{"first frame", 0, 10000, "2025-07-01T14:00:00Z", 1, 10000},
+		{"10 minutes in 10 seconds", 10 * time.Second, 15000, "2025-07-01T13:50:10Z", 2, 15000},
+		{"between frames", 15 * time.Second, 15000, "2025-07-01T13:45:15Z", 3, 15000},
+		{"holds the last frame", time.Hour, 30000, "2025-07-01T14:40:00Z", 3, 30000},
+	}
+	for _, tt := range tests {
+		currentTime := now.Add(tt.elapsed)
+		data, err := replay.UsageData(currentTime)
+		if err != nil {
+			t.Fatalf("%s: UsageData() error = %v", tt.name, err)
+		}
+		active := findActiveBlock(data.Blocks)
+		if active == nil || active.TotalTokens != tt.tokens || active.StartTime != tt.start {
+			t.Errorf("%s: active block = %+v, expected %d tokens from %s", tt.name, active, tt.tokens, tt.start)
+		}
+
+		entries, err := readJSONLEntries(logFile)
+		if err != nil || len(entries) != tt.messages {
+			t.Errorf("%s: replayed log has %d messages (%v), expected %d", tt.name, len(entries), err, tt.messages)
+		}
+
+		today, _ := findDay(replay.Daily(currentTime), currentTime)
+		if today.TotalTokens != tt.today {
+			t.Errorf("%s: today = %+v, expected %d tokens", tt.name, today, tt.today)
+		}
+	}
+}
+
+func TestNewReplayerRejectsSlowSpeeds(t *testing.T) {
+	for _, speed := range []float64{0, 0.5, -60} {
+		if _, err := NewReplayer(testRecording(), speed, t.TempDir(), time.Now()); err == nil {
+			t.Errorf("NewReplayer(speed %v) error = nil, expected an error", speed)
+		}
+	}
+}
+
+func TestSandboxConfig(t *testing.T) {
+	cfg := NewConfig()
+	cfg.HookSpecs = []string{"session_end=notify-send done"}
+	cfg.ChatWebhook.URL = "https://hooks.slack.com/services/x"
+	cfg.Issue = IssueConfig{ID: "ABC-1", WebhookURL: "https://example.com/issues"}
+	cfg.TimeTracker.Service = "toggl"
+	cfg.Notify.Enabled = true
+	cfg.SystemLog = "syslog"
+	cfg.Bell.Enabled = true
+	cfg.Focus.OnShortcut = "Work"
+	cfg.RemoteSync = time.Minute
+
+	sandboxConfig(cfg)
+	cfg.Hooks, _ = parseHooks(cfg.HookSpecs)
+	if rules := buildRules(cfg); len(rules) != 0 {
+		t.Errorf("buildRules() = %d rules, expected none acting outside the replay", len(rules))
+	}
+	if len(cfg.HookSpecs) != 0 || cfg.ChatWebhook.URL != "" || cfg.Issue.WebhookURL != "" || cfg.TimeTracker.Service != "" ||
+		cfg.Notify.Enabled || cfg.SystemLog != "" || cfg.Bell.Enabled || cfg.RemoteSync != 0 {
+		t.Errorf("sandboxConfig() left an integration on: %+v", cfg)
+	}
+}
+
+func TestLoadRecording(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, recording any) string {
+		path := filepath.Join(dir, name)
+		data, _ := json.Marshal(recording)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	if recording, err := loadRecording(write("ok.json", testRecording())); err != nil || len(recording.Frames) != 3 {
+		t.Errorf("loadRecording() = %v, %v, expected 3 frames", recording, err)
+	}
+	for _, tt := range []struct {
+		name      string
+		recording Recording
+		expectErr string
+	}{
+		{"version.json", Recording{Version: 2, Frames: testRecording().Frames}, "unsupported recording version 2"},
+		{"empty.json", Recording{Version: RecordingVersion}, "no frames recorded"},
+	} {
+		if _, err := loadRecording(write(tt.name, tt.recording)); err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+			t.Errorf("loadRecording(%s) error = %v, expected %q", tt.name, err, tt.expectErr)
+		}
+	}
+}
+
+func TestRecordMessages(t *testing.T) {
+	projectsDir := t.TempDir()
+	file := filepath.Join(projectsDir, "-home-me-src-cctop", "3f2a9c.jsonl")
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	lines := `{"timestamp":"2025-06-20T08:00:00Z","type":"assistant","requestId":"req_0","message":{"id":"msg_0","usage":{"input_tokens":1}}}
+{"timestamp":"2025-06-20T09:30:00Z","type":"assistant","requestId":"req_2","message":{"id":"msg_2","usage":{"input_tokens":3}}}
+{"timestamp":"2025-06-20T09:10:00Z","type":"assistant","requestId":"req_1","cwd":"/home/me/src/cctop","message":{"id":"msg_1","usage":{"input_tokens":2}}}
+{"timestamp":"2025-06-20T09:10:00Z","type":"assistant","requestId":"req_1","cwd":"/home/me/src/cctop","message":{"id":"msg_1","usage":{"input_tokens":2}}}
+`
+	if err := os.WriteFile(file, []byte(lines), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	messages := recordMessages([]string{projectsDir}, time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC))
+	if len(messages) != 2 || messages[0].Message.ID != "msg_1" || messages[1].Message.ID != "msg_2" {
+		t.Fatalf("recordMessages() = %+v, expected msg_1 and msg_2 in time order", messages)
+	}
+	if messages[0].File != "-home-me-src-cctop/3f2a9c.jsonl" || messages[0].Cwd != "/home/me/src/cctop" {
+		t.Errorf("recordMessages()[0] = %+v, expected the relative log file and cwd", messages[0])
+	}
+}
+
+func TestReplayRendersSession(t *testing.T) {
+	oldDisplay, oldConfig, oldEstimator, oldCurrency, oldBurnCalc, oldReplayer := display, config, estimator, currency, burnCalc, replayer
+	defer func() {
+		display, config, estimator, currency, burnCalc, replayer = oldDisplay, oldConfig, oldEstimator, oldCurrency, oldBurnCalc, oldReplayer
+		dailyCache.days = nil
+	}()
+	display = NewDisplay("UTC")
+	config = NewConfig()
+	estimator = NewTokenLimitEstimator()
+	currency = NewCurrencyConverter("USD", 0)
+	burnCalc = NewBurnRateCalculator()
+	dailyCache.days = nil
+
+	dir := t.TempDir()
+	t.Setenv("CLAUDE_CONFIG_DIR", filepath.Join(dir, "claude"))
+	now := time.Now()
+	var err error
+	if replayer, err = NewReplayer(testRecording(), 60, dir, now.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := loadUsageData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := NewSession(findActiveBlock(data.Blocks), data.Blocks, "auto", 100000, now)
+	output := string(stripANSI([]byte(display.Render(session, estimator, "pro"))))
+	if !strings.Contains(output, "30.0% (30,000/100,000)") {
+		t.Errorf("Render() of the replay = %q, expected the last frame's 30,000 tokens", output)
+	}
+	if len(session.MessageTimes) != 3 {
+		t.Errorf("session has %d message times, expected the 3 replayed messages", len(session.MessageTimes))
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Report periods
+const (
+	ReportPeriodWeek  = "week"
+	ReportPeriodMonth = "month"
+)
+
+// UsageReport aggregates usage over a reporting period
+type UsageReport struct {
+	Period      string
+	Start       time.Time
+	End         time.Time
+	Days        []DailyUsage // One entry per day in the period, including idle days
+	TotalTokens int
+	TotalCost   float64
+	AvgBurnRate float64 // Tokens per minute while sessions were active
+	PeakBlock   *Block
+	Sessions    int
+}
+
+var reportPeriod string
+
+// sparkChars are the levels used to draw sparklines
+var sparkChars = []rune("▁▂▃▄▅▆▇█")
+
+// runReport prints a usage summary for the selected period
+func runReport(cmd *cobra.Command, args []string) {
+	if reportPeriod != ReportPeriodWeek && reportPeriod != ReportPeriodMonth {
+		fmt.Printf("Invalid period %q (use week or month)\n", reportPeriod)
+		return
+	}
+
+	data := fetchUsageData()
+	if data == nil {
+		fmt.Println("Failed to get usage data")
+		return
+	}
+
+	report := buildUsageReport(data.Blocks, fetchDailyUsage(), reportPeriod, time.Now())
+	fmt.Print(display.RenderReport(report))
+}
+
+// reportDays returns the number of days covered by a period
+func reportDays(period string) int {
+	if period == ReportPeriodMonth {
+		return 30
+	}
+	return 7
+}
+
+// buildUsageReport aggregates blocks and daily usage for the rolling period ending today
+func buildUsageReport(blocks []Block, days []DailyUsage, period string, currentTime time.Time) UsageReport {
+	year, month, day := currentTime.Date()
+	end := time.Date(year, month, day, 0, 0, 0, 0, currentTime.Location()).AddDate(0, 0, 1)
+	start := end.AddDate(0, 0, -reportDays(period))
+
+	report := UsageReport{Period: period, Start: start, End: end}
+
+	byDate := make(map[string]DailyUsage)
+	for _, d := range days {
+		byDate[d.Date] = d
+	}
+	for date := start; date.Before(end); date = date.AddDate(0, 0, 1) {
+		dateStr := date.Format(DateFormat)
+		entry, ok := byDate[dateStr]
+		if !ok {
+			entry = DailyUsage{Date: dateStr}
+		}
+		report.Days = append(report.Days, entry)
+		report.TotalTokens += entry.TotalTokens
+		report.TotalCost += entry.TotalCost
+	}
+
+	report.summarizeBlocks(blocks, currentTime)
+	return report
+}
+
+// summarizeBlocks finds the peak session and average burn rate within the period
+func (r *UsageReport) summarizeBlocks(blocks []Block, currentTime time.Time) {
+	var activeMinutes float64
+	var blockTokens int
+
+	for i := range blocks {
+		block := &blocks[i]
+		if block.IsGap {
+			continue
+		}
+		startTime, err := time.Parse(time.RFC3339, block.StartTime)
+		if err != nil || startTime.Before(r.Start) || !startTime.Before(r.End) {
+			continue
+		}
+
+		r.Sessions++
+		if r.PeakBlock == nil || block.TotalTokens > r.PeakBlock.TotalTokens {
+			r.PeakBlock = block
+		}
+
+		endTime := burnCalc.getBlockEndTime(*block, currentTime)
+		if minutes := endTime.Sub(startTime).Minutes(); minutes > 0 {
+			activeMinutes += minutes
+			blockTokens += block.TotalTokens
+		}
+	}
+
+	if activeMinutes > 0 {
+		r.AvgBurnRate = float64(blockTokens) / activeMinutes
+	}
+}
+
+// sparkline renders values as a single line of block characters
+func sparkline(values []int) string {
+	maxValue := 0
+	for _, v := range values {
+		if v > maxValue {
+			maxValue = v
+		}
+	}
+
+	line := make([]rune, len(values))
+	for i, v := range values {
+		level := 0
+		if maxValue > 0 {
+			level = v * (len(sparkChars) - 1) / maxValue
+		}
+		line[i] = sparkChars[level]
+	}
+	return string(line)
+}
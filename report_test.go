@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildUsageReport(t *testing.T) {
+	oldBurnCalc := burnCalc
+	burnCalc = NewBurnRateCalculator()
+	defer func() { burnCalc = oldBurnCalc }()
+
+	now := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+	days := []DailyUsage{
+		{Date: "2025-06-10", TotalTokens: 99999, TotalCost: 99}, // Outside the week
+		{Date: "2025-06-15", TotalTokens: 1000, TotalCost: 1.5},
+		{Date: "2025-06-20", TotalTokens: 3000, TotalCost: 2.5},
+	}
+	blocks := []Block{
+		{StartTime: "2025-06-10T00:00:00Z", ActualEndTime: "2025-06-10T01:00:00Z", TotalTokens: 99999},
+		{StartTime: "2025-06-15T00:00:00Z", ActualEndTime: "2025-06-15T01:00:00Z", TotalTokens: 1200},
+		{StartTime: "2025-06-15T06:00:00Z", IsGap: true},
+		{StartTime: "2025-06-20T10:00:00Z", TotalTokens: 2400, IsActive: true},
+	}
+
+	report := buildUsageReport(blocks, days, ReportPeriodWeek, now)
+	if len(report.Days) != 7 {
+		t.Fatalf("len(Days) = %d, expected 7", len(report.Days))
+	}
+	if report.Days[0].Date != "2025-06-14" || report.Days[6].Date != "2025-06-20" {
+		t.Errorf("Days span %s..%s, expected 2025-06-14..2025-06-20", report.Days[0].Date, report.Days[6].Date)
+	}
+	if report.TotalTokens != 4000 || report.TotalCost != 4.0 {
+		t.Errorf("totals = %d/%.2f, expected 4000/4.00", report.TotalTokens, report.TotalCost)
+	}
+	if report.Sessions != 2 || report.PeakBlock == nil || report.PeakBlock.TotalTokens != 2400 {
+		t.Errorf("sessions = %d peak = %+v, expected 2 sessions with 2400 peak", report.Sessions, report.PeakBlock)
+	}
+	// 3600 tokens over 60 + 120 active minutes
+	if report.AvgBurnRate != 20 {
+		t.Errorf("AvgBurnRate = %.2f, expected 20.00", report.AvgBurnRate)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	if result := sparkline([]int{0, 50, 100}); result != "▁▄█" {
+		t.Errorf("sparkline() = %s, expected ▁▄█", result)
+	}
+	if result := sparkline([]int{0, 0}); result != "▁▁" {
+		t.Errorf("sparkline() = %s, expected ▁▁", result)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// SetWidth sets the terminal columns the views are laid out for, 0 when unknown.
+// Unknown widths keep the classic layout with ProgressBarWidth bars.
+func (d *Display) SetWidth(width int) {
+	d.width = width
+}
+
+// compact reports whether the terminal is too narrow for bars next to their labels
+func (d *Display) compact() bool {
+	return d.width > 0 && d.width < CompactLayoutWidth
+}
+
+// wide reports whether the terminal has room for a second column of panels
+func (d *Display) wide() bool {
+	return d.width >= WideLayoutWidth
+}
+
+// mainWidth returns the columns of the main column
+func (d *Display) mainWidth() int {
+	if d.wide() {
+		return (d.width - ColumnGap) / 2
+	}
+	return d.width
+}
+
+// barWidth returns the width of progress bars, scaled to the terminal
+func (d *Display) barWidth() int {
+	switch {
+	case d.width <= 0:
+		return ProgressBarWidth
+	case d.compact():
+		return clampInt(d.width-4, MinProgressBarWidth, MaxProgressBarWidth) // Indent and brackets
+	default:
+		return clampInt(d.mainWidth()-BarLineReserve, MinProgressBarWidth, MaxProgressBarWidth)
+	}
+}
+
+// miniBarWidth returns the width of per-model and per-project bars
+func (d *Display) miniBarWidth() int {
+	return min(ModelBarWidth, d.barWidth()/2)
+}
+
+// writeBarLine writes "Label   [bar] detail", or on compact terminals the label and
+// detail on one line with the bar indented below
+func (d *Display) writeBarLine(buffer *strings.Builder, label, bar, detail string) {
+	if d.compact() {
+		fmt.Fprintf(buffer, "%s %s\n  %s\n", label, detail, bar)
+		return
+	}
+	fmt.Fprintf(buffer, "%-7s %s %s\n", label, bar, detail)
+}
+
+// statusSeparator separates the parts of the status bar, one per line on compact terminals
+func (d *Display) statusSeparator() string {
+	if d.compact() {
+		return "\n"
+	}
+	return "  "
+}
+
+// fitWidth truncates lines longer than the terminal, so they don't wrap and push the
+// view off screen
+func (d *Display) fitWidth(text string) string {
+	if d.width <= 0 {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = truncateANSI(line, d.width)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// visibleWidth returns the columns a line takes on screen, ignoring escape sequences
+func visibleWidth(line string) int {
+	return runewidth.StringWidth(string(stripANSI([]byte(line))))
+}
+
+// truncateANSI shortens a line to width visible columns ending in "…", keeping escape
+// sequences intact and resetting colors after the cut
+func truncateANSI(line string, width int) string {
+	if width <= 0 || visibleWidth(line) <= width {
+		return line
+	}
+
+	var b strings.Builder
+	visible := 0
+	escaped := false
+	for i := 0; i < len(line); {
+		if loc := ansiPattern.FindStringIndex(line[i:]); loc != nil && loc[0] == 0 {
+			b.WriteString(line[i : i+loc[1]])
+			i += loc[1]
+			escaped = true
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(line[i:])
+		if visible+runewidth.RuneWidth(r) > width-1 {
+			break
+		}
+		b.WriteRune(r)
+		visible += runewidth.RuneWidth(r)
+		i += size
+	}
+	b.WriteString("…")
+	if escaped {
+		b.WriteString(resetStyle)
+	}
+	return b.String()
+}
+
+// joinColumns lays out right next to left, padding left's lines to leftWidth and
+// truncating right's lines to the same width
+func joinColumns(left, right string, leftWidth, gap int) string {
+	leftLines := strings.Split(strings.TrimSuffix(left, "\n"), "\n")
+	rightLines := strings.Split(strings.TrimSuffix(right, "\n"), "\n")
+
+	var b strings.Builder
+	for i := 0; i < max(len(leftLines), len(rightLines)); i++ {
+		var l, r string
+		if i < len(leftLines) {
+			l = truncateANSI(leftLines[i], leftWidth)
+		}
+		if i < len(rightLines) {
+			r = truncateANSI(rightLines[i], leftWidth)
+		}
+		if r == "" {
+			b.WriteString(l + "\n")
+			continue
+		}
+		b.WriteString(l + strings.Repeat(" ", leftWidth-visibleWidth(l)+gap) + r + "\n")
+	}
+	return b.String()
+}
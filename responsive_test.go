@@ -0,0 +1,121 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+func TestBarWidth(t *testing.T) {
+	tests := []struct {
+		width        int
+		expected     int
+		expectedMini int
+	}{
+		{0, ProgressBarWidth, ModelBarWidth}, // Unknown: classic layout
+		{40, 36, 18},                         // Compact: whole line minus indent and brackets
+		{90, 50, ModelBarWidth},
+		{45, 41, ModelBarWidth},
+		{70, 30, 15},
+		{20, 16, 8},
+		{8, MinProgressBarWidth, 5},
+		{200, 58, ModelBarWidth}, // Wide: half the terminal
+		{400, MaxProgressBarWidth, ModelBarWidth},
+	}
+
+	for _, tt := range tests {
+		d := NewDisplay("UTC")
+		d.SetWidth(tt.width)
+		if got := d.barWidth(); got != tt.expected {
+			t.Errorf("barWidth() at %d columns = %d, expected %d", tt.width, got, tt.expected)
+		}
+		if got := d.miniBarWidth(); got != tt.expectedMini {
+			t.Errorf("miniBarWidth() at %d columns = %d, expected %d", tt.width, got, tt.expectedMini)
+		}
+	}
+}
+
+func TestWriteBarLine(t *testing.T) {
+	d := NewDisplay("UTC")
+	var buffer strings.Builder
+	d.writeBarLine(&buffer, "Tokens", "[||  ]", "45.0% (63,000/140,000)")
+	if expected := "Tokens  [||  ] 45.0% (63,000/140,000)\n"; buffer.String() != expected {
+		t.Errorf("writeBarLine() = %q, expected %q", buffer.String(), expected)
+	}
+
+	d.SetWidth(40)
+	buffer.Reset()
+	d.writeBarLine(&buffer, "Tokens", "[||  ]", "45.0% (63,000/140,000)")
+	if expected := "Tokens 45.0% (63,000/140,000)\n  [||  ]\n"; buffer.String() != expected {
+		t.Errorf("compact writeBarLine() = %q, expected %q", buffer.String(), expected)
+	}
+}
+
+func TestTruncateANSI(t *testing.T) {
+	red := "\x1b[31m"
+	reset := "\x1b[0m"
+	tests := []struct {
+		line     string
+		width    int
+		expected string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"Tokens: 63,000/140,000", 10, "Tokens: 6…"},
+		{red + "Status: WARNING" + reset, 8, red + "Status:…" + reset},
+		{"残り時間は二時間です", 5, "残り…"}, // Wide characters take two columns
+	}
+
+	for _, tt := range tests {
+		if got := truncateANSI(tt.line, tt.width); got != tt.expected {
+			t.Errorf("truncateANSI(%q, %d) = %q, expected %q", tt.line, tt.width, got, tt.expected)
+		}
+	}
+}
+
+func TestJoinColumns(t *testing.T) {
+	left := "Tokens [||]\nSession [|]\nWeek\n"
+	right := "Models\n" + "\x1b[36mOpus\x1b[0m 80%\n"
+
+	expected := "" +
+		"Tokens [||]   Models\n" +
+		"Session [|]   \x1b[36mOpus\x1b[0m 80%\n" +
+		"Week\n"
+	if got := joinColumns(left, right, 11, 3); got != expected {
+		t.Errorf("joinColumns() = %q, expected %q", got, expected)
+	}
+}
+
+func TestRenderResponsive(t *testing.T) {
+	oldConfig, oldEstimator, oldNoColor := config, estimator, color.NoColor
+	defer func() { config, estimator, color.NoColor = oldConfig, oldEstimator, oldNoColor }()
+	config = NewConfig()
+	estimator = NewTokenLimitEstimator()
+	color.NoColor = true
+
+	start := time.Now().Add(-2 * time.Hour)
+	session := newTestSession(start, 63000, 140000)
+	session.Cache = CacheMetrics{ReadTokens: 1000}
+
+	d := NewDisplay("UTC")
+	d.SetWidth(50)
+	for _, line := range strings.Split(d.Render(session, estimator, "max5"), "\n") {
+		if visibleWidth(line) > 50 {
+			t.Errorf("compact line %q is %d columns, expected at most 50", line, visibleWidth(line))
+		}
+	}
+
+	d.SetWidth(160)
+	output := d.Render(session, estimator, "max5")
+	var tokenLine string
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "Tokens  [") {
+			tokenLine = line
+		}
+	}
+	if !strings.Contains(tokenLine, "cache 0% hit") {
+		t.Errorf("wide layout token line = %q, expected the cache panel beside it", tokenLine)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy retries a failing operation with exponential backoff. Delays are jittered
+// so processes contending for the same resource, e.g. the daemon and the monitor
+// writing the archive, do not retry in lockstep.
+type RetryPolicy struct {
+	Attempts int           // Tries including the first
+	Base     time.Duration // Delay before the first retry, doubled for each further retry
+	Max      time.Duration // Longest delay between tries
+}
+
+// sleep waits between tries, replaced in tests
+var sleep = time.Sleep
+
+// Do runs op until it succeeds, fails with an error retryable rejects, or the
+// attempts run out, and returns the last error
+func (p RetryPolicy) Do(op func() error, retryable func(error) bool) error {
+	var err error
+	for attempt := 0; attempt < max(p.Attempts, 1); attempt++ {
+		if attempt > 0 {
+			sleep(p.Delay(attempt))
+		}
+		if err = op(); err == nil || !retryable(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// Delay returns the wait before retry n (from 1): Base doubled n-1 times and capped
+// at Max, of which the upper half is random
+func (p RetryPolicy) Delay(n int) time.Duration {
+	delay := p.Max
+	if n <= 30 && p.Base<<(n-1) > 0 && p.Base<<(n-1) < p.Max {
+		delay = p.Base << (n - 1)
+	}
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDo(t *testing.T) {
+	original := sleep
+	defer func() { sleep = original }()
+
+	busy := errors.New("database is locked")
+	fatal := errors.New("no such table")
+	tests := []struct {
+		name          string
+		failures      []error // Errors of the first tries, then success
+		expectedErr   error
+		expectedTries int
+	}{
+		{"first try", nil, nil, 1},
+		{"succeeds after contention", []error{busy, busy}, nil, 3},
+		{"gives up", []error{busy, busy, busy, busy}, busy, 3},
+		{"not retryable", []error{fatal, busy}, fatal, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var slept []time.Duration
+			sleep = func(d time.Duration) { slept = append(slept, d) }
+			tries := 0
+			policy := RetryPolicy{Attempts: 3, Base: 100 * time.Millisecond, Max: time.Second}
+			err := policy.Do(func() error {
+				tries++
+				if tries <= len(tt.failures) {
+					return tt.failures[tries-1]
+				}
+				return nil
+			}, func(err error) bool { return err == busy })
+
+			if err != tt.expectedErr {
+				t.Errorf("Do() error = %v, expected %v", err, tt.expectedErr)
+			}
+			if tries != tt.expectedTries {
+				t.Errorf("Do() tried %d times, expected %d", tries, tt.expectedTries)
+			}
+			if len(slept) != tries-1 {
+				t.Errorf("Do() slept %d times, expected %d", len(slept), tries-1)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{Attempts: 10, Base: 100 * time.Millisecond, Max: time.Second}
+	tests := []struct {
+		retry int
+		max   time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second}, // Capped
+		{100, time.Second},
+	}
+	for _, tt := range tests {
+		for range 20 {
+			if got := policy.Delay(tt.retry); got < tt.max/2 || got > tt.max {
+				t.Errorf("Delay(%d) = %v, expected between %v and %v", tt.retry, got, tt.max/2, tt.max)
+			}
+		}
+	}
+}
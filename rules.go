@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// Action is something a rule performs when it fires
+type Action interface {
+	Name() string
+	Run(session *Session) error
+}
+
+// Rule runs an action when token usage crosses a threshold, and an optional
+// release action once usage falls back below it (e.g. when a new session starts)
+type Rule struct {
+	Threshold float64                                            // Token usage percentage
+	Condition func(session *Session, currentTime time.Time) bool // Replaces Threshold when set
+	Action    Action
+	Release   Action // May be nil
+	active    bool
+}
+
+// matches reports whether the rule's condition holds for the session; none holds
+// without an active session
+func (r *Rule) matches(session *Session, currentTime time.Time) bool {
+	if session == nil {
+		return false
+	}
+	if r.Condition != nil {
+		return r.Condition(session, currentTime)
+	}
+	return !session.NoLimit && session.Metrics.Tokens.Percentage >= r.Threshold
+}
+
+// RulesEngine evaluates rules against each refreshed session
+type RulesEngine struct {
+	rules []*Rule
+}
+
+// NewRulesEngine creates an engine for the given rules
+func NewRulesEngine(rules ...*Rule) *RulesEngine {
+	return &RulesEngine{rules: rules}
+}
+
+// Evaluate fires actions for rules whose condition changed. A nil session means no
+// session is active, which releases every active rule.
+// Action failures are returned together so callers can surface them.
+func (e *RulesEngine) Evaluate(session *Session, currentTime time.Time) []error {
+	var errs []error
+
+	for _, rule := range e.rules {
+		matches := rule.matches(session, currentTime)
+		switch {
+		case !rule.active && matches:
+			rule.active = true
+			if err := rule.Action.Run(session); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", rule.Action.Name(), err))
+			}
+		case rule.active && !matches:
+			rule.active = false
+			if rule.Release == nil {
+				continue
+			}
+			if err := rule.Release.Run(session); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", rule.Release.Name(), err))
+			}
+		}
+	}
+	return errs
+}
+
+// ShortcutAction runs a macOS Shortcut, e.g. one that turns a Focus mode on or off
+type ShortcutAction struct {
+	Shortcut string
+}
+
+// Name describes the action
+func (a ShortcutAction) Name() string {
+	return fmt.Sprintf("shortcut %q", a.Shortcut)
+}
+
+// Run invokes `shortcuts run` with the configured shortcut
+func (a ShortcutAction) Run(session *Session) error {
+	if runtime.GOOS != "darwin" {
+		return fmt.Errorf("shortcuts are only available on macOS")
+	}
+	return exec.Command("shortcuts", "run", a.Shortcut).Run()
+}
+
+// FocusConfig holds macOS Focus integration configuration
+type FocusConfig struct {
+	Threshold   float64 // Token usage percentage that turns Focus on
+	OnShortcut  string  // Shortcut run when the threshold is crossed
+	OffShortcut string  // Shortcut run when usage drops back (optional)
+}
+
+// buildRules creates rules from the configuration
+func buildRules(cfg *Config) []*Rule {
+	var rules []*Rule
+	if cfg.Focus.OnShortcut != "" {
+		rule := &Rule{
+			Threshold: cfg.Focus.Threshold,
+			Action:    ShortcutAction{Shortcut: cfg.Focus.OnShortcut},
+		}
+		if cfg.Focus.OffShortcut != "" {
+			rule.Release = ShortcutAction{Shortcut: cfg.Focus.OffShortcut}
+		}
+		rules = append(rules, rule)
+	}
+	if cfg.Tracker != nil {
+		rules = append(rules, &Rule{
+			Condition: sessionActiveCondition,
+			Action:    inBackground(TimeTrackerAction{Tracker: cfg.Tracker}),
+			Release:   inBackground(TimeTrackerAction{Tracker: cfg.Tracker, Stop: true}),
+		})
+	}
+	if cfg.ChatWebhook.URL != "" {
+		for _, threshold := range cfg.ChatWebhook.Thresholds {
+			rules = append(rules, &Rule{Threshold: threshold, Action: inBackground(NewChatWebhookAction(cfg.ChatWebhook.URL, threshold))})
+		}
+	}
+	for _, hook := range cfg.Hooks {
+		if threshold, ok := hookThreshold(hook.Event); ok {
+			rules = append(rules, &Rule{Threshold: threshold, Action: inBackground(hook)})
+		}
+	}
+	return rules
+}
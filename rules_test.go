@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// recordingAction counts how often it runs
+type recordingAction struct {
+	runs *int
+	err  error
+}
+
+func (a recordingAction) Name() string { return "recording" }
+
+func (a recordingAction) Run(session *Session) error {
+	*a.runs++
+	return a.err
+}
+
+func TestRulesEngine(t *testing.T) {
+	var onRuns, offRuns int
+	engine := NewRulesEngine(&Rule{
+		Threshold: 80,
+		Action:    recordingAction{runs: &onRuns},
+		Release:   recordingAction{runs: &offRuns},
+	})
+
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	now := start.Add(time.Hour)
+
+	engine.Evaluate(newTestSession(start, 5000, 10000), now)
+	engine.Evaluate(newTestSession(start, 8500, 10000), now)
+	engine.Evaluate(newTestSession(start, 9500, 10000), now)
+	if onRuns != 1 || offRuns != 0 {
+		t.Errorf("runs = %d/%d, expected action once and no release", onRuns, offRuns)
+	}
+
+	// New session resets usage below the threshold
+	engine.Evaluate(newTestSession(start.Add(5*time.Hour), 100, 10000), now)
+	if offRuns != 1 {
+		t.Errorf("release runs = %d, expected 1", offRuns)
+	}
+}
+
+func TestRulesEngineErrors(t *testing.T) {
+	var runs int
+	engine := NewRulesEngine(&Rule{
+		Threshold: 50,
+		Action:    recordingAction{runs: &runs, err: errors.New("boom")},
+	})
+
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	errs := engine.Evaluate(newTestSession(start, 6000, 10000), start)
+	if len(errs) != 1 {
+		t.Errorf("len(errs) = %d, expected 1", len(errs))
+	}
+}
+
+func TestBuildRules(t *testing.T) {
+	cfg := NewConfig()
+	if rules := buildRules(cfg); len(rules) != 0 {
+		t.Errorf("len(rules) = %d without focus shortcut, expected 0", len(rules))
+	}
+
+	cfg.Focus.OnShortcut = "Focus On"
+	cfg.Focus.OffShortcut = "Focus Off"
+	rules := buildRules(cfg)
+	if len(rules) != 1 || rules[0].Release == nil || rules[0].Threshold != 80 {
+		t.Errorf("rules = %+v, expected one focus rule at 80%% with release", rules)
+	}
+}
+
+func TestRulesEngineCondition(t *testing.T) {
+	var startRuns, stopRuns int
+	engine := NewRulesEngine(&Rule{
+		Condition: sessionActiveCondition,
+		Action:    recordingAction{runs: &startRuns},
+		Release:   recordingAction{runs: &stopRuns},
+	})
+
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	session := newTestSession(start, 1000, 10000)
+	session.Block.IsActive = true
+	session.Block.ActualEndTime = start.Add(time.Hour).Format(time.RFC3339)
+
+	engine.Evaluate(session, start.Add(time.Hour+time.Minute))
+	engine.Evaluate(session, start.Add(time.Hour+2*time.Minute))
+	engine.Evaluate(session, start.Add(2*time.Hour)) // Idle for an hour
+	if startRuns != 1 || stopRuns != 1 {
+		t.Errorf("runs = %d/%d, expected one start and one stop", startRuns, stopRuns)
+	}
+
+	// The block expires while still active: no session is left to evaluate
+	later := newTestSession(start.Add(SessionDuration), 1000, 10000)
+	later.Block.IsActive = true
+	engine.Evaluate(later, start.Add(SessionDuration+time.Minute))
+	engine.Evaluate(nil, start.Add(2*SessionDuration))
+	if startRuns != 2 || stopRuns != 2 {
+		t.Errorf("runs after the session disappeared = %d/%d, expected the tracker stopped", startRuns, stopRuns)
+	}
+}
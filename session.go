@@ -1,21 +1,61 @@
 package main
 
 import (
+	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
 
 // Session represents an active Claude session with all related data
 type Session struct {
-	StartTime     time.Time
-	EndTime       time.Time
-	Block         *Block
-	AllBlocks     []Block
-	PrimaryModel  string
-	CurrentModels []string
-	Metrics       SessionMetrics
-	BurnRate      float64
-	TodayCost     float64
+	Plan              string // Plan the session was loaded for, switched with the plan key
+	Profile           string // Profile the session was loaded for, "" for all profiles
+	StartTime         time.Time
+	EndTime           time.Time
+	Block             *Block
+	AllBlocks         []Block
+	PrimaryModel      string
+	CurrentModels     []string
+	Metrics           SessionMetrics
+	BurnRate          float64
+	RecentRates       *BurnRates       // Instantaneous and smoothed rates, nil until two refreshes were sampled
+	CostBurnRate      float64          // USD per hour
+	WindowRates       []WindowBurnRate // Burn rates over each of config.BurnWindows
+	BurnBand          *BurnBand        // Spread of burn rates within the burn window, nil without recent usage
+	LimitChange       *LimitTransition // Limit change to highlight, nil unless the limit changed recently
+	ModelSwitch       *ModelSwitch     // Opus to Sonnet switch point, nil for plans without one
+	ConflictingBlocks []Block          // Other blocks ccusage reported as active, normally none
+	Cost              CostMetrics
+	TodayCost         float64
+	Cycle             CycleUsage
+	Daily             []DailyUsage
+	ModelTokens       map[string]int // Tokens per model, only loaded when model bars are enabled
+	Typical           TypicalShape
+	DailyTokens       TokenMetrics
+	Weekly            WeeklyMetrics
+	SoftLimit         TokenMetrics      // Usage against the personal soft limit, zero Limit when unset
+	Team              []TeamMemberUsage // Per-member usage against their share, only with --team-share
+	Cache             CacheMetrics
+	RecentUsage       []int // Tokens per sparkline bucket over the last hour
+	BreakReminder     string
+	NoLimit           bool           // No trustworthy limit: percentages and limit-based status are not shown
+	Alerts            *AlertLevels   // Configured --alert-thresholds of the plan, nil when usage levels do not affect the status
+	WeeklyAlerts      *AlertLevels   // Percentage --alert-thresholds of the plan for the weekly window, nil for the bar colors
+	ProfileUsage      []ProfileUsage // Per-profile tokens, only with multiple profiles
+	Projects          []ProjectUsage // Per-project tokens, only loaded when the projects panel is enabled
+	MessageTimes      []time.Time    // Sorted message timestamps, only loaded for idle segments or active time
+	Timeline          []TimedTokens  // Tokens of each message, only loaded for the forecast chart
+}
+
+// ModelShare is a model family's share of the session's tokens
+type ModelShare struct {
+	Family         string
+	Tokens         int
+	Weight         float64 // Limit consumption per token relative to the baseline model, see --model-weights
+	WeightedTokens int
+	Percentage     float64 // Share of the session's weighted tokens, the plain share without weights
 }
 
 // SessionMetrics contains all calculated metrics for a session
@@ -25,24 +65,90 @@ type SessionMetrics struct {
 }
 
 // NewSession creates a new Session from an active block
-func NewSession(block *Block, allBlocks []Block, tokenLimit int, currentTime time.Time) *Session {
+func NewSession(block *Block, allBlocks []Block, plan string, tokenLimit int, currentTime time.Time) *Session {
 	startTime, _ := time.Parse(time.RFC3339, block.StartTime)
 	endTime := startTime.Add(5 * time.Hour)
+	dailyUsage := cachedDailyUsage(currentTime)
 
 	session := &Session{
+		Plan:          plan,
 		Block:         block,
 		AllBlocks:     allBlocks,
 		StartTime:     startTime,
 		EndTime:       endTime,
 		BurnRate:      burnCalc.Calculate(allBlocks, currentTime),
-		TodayCost:     fetchTodayTotalCost(currentTime),
+		CostBurnRate:  burnCalc.CalculateCost(allBlocks, currentTime),
+		WindowRates:   burnCalc.CalculateWindows(allBlocks, currentTime, config.BurnWindows),
+		Cost:          calculateCostMetrics(dailyUsage, currentTime, config.Budget, config.BudgetPeriod),
+		TodayCost:     todayCost(dailyUsage, currentTime),
+		Cycle:         summarizeCycle(dailyUsage, NewBillingCycle(currentTime, config.BillingAnchorDay)),
+		Daily:         dailyUsage,
+		Cache:         calculateCacheMetrics(block.TokenCounts),
 		CurrentModels: block.Models,
 		PrimaryModel:  determinePrimaryModel(block.Models),
 	}
 
+	if config.BudgetPeriod == BudgetPeriodSession {
+		session.Cost = sessionCostMetrics(block, config.Budget)
+	}
+	if config.ModelBars {
+		session.ModelTokens = loadModelTokens(block, currentTime)
+	}
+	if config.ProjectsPanel {
+		session.Projects = loadProjectUsage(block, currentTime)
+	}
+	if len(config.Profiles) > 1 {
+		session.ProfileUsage = calculateProfileUsage(block, currentTime)
+	}
+	if band, ok := burnCalc.CalculateBand(allBlocks, currentTime); ok {
+		session.BurnBand = &band
+	}
+	if config.IdleSegments || config.ActiveTime || config.ForecastChart {
+		timeline := loadTimedTokens(block, currentTime)
+		session.MessageTimes = messageTimes(timeline)
+		if config.ForecastChart {
+			session.Timeline = timeline
+		}
+	}
+	if config.TypicalShape {
+		session.Typical = typicalTokensAt(allBlocks, currentTime.Sub(startTime))
+	}
+
+	session.NoLimit = config.NoLimit || config.APIPlan() || limitConfidence(allBlocks).Level == ConfidenceNone
+
 	// Calculate metrics
 	session.Metrics.Tokens = session.calculateTokenMetrics(tokenLimit)
 	session.Metrics.Time = session.calculateTimeMetrics(currentTime)
+	if !session.NoLimit {
+		plan := estimator.GetActualPlan(plan, allBlocks)
+		session.ModelSwitch = predictModelSwitch(plan, session.Metrics.Tokens, block.Models, session.ModelTokens, session.BurnRate, currentTime)
+		if levels, ok := config.CustomAlertLevels(plan, tokenLimit); ok {
+			session.Alerts = &levels
+		}
+	}
+	if session.TracksSpend() {
+		// Percentages of the budget; token counts have no limit to resolve against
+		if levels, ok := config.PercentAlertLevels(PlanAPI); ok {
+			session.Alerts = &levels
+		}
+	}
+	if len(config.TeamShares) > 0 && !session.NoLimit {
+		session.Team = calculateTeamUsage(session.ProfileUsage, config.TeamShares, tokenLimit)
+	}
+	if config.SoftLimit > 0 {
+		session.SoftLimit = session.calculateTokenMetrics(config.SoftLimit)
+	}
+	if config.DailyBar {
+		session.DailyTokens = calculateDailyMetrics(dailyUsage, currentTime, tokenLimit, config.DailyBudget)
+	}
+	if config.WeeklyBar {
+		actualPlan := estimator.GetActualPlan(plan, allBlocks)
+		weeklyLimit := estimateWeeklyLimit(actualPlan, tokenLimit)
+		session.Weekly = calculateWeeklyMetrics(allBlocks, currentTime, weeklyLimit)
+		if levels, ok := config.PercentAlertLevels(actualPlan); ok {
+			session.WeeklyAlerts = &levels
+		}
+	}
 
 	return session
 }
@@ -85,25 +191,81 @@ func (s *Session) calculateTimeMetrics(currentTime time.Time) TimeMetrics {
 	}
 }
 
+// Advance recomputes the time metrics for a redraw between refreshes,
+// so countdowns keep moving without fetching new usage data
+func (s *Session) Advance(currentTime time.Time) {
+	s.Metrics.Time = s.calculateTimeMetrics(currentTime)
+}
+
 // GetPredictedEndTime calculates when tokens will be depleted
 func (s *Session) GetPredictedEndTime(currentTime time.Time) time.Time {
-	if s.BurnRate > 0 && s.Metrics.Tokens.Remaining > 0 {
-		minutesToDepletion := float64(s.Metrics.Tokens.Remaining) / s.BurnRate
+	return s.depletionAt(s.forecastRate(), currentTime)
+}
+
+// forecastRate returns the burn rate predictions are based on, in tokens per minute
+func (s *Session) forecastRate() float64 {
+	if experimentEnabled(ExperimentForecastV2) && s.RecentRates != nil {
+		return s.RecentRates.Smoothed
+	}
+	return s.BurnRate
+}
+
+// depletionAt returns when the remaining tokens run out at rate tokens per minute,
+// or the session end if they never do
+func (s *Session) depletionAt(rate float64, currentTime time.Time) time.Time {
+	if rate > 0 && s.Metrics.Tokens.Remaining > 0 {
+		minutesToDepletion := float64(s.Metrics.Tokens.Remaining) / rate
 		return currentTime.Add(time.Duration(minutesToDepletion) * time.Minute)
 	}
 	return s.EndTime
 }
 
+// PredictedEndRange returns the earliest and latest depletion times, at the P90 and P10
+// burn rates of the band. Without a band both are the point estimate.
+func (s *Session) PredictedEndRange(currentTime time.Time) Interval {
+	if s.BurnBand == nil {
+		predictedEnd := s.GetPredictedEndTime(currentTime)
+		return Interval{Start: predictedEnd, End: predictedEnd}
+	}
+	return Interval{
+		Start: s.depletionAt(s.BurnBand.P90, currentTime),
+		End:   s.depletionAt(s.BurnBand.P10, currentTime),
+	}
+}
+
+// pessimisticEndTime returns the earlier of the point estimate and the range's earliest depletion
+func (s *Session) pessimisticEndTime(currentTime time.Time) time.Time {
+	return minTime(s.GetPredictedEndTime(currentTime), s.PredictedEndRange(currentTime).Start)
+}
+
 // GetStatus returns the current status of the session
 func (s *Session) GetStatus() string {
+	return s.statusAt(time.Now())
+}
+
+// statusAt returns the status of the session at currentTime
+func (s *Session) statusAt(currentTime time.Time) string {
+	if s.TracksSpend() {
+		return s.spendStatus(currentTime)
+	}
+	if s.NoLimit {
+		return "OK"
+	}
 	if s.Metrics.Tokens.Used > s.Metrics.Tokens.Limit {
 		return "LIMIT EXCEEDED"
 	}
+	if s.Alerts != nil && s.Metrics.Tokens.Percentage >= s.Alerts.Critical {
+		return "CRITICAL"
+	}
 
-	predictedEnd := s.GetPredictedEndTime(time.Now())
+	// Keyed off the pessimistic bound, so recent bursts warn before the average catches up
+	predictedEnd := s.pessimisticEndTime(currentTime)
 	if predictedEnd.Before(s.EndTime) {
 		return "WARNING"
 	}
+	if s.Alerts != nil && s.Metrics.Tokens.Percentage >= s.Alerts.Warning {
+		return "WARNING"
+	}
 
 	return "OK"
 }
@@ -115,8 +277,13 @@ func (s *Session) IsOverLimit() bool {
 
 // GetStatusColor returns the appropriate color for the current status
 func (s *Session) GetStatusColor() string {
-	switch s.GetStatus() {
-	case "LIMIT EXCEEDED":
+	return statusColor(s.GetStatus())
+}
+
+// statusColor returns the color name for a status
+func statusColor(status string) string {
+	switch status {
+	case "LIMIT EXCEEDED", "CRITICAL":
 		return "red"
 	case "WARNING":
 		return "yellow"
@@ -125,6 +292,93 @@ func (s *Session) GetStatusColor() string {
 	}
 }
 
+// ModelShares groups model tokens by family, weighted by --model-weights, largest share first
+func (s *Session) ModelShares() []ModelShare {
+	byFamily := make(map[string]int)
+	for model, tokens := range s.ModelTokens {
+		byFamily[modelFamily(model)] += tokens
+	}
+
+	weightedTotal := 0
+	shares := make([]ModelShare, 0, len(byFamily))
+	for family, tokens := range byFamily {
+		weight := modelWeight(family)
+		weighted := int(float64(tokens) * weight)
+		weightedTotal += weighted
+		shares = append(shares, ModelShare{
+			Family:         family,
+			Tokens:         tokens,
+			Weight:         weight,
+			WeightedTokens: weighted,
+		})
+	}
+	if weightedTotal == 0 {
+		return nil
+	}
+	for i := range shares {
+		shares[i].Percentage = float64(shares[i].WeightedTokens) / float64(weightedTotal) * 100
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].WeightedTokens == shares[j].WeightedTokens {
+			return shares[i].Family < shares[j].Family
+		}
+		return shares[i].WeightedTokens > shares[j].WeightedTokens
+	})
+	return shares
+}
+
+// loadModelTokens reads per-model token usage for the block from JSONL logs
+func loadModelTokens(block *Block, currentTime time.Time) map[string]int {
+	endTime := block.ActualEndTime
+	if endTime == "" {
+		endTime = currentTime.Format(time.RFC3339)
+	}
+	modelTokens, err := NewMessageTokenReader().GetBlockModelTokens(block.StartTime, endTime)
+	if err != nil {
+		return nil
+	}
+	return modelTokens
+}
+
+// modelWeight returns the configured weight of a model family, 1 when unset
+func modelWeight(family string) float64 {
+	if config == nil {
+		return 1
+	}
+	if weight, ok := config.ModelWeights[strings.ToLower(family)]; ok {
+		return weight
+	}
+	return 1
+}
+
+// parseModelWeights parses --model-weights values (family=weight) into lowercase family keys
+func parseModelWeights(specs map[string]string) (map[string]float64, error) {
+	weights := make(map[string]float64, len(specs))
+	for family, value := range specs {
+		weight, err := strconv.ParseFloat(value, 64)
+		if err != nil || weight <= 0 {
+			return nil, fmt.Errorf("invalid model weight %s=%s, expected a positive number", family, value)
+		}
+		weights[strings.ToLower(family)] = weight
+	}
+	return weights, nil
+}
+
+// modelFamily maps a full model name to its family (Opus, Sonnet, Haiku)
+func modelFamily(model string) string {
+	modelLower := strings.ToLower(model)
+	switch {
+	case strings.Contains(modelLower, "opus"):
+		return "Opus"
+	case strings.Contains(modelLower, "sonnet"):
+		return "Sonnet"
+	case strings.Contains(modelLower, "haiku"):
+		return "Haiku"
+	default:
+		return model
+	}
+}
+
 // determinePrimaryModel determines the currently active model from session data
 func determinePrimaryModel(models []string) string {
 	if len(models) == 0 {
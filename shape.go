@@ -0,0 +1,56 @@
+package main
+
+import (
+	"time"
+)
+
+// TypicalShape describes where historical sessions usually are at a point in the window
+type TypicalShape struct {
+	Tokens  int  // Median tokens consumed by this point in past sessions
+	Samples int  // Number of historical sessions used
+	Valid   bool // False when there is not enough history
+}
+
+// typicalTokensAt returns the median tokens historical sessions had consumed after elapsed time.
+// Per-block timestamps are not available from ccusage, so consumption is assumed linear
+// between a block's start and its actual end time.
+func typicalTokensAt(blocks []Block, elapsed time.Duration) TypicalShape {
+	var consumed []int
+	for _, block := range blocks {
+		if block.IsGap || block.IsActive || block.TotalTokens == 0 {
+			continue
+		}
+
+		startTime, err := time.Parse(time.RFC3339, block.StartTime)
+		if err != nil {
+			continue
+		}
+		endTime, err := time.Parse(time.RFC3339, block.ActualEndTime)
+		if err != nil {
+			continue
+		}
+
+		consumed = append(consumed, blockTokensAt(block.TotalTokens, endTime.Sub(startTime), elapsed))
+	}
+
+	if len(consumed) < MinHistoricalSessions {
+		return TypicalShape{Samples: len(consumed)}
+	}
+
+	return TypicalShape{
+		Tokens:  CalculateMedianTokens(consumed),
+		Samples: len(consumed),
+		Valid:   true,
+	}
+}
+
+// blockTokensAt linearly interpolates a block's tokens at elapsed time into the block
+func blockTokensAt(total int, duration, elapsed time.Duration) int {
+	if duration <= 0 || elapsed >= duration {
+		return total
+	}
+	if elapsed <= 0 {
+		return 0
+	}
+	return int(float64(total) * float64(elapsed) / float64(duration))
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTypicalTokensAt(t *testing.T) {
+	start := time.Date(2025, 6, 20, 0, 0, 0, 0, time.UTC)
+	block := func(tokens int, duration time.Duration) Block {
+		return Block{
+			StartTime:     start.Format(time.RFC3339),
+			ActualEndTime: start.Add(duration).Format(time.RFC3339),
+			TotalTokens:   tokens,
+		}
+	}
+
+	blocks := []Block{
+		block(1000, 2*time.Hour),
+		block(2000, 2*time.Hour),
+		block(3000, 4*time.Hour),
+		block(4000, 1*time.Hour),
+		block(5000, 4*time.Hour),
+		{StartTime: start.Format(time.RFC3339), TotalTokens: 9000, IsActive: true},
+	}
+
+	// At 1h: 500, 1000, 750, 4000, 1250 -> median 1000
+	shape := typicalTokensAt(blocks, time.Hour)
+	if !shape.Valid || shape.Samples != 5 {
+		t.Fatalf("shape = %+v, expected valid with 5 samples", shape)
+	}
+	if shape.Tokens != 1000 {
+		t.Errorf("Tokens = %d, expected 1000", shape.Tokens)
+	}
+
+	if shape := typicalTokensAt(blocks[:3], time.Hour); shape.Valid {
+		t.Errorf("shape = %+v, expected invalid with too little history", shape)
+	}
+}
+
+func TestBlockTokensAt(t *testing.T) {
+	if got := blockTokensAt(1000, 2*time.Hour, 30*time.Minute); got != 250 {
+		t.Errorf("blockTokensAt() = %d, expected 250", got)
+	}
+	if got := blockTokensAt(1000, 2*time.Hour, 3*time.Hour); got != 1000 {
+		t.Errorf("blockTokensAt() after end = %d, expected 1000", got)
+	}
+	if got := blockTokensAt(1000, 0, time.Hour); got != 1000 {
+		t.Errorf("blockTokensAt() zero duration = %d, expected 1000", got)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWriteFileAtomicConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state", "events.json")
+
+	// Writers of differently sized contents race with a reader, which must only
+	// ever see one complete version
+	contents := [][]byte{bytes.Repeat([]byte("a"), 10), bytes.Repeat([]byte("b"), 100000)}
+	var wg sync.WaitGroup
+	for w := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				if err := writeFileAtomic(path, contents[w%2], 0o600); err != nil {
+					t.Errorf("writeFileAtomic() error = %v", err)
+					return
+				}
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 200 {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue // Not written yet
+			}
+			if !bytes.Equal(data, contents[0]) && !bytes.Equal(data, contents[1]) {
+				t.Errorf("read a partial write of %d bytes", len(data))
+				return
+			}
+		}
+	}()
+	wg.Wait()
+	<-done
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("state directory has %d files, expected no temporary files left", len(entries))
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, expected 0600", info.Mode().Perm())
+	}
+}
+
+func TestLimitLogConcurrentProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "limits.json")
+	now := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+
+	// Each log stands for a separate cctop process that loaded the file at startup
+	var wg sync.WaitGroup
+	for w := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log := NewLimitLog(path)
+			for i := range 5 {
+				log.Record(LimitChange{Time: now, Limit: 1000*w + i, Plan: "max5", Method: "p40"})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if changes := NewLimitLog(path).Changes(); len(changes) != 40 {
+		t.Errorf("recorded %d changes, expected 40 with none lost", len(changes))
+	}
+}
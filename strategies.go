@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Estimator is an estimation strategy: it condenses the message token counts of
+// a reference session into the tokens/message value the limit is scaled from
+type Estimator interface {
+	Name() string        // Method as passed to --estimator, e.g. p40
+	Description() string // Shown in the estimation info line
+	TokensPerMessage(messageTokens []int, block *Block) int
+}
+
+// Defaults for strategies that take a parameter
+const (
+	DefaultEWMAHalfLife      = 20 // Messages after which a message's weight halves
+	DefaultBayesPriorWeight  = 50 // Messages the prior is worth
+	DefaultEstimationMethod  = "p40"
+	maxEstimatorPercentile   = 99
+	maxEstimatorTrimFraction = 49
+)
+
+// BuiltinEstimators are the strategies compared side by side in analyze output
+var BuiltinEstimators = []string{"p25", "p40", "median", "p75", "trim10", "mode", "avg", "ewma", "bayes"}
+
+// ParseEstimator returns the strategy for a method name (pNN, median, trimNN, mode, avg, ewma[NN], bayes)
+func ParseEstimator(method string) (Estimator, error) {
+	var value float64
+	switch {
+	case method == "median":
+		return PercentileEstimator{Percentile: 50}, nil
+	case method == "mode":
+		return ModeEstimator{}, nil
+	case method == "avg":
+		return AverageEstimator{}, nil
+	case method == "ewma":
+		return EWMAEstimator{HalfLife: DefaultEWMAHalfLife}, nil
+	case method == "bayes":
+		return BayesianEstimator{PriorTokensPerMsg: DefaultTokensPerMsg, PriorWeight: DefaultBayesPriorWeight}, nil
+	case strings.HasPrefix(method, "ewma"):
+		if _, err := fmt.Sscanf(method, "ewma%f", &value); err == nil && value >= 1 {
+			return EWMAEstimator{HalfLife: value}, nil
+		}
+	case strings.HasPrefix(method, "trim"):
+		if _, err := fmt.Sscanf(method, "trim%f", &value); err == nil && value >= 0 && value <= maxEstimatorTrimFraction {
+			return TrimmedMeanEstimator{Trim: value}, nil
+		}
+	case strings.HasPrefix(method, "p"):
+		if _, err := fmt.Sscanf(method, "p%f", &value); err == nil && value >= 1 && value <= maxEstimatorPercentile {
+			return PercentileEstimator{Percentile: value}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown estimation method %q (see 'cctop list-est')", method)
+}
+
+// PercentileEstimator uses a percentile of the message token counts
+type PercentileEstimator struct {
+	Percentile float64
+}
+
+// Name returns the method name
+func (s PercentileEstimator) Name() string {
+	if s.Percentile == 50 {
+		return "median"
+	}
+	return fmt.Sprintf("p%.0f", s.Percentile)
+}
+
+// Description describes the method
+func (s PercentileEstimator) Description() string {
+	if s.Percentile == 50 {
+		return "median"
+	}
+	return fmt.Sprintf("%.0fth percentile", s.Percentile)
+}
+
+// TokensPerMessage returns the percentile without reordering the input
+func (s PercentileEstimator) TokensPerMessage(messageTokens []int, block *Block) int {
+	sorted := make([]int, len(messageTokens))
+	copy(sorted, messageTokens)
+	sort.Ints(sorted)
+	return percentileOfSorted(sorted, s.Percentile)
+}
+
+// TrimmedMeanEstimator averages the message token counts without the top and bottom Trim percent
+type TrimmedMeanEstimator struct {
+	Trim float64
+}
+
+// Name returns the method name
+func (s TrimmedMeanEstimator) Name() string { return fmt.Sprintf("trim%.0f", s.Trim) }
+
+// Description describes the method
+func (s TrimmedMeanEstimator) Description() string {
+	return fmt.Sprintf("%.0f%% trimmed mean", s.Trim)
+}
+
+// TokensPerMessage returns the trimmed mean
+func (s TrimmedMeanEstimator) TokensPerMessage(messageTokens []int, block *Block) int {
+	return CalculateTrimmedMean(messageTokens, s.Trim)
+}
+
+// ModeEstimator uses the most frequent message token count
+type ModeEstimator struct{}
+
+// Name returns the method name
+func (ModeEstimator) Name() string { return "mode" }
+
+// Description describes the method
+func (ModeEstimator) Description() string { return "mode" }
+
+// TokensPerMessage returns the mode
+func (ModeEstimator) TokensPerMessage(messageTokens []int, block *Block) int {
+	return CalculateMode(messageTokens)
+}
+
+// AverageEstimator divides the session's total tokens by its entries
+type AverageEstimator struct{}
+
+// Name returns the method name
+func (AverageEstimator) Name() string { return "avg" }
+
+// Description describes the method
+func (AverageEstimator) Description() string { return "average" }
+
+// TokensPerMessage returns the session average
+func (AverageEstimator) TokensPerMessage(messageTokens []int, block *Block) int {
+	if block == nil || block.Entries == 0 {
+		return 0
+	}
+	return block.TotalTokens / block.Entries
+}
+
+// EWMAEstimator weights recent messages more, so a change in how you work shows
+// up quickly. Messages are expected in chronological order.
+type EWMAEstimator struct {
+	HalfLife float64 // In messages
+}
+
+// Name returns the method name
+func (s EWMAEstimator) Name() string {
+	if s.HalfLife == DefaultEWMAHalfLife {
+		return "ewma"
+	}
+	return fmt.Sprintf("ewma%.0f", s.HalfLife)
+}
+
+// Description describes the method
+func (s EWMAEstimator) Description() string {
+	return fmt.Sprintf("EWMA (half-life %.0f msgs)", s.HalfLife)
+}
+
+// TokensPerMessage returns the exponentially weighted moving average
+func (s EWMAEstimator) TokensPerMessage(messageTokens []int, block *Block) int {
+	if len(messageTokens) == 0 {
+		return 0
+	}
+	alpha := 1 - math.Pow(0.5, 1/s.HalfLife)
+	average := float64(messageTokens[0])
+	for _, tokens := range messageTokens[1:] {
+		average = alpha*float64(tokens) + (1-alpha)*average
+	}
+	return int(math.Round(average))
+}
+
+// BayesianEstimator shrinks the session's mean towards a prior, so sessions with
+// few messages cannot swing the estimate far
+type BayesianEstimator struct {
+	PriorTokensPerMsg int
+	PriorWeight       float64 // Messages the prior is worth
+}
+
+// Name returns the method name
+func (BayesianEstimator) Name() string { return "bayes" }
+
+// Description describes the method
+func (BayesianEstimator) Description() string { return "Bayesian mean" }
+
+// TokensPerMessage returns the posterior mean
+func (s BayesianEstimator) TokensPerMessage(messageTokens []int, block *Block) int {
+	total := s.PriorWeight * float64(s.PriorTokensPerMsg)
+	for _, tokens := range messageTokens {
+		total += float64(tokens)
+	}
+	weight := s.PriorWeight + float64(len(messageTokens))
+	if weight == 0 {
+		return 0
+	}
+	return int(math.Round(total / weight))
+}
+
+// percentileOfSorted returns the nearest-rank percentile of sorted values
+func percentileOfSorted(sorted []int, percentile float64) int {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := int(math.Ceil(float64(len(sorted))*percentile/100.0)) - 1
+	return sorted[clampInt(index, 0, len(sorted)-1)]
+}
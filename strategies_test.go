@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseEstimator(t *testing.T) {
+	tests := []struct {
+		method   string
+		expected string // Name of the parsed strategy, "" for an error
+	}{
+		{"p40", "p40"},
+		{"p50", "median"},
+		{"median", "median"},
+		{"trim10", "trim10"},
+		{"mode", "mode"},
+		{"avg", "avg"},
+		{"ewma", "ewma"},
+		{"ewma50", "ewma50"},
+		{"bayes", "bayes"},
+		{"p0", ""},
+		{"p100", ""},
+		{"trim50", ""},
+		{"ewma0", ""},
+		{"fastest", ""},
+	}
+
+	for _, tt := range tests {
+		strategy, err := ParseEstimator(tt.method)
+		switch {
+		case tt.expected == "" && err == nil:
+			t.Errorf("ParseEstimator(%q) = %s, expected an error", tt.method, strategy.Name())
+		case tt.expected != "" && err != nil:
+			t.Errorf("ParseEstimator(%q) error: %v", tt.method, err)
+		case tt.expected != "" && strategy.Name() != tt.expected:
+			t.Errorf("ParseEstimator(%q).Name() = %s, expected %s", tt.method, strategy.Name(), tt.expected)
+		}
+	}
+}
+
+func TestEstimatorStrategies(t *testing.T) {
+	tokens := []int{100, 200, 100, 300, 400, 100, 500, 600, 700, 1000}
+	block := &Block{TotalTokens: 4000, Entries: 10}
+
+	tests := []struct {
+		strategy Estimator
+		expected int
+	}{
+		{PercentileEstimator{Percentile: 40}, 200},
+		{PercentileEstimator{Percentile: 50}, 300},
+		{TrimmedMeanEstimator{Trim: 10}, 362}, // Drops one 100 and the 1000
+		{ModeEstimator{}, 100},
+		{AverageEstimator{}, 400},
+		{EWMAEstimator{HalfLife: 1}, 794},                                 // Each message halves the weight of the history
+		{BayesianEstimator{PriorTokensPerMsg: 150, PriorWeight: 10}, 275}, // (10*150 + 4000) / 20
+	}
+
+	for _, tt := range tests {
+		if got := tt.strategy.TokensPerMessage(tokens, block); got != tt.expected {
+			t.Errorf("%s.TokensPerMessage() = %d, expected %d", tt.strategy.Name(), got, tt.expected)
+		}
+	}
+	if tokens[0] != 100 || tokens[9] != 1000 {
+		t.Errorf("strategies reordered the input: %v", tokens)
+	}
+}
+
+func TestSetEstimationMethodFallback(t *testing.T) {
+	est := NewTokenLimitEstimator()
+	est.SetEstimationMethod("bayes")
+	if est.GetEstimationMethod() != "bayes" {
+		t.Errorf("GetEstimationMethod() = %s, expected bayes", est.GetEstimationMethod())
+	}
+	est.SetEstimationMethod("unknown")
+	if est.GetEstimationMethod() != DefaultEstimationMethod {
+		t.Errorf("GetEstimationMethod() = %s after an unknown method, expected %s", est.GetEstimationMethod(), DefaultEstimationMethod)
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// SupportLogLines is how much of the log file goes into a support bundle
+const SupportLogLines = 500
+
+var (
+	supportBundleOut string
+	supportBundleYes bool
+)
+
+// BundleItem is a part of the support bundle the user is asked about separately
+type BundleItem struct {
+	Description string
+	Collect     func() (map[string][]byte, error) // File names in the zip to contents
+}
+
+// runSupportBundle writes a zip of the items the user agrees to share, for attaching to an issue
+func runSupportBundle(cmd *cobra.Command, args []string) {
+	homeDir, _ := os.UserHomeDir()
+	out := supportBundleOut
+	if out == "" {
+		out = fmt.Sprintf("cctop-support-%s.zip", time.Now().Format("20060102-150405"))
+	}
+
+	fmt.Println("cctop support bundle: nothing is uploaded; review the zip before attaching it to an issue.")
+	stdin := bufio.NewReader(os.Stdin)
+	files := make(map[string][]byte)
+	for _, item := range supportBundleItems(homeDir) {
+		if !supportBundleYes && !askConsent(stdin, os.Stdout, item.Description) {
+			continue
+		}
+		collected, err := item.Collect()
+		if err != nil {
+			fmt.Printf("  skipped: %v\n", err)
+			continue
+		}
+		for name, data := range collected {
+			files[name] = data
+		}
+	}
+	if len(files) == 0 {
+		fmt.Println("Nothing to bundle")
+		return
+	}
+
+	file, err := os.Create(out)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer file.Close()
+	if err := writeBundle(file, files); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Wrote %s (%d files)\n", out, len(files))
+}
+
+// supportBundleItems lists what a bundle can contain, each sanitized of the home directory
+func supportBundleItems(homeDir string) []BundleItem {
+	return []BundleItem{
+		{
+			Description: "version, platform and enabled features (same as 'cctop about --report')",
+			Collect: func() (map[string][]byte, error) {
+				var buffer strings.Builder
+				for _, field := range append(environmentFields(), configFields(config)...) {
+					fmt.Fprintf(&buffer, "%-18s %s\n", field.Name+":", field.Value)
+				}
+				return map[string][]byte{"version.txt": []byte(buffer.String())}, nil
+			},
+		},
+		{
+			Description: "effective settings, with tokens, webhook URLs and hook commands masked",
+			Collect: func() (map[string][]byte, error) {
+				var buffer strings.Builder
+				for _, problem := range configFile.Problems {
+					fmt.Fprintf(&buffer, "# problem: %s\n", problem)
+				}
+				for _, setting := range effectiveSettings(rootFlags(), configFile) {
+					fmt.Fprintf(&buffer, "%-24s %-40s %s\n", setting.Name, setting.Value, setting.Source)
+				}
+				return map[string][]byte{"config.txt": []byte(sanitizeText(buffer.String(), homeDir))}, nil
+			},
+		},
+		{
+			Description: fmt.Sprintf("last %d lines of the cctop log (may name project directories)", SupportLogLines),
+			Collect: func() (map[string][]byte, error) {
+				lines, err := tailLines(config.Log.File, SupportLogLines)
+				if err != nil {
+					return nil, err
+				}
+				text := strings.Join(lines, "\n") + "\n"
+				return map[string][]byte{"cctop.log": []byte(sanitizeText(text, homeDir))}, nil
+			},
+		},
+		{
+			Description: "block statistics (tokens, messages, cost, models) with relative times and the estimated limit",
+			Collect: func() (map[string][]byte, error) {
+				estimator.SetEstimationMethod(estimationMethod)
+				data := fetchUsageData()
+				if data == nil {
+					return nil, fmt.Errorf("failed to get usage data")
+				}
+				var buffer bytes.Buffer
+				if err := writeAnonymizedBlocks(&buffer, data.Blocks); err != nil {
+					return nil, err
+				}
+				estimate := fmt.Sprintf("plan: %s\ndetected: %s\nestimator: %s\nlimit: %d\n",
+					config.Plan,
+					estimator.GetActualPlan(config.Plan, data.Blocks),
+					estimationMethod,
+					estimator.EstimateLimit(config.Plan, data.Blocks))
+				return map[string][]byte{"blocks.csv": buffer.Bytes(), "estimate.txt": []byte(estimate)}, nil
+			},
+		},
+	}
+}
+
+// askConsent asks a yes/no question, defaulting to no
+func askConsent(r *bufio.Reader, w io.Writer, description string) bool {
+	fmt.Fprintf(w, "Include %s? [y/N] ", description)
+	answer, _ := r.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// sanitizeText replaces the home directory with ~ so user names in paths are not shared
+func sanitizeText(text, homeDir string) string {
+	if homeDir == "" || homeDir == "/" {
+		return text
+	}
+	return strings.ReplaceAll(text, homeDir, "~")
+}
+
+// writeAnonymizedBlocks writes block statistics without ids or dates. Start times are
+// hours after the first block, which keeps gaps and limit timing but not when work happened.
+func writeAnonymizedBlocks(w io.Writer, blocks []Block) error {
+	out := csv.NewWriter(w)
+	_ = out.Write([]string{"start_offset_hours", "duration_minutes", "active", "models", "input_tokens",
+		"output_tokens", "cache_creation_tokens", "cache_read_tokens", "total_tokens", "entries", "cost_usd"})
+
+	var first time.Time
+	for _, block := range blocks {
+		if block.IsGap {
+			continue
+		}
+		start, err := time.Parse(time.RFC3339, block.StartTime)
+		if err != nil {
+			continue
+		}
+		if first.IsZero() {
+			first = start
+		}
+		duration := ""
+		if end, err := time.Parse(time.RFC3339, block.ActualEndTime); err == nil {
+			duration = strconv.Itoa(int(end.Sub(start).Minutes()))
+		}
+
+		families := make([]string, 0, len(block.Models))
+		for _, model := range block.Models {
+			families = append(families, modelFamily(model))
+		}
+		counts := block.TokenCounts
+		_ = out.Write([]string{
+			strconv.FormatFloat(start.Sub(first).Hours(), 'f', 1, 64),
+			duration,
+			strconv.FormatBool(block.IsActive),
+			strings.Join(families, " "),
+			strconv.Itoa(counts.InputTokens),
+			strconv.Itoa(counts.OutputTokens),
+			strconv.Itoa(counts.CacheCreationInputTokens),
+			strconv.Itoa(counts.CacheReadInputTokens),
+			strconv.Itoa(block.TotalTokens),
+			strconv.Itoa(block.Entries),
+			strconv.FormatFloat(block.CostUSD, 'f', 6, 64),
+		})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// writeBundle writes the files as a zip archive
+func writeBundle(w io.Writer, files map[string][]byte) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	zipWriter := zip.NewWriter(w)
+	for _, name := range names {
+		entry, err := zipWriter.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := entry.Write(files[name]); err != nil {
+			return err
+		}
+	}
+	return zipWriter.Close()
+}
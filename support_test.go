@@ -0,0 +1,91 @@
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestAskConsent(t *testing.T) {
+	tests := []struct {
+		answer   string
+		expected bool
+	}{
+		{"y\n", true},
+		{"YES\n", true},
+		{"\n", false},
+		{"n\n", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if got := askConsent(bufio.NewReader(strings.NewReader(tt.answer)), &out, "logs"); got != tt.expected {
+			t.Errorf("askConsent(%q) = %v, expected %v", tt.answer, got, tt.expected)
+		}
+		if !strings.Contains(out.String(), "Include logs? [y/N]") {
+			t.Errorf("prompt = %q, expected the item to be named", out.String())
+		}
+	}
+}
+
+func TestSanitizeText(t *testing.T) {
+	got := sanitizeText("reading /home/alice/.claude/projects", "/home/alice")
+	if got != "reading ~/.claude/projects" {
+		t.Errorf("sanitizeText() = %q, expected the home directory replaced", got)
+	}
+	if got := sanitizeText("/etc/x", "/"); got != "/etc/x" {
+		t.Errorf("sanitizeText() with / home = %q, expected unchanged", got)
+	}
+}
+
+func TestWriteAnonymizedBlocks(t *testing.T) {
+	blocks := []Block{
+		{ID: "secret-id", StartTime: "2025-07-01T10:00:00Z", ActualEndTime: "2025-07-01T12:30:00Z", Models: []string{"claude-opus-4-20250514"}, TotalTokens: 1000, Entries: 5},
+		{StartTime: "2025-07-01T15:00:00Z", IsGap: true},
+		{StartTime: "2025-07-02T10:00:00Z", TotalTokens: 200, IsActive: true},
+	}
+
+	var buffer bytes.Buffer
+	if err := writeAnonymizedBlocks(&buffer, blocks); err != nil {
+		t.Fatalf("writeAnonymizedBlocks() error = %v", err)
+	}
+	output := buffer.String()
+	if strings.Contains(output, "2025") || strings.Contains(output, "secret-id") {
+		t.Errorf("output contains dates or ids:\n%s", output)
+	}
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, expected header and 2 blocks:\n%s", len(lines), output)
+	}
+	if !strings.HasPrefix(lines[1], "0.0,150,false,Opus,") {
+		t.Errorf("first block = %q, expected offset 0 and 150 minutes", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "24.0,,true,,") {
+		t.Errorf("second block = %q, expected offset 24 hours", lines[2])
+	}
+}
+
+func TestWriteBundle(t *testing.T) {
+	var buffer bytes.Buffer
+	files := map[string][]byte{"version.txt": []byte("cctop dev\n"), "config.txt": []byte("plan auto\n")}
+	if err := writeBundle(&buffer, files); err != nil {
+		t.Fatalf("writeBundle() error = %v", err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		t.Fatalf("zip.NewReader() error = %v", err)
+	}
+	if len(reader.File) != 2 || reader.File[0].Name != "config.txt" {
+		t.Fatalf("zip files = %v, expected config.txt and version.txt", reader.File)
+	}
+	entry, _ := reader.File[1].Open()
+	data, _ := io.ReadAll(entry)
+	if string(data) != "cctop dev\n" {
+		t.Errorf("version.txt = %q, expected %q", data, "cctop dev\n")
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// System log backends
+const (
+	SyslogAuto     = "auto"
+	SyslogJournald = "journald"
+	SyslogSyslog   = "syslog"
+	SyslogOSLog    = "oslog"
+)
+
+// journaldSocket is the native journald protocol socket
+const journaldSocket = "/run/systemd/journal/socket"
+
+// Log priorities (syslog severities)
+const (
+	PriorityErr     = 3
+	PriorityWarning = 4
+	PriorityNotice  = 5
+	PriorityInfo    = 6
+)
+
+// SystemLog writes status changes and alerts to the system log with structured fields
+type SystemLog struct {
+	backend string
+	write   func(priority int, message string, fields map[string]string) error
+}
+
+// NewSystemLog creates a logger for the backend, choosing one for this system with "auto"
+func NewSystemLog(backend string) (*SystemLog, error) {
+	if backend == SyslogAuto {
+		backend = detectSyslogBackend()
+	}
+
+	l := &SystemLog{backend: backend}
+	switch backend {
+	case SyslogJournald:
+		l.write = writeJournald
+	case SyslogOSLog:
+		l.write = writeOSLog
+	case SyslogSyslog:
+		write, err := newSyslogWriter()
+		if err != nil {
+			return nil, err
+		}
+		l.write = write
+	default:
+		return nil, fmt.Errorf("unknown system log backend %q (auto, journald, syslog, oslog)", backend)
+	}
+	return l, nil
+}
+
+// detectSyslogBackend prefers journald when running, the unified log on macOS, and syslog otherwise
+func detectSyslogBackend() string {
+	if _, err := os.Stat(journaldSocket); err == nil {
+		return SyslogJournald
+	}
+	if runtime.GOOS == "darwin" {
+		return SyslogOSLog
+	}
+	return SyslogSyslog
+}
+
+// LogStatus writes a status change event
+func (l *SystemLog) LogStatus(event StatusEvent, session *Session) error {
+	fields := sessionLogFields(session)
+	fields["EVENT"] = "status_change"
+	fields["STATUS"] = event.Status
+	return l.write(statusPriority(event.Status), fmt.Sprintf("%s: %s", event.Status, event.Reason), fields)
+}
+
+// LogAlert writes an alert (a notification that was triggered)
+func (l *SystemLog) LogAlert(message string, session *Session) error {
+	fields := sessionLogFields(session)
+	fields["EVENT"] = "alert"
+	return l.write(PriorityWarning, message, fields)
+}
+
+// sessionLogFields returns the structured fields describing a session
+func sessionLogFields(session *Session) map[string]string {
+	fields := make(map[string]string)
+	if session == nil {
+		return fields
+	}
+	tokens := session.Metrics.Tokens
+	fields["TOKENS_USED"] = fmt.Sprint(tokens.Used)
+	fields["TOKEN_LIMIT"] = fmt.Sprint(tokens.Limit)
+	fields["PERCENTAGE"] = fmt.Sprintf("%.1f", tokens.Percentage)
+	fields["BURN_RATE"] = fmt.Sprintf("%.1f", session.BurnRate)
+	fields["SESSION_START"] = session.StartTime.Format(time.RFC3339)
+	return fields
+}
+
+// statusPriority maps a session status to a log priority
+func statusPriority(status string) int {
+	switch status {
+	case "LIMIT EXCEEDED", "CRITICAL":
+		return PriorityErr
+	case "WARNING":
+		return PriorityWarning
+	default:
+		return PriorityInfo
+	}
+}
+
+// formatLogFields renders fields as sorted key=value pairs for text based logs
+func formatLogFields(fields map[string]string) string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = fmt.Sprintf("%s=%q", strings.ToLower(key), fields[key])
+	}
+	return strings.Join(parts, " ")
+}
+
+// journaldMessage encodes an entry in the journald native protocol.
+// Custom fields are prefixed with CCTOP_ so they can be queried with journalctl.
+func journaldMessage(priority int, message string, fields map[string]string) []byte {
+	var b strings.Builder
+	writeField := func(key, value string) {
+		// Newlines would need the binary field encoding; keep entries single line
+		fmt.Fprintf(&b, "%s=%s\n", key, strings.ReplaceAll(value, "\n", " "))
+	}
+
+	writeField("MESSAGE", message)
+	writeField("PRIORITY", fmt.Sprint(priority))
+	writeField("SYSLOG_IDENTIFIER", "cctop")
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		writeField("CCTOP_"+key, fields[key])
+	}
+	return []byte(b.String())
+}
+
+// writeJournald sends an entry to journald over its native socket
+func writeJournald(priority int, message string, fields map[string]string) error {
+	conn, err := net.Dial("unixgram", journaldSocket)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Write(journaldMessage(priority, message, fields))
+	return err
+}
+
+// writeOSLog writes to the macOS unified log via logger(1)
+func writeOSLog(priority int, message string, fields map[string]string) error {
+	level := "user.info"
+	switch {
+	case priority <= PriorityErr:
+		level = "user.err"
+	case priority <= PriorityWarning:
+		level = "user.warning"
+	}
+	return exec.Command("logger", "-t", "cctop", "-p", level, message+" "+formatLogFields(fields)).Run()
+}
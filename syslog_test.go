@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJournaldMessage(t *testing.T) {
+	message := string(journaldMessage(PriorityWarning, "WARNING: burn\nrate", map[string]string{
+		"STATUS":      "WARNING",
+		"TOKENS_USED": "5000",
+	}))
+
+	expected := "MESSAGE=WARNING: burn rate\nPRIORITY=4\nSYSLOG_IDENTIFIER=cctop\nCCTOP_STATUS=WARNING\nCCTOP_TOKENS_USED=5000\n"
+	if message != expected {
+		t.Errorf("journaldMessage() = %q, expected %q", message, expected)
+	}
+}
+
+func TestFormatLogFields(t *testing.T) {
+	result := formatLogFields(map[string]string{"STATUS": "LIMIT EXCEEDED", "EVENT": "alert"})
+	expected := `event="alert" status="LIMIT EXCEEDED"`
+	if result != expected {
+		t.Errorf("formatLogFields() = %s, expected %s", result, expected)
+	}
+}
+
+func TestSystemLogStatus(t *testing.T) {
+	var priority int
+	var message string
+	var fields map[string]string
+	l := &SystemLog{write: func(p int, m string, f map[string]string) error {
+		priority, message, fields = p, m, f
+		return nil
+	}}
+
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	session := newTestSession(start, 12000, 10000)
+	event := StatusEvent{Status: "LIMIT EXCEEDED", Reason: "tokens 12,000 exceeded limit 10,000"}
+	if err := l.LogStatus(event, session); err != nil {
+		t.Fatal(err)
+	}
+
+	if priority != PriorityErr {
+		t.Errorf("priority = %d, expected %d", priority, PriorityErr)
+	}
+	if !strings.HasPrefix(message, "LIMIT EXCEEDED: tokens") {
+		t.Errorf("message = %q, expected status and reason", message)
+	}
+	if fields["EVENT"] != "status_change" || fields["TOKENS_USED"] != "12000" || fields["TOKEN_LIMIT"] != "10000" {
+		t.Errorf("fields = %v, expected status change with token counts", fields)
+	}
+}
+
+func TestNewSystemLogUnknownBackend(t *testing.T) {
+	if _, err := NewSystemLog("carrier-pigeon"); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+}
@@ -0,0 +1,26 @@
+//go:build !windows
+
+package main
+
+import "log/syslog"
+
+// newSyslogWriter connects to the local syslog daemon
+func newSyslogWriter() (func(priority int, message string, fields map[string]string) error, error) {
+	writer, err := syslog.New(syslog.LOG_USER|syslog.LOG_INFO, "cctop")
+	if err != nil {
+		return nil, err
+	}
+	return func(priority int, message string, fields map[string]string) error {
+		line := message + " " + formatLogFields(fields)
+		switch {
+		case priority <= PriorityErr:
+			return writer.Err(line)
+		case priority <= PriorityWarning:
+			return writer.Warning(line)
+		case priority <= PriorityNotice:
+			return writer.Notice(line)
+		default:
+			return writer.Info(line)
+		}
+	}, nil
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package main
+
+import "fmt"
+
+// newSyslogWriter reports that syslog is unavailable on Windows
+func newSyslogWriter() (func(priority int, message string, fields map[string]string) error, error) {
+	return nil, fmt.Errorf("syslog is not supported on windows")
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// TeamMemberUsage is a member's usage against their share of the session limit
+type TeamMemberUsage struct {
+	Name   string       `json:"name"`
+	Share  float64      `json:"share"`  // Percentage of the session limit
+	Tokens TokenMetrics `json:"tokens"` // Limit is the member's allowance
+}
+
+// parseTeamShares parses --team-share values (name=percent) for a shared account.
+// Every member must be a --claude-dir profile, and shares may not exceed 100% together.
+func parseTeamShares(specs map[string]string, profiles []Profile) (map[string]float64, error) {
+	known := make(map[string]bool, len(profiles))
+	for _, profile := range profiles {
+		known[profile.Name] = true
+	}
+
+	shares := make(map[string]float64, len(specs))
+	total := 0.0
+	for name, value := range specs {
+		share, err := strconv.ParseFloat(value, 64)
+		if err != nil || share <= 0 || share > 100 {
+			return nil, fmt.Errorf("invalid team share %s=%s, expected a percentage between 0 and 100", name, value)
+		}
+		if !known[name] {
+			return nil, fmt.Errorf("team member %q has no --claude-dir profile with that name", name)
+		}
+		shares[name] = share
+		total += share
+	}
+	if total > 100 {
+		return nil, fmt.Errorf("team shares add up to %.0f%%, expected at most 100%%", total)
+	}
+	return shares, nil
+}
+
+// calculateTeamUsage compares each member's tokens with their share of the limit,
+// in profile order
+func calculateTeamUsage(usage []ProfileUsage, shares map[string]float64, limit int) []TeamMemberUsage {
+	var team []TeamMemberUsage
+	for _, profile := range usage {
+		share, ok := shares[profile.Name]
+		if !ok {
+			continue
+		}
+		allowance := int(float64(limit) * share / 100)
+		member := TeamMemberUsage{
+			Name:   profile.Name,
+			Share:  share,
+			Tokens: TokenMetrics{Used: profile.Tokens, Limit: allowance, Remaining: allowance - profile.Tokens},
+		}
+		if allowance > 0 {
+			member.Tokens.Percentage = float64(profile.Tokens) / float64(allowance) * 100
+		}
+		team = append(team, member)
+	}
+	return team
+}
+
+// teamShareMessage builds the notification text for a member exceeding their share
+func teamShareMessage(member TeamMemberUsage) string {
+	return tr("%s exceeded their %.0f%% share of the session limit (%s/%s)",
+		member.Name, member.Share, formatNumber(member.Tokens.Used), formatNumber(member.Tokens.Limit))
+}
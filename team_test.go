@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTeamShares(t *testing.T) {
+	profiles := []Profile{{Name: "alice"}, {Name: "bob"}}
+	tests := []struct {
+		specs   map[string]string
+		wantErr string
+	}{
+		{map[string]string{"alice": "60", "bob": "40"}, ""},
+		{map[string]string{"alice": "50"}, ""},
+		{map[string]string{"alice": "0"}, "invalid team share"},
+		{map[string]string{"alice": "half"}, "invalid team share"},
+		{map[string]string{"carol": "20"}, "no --claude-dir profile"},
+		{map[string]string{"alice": "70", "bob": "40"}, "add up to 110%"},
+	}
+
+	for _, tt := range tests {
+		_, err := parseTeamShares(tt.specs, profiles)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("parseTeamShares(%v) error: %v", tt.specs, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("parseTeamShares(%v) error = %v, expected %q", tt.specs, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCalculateTeamUsage(t *testing.T) {
+	usage := []ProfileUsage{{Name: "alice", Tokens: 30000}, {Name: "bob", Tokens: 45000}, {Name: "guest", Tokens: 1000}}
+	team := calculateTeamUsage(usage, map[string]float64{"alice": 60, "bob": 40}, 100000)
+
+	if len(team) != 2 {
+		t.Fatalf("len(team) = %d, expected 2 (members without a share are skipped)", len(team))
+	}
+	if team[0].Name != "alice" || team[0].Tokens.Limit != 60000 || team[0].Tokens.Percentage != 50 {
+		t.Errorf("team[0] = %+v, expected alice at 50%% of 60,000", team[0])
+	}
+	if team[1].Name != "bob" || team[1].Tokens.Limit != 40000 || team[1].Tokens.Remaining != -5000 {
+		t.Errorf("team[1] = %+v, expected bob 5,000 over 40,000", team[1])
+	}
+}
+
+func TestNotifierTeamShare(t *testing.T) {
+	var sent []string
+	n := newTestNotifier(&sent)
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+
+	session := newTestSession(start, 10000, 100000)
+	session.Team = calculateTeamUsage([]ProfileUsage{{Name: "alice", Tokens: 5000}, {Name: "bob", Tokens: 41000}},
+		map[string]float64{"alice": 60, "bob": 40}, 100000)
+	n.Check(session, start.Add(time.Hour))
+	n.Check(session, start.Add(2*time.Hour))
+
+	expected := "bob exceeded their 40% share of the session limit (41,000/40,000)"
+	if len(sent) != 1 || sent[0] != expected {
+		t.Errorf("sent = %v, expected [%s]", sent, expected)
+	}
+}
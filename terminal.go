@@ -0,0 +1,81 @@
+package main
+
+import (
+	"io"
+	"os"
+	"regexp"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// Terminal is where rendered views are written. The interactive TUI draws
+// through bubbletea; one-shot and headless output goes through a Terminal so
+// the same render code works on ANSI terminals, the Windows console and pipes.
+type Terminal interface {
+	io.Writer
+	Width() int  // Columns, or 0 when unknown
+	Color() bool // Whether escape sequences reach the screen
+}
+
+// Escape sequences written outside bubbletea
+const (
+	clearScreen = "\x1b[H\x1b[2J" // Moves the cursor home and erases the screen
+	resetStyle  = "\x1b[0m"       // Ends any SGR styling
+)
+
+// ansiPattern matches SGR and cursor control escape sequences
+var ansiPattern = regexp.MustCompile("\x1b\\[[0-9;?]*[A-Za-z]")
+
+// NewTerminal returns the terminal implementation for the file: a console
+// for a Windows tty, ANSI for other ttys, and plain output otherwise
+func NewTerminal(file *os.File) Terminal {
+	if !term.IsTerminal(file.Fd()) {
+		return &plainTerminal{out: file}
+	}
+	return &ansiTerminal{out: newConsoleWriter(file), fd: file.Fd()}
+}
+
+// ansiTerminal writes escape sequences as they are. On Windows the writer
+// translates them to console API calls.
+type ansiTerminal struct {
+	out io.Writer
+	fd  uintptr
+}
+
+func (t *ansiTerminal) Write(p []byte) (int, error) { return t.out.Write(p) }
+
+// Width returns the terminal's current width
+func (t *ansiTerminal) Width() int {
+	width, _, err := term.GetSize(t.fd)
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// Color reports that escape sequences are shown
+func (t *ansiTerminal) Color() bool { return true }
+
+// plainTerminal strips escape sequences, for pipes, files and headless use
+type plainTerminal struct {
+	out io.Writer
+}
+
+// Write writes p without escape sequences, reporting len(p) as written
+func (t *plainTerminal) Write(p []byte) (int, error) {
+	if _, err := t.out.Write(stripANSI(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Width reports an unknown width
+func (t *plainTerminal) Width() int { return 0 }
+
+// Color reports that escape sequences are removed
+func (t *plainTerminal) Color() bool { return false }
+
+// stripANSI removes escape sequences from text
+func stripANSI(p []byte) []byte {
+	return ansiPattern.ReplaceAll(p, nil)
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package main
+
+import (
+	"io"
+	"os"
+)
+
+// newConsoleWriter returns the file itself; ANSI terminals handle escape sequences natively
+func newConsoleWriter(file *os.File) io.Writer {
+	return file
+}
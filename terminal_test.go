@@ -0,0 +1,24 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPlainTerminal(t *testing.T) {
+	var buffer bytes.Buffer
+	terminal := &plainTerminal{out: &buffer}
+
+	input := "\x1b[32mOK\x1b[0m \x1b[1;31mLIMIT\x1b[0m\x1b[?25l"
+	n, err := terminal.Write([]byte(input))
+	if err != nil || n != len(input) {
+		t.Errorf("Write() = %d, %v, expected %d, nil", n, err, len(input))
+	}
+	if buffer.String() != "OK LIMIT" {
+		t.Errorf("Write() wrote %q, expected %q", buffer.String(), "OK LIMIT")
+	}
+
+	if terminal.Color() || terminal.Width() != 0 {
+		t.Error("plainTerminal reports color or a width")
+	}
+}
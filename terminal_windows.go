@@ -0,0 +1,34 @@
+//go:build windows
+
+package main
+
+import (
+	"io"
+	"os"
+
+	"github.com/mattn/go-colorable"
+	"golang.org/x/sys/windows"
+)
+
+// newConsoleWriter turns on virtual terminal processing so the console handles
+// escape sequences natively (Windows 10 and later). Legacy consoles without it
+// get the sequences translated to console API calls instead.
+func newConsoleWriter(file *os.File) io.Writer {
+	if enableVirtualTerminal(file) {
+		return file
+	}
+	return colorable.NewColorable(file)
+}
+
+// enableVirtualTerminal reports whether the console now interprets escape sequences
+func enableVirtualTerminal(file *os.File) bool {
+	handle := windows.Handle(file.Fd())
+	var mode uint32
+	if err := windows.GetConsoleMode(handle, &mode); err != nil {
+		return false
+	}
+	if mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING != 0 {
+		return true
+	}
+	return windows.SetConsoleMode(handle, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING) == nil
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/fatih/color"
+)
+
+// Color roles name what a color marks, so themes and --colors can restyle each element
+const (
+	RoleOK        = "ok"         // Normal status and values within limits
+	RoleWarning   = "warning"    // Warning status and notices
+	RoleDanger    = "danger"     // Exceeded limits and errors
+	RoleInfo      = "info"       // Highlighted values and labels
+	RoleMuted     = "muted"      // Secondary text and faint markers
+	RoleAccent    = "accent"     // Model specific details such as the Opus switch
+	RoleBarLow    = "bar-low"    // Token bar below the medium threshold
+	RoleBarMid    = "bar-mid"    // Token bar between the medium and high thresholds
+	RoleBarHigh   = "bar-high"   // Token bar above the high threshold
+	RoleBarTime   = "bar-time"   // Elapsed part of the session bar
+	RoleBarSwitch = "bar-switch" // Opus to Sonnet switch line on the token bar
+	RoleBanner    = "banner"     // Safe mode banner
+)
+
+// colorRoles lists the roles in the order they are documented
+var colorRoles = []string{
+	RoleOK, RoleWarning, RoleDanger, RoleInfo, RoleMuted, RoleAccent,
+	RoleBarLow, RoleBarMid, RoleBarHigh, RoleBarTime, RoleBarSwitch, RoleBanner,
+}
+
+// Theme maps color roles to color specs, see parseColorSpec
+type Theme map[string]string
+
+// themes are the built-in themes selectable with --theme
+var themes = map[string]Theme{
+	"default": {
+		RoleOK: "green", RoleWarning: "yellow", RoleDanger: "red", RoleInfo: "cyan",
+		RoleMuted: "hi-black", RoleAccent: "magenta",
+		RoleBarLow: "green", RoleBarMid: "yellow", RoleBarHigh: "red", RoleBarTime: "blue",
+		RoleBarSwitch: "red", RoleBanner: "black on-yellow",
+	},
+	// Solarized accent colors from the 256-color palette
+	"solarized": {
+		RoleOK: "64", RoleWarning: "136", RoleDanger: "160", RoleInfo: "37",
+		RoleMuted: "240", RoleAccent: "125",
+		RoleBarLow: "64", RoleBarMid: "136", RoleBarHigh: "160", RoleBarTime: "33",
+		RoleBarSwitch: "166", RoleBanner: "230 on-136",
+	},
+	// No colors, only emphasis, for terminals or readers that cannot tell colors apart
+	"monochrome": {
+		RoleOK: "none", RoleWarning: "bold", RoleDanger: "bold underline", RoleInfo: "none",
+		RoleMuted: "faint", RoleAccent: "italic",
+		RoleBarLow: "none", RoleBarMid: "bold", RoleBarHigh: "bold", RoleBarTime: "faint",
+		RoleBarSwitch: "underline", RoleBanner: "reverse",
+	},
+	"high-contrast": {
+		RoleOK: "bold hi-green", RoleWarning: "bold hi-yellow", RoleDanger: "bold hi-red", RoleInfo: "bold hi-cyan",
+		RoleMuted: "white", RoleAccent: "bold hi-magenta",
+		RoleBarLow: "hi-green", RoleBarMid: "hi-yellow", RoleBarHigh: "hi-red", RoleBarTime: "hi-blue",
+		RoleBarSwitch: "bold hi-white", RoleBanner: "bold black on-hi-yellow",
+	},
+}
+
+// activeColors holds the parsed colors of the selected theme and overrides; plain roles are nil
+var activeColors = mustThemeColors("default", nil)
+
+// colorNames maps color names to their foreground attribute; backgrounds are prefixed with on-
+var colorNames = map[string]color.Attribute{
+	"black": color.FgBlack, "red": color.FgRed, "green": color.FgGreen, "yellow": color.FgYellow,
+	"blue": color.FgBlue, "magenta": color.FgMagenta, "cyan": color.FgCyan, "white": color.FgWhite,
+	"hi-black": color.FgHiBlack, "hi-red": color.FgHiRed, "hi-green": color.FgHiGreen, "hi-yellow": color.FgHiYellow,
+	"hi-blue": color.FgHiBlue, "hi-magenta": color.FgHiMagenta, "hi-cyan": color.FgHiCyan, "hi-white": color.FgHiWhite,
+}
+
+// styleNames maps text styles to their attribute
+var styleNames = map[string]color.Attribute{
+	"bold": color.Bold, "faint": color.Faint, "italic": color.Italic,
+	"underline": color.Underline, "reverse": color.ReverseVideo,
+}
+
+// themeNames returns the built-in theme names, sorted
+func themeNames() []string {
+	return slices.Sorted(maps.Keys(themes))
+}
+
+// parseColorSpec parses space separated words into color attributes: color names
+// (red, hi-red), 256-color palette numbers (0-255), styles (bold, faint, italic,
+// underline, reverse), backgrounds (on-yellow, on-136), or none for plain text
+func parseColorSpec(spec string) ([]color.Attribute, error) {
+	var attrs []color.Attribute
+	for _, word := range strings.Fields(strings.ToLower(spec)) {
+		background := strings.HasPrefix(word, "on-")
+		name := strings.TrimPrefix(word, "on-")
+		if n, err := strconv.Atoi(name); err == nil && n >= 0 && n <= 255 {
+			// 256-color sequences are written as 38;5;N, or 48;5;N for the background
+			mode := color.Attribute(38)
+			if background {
+				mode = 48
+			}
+			attrs = append(attrs, mode, 5, color.Attribute(n))
+			continue
+		}
+		if fg, ok := colorNames[name]; ok {
+			if background {
+				fg += color.BgBlack - color.FgBlack
+			}
+			attrs = append(attrs, fg)
+			continue
+		}
+		if style, ok := styleNames[word]; ok {
+			attrs = append(attrs, style)
+			continue
+		}
+		if word != "none" {
+			return nil, fmt.Errorf("unknown color %q", word)
+		}
+	}
+	return attrs, nil
+}
+
+// themeColors resolves the colors of a theme with per-role overrides applied
+func themeColors(name string, overrides map[string]string) (map[string]*color.Color, error) {
+	theme, ok := themes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(themeNames(), ", "))
+	}
+	specs := maps.Clone(theme)
+	for role, spec := range overrides {
+		if !slices.Contains(colorRoles, role) {
+			return nil, fmt.Errorf("unknown color role %q (available: %s)", role, strings.Join(colorRoles, ", "))
+		}
+		specs[role] = spec
+	}
+
+	colors := make(map[string]*color.Color, len(specs))
+	for role, spec := range specs {
+		attrs, err := parseColorSpec(spec)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", role, err)
+		}
+		if len(attrs) > 0 {
+			colors[role] = color.New(attrs...)
+		}
+	}
+	return colors, nil
+}
+
+// mustThemeColors resolves a built-in theme, panicking on invalid built-in specs
+func mustThemeColors(name string, overrides map[string]string) map[string]*color.Color {
+	colors, err := themeColors(name, overrides)
+	if err != nil {
+		panic(err)
+	}
+	return colors
+}
+
+// applyTheme selects the theme and overrides used by the display
+func applyTheme(name string, overrides map[string]string) error {
+	colors, err := themeColors(name, overrides)
+	if err != nil {
+		return err
+	}
+	activeColors = colors
+	return nil
+}
+
+// validColorOverrides checks --colors values given as role=spec pairs
+func validColorOverrides(value string) error {
+	overrides := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		role, spec, _ := strings.Cut(pair, "=")
+		overrides[role] = spec
+	}
+	_, err := themeColors("default", overrides)
+	return err
+}
+
+// colorDisabled reports whether output must be plain: NO_COLOR is set to any
+// non-empty value (https://no-color.org), TERM is dumb, or stdout is not a terminal
+func colorDisabled() bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return true
+	}
+	return !term.IsTerminal(os.Stdout.Fd())
+}
+
+// paint formats text in the color of a role
+func paint(role, format string, a ...interface{}) string {
+	c := activeColors[role]
+	if c == nil {
+		return fmt.Sprintf(format, a...)
+	}
+	return c.Sprintf(format, a...)
+}
+
+// Role painters with the signature of the fatih/color helpers they replace
+func okString(format string, a ...interface{}) string      { return paint(RoleOK, format, a...) }
+func warningString(format string, a ...interface{}) string { return paint(RoleWarning, format, a...) }
+func dangerString(format string, a ...interface{}) string  { return paint(RoleDanger, format, a...) }
+func infoString(format string, a ...interface{}) string    { return paint(RoleInfo, format, a...) }
+func mutedString(format string, a ...interface{}) string   { return paint(RoleMuted, format, a...) }
+func accentString(format string, a ...interface{}) string  { return paint(RoleAccent, format, a...) }
+
+// runThemes lists the built-in themes with a sample of each role
+func runThemes() {
+	current := activeColors
+	defer func() { activeColors = current }()
+	for _, name := range themeNames() {
+		activeColors = mustThemeColors(name, nil)
+		marker := " "
+		if name == config.Theme {
+			marker = "*"
+		}
+		samples := make([]string, 0, len(colorRoles))
+		for _, role := range colorRoles {
+			samples = append(samples, paint(role, "%s", role))
+		}
+		fmt.Printf("%s %-14s %s\n", marker, name, strings.Join(samples, " "))
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestParseColorSpec(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected []color.Attribute
+		wantErr  bool
+	}{
+		{"red", []color.Attribute{color.FgRed}, false},
+		{"bold hi-green", []color.Attribute{color.Bold, color.FgHiGreen}, false},
+		{"black on-yellow", []color.Attribute{color.FgBlack, color.BgYellow}, false},
+		{"136 on-230", []color.Attribute{38, 5, 136, 48, 5, 230}, false},
+		{"none", nil, false},
+		{"chartreuse", nil, true},
+		{"256", nil, true},
+	}
+
+	for _, tt := range tests {
+		attrs, err := parseColorSpec(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseColorSpec(%q) error = %v, expected error %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if len(attrs) != len(tt.expected) {
+			t.Errorf("parseColorSpec(%q) = %v, expected %v", tt.spec, attrs, tt.expected)
+			continue
+		}
+		for i := range attrs {
+			if attrs[i] != tt.expected[i] {
+				t.Errorf("parseColorSpec(%q) = %v, expected %v", tt.spec, attrs, tt.expected)
+				break
+			}
+		}
+	}
+}
+
+func TestBuiltinThemesCoverAllRoles(t *testing.T) {
+	for _, name := range themeNames() {
+		if _, err := themeColors(name, nil); err != nil {
+			t.Errorf("themeColors(%q) error = %v", name, err)
+		}
+		for _, role := range colorRoles {
+			if _, ok := themes[name][role]; !ok {
+				t.Errorf("theme %q has no color for %q", name, role)
+			}
+		}
+	}
+}
+
+func TestThemeColorsErrors(t *testing.T) {
+	tests := []struct {
+		theme     string
+		overrides map[string]string
+		wantErr   bool
+	}{
+		{"default", map[string]string{RoleBarHigh: "magenta"}, false},
+		{"neon", nil, true},
+		{"default", map[string]string{"sparkles": "red"}, true},
+		{"default", map[string]string{RoleWarning: "orange"}, true},
+	}
+
+	for _, tt := range tests {
+		_, err := themeColors(tt.theme, tt.overrides)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("themeColors(%q, %v) error = %v, expected error %v", tt.theme, tt.overrides, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidColorOverrides(t *testing.T) {
+	if err := validColorOverrides("bar-high=magenta,warning=bold 208"); err != nil {
+		t.Errorf("validColorOverrides() error = %v, expected nil", err)
+	}
+	if err := validColorOverrides("bar=red"); err == nil {
+		t.Errorf("validColorOverrides(%q) error = nil, expected an error", "bar=red")
+	}
+}
+
+func TestPaint(t *testing.T) {
+	oldColors, oldNoColor := activeColors, color.NoColor
+	defer func() { activeColors, color.NoColor = oldColors, oldNoColor }()
+	color.NoColor = false
+
+	if err := applyTheme("default", map[string]string{RoleBarHigh: "magenta"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := paint(RoleBarHigh, "|"); got != "\x1b[35m|\x1b[0m" {
+		t.Errorf("paint(bar-high) with override = %q, expected magenta", got)
+	}
+
+	if err := applyTheme("monochrome", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := okString("%d tokens", 5); got != "5 tokens" {
+		t.Errorf("okString() with monochrome = %q, expected plain text", got)
+	}
+
+	color.NoColor = true
+	if got := dangerString("exceeded"); strings.Contains(got, "\x1b") {
+		t.Errorf("dangerString() with NO_COLOR = %q, expected no escape sequences", got)
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Time tracking services
+const (
+	TrackerToggl    = "toggl"
+	TrackerClockify = "clockify"
+)
+
+// Default API endpoints of the time tracking services
+const (
+	togglAPIURL    = "https://api.track.toggl.com/api/v9"
+	clockifyAPIURL = "https://api.clockify.me/api/v1"
+)
+
+// TimeTrackerConfig holds time tracker integration configuration
+type TimeTrackerConfig struct {
+	Service   string // toggl or clockify ("" = disabled)
+	Token     string // API token, CCTOP_TIME_TRACKER_TOKEN when empty
+	Workspace string // Workspace ID entries are created in
+}
+
+// TimeTracker starts and stops running time entries
+type TimeTracker interface {
+	Start(description string, tags []string, at time.Time) error
+	Stop(at time.Time) error
+}
+
+// NewTimeTracker creates a client for the configured service
+func NewTimeTracker(cfg TimeTrackerConfig) (TimeTracker, error) {
+	token := cfg.Token
+	if token == "" {
+		token = os.Getenv("CCTOP_TIME_TRACKER_TOKEN")
+	}
+	if token == "" || cfg.Workspace == "" {
+		return nil, fmt.Errorf("time tracker %s needs an API token and a workspace", cfg.Service)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	switch cfg.Service {
+	case TrackerToggl:
+		return &TogglTracker{baseURL: togglAPIURL, token: token, workspace: cfg.Workspace, client: client}, nil
+	case TrackerClockify:
+		return &ClockifyTracker{baseURL: clockifyAPIURL, token: token, workspace: cfg.Workspace, client: client}, nil
+	default:
+		return nil, fmt.Errorf("unknown time tracker %q (toggl, clockify)", cfg.Service)
+	}
+}
+
+// TimeTrackerAction starts a time entry for the session's top project, or stops the running one
+type TimeTrackerAction struct {
+	Tracker TimeTracker
+	Stop    bool
+}
+
+// Name describes the action
+func (a TimeTrackerAction) Name() string {
+	if a.Stop {
+		return "stop time entry"
+	}
+	return "start time entry"
+}
+
+// Run starts or stops the time entry
+func (a TimeTrackerAction) Run(session *Session) error {
+	if a.Stop {
+		return a.Tracker.Stop(time.Now())
+	}
+
+	tags := []string{"claude"}
+	description := "Claude session"
+	if project := sessionProject(session); project != "" {
+		description = "Claude: " + project
+		tags = append(tags, project)
+	}
+	return a.Tracker.Start(description, tags, time.Now())
+}
+
+// sessionProject returns the project with the most tokens in the session
+func sessionProject(session *Session) string {
+	projects := session.Projects
+	if len(projects) == 0 && session.Block != nil && session.Block.StartTime != "" {
+		projects = loadProjectUsage(session.Block, time.Now())
+	}
+	if len(projects) == 0 {
+		return ""
+	}
+	return projects[0].Name
+}
+
+// sessionActiveCondition is a rule condition that holds while the session has recent activity
+func sessionActiveCondition(session *Session, currentTime time.Time) bool {
+	return isSessionActive(session.Block, currentTime)
+}
+
+// TogglTracker manages Toggl Track time entries
+type TogglTracker struct {
+	baseURL   string
+	token     string
+	workspace string
+	client    *http.Client
+	entryID   int64 // Running entry started by cctop
+}
+
+// Start creates a running Toggl time entry
+func (t *TogglTracker) Start(description string, tags []string, at time.Time) error {
+	workspaceID, err := parseWorkspaceID(t.workspace)
+	if err != nil {
+		return err
+	}
+	body := map[string]interface{}{
+		"created_with": "cctop",
+		"description":  description,
+		"tags":         tags,
+		"start":        at.UTC().Format(time.RFC3339),
+		"duration":     -1, // Running
+		"workspace_id": workspaceID,
+	}
+	var entry struct {
+		ID int64 `json:"id"`
+	}
+	url := fmt.Sprintf("%s/workspaces/%s/time_entries", t.baseURL, t.workspace)
+	if err := t.do(http.MethodPost, url, body, &entry); err != nil {
+		return err
+	}
+	t.entryID = entry.ID
+	return nil
+}
+
+// Stop stops the running entry started by Start
+func (t *TogglTracker) Stop(at time.Time) error {
+	if t.entryID == 0 {
+		return nil
+	}
+	url := fmt.Sprintf("%s/workspaces/%s/time_entries/%d/stop", t.baseURL, t.workspace, t.entryID)
+	if err := t.do(http.MethodPatch, url, nil, nil); err != nil {
+		return err
+	}
+	t.entryID = 0
+	return nil
+}
+
+// do sends an authenticated Toggl request
+func (t *TogglTracker) do(method, url string, body, result interface{}) error {
+	req, err := newJSONRequest(method, url, body)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(t.token, "api_token")
+	return doJSONRequest(t.client, req, result)
+}
+
+// ClockifyTracker manages Clockify time entries
+type ClockifyTracker struct {
+	baseURL   string
+	token     string
+	workspace string
+	client    *http.Client
+	userID    string // Looked up on first stop
+	running   bool
+}
+
+// Start creates a running Clockify time entry.
+// Clockify tags are IDs, so the project name is added to the description instead.
+func (c *ClockifyTracker) Start(description string, tags []string, at time.Time) error {
+	body := map[string]interface{}{
+		"start":       at.UTC().Format(time.RFC3339),
+		"description": description,
+	}
+	url := fmt.Sprintf("%s/workspaces/%s/time-entries", c.baseURL, c.workspace)
+	if err := c.do(http.MethodPost, url, body, nil); err != nil {
+		return err
+	}
+	c.running = true
+	return nil
+}
+
+// Stop ends the user's running Clockify entry
+func (c *ClockifyTracker) Stop(at time.Time) error {
+	if !c.running {
+		return nil
+	}
+	if c.userID == "" {
+		var user struct {
+			ID string `json:"id"`
+		}
+		if err := c.do(http.MethodGet, c.baseURL+"/user", nil, &user); err != nil {
+			return err
+		}
+		c.userID = user.ID
+	}
+	url := fmt.Sprintf("%s/workspaces/%s/user/%s/time-entries", c.baseURL, c.workspace, c.userID)
+	if err := c.do(http.MethodPatch, url, map[string]string{"end": at.UTC().Format(time.RFC3339)}, nil); err != nil {
+		return err
+	}
+	c.running = false
+	return nil
+}
+
+// do sends an authenticated Clockify request
+func (c *ClockifyTracker) do(method, url string, body, result interface{}) error {
+	req, err := newJSONRequest(method, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Api-Key", c.token)
+	return doJSONRequest(c.client, req, result)
+}
+
+// parseWorkspaceID parses a numeric Toggl workspace ID
+func parseWorkspaceID(workspace string) (int64, error) {
+	var id int64
+	if _, err := fmt.Sscan(workspace, &id); err != nil {
+		return 0, fmt.Errorf("invalid Toggl workspace ID %q", workspace)
+	}
+	return id, nil
+}
+
+// newJSONRequest builds a request with an optional JSON body
+func newJSONRequest(method, url string, body interface{}) (*http.Request, error) {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// doJSONRequest sends a request and decodes a JSON response into result when non-nil
+func doJSONRequest(client *http.Client, req *http.Request, result interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s: %s", req.Method, req.URL.Path, resp.Status)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
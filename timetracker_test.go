@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTogglTracker(t *testing.T) {
+	var requests []string
+	var started map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if user, pass, _ := r.BasicAuth(); user != "secret" || pass != "api_token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.Method == http.MethodPost {
+			_ = json.NewDecoder(r.Body).Decode(&started)
+			_, _ = w.Write([]byte(`{"id": 42}`))
+		}
+	}))
+	defer server.Close()
+
+	tracker := &TogglTracker{baseURL: server.URL, token: "secret", workspace: "7", client: server.Client()}
+	at := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	if err := tracker.Start("Claude: cctop", []string{"claude", "cctop"}, at); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.Stop(at.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.Stop(at.Add(2 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"POST /workspaces/7/time_entries", "PATCH /workspaces/7/time_entries/42/stop"}
+	if len(requests) != len(expected) {
+		t.Fatalf("requests = %v, expected %v", requests, expected)
+	}
+	for i := range expected {
+		if requests[i] != expected[i] {
+			t.Errorf("requests[%d] = %s, expected %s", i, requests[i], expected[i])
+		}
+	}
+	if started["description"] != "Claude: cctop" || started["duration"] != float64(-1) || started["workspace_id"] != float64(7) {
+		t.Errorf("started entry = %v, expected a running entry in workspace 7", started)
+	}
+}
+
+func TestClockifyTracker(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Header.Get("X-Api-Key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/user" {
+			_, _ = w.Write([]byte(`{"id": "u1"}`))
+		}
+	}))
+	defer server.Close()
+
+	tracker := &ClockifyTracker{baseURL: server.URL, token: "secret", workspace: "w1", client: server.Client()}
+	at := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	if err := tracker.Start("Claude session", nil, at); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.Stop(at.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"POST /workspaces/w1/time-entries", "GET /user", "PATCH /workspaces/w1/user/u1/time-entries"}
+	if len(requests) != len(expected) {
+		t.Fatalf("requests = %v, expected %v", requests, expected)
+	}
+	for i := range expected {
+		if requests[i] != expected[i] {
+			t.Errorf("requests[%d] = %s, expected %s", i, requests[i], expected[i])
+		}
+	}
+}
+
+func TestNewTimeTracker(t *testing.T) {
+	t.Setenv("CCTOP_TIME_TRACKER_TOKEN", "")
+
+	tests := []struct {
+		name      string
+		cfg       TimeTrackerConfig
+		expectErr bool
+	}{
+		{name: "Toggl", cfg: TimeTrackerConfig{Service: TrackerToggl, Token: "t", Workspace: "1"}},
+		{name: "Clockify", cfg: TimeTrackerConfig{Service: TrackerClockify, Token: "t", Workspace: "w"}},
+		{name: "Missing token", cfg: TimeTrackerConfig{Service: TrackerToggl, Workspace: "1"}, expectErr: true},
+		{name: "Unknown service", cfg: TimeTrackerConfig{Service: "harvest", Token: "t", Workspace: "1"}, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTimeTracker(tt.cfg); (err != nil) != tt.expectErr {
+				t.Errorf("NewTimeTracker() error = %v, expected error %v", err, tt.expectErr)
+			}
+		})
+	}
+}
+
+// recordingTracker records starts and stops
+type recordingTracker struct {
+	calls []string
+}
+
+func (r *recordingTracker) Start(description string, tags []string, at time.Time) error {
+	r.calls = append(r.calls, "start")
+	return nil
+}
+
+func (r *recordingTracker) Stop(at time.Time) error {
+	r.calls = append(r.calls, "stop")
+	return nil
+}
+
+func TestShutdownStopsTimeEntry(t *testing.T) {
+	oldConfig := config
+	defer func() { config = oldConfig }()
+	config = NewConfig()
+	tracker := &recordingTracker{}
+	config.Tracker = tracker
+
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	session := newTestSession(start, 1000, 10000)
+	session.Projects = []ProjectUsage{{Name: "cctop"}}
+	engine := NewRulesEngine(buildRules(config)...)
+	session.Block.IsActive = true
+	session.Block.ActualEndTime = start.Add(time.Hour).Format(time.RFC3339)
+	if errs := engine.Evaluate(session, start.Add(time.Hour+time.Minute)); len(errs) != 0 {
+		t.Fatalf("Evaluate() errors = %v", errs)
+	}
+
+	shutdown()
+	if len(tracker.calls) != 2 || tracker.calls[0] != "start" || tracker.calls[1] != "stop" {
+		t.Errorf("tracker calls = %v, expected the entry to be started and stopped on exit", tracker.calls)
+	}
+}
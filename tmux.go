@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// DefaultTmuxFormat renders e.g. "CC 62% 1h23m $4.12" with the percentage colored by usage
+const DefaultTmuxFormat = "#[fg={{.Color}}]CC {{if .NoLimit}}{{.Used}}{{else}}{{.Percent}}%{{end}}#[default] {{.Remaining}} {{.Cost}}"
+
+var tmuxFormat string
+
+// TmuxData is the data available to the tmux format template.
+// String fields are escaped so they cannot inject tmux formats.
+type TmuxData struct {
+	Percent   int    // Token usage percentage, rounded down
+	Used      string // Tokens used, e.g. 12,345
+	Limit     string // Estimated token limit
+	Remaining string // Time until the session resets, e.g. 1h23m
+	Reset     string // Reset time, e.g. 15:00
+	Cost      string // Today's cost in the display currency
+	BurnRate  string // Tokens per minute
+	Status    string // OK, WARNING or EXCEEDED
+	Model     string
+	Plan      string
+	Color     string // green, yellow or red, for #[fg=...]
+	NoLimit   bool   // No limit is known, so Percent and Limit are guesses
+}
+
+// runTmux prints a single status line for tmux's status-right
+func runTmux(cmd *cobra.Command, args []string) {
+	report, err := currentStatusReport(time.Now())
+	if err != nil {
+		// Keep the status bar quiet rather than showing an error
+		fmt.Println("CC --")
+		return
+	}
+
+	line, err := renderTmux(tmuxFormat, newTmuxData(report, display.timezone))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println(line)
+}
+
+// newTmuxData extracts the template fields from a status report
+func newTmuxData(report *StatusReport, loc *time.Location) TmuxData {
+	data := TmuxData{
+		Percent:   int(report.Tokens.Percentage),
+		Used:      tmuxEscape(formatNumber(report.Tokens.Used)),
+		Limit:     tmuxEscape(formatNumber(report.Tokens.Limit)),
+		Remaining: tmuxEscape(strings.ReplaceAll(formatTime(report.Time.MinutesRemaining), " ", "")),
+		Reset:     report.EndTime.In(loc).Format(TimeFormatShort),
+		Cost:      tmuxEscape(formatCost(report.Cost.TodayUSD)),
+		BurnRate:  fmt.Sprintf("%.0f", report.BurnRate),
+		Status:    tmuxEscape(report.Status),
+		Model:     tmuxEscape(report.PrimaryModel),
+		Plan:      tmuxEscape(report.Plan),
+		Color:     config.AlertLevels(report.Plan, report.Tokens.Limit).Color(report.Tokens.Percentage),
+		NoLimit:   report.NoLimit,
+	}
+	if data.NoLimit {
+		data.Color = "green"
+	}
+	return data
+}
+
+// renderTmux executes the format template and flattens it to one line
+func renderTmux(format string, data TmuxData) (string, error) {
+	tmpl, err := template.New("tmux").Parse(format)
+	if err != nil {
+		return "", fmt.Errorf("invalid tmux format: %w", err)
+	}
+	var buffer strings.Builder
+	if err := tmpl.Execute(&buffer, data); err != nil {
+		return "", fmt.Errorf("invalid tmux format: %w", err)
+	}
+	// tmux only shows the first line of #() output
+	return strings.TrimSpace(strings.ReplaceAll(buffer.String(), "\n", " ")), nil
+}
+
+// tmuxEscape doubles # so values are shown literally rather than parsed as tmux formats
+func tmuxEscape(s string) string {
+	return strings.ReplaceAll(s, "#", "##")
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRenderTmux(t *testing.T) {
+	oldConfig := config
+	defer func() { config = oldConfig }()
+	config = NewConfig()
+
+	report := &StatusReport{
+		Tokens:       TokenMetrics{Used: 86800, Limit: 140000, Percentage: 62},
+		Time:         TimeMetrics{MinutesRemaining: 83},
+		EndTime:      time.Date(2025, 6, 20, 15, 0, 0, 0, time.UTC),
+		Cost:         CostReport{TodayUSD: 4.12},
+		PrimaryModel: "Opus #1",
+		Status:       "OK",
+	}
+	data := newTmuxData(report, time.UTC)
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{DefaultTmuxFormat, "#[fg=yellow]CC 62%#[default] 1h23m $4.12"},
+		{"{{.Used}}/{{.Limit}} resets {{.Reset}}", "86,800/140,000 resets 15:00"},
+		{"{{.Model}}", "Opus ##1"},
+		{"{{if ge .Percent 60}}HIGH{{end}}\n{{.Status}}", "HIGH OK"},
+	}
+
+	for _, tt := range tests {
+		line, err := renderTmux(tt.format, data)
+		if err != nil {
+			t.Errorf("renderTmux(%q) error = %v", tt.format, err)
+			continue
+		}
+		if line != tt.expected {
+			t.Errorf("renderTmux(%q) = %q, expected %q", tt.format, line, tt.expected)
+		}
+	}
+
+	if _, err := renderTmux("{{.Missing}}", data); err == nil {
+		t.Error("renderTmux() with an unknown field succeeded, expected an error")
+	}
+}
+
+func TestRenderTmuxNoLimit(t *testing.T) {
+	oldConfig := config
+	defer func() { config = oldConfig }()
+	config = NewConfig()
+
+	report := &StatusReport{
+		Tokens:  TokenMetrics{Used: 12345, Limit: 7000, Percentage: 176},
+		Time:    TimeMetrics{MinutesRemaining: 30},
+		NoLimit: true,
+	}
+	line, err := renderTmux(DefaultTmuxFormat, newTmuxData(report, time.UTC))
+	if expected := "#[fg=green]CC 12,345#[default] 30m $0.00"; err != nil || line != expected {
+		t.Errorf("renderTmux() = %q, %v, expected %q", line, err, expected)
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ViewMode identifies which screen the TUI is showing
+type ViewMode int
+
+// Available views
+const (
+	ViewSession ViewMode = iota
+	ViewHistory
+	ViewDaily
+	ViewEvents
+)
+
+// viewCount is the number of views cycled through with tab
+const viewCount = 4
+
+// planCycle is the order plans are cycled through with the plan key
+var planCycle = []string{"auto", "pro", "max5", "max20"}
+
+// reloadRequests wakes the refresh loop early after the plan or profile was switched
+var reloadRequests = make(chan struct{}, 1)
+
+// tickMsg triggers a periodic refresh
+type tickMsg time.Time
+
+// frameMsg triggers a redraw of time-based values between refreshes
+type frameMsg time.Time
+
+// usageMsg carries the result of a background refresh
+type usageMsg struct {
+	plan       string
+	profile    string
+	session    *Session
+	tokenLimit int
+	err        error
+}
+
+// Model is the bubbletea model driving the interactive monitor
+type Model struct {
+	view       ViewMode
+	paused     bool
+	reload     bool // Refresh on the next tick even when paused, the plan or profile changed
+	plan       string
+	profile    string
+	tokenLimit int
+	session    *Session
+	err        error
+	whatsNew   []ReleaseNotes // Release notes shown until a key is pressed
+}
+
+// NewModel creates a new TUI model
+func NewModel(plan string, tokenLimit int) Model {
+	return Model{
+		view:       ViewSession,
+		plan:       plan,
+		profile:    config.Profile,
+		tokenLimit: tokenLimit,
+	}
+}
+
+// Init starts the first refresh and the frame clock.
+// Data refreshes and redraws are scheduled independently: usage is fetched every
+// refresh interval, while the clock and countdowns are redrawn every frame.
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(refreshCmd(m.plan, m.profile, m.tokenLimit), frameCmd())
+}
+
+// Update handles key presses, ticks and refresh results
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	case tea.WindowSizeMsg:
+		// Sent at startup and on every resize (SIGWINCH)
+		display.SetWidth(msg.Width)
+		castRecorder.Resize(msg.Width, msg.Height, time.Now())
+		return m, nil
+	case frameMsg:
+		if m.session != nil && !m.paused {
+			m.session.Advance(time.Time(msg))
+		}
+		return m, frameCmd()
+	case tickMsg:
+		if m.paused && !m.reload {
+			return m, tickCmd(refreshInterval(m.session, time.Now()))
+		}
+		m.reload = false
+		return m, refreshCmd(m.plan, m.profile, m.tokenLimit)
+	case usageMsg:
+		if msg.plan != m.plan || msg.profile != m.profile {
+			// Loaded before the plan or profile was switched; the reload comes next
+			return m, tickCmd(refreshInterval(m.session, time.Now()))
+		}
+		m.err = msg.err
+		if msg.err == nil {
+			m.session = msg.session
+			m.tokenLimit = msg.tokenLimit
+		}
+		var idle *NoActiveSessionError
+		if errors.As(msg.err, &idle) {
+			// The session ended; show the idle screen until a new block starts
+			m.session = nil
+		}
+		return m, tickCmd(refreshInterval(m.session, time.Now()))
+	}
+	return m, nil
+}
+
+// handleKey processes keyboard input
+func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	action := keyMap.Action(msg.String())
+	if msg.String() == "ctrl+c" {
+		action = ActionQuit
+	}
+	if len(m.whatsNew) > 0 && action != ActionQuit {
+		// The first key only dismisses the what's new panel
+		m.whatsNew = nil
+		return m, nil
+	}
+	switch action {
+	case ActionQuit:
+		return m, tea.Quit
+	case ActionSessionView:
+		m.view = ViewSession
+	case ActionHistoryView:
+		m.view = ViewHistory
+	case ActionDailyView:
+		m.view = ViewDaily
+	case ActionEventsView:
+		m.view = ViewEvents
+	case ActionNextView:
+		m.view = (m.view + 1) % viewCount
+	case ActionPrevView:
+		m.view = (m.view + viewCount - 1) % viewCount
+	case ActionPause:
+		m.paused = !m.paused
+	case ActionProfile:
+		if len(config.Profiles) == 0 {
+			return m, nil
+		}
+		m.profile = nextProfile(m.profile)
+		return m.requestReload(), nil
+	case ActionPlan:
+		m.plan = nextPlan(m.plan)
+		return m.requestReload(), nil
+	}
+	return m, nil
+}
+
+// requestReload re-estimates the limit on a refresh that the running refresh loop
+// starts at once, rather than starting a second loop
+func (m Model) requestReload() Model {
+	m.tokenLimit = 0
+	m.reload = true
+	select {
+	case reloadRequests <- struct{}{}:
+	default:
+		// A reload is already pending
+	}
+	return m
+}
+
+// View renders the current screen
+func (m Model) View() string {
+	var body string
+	var idle *NoActiveSessionError
+	err := m.err
+	switch {
+	case errors.As(m.err, &idle):
+		body = display.RenderIdle(idle.Idle, time.Now())
+		err = nil
+	case m.session == nil && m.err != nil:
+		body = display.RenderError(m.err)
+	case m.session == nil:
+		body = "Loading...\n"
+	case m.view == ViewHistory:
+		body = display.RenderHistory(buildHistoryRows(m.session.AllBlocks, m.tokenLimit, HistoryViewRows), m.tokenLimit)
+	case m.view == ViewDaily:
+		body = display.RenderDaily(m.session.Daily)
+	case m.view == ViewEvents:
+		body = display.RenderEvents(eventLog.Events(), EventsViewRows)
+	default:
+		body = display.Render(m.session, estimator, m.plan)
+	}
+	if len(m.whatsNew) > 0 {
+		body = display.RenderWhatsNew(m.whatsNew) + mutedString("Press any key to dismiss, 'cctop whatsnew' shows this again") + "\n\n" + body
+	}
+	view := display.fitWidth(display.RenderSafeModeBanner(configFile) + body + display.RenderFooter(m.view, m.paused, err))
+	castRecorder.Screen(view, time.Now())
+	return view
+}
+
+// nextPlan returns the plan following the given one in planCycle. The API plan is kept,
+// since it has no token limits to cycle through.
+func nextPlan(plan string) string {
+	if plan == PlanAPI {
+		return plan
+	}
+	for i, p := range planCycle {
+		if p == plan {
+			return planCycle[(i+1)%len(planCycle)]
+		}
+	}
+	return planCycle[0]
+}
+
+// nextProfile returns the profile following the given one, cycling through "all" first
+func nextProfile(profile string) string {
+	names := profileNames()
+	for i, name := range names {
+		if name == profile {
+			return names[(i+1)%len(names)]
+		}
+	}
+	return ""
+}
+
+// tickCmd schedules the next refresh, which comes early when a watched log file changes
+func tickCmd(interval time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		return tickMsg(waitForRefresh(interval, projectWatcher.Changes(), reloadRequests))
+	}
+}
+
+// frameCmd schedules the next redraw on a FrameInterval boundary of the wall clock,
+// so the seconds of the clock change together with the countdowns
+func frameCmd() tea.Cmd {
+	return tea.Every(config.FrameInterval, func(t time.Time) tea.Msg {
+		return frameMsg(t)
+	})
+}
+
+// refreshCmd fetches usage data in the background.
+// A zero tokenLimit re-estimates the limit for the plan.
+func refreshCmd(plan, profile string, tokenLimit int) tea.Cmd {
+	return func() tea.Msg {
+		if tokenLimit == 0 {
+			tokenLimit = getInitialTokenLimit(plan)
+		}
+		session, err := loadSession(plan, profile, &tokenLimit)
+		return usageMsg{plan: plan, profile: profile, session: session, tokenLimit: tokenLimit, err: err}
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestNextPlan(t *testing.T) {
+	tests := []struct {
+		plan     string
+		expected string
+	}{
+		{plan: "auto", expected: "pro"},
+		{plan: "pro", expected: "max5"},
+		{plan: "max20", expected: "auto"},
+		{plan: "unknown", expected: "auto"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.plan, func(t *testing.T) {
+			if result := nextPlan(tt.plan); result != tt.expected {
+				t.Errorf("nextPlan(%s) = %s, expected %s", tt.plan, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestModelKeyHandling(t *testing.T) {
+	m := NewModel("auto", 7000)
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2")})
+	m = updated.(Model)
+	if m.view != ViewHistory {
+		t.Errorf("view = %d, expected ViewHistory", m.view)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(Model)
+	if m.view != ViewDaily {
+		t.Errorf("view = %d, expected ViewDaily", m.view)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	m = updated.(Model)
+	if !m.paused {
+		t.Error("expected model to be paused after space")
+	}
+
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}); cmd == nil {
+		t.Error("expected quit command for q")
+	}
+}
+
+func TestModelPlanSwitchReloads(t *testing.T) {
+	oldConfig := config
+	defer func() { config = oldConfig }()
+	config = NewConfig()
+	defer func() {
+		select {
+		case <-reloadRequests:
+		default:
+		}
+	}()
+
+	m := NewModel("auto", 7000)
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	m = updated.(Model)
+	if cmd != nil {
+		t.Error("plan key returned a command, expected the running refresh loop to reload")
+	}
+	if m.plan != "pro" || m.tokenLimit != 0 || !m.reload {
+		t.Errorf("after the plan key plan = %q, limit = %d, reload = %v, expected pro, 0 and true", m.plan, m.tokenLimit, m.reload)
+	}
+	if config.Plan != "auto" {
+		t.Errorf("config.Plan = %q, expected the plan key not to change it", config.Plan)
+	}
+	if len(reloadRequests) != 1 {
+		t.Error("expected a reload request for the refresh loop")
+	}
+
+	// A refresh started before the switch is dropped
+	updated, _ = m.Update(usageMsg{plan: "auto", session: &Session{}, tokenLimit: 7000})
+	m = updated.(Model)
+	if m.session != nil || m.tokenLimit != 0 {
+		t.Errorf("stale refresh was applied: session = %v, limit = %d", m.session, m.tokenLimit)
+	}
+}
+
+func TestPollInterval(t *testing.T) {
+	now := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		session  *Session
+		expected time.Duration
+	}{
+		{
+			name:     "No session",
+			session:  nil,
+			expected: config.IdleInterval,
+		},
+		{
+			name:     "Recent activity",
+			session:  &Session{Block: &Block{IsActive: true, ActualEndTime: "2025-06-20T11:58:00Z"}},
+			expected: config.UpdateInterval,
+		},
+		{
+			name:     "Idle active block",
+			session:  &Session{Block: &Block{IsActive: true, ActualEndTime: "2025-06-20T11:30:00Z"}},
+			expected: config.IdleInterval,
+		},
+		{
+			name:     "Missing activity time",
+			session:  &Session{Block: &Block{IsActive: true}},
+			expected: config.UpdateInterval,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := pollInterval(tt.session, now); result != tt.expected {
+				t.Errorf("pollInterval() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestModelFrameAdvancesCountdown(t *testing.T) {
+	oldConfig := config
+	defer func() { config = oldConfig }()
+	config = NewConfig()
+
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	m := NewModel("auto", 7000)
+	m.session = newTestSession(start, 1000, 7000)
+	m.session.Advance(start.Add(time.Hour))
+
+	updated, cmd := m.Update(frameMsg(start.Add(time.Hour + 90*time.Second)))
+	m = updated.(Model)
+	if remaining := m.session.Metrics.Time.MinutesRemaining; remaining != 238.5 {
+		t.Errorf("MinutesRemaining = %.1f after a frame, expected 238.5", remaining)
+	}
+	if cmd == nil {
+		t.Error("frame returned no command, expected the next frame to be scheduled")
+	}
+
+	// A paused model keeps showing the frozen values
+	m.paused = true
+	updated, _ = m.Update(frameMsg(start.Add(2 * time.Hour)))
+	if remaining := updated.(Model).session.Metrics.Time.MinutesRemaining; remaining != 238.5 {
+		t.Errorf("MinutesRemaining = %.1f while paused, expected 238.5", remaining)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// usageSample is the session's token count at a point in time
+type usageSample struct {
+	Time   time.Time
+	Tokens int
+}
+
+// UsageHistory is a ring buffer of token samples for the current session
+type UsageHistory struct {
+	mu           sync.Mutex
+	samples      []usageSample
+	next         int // Index the next sample is written to
+	count        int
+	sessionStart time.Time
+}
+
+// NewUsageHistory creates a history holding up to size samples
+func NewUsageHistory(size int) *UsageHistory {
+	return &UsageHistory{samples: make([]usageSample, size)}
+}
+
+// Record adds the session's current token count, starting over for a new session
+func (h *UsageHistory) Record(session *Session, currentTime time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !session.StartTime.Equal(h.sessionStart) {
+		h.sessionStart = session.StartTime
+		h.next = 0
+		h.count = 0
+	}
+
+	h.samples[h.next] = usageSample{Time: currentTime, Tokens: session.Metrics.Tokens.Used}
+	h.next = (h.next + 1) % len(h.samples)
+	if h.count < len(h.samples) {
+		h.count++
+	}
+}
+
+// ordered returns the samples oldest first
+func (h *UsageHistory) ordered() []usageSample {
+	ordered := make([]usageSample, 0, h.count)
+	start := (h.next - h.count + len(h.samples)) % len(h.samples)
+	for i := 0; i < h.count; i++ {
+		ordered = append(ordered, h.samples[(start+i)%len(h.samples)])
+	}
+	return ordered
+}
+
+// Buckets returns tokens consumed in each of buckets equal intervals over the window ending now.
+// It returns nil until at least two samples fall within the window.
+func (h *UsageHistory) Buckets(currentTime time.Time, window time.Duration, buckets int) []int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	windowStart := currentTime.Add(-window)
+	bucketSize := window / time.Duration(buckets)
+	result := make([]int, buckets)
+
+	samples := h.ordered()
+	inWindow := 0
+	for i := 1; i < len(samples); i++ {
+		if samples[i].Time.Before(windowStart) || samples[i].Time.After(currentTime) {
+			continue
+		}
+		inWindow++
+		delta := samples[i].Tokens - samples[i-1].Tokens
+		if delta <= 0 {
+			continue
+		}
+		index := clampInt(int(samples[i].Time.Sub(windowStart)/bucketSize), 0, buckets-1)
+		result[index] += delta
+	}
+
+	if inWindow == 0 {
+		return nil
+	}
+	return result
+}
+
+// BurnRates returns the short-term burn rates of the recorded samples
+func (h *UsageHistory) BurnRates(calculator EWMABurnRate, currentTime time.Time) (BurnRates, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return calculator.Calculate(h.ordered(), currentTime)
+}
+
+// usageTrend compares the most recent third of the buckets with the oldest third
+func usageTrend(buckets []int) string {
+	third := len(buckets) / 3
+	if third == 0 {
+		return ""
+	}
+	early, late := 0, 0
+	for i := 0; i < third; i++ {
+		early += buckets[i]
+		late += buckets[len(buckets)-third+i]
+	}
+
+	switch {
+	case early == 0 && late == 0:
+		return "idle"
+	case float64(late) > float64(early)*UsageTrendRatio:
+		return "speeding up"
+	case float64(late)*UsageTrendRatio < float64(early):
+		return "slowing down"
+	default:
+		return "steady"
+	}
+}
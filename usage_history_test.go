@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUsageHistoryBuckets(t *testing.T) {
+	h := NewUsageHistory(4)
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	now := start.Add(2 * time.Hour)
+
+	record := func(minutesAgo, tokens int) {
+		h.Record(newTestSession(start, tokens, 10000), now.Add(-time.Duration(minutesAgo)*time.Minute))
+	}
+
+	record(90, 500) // Outside the window, only the base for the next delta
+	if buckets := h.Buckets(now, time.Hour, 3); buckets != nil {
+		t.Errorf("Buckets() with a single sample = %v, expected nil", buckets)
+	}
+
+	record(50, 1000)
+	record(30, 1200) // +200 in the second bucket
+	record(5, 2000)  // +800 in the third bucket
+	record(1, 2600)  // +600 in the third bucket; overwrites the 90 minute sample, so 50 minutes has no delta
+
+	expected := []int{0, 200, 1400}
+	buckets := h.Buckets(now, time.Hour, 3)
+	if len(buckets) != len(expected) {
+		t.Fatalf("Buckets() = %v, expected %v", buckets, expected)
+	}
+	for i := range expected {
+		if buckets[i] != expected[i] {
+			t.Errorf("Buckets()[%d] = %d, expected %d", i, buckets[i], expected[i])
+		}
+	}
+
+	// A new session starts over
+	h.Record(newTestSession(start.Add(5*time.Hour), 100, 10000), now)
+	if buckets := h.Buckets(now, time.Hour, 3); buckets != nil {
+		t.Errorf("Buckets() after new session = %v, expected nil", buckets)
+	}
+}
+
+func TestUsageTrend(t *testing.T) {
+	tests := []struct {
+		name     string
+		buckets  []int
+		expected string
+	}{
+		{name: "Speeding up", buckets: []int{100, 100, 200, 300, 400, 500}, expected: "speeding up"},
+		{name: "Slowing down", buckets: []int{500, 400, 300, 200, 100, 0}, expected: "slowing down"},
+		{name: "Steady", buckets: []int{100, 120, 100, 110, 100, 110}, expected: "steady"},
+		{name: "Idle", buckets: []int{0, 0, 50, 0, 0, 0}, expected: "idle"},
+		{name: "Too few buckets", buckets: []int{1, 2}, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := usageTrend(tt.buckets); result != tt.expected {
+				t.Errorf("usageTrend(%v) = %q, expected %q", tt.buckets, result, tt.expected)
+			}
+		})
+	}
+}
@@ -3,33 +3,20 @@ package main
 import (
 	"fmt"
 	"math"
-	"strings"
 	"time"
 )
 
-// formatNumber formats a number with comma separators
+// formatNumber formats a token count in the configured number format
 func formatNumber(n int) string {
-	s := fmt.Sprintf("%d", n)
-	if n < 1000 {
-		return s
-	}
-
-	// Build result from right to left
-	var result strings.Builder
-	for i := len(s) - 1; i >= 0; i-- {
-		if (len(s)-i-1)%3 == 0 && len(s)-i-1 > 0 {
-			result.WriteString(",")
-		}
-		result.WriteByte(s[i])
-	}
+	return numbers.Format(n)
+}
 
-	// Reverse the string
-	runes := []rune(result.String())
-	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
-		runes[i], runes[j] = runes[j], runes[i]
+// formatLimit formats a token limit, "none" when there is none (API plan)
+func formatLimit(limit int) string {
+	if limit <= 0 {
+		return "none"
 	}
-
-	return string(runes)
+	return formatNumber(limit)
 }
 
 // formatTime formats minutes into a human-readable time string
@@ -52,12 +39,6 @@ func formatTime(minutes float64) string {
 	return fmt.Sprintf("%dh %dm", hours, mins)
 }
 
-// Terminal control functions
-func hideCursor()   { fmt.Print(HideCursor) }
-func showCursor()   { fmt.Print(ShowCursor) }
-func clearScreen()  { fmt.Print(ClearScreen) }
-func clearAndHome() { fmt.Print(ClearAndHome) }
-
 // Time utility functions moved from burnrate.go
 func maxTime(a, b time.Time) time.Time {
 	if a.After(b) {
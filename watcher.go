@@ -0,0 +1,188 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// ProjectWatcher reports new lines in Claude's JSONL logs using filesystem
+// notifications (inotify, kqueue or ReadDirectoryChangesW), so refreshes happen
+// as soon as a message is logged instead of on the next poll
+type ProjectWatcher struct {
+	watcher     *fsnotify.Watcher
+	changes     chan struct{}
+	debounce    time.Duration // Writes within this time of each other trigger one refresh
+	minInterval time.Duration // Minimum time between refreshes triggered by writes
+}
+
+// projectWatcher is the running watcher, nil while polling
+var projectWatcher *ProjectWatcher
+
+// startProjectWatcher starts watching the projects directories when enabled,
+// logging the reason and falling back to polling when watching is unavailable
+func startProjectWatcher() {
+	if !config.Watch {
+		return
+	}
+	watcher, err := NewProjectWatcher(defaultProjectsDirs())
+	if err != nil {
+		logger.Warnf("file watching unavailable, polling every %s: %v", config.UpdateInterval, err)
+		return
+	}
+	projectWatcher = watcher
+	logger.Debugf("watching %v for new messages", defaultProjectsDirs())
+}
+
+// NewProjectWatcher watches the projects directories and each project in them.
+// It fails when the platform has no notification support or no directory can be watched.
+func NewProjectWatcher(dirs []string) (*ProjectWatcher, error) {
+	return newProjectWatcher(dirs, WatchDebounce, WatchMinInterval)
+}
+
+// newProjectWatcher creates a watcher that coalesces writes over debounce and
+// signals at most once per minInterval
+func newProjectWatcher(dirs []string, debounce, minInterval time.Duration) (*ProjectWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &ProjectWatcher{watcher: watcher, changes: make(chan struct{}, 1), debounce: debounce, minInterval: minInterval}
+	var lastErr error
+	watched := 0
+	for _, dir := range dirs {
+		if err := w.addTree(dir); err != nil {
+			lastErr = err
+			continue
+		}
+		watched++
+	}
+	if watched == 0 {
+		watcher.Close()
+		if lastErr == nil {
+			lastErr = os.ErrNotExist
+		}
+		return nil, lastErr
+	}
+
+	go w.run()
+	return w, nil
+}
+
+// addTree watches a projects directory and its project subdirectories.
+// Watches are not recursive, so new projects are added as they appear.
+func (w *ProjectWatcher) addTree(dir string) error {
+	if err := w.watcher.Add(dir); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			if err := w.watcher.Add(filepath.Join(dir, entry.Name())); err != nil {
+				logger.Warnf("watching %s: %v", entry.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// run forwards log file events until the watcher is closed. Claude writes a
+// response in several chunks, so writes are coalesced over the debounce time
+// and signalled at most once per minimum interval.
+func (w *ProjectWatcher) run() {
+	var pending <-chan time.Time
+	var last time.Time
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if w.handle(event) && pending == nil {
+				pending = time.After(w.delay(last, time.Now()))
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			logger.Warnf("file watching: %v", err)
+		case last = <-pending:
+			pending = nil
+			w.signal()
+		}
+	}
+}
+
+// delay returns how long to wait before signalling a change first seen at
+// currentTime, given the time of the last signal
+func (w *ProjectWatcher) delay(last, currentTime time.Time) time.Duration {
+	return maxDuration(w.debounce, last.Add(w.minInterval).Sub(currentTime))
+}
+
+// handle watches newly created projects and reports whether the event is a write to a JSONL file
+func (w *ProjectWatcher) handle(event fsnotify.Event) bool {
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			if err := w.watcher.Add(event.Name); err != nil {
+				logger.Warnf("watching %s: %v", event.Name, err)
+			}
+			return false
+		}
+	}
+	return filepath.Ext(event.Name) == ".jsonl" && (event.Has(fsnotify.Write) || event.Has(fsnotify.Create))
+}
+
+// signal requests a refresh. Changes made while a refresh is running collapse
+// into a single pending one.
+func (w *ProjectWatcher) signal() {
+	select {
+	case w.changes <- struct{}{}:
+	default:
+	}
+}
+
+// Changes returns a channel that receives a value after a log file changes.
+// A nil watcher returns a nil channel, which never receives.
+func (w *ProjectWatcher) Changes() <-chan struct{} {
+	if w == nil {
+		return nil
+	}
+	return w.changes
+}
+
+// Close stops watching
+func (w *ProjectWatcher) Close() error {
+	return w.watcher.Close()
+}
+
+// refreshInterval returns how long to wait before the next refresh. While the
+// logs are watched new messages trigger refreshes, so polling only keeps the
+// clock and countdowns current.
+func refreshInterval(session *Session, currentTime time.Time) time.Duration {
+	interval := pollInterval(session, currentTime)
+	if projectWatcher != nil {
+		return maxDuration(interval, WatchedRefreshInterval)
+	}
+	return interval
+}
+
+// waitForRefresh blocks until the interval has passed, a log file changes or a reload is requested
+func waitForRefresh(interval time.Duration, changes, reload <-chan struct{}) time.Time {
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	select {
+	case t := <-timer.C:
+		return t
+	case <-changes:
+		return time.Now()
+	case <-reload:
+		return time.Now()
+	}
+}
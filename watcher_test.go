@@ -0,0 +1,123 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestProjectWatcherChanges(t *testing.T) {
+	projects := t.TempDir()
+	if err := os.Mkdir(filepath.Join(projects, "app"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	watcher, err := newProjectWatcher([]string{projects}, 10*time.Millisecond, 50*time.Millisecond)
+	if err != nil {
+		t.Skipf("file watching unavailable: %v", err)
+	}
+	defer watcher.Close()
+
+	expectChange := func(what string) {
+		t.Helper()
+		select {
+		case <-watcher.Changes():
+		case <-time.After(2 * time.Second):
+			t.Errorf("no change reported after %s", what)
+		}
+	}
+	write := func(path string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(`{"type":"assistant"}`+"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(filepath.Join(projects, "app", "session.jsonl"))
+	expectChange("writing to an existing project")
+
+	// New projects are watched as they appear
+	newProject := filepath.Join(projects, "other")
+	if err := os.Mkdir(newProject, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	write(filepath.Join(newProject, "session.jsonl"))
+	expectChange("writing to a new project")
+
+	// Other files are ignored, once pending events from the last write are drained
+	time.Sleep(200 * time.Millisecond)
+	select {
+	case <-watcher.Changes():
+	default:
+	}
+	write(filepath.Join(projects, "app", "notes.txt"))
+	select {
+	case <-watcher.Changes():
+		t.Error("change reported for a non-JSONL file")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestProjectWatcherDelay(t *testing.T) {
+	w := &ProjectWatcher{debounce: WatchDebounce, minInterval: WatchMinInterval}
+	now := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		last     time.Time
+		expected time.Duration
+	}{
+		{"never signalled", time.Time{}, WatchDebounce},
+		{"signalled long ago", now.Add(-time.Minute), WatchDebounce},
+		{"signalled just now", now.Add(-time.Second), WatchMinInterval - time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := w.delay(tt.last, now); got != tt.expected {
+			t.Errorf("%s: delay() = %v, expected %v", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestNewProjectWatcherMissingDir(t *testing.T) {
+	if _, err := NewProjectWatcher([]string{filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("NewProjectWatcher() of a missing directory succeeded, expected an error")
+	}
+}
+
+func TestRefreshInterval(t *testing.T) {
+	oldConfig, oldWatcher := config, projectWatcher
+	defer func() { config, projectWatcher = oldConfig, oldWatcher }()
+	config = NewConfig()
+
+	now := time.Now()
+	session := newTestSession(now.Add(-time.Hour), 1000, 7000)
+	session.Block.IsActive = true
+	session.Block.ActualEndTime = now.Format(time.RFC3339)
+
+	projectWatcher = nil
+	if result := refreshInterval(session, now); result != UpdateInterval {
+		t.Errorf("refreshInterval() polling = %v, expected %v", result, UpdateInterval)
+	}
+	projectWatcher = &ProjectWatcher{}
+	if result := refreshInterval(session, now); result != WatchedRefreshInterval {
+		t.Errorf("refreshInterval() watching = %v, expected %v", result, WatchedRefreshInterval)
+	}
+}
+
+func TestWaitForRefresh(t *testing.T) {
+	changes := make(chan struct{}, 1)
+	changes <- struct{}{}
+
+	start := time.Now()
+	waitForRefresh(time.Minute, changes, nil)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("waitForRefresh() with a change took %v, expected to return at once", elapsed)
+	}
+
+	start = time.Now()
+	waitForRefresh(10*time.Millisecond, nil, nil)
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Errorf("waitForRefresh() without changes took %v, expected the full interval", elapsed)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Chat services a webhook URL can belong to
+const (
+	ChatSlack   = "slack"
+	ChatDiscord = "discord"
+)
+
+// ChatWebhookConfig holds Slack or Discord webhook configuration
+type ChatWebhookConfig struct {
+	URL        string    // Incoming webhook URL; the service is detected from the host
+	Thresholds []float64 // Token usage percentages that post a message
+}
+
+// ChatWebhookAction posts a message to a Slack or Discord incoming webhook.
+// A zero Threshold posts the session end summary.
+type ChatWebhookAction struct {
+	URL       string
+	Threshold float64
+	client    *http.Client
+}
+
+// NewChatWebhookAction creates an action posting to the webhook
+func NewChatWebhookAction(webhookURL string, threshold float64) ChatWebhookAction {
+	return ChatWebhookAction{
+		URL:       webhookURL,
+		Threshold: threshold,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name describes the action
+func (a ChatWebhookAction) Name() string {
+	if a.Threshold > 0 {
+		return fmt.Sprintf("%s webhook at %.0f%%", chatService(a.URL), a.Threshold)
+	}
+	return fmt.Sprintf("%s webhook on session end", chatService(a.URL))
+}
+
+// Run posts the message for the session
+func (a ChatWebhookAction) Run(session *Session) error {
+	message := sessionEndMessage(session)
+	if a.Threshold > 0 {
+		message = fmt.Sprintf("%s, resets %s", thresholdMessage(a.Threshold, session), session.EndTime.In(display.timezone).Format(TimeFormatShort))
+	}
+
+	req, err := newJSONRequest(http.MethodPost, a.URL, chatPayload(chatService(a.URL), "cctop: "+message))
+	if err != nil {
+		return err
+	}
+	return doJSONRequest(a.client, req, nil)
+}
+
+// chatService detects the service from the webhook host, defaulting to Slack
+// (whose payload is also accepted by Mattermost and Rocket.Chat)
+func chatService(webhookURL string) string {
+	parsed, err := url.Parse(webhookURL)
+	if err == nil && (strings.HasSuffix(parsed.Hostname(), "discord.com") || strings.HasSuffix(parsed.Hostname(), "discordapp.com")) {
+		return ChatDiscord
+	}
+	return ChatSlack
+}
+
+// chatPayload builds the JSON body for the service
+func chatPayload(service, message string) map[string]string {
+	if service == ChatDiscord {
+		return map[string]string{"content": message}
+	}
+	return map[string]string{"text": message}
+}
+
+// sessionEndMessage summarizes an ended session
+func sessionEndMessage(session *Session) string {
+	cost := 0.0
+	if session.Block != nil {
+		cost = session.Block.CostUSD
+	}
+	message := fmt.Sprintf("session %s-%s ended, %s tokens (%s)",
+		session.StartTime.In(display.timezone).Format(TimeFormatShort),
+		session.EndTime.In(display.timezone).Format(TimeFormatShort),
+		formatNumber(session.Metrics.Tokens.Used),
+		formatCost(cost))
+	if session.PrimaryModel != "" {
+		message += ", mostly " + session.PrimaryModel
+	}
+	if activity := session.Activity(session.EndTime); activity != nil {
+		message += fmt.Sprintf(", active %s of %s (%.0f%%)",
+			formatTime(activity.ActiveMinutes), formatTime(activity.ElapsedMinutes), activity.Percentage)
+	}
+	return message
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChatService(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"https://hooks.slack.com/services/T000/B000/XXXX", ChatSlack},
+		{"https://discord.com/api/webhooks/123/abc", ChatDiscord},
+		{"https://discordapp.com/api/webhooks/123/abc", ChatDiscord},
+		{"https://ptb.discord.com/api/webhooks/123/abc", ChatDiscord},
+		{"https://mattermost.example.com/hooks/xyz", ChatSlack},
+	}
+
+	for _, tt := range tests {
+		if got := chatService(tt.url); got != tt.expected {
+			t.Errorf("chatService(%q) = %s, expected %s", tt.url, got, tt.expected)
+		}
+	}
+}
+
+func TestChatWebhookAction(t *testing.T) {
+	oldDisplay := display
+	defer func() { display = oldDisplay }()
+	display = NewDisplay("Asia/Tokyo")
+
+	var payload map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer server.Close()
+
+	start := time.Date(2025, 6, 20, 9, 0, 0, 0, time.UTC)
+	session := newTestSession(start, 42000, 140000)
+	session.Block.CostUSD = 12.5
+	session.PrimaryModel = "opus"
+
+	if err := NewChatWebhookAction(server.URL, 0).Run(session); err != nil {
+		t.Fatal(err)
+	}
+	expected := "cctop: session 18:00-23:00 ended, 42,000 tokens ($12.50), mostly opus"
+	if payload["text"] != expected {
+		t.Errorf("payload = %v, expected text %q", payload, expected)
+	}
+
+	if err := NewChatWebhookAction(server.URL, 80).Run(session); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(payload["text"], "cctop: ") || !strings.HasSuffix(payload["text"], "resets 23:00") {
+		t.Errorf("payload = %v, expected threshold message with reset time", payload)
+	}
+}
+
+func TestChatPayload(t *testing.T) {
+	if got := chatPayload(ChatDiscord, "hi"); got["content"] != "hi" || len(got) != 1 {
+		t.Errorf("chatPayload(discord) = %v, expected content field", got)
+	}
+	if got := chatPayload(ChatSlack, "hi"); got["text"] != "hi" || len(got) != 1 {
+		t.Errorf("chatPayload(slack) = %v, expected text field", got)
+	}
+}
+
+func TestBuildRulesChatWebhook(t *testing.T) {
+	cfg := NewConfig()
+	cfg.ChatWebhook.URL = "https://hooks.slack.com/services/T000/B000/XXXX"
+	cfg.ChatWebhook.Thresholds = []float64{80, 95}
+
+	rules := buildRules(cfg)
+	var found int
+	for _, rule := range rules {
+		background, ok := rule.Action.(backgroundAction)
+		if !ok {
+			continue
+		}
+		if _, ok := background.Action.(ChatWebhookAction); ok {
+			found++
+		}
+	}
+	if found != 2 {
+		t.Errorf("buildRules() created %d webhook rules, expected 2", found)
+	}
+}
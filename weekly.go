@@ -0,0 +1,57 @@
+package main
+
+import (
+	"time"
+)
+
+// WeeklyMetrics holds token usage over the rolling weekly limit window
+type WeeklyMetrics struct {
+	Tokens TokenMetrics `json:"tokens"`
+	Reset  time.Time    `json:"reset"` // When the oldest tokens in the window drop out (zero if none)
+}
+
+// estimateWeeklyLimit returns the weekly token cap for a plan.
+// Without --weekly-limit, it is the session limit times the plan's sessions per week.
+// Those session counts are rough guesses: the weekly limits are published as hours of
+// model use, not tokens, so the estimate only gives a sense of scale.
+func estimateWeeklyLimit(plan string, sessionLimit int) int {
+	if config.WeeklyLimit > 0 {
+		return config.WeeklyLimit
+	}
+	sessions, ok := config.WeeklySessions[plan]
+	if !ok {
+		sessions = config.WeeklySessions["pro"]
+	}
+	return sessionLimit * sessions
+}
+
+// calculateWeeklyMetrics sums the tokens of blocks started within the last WeeklyWindow
+func calculateWeeklyMetrics(blocks []Block, currentTime time.Time, limit int) WeeklyMetrics {
+	windowStart := currentTime.Add(-WeeklyWindow)
+
+	var metrics WeeklyMetrics
+	var oldest time.Time
+	for _, block := range blocks {
+		if block.IsGap {
+			continue
+		}
+		startTime, err := time.Parse(time.RFC3339, block.StartTime)
+		if err != nil || !startTime.After(windowStart) || startTime.After(currentTime) {
+			continue
+		}
+		metrics.Tokens.Used += block.TotalTokens
+		if oldest.IsZero() || startTime.Before(oldest) {
+			oldest = startTime
+		}
+	}
+
+	if !oldest.IsZero() {
+		metrics.Reset = oldest.Add(WeeklyWindow)
+	}
+	metrics.Tokens.Limit = limit
+	metrics.Tokens.Remaining = limit - metrics.Tokens.Used
+	if limit > 0 {
+		metrics.Tokens.Percentage = float64(metrics.Tokens.Used) / float64(limit) * 100
+	}
+	return metrics
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalculateWeeklyMetrics(t *testing.T) {
+	now := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+	blocks := []Block{
+		{StartTime: now.Add(-8 * 24 * time.Hour).Format(time.RFC3339), TotalTokens: 90000}, // Outside the window
+		{StartTime: now.Add(-6 * 24 * time.Hour).Format(time.RFC3339), TotalTokens: 30000},
+		{StartTime: now.Add(-3 * 24 * time.Hour).Format(time.RFC3339), TotalTokens: 5000, IsGap: true},
+		{StartTime: now.Add(-2 * time.Hour).Format(time.RFC3339), TotalTokens: 20000},
+	}
+
+	metrics := calculateWeeklyMetrics(blocks, now, 200000)
+	if metrics.Tokens.Used != 50000 || metrics.Tokens.Percentage != 25 || metrics.Tokens.Remaining != 150000 {
+		t.Errorf("tokens = %+v, expected 50000/200000 (25%%)", metrics.Tokens)
+	}
+	if expected := now.Add(24 * time.Hour); !metrics.Reset.Equal(expected) {
+		t.Errorf("reset = %v, expected %v", metrics.Reset, expected)
+	}
+
+	if empty := calculateWeeklyMetrics(nil, now, 200000); !empty.Reset.IsZero() || empty.Tokens.Used != 0 {
+		t.Errorf("calculateWeeklyMetrics(nil) = %+v, expected no usage", empty)
+	}
+}
+
+func TestEstimateWeeklyLimit(t *testing.T) {
+	oldConfig := config
+	defer func() { config = oldConfig }()
+	config = NewConfig()
+
+	tests := []struct {
+		plan     string
+		override int
+		expected int
+	}{
+		{"pro", 0, 7000 * config.WeeklySessions["pro"]},
+		{"max20", 0, 140000 * config.WeeklySessions["max20"]},
+		{"unknown", 0, 7000 * config.WeeklySessions["pro"]},
+		{"max5", 1000000, 1000000},
+	}
+
+	for _, tt := range tests {
+		config.WeeklyLimit = tt.override
+		sessionLimit := config.GetTokenLimit(tt.plan)
+		if got := estimateWeeklyLimit(tt.plan, sessionLimit); got != tt.expected {
+			t.Errorf("estimateWeeklyLimit(%q) = %d, expected %d", tt.plan, got, tt.expected)
+		}
+	}
+}
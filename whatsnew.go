@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// ReleaseNotes lists the user-visible changes of a release
+type ReleaseNotes struct {
+	Version string
+	Notes   []string
+}
+
+// releaseNotes are embedded in the binary, newest first, keyed by the release tag the
+// binary is built with (version). The what's new panel shows the entries added since
+// the user last saw it, so add one when tagging a release.
+var releaseNotes []ReleaseNotes
+
+var whatsNewAll bool
+
+// defaultWhatsNewPath returns the file recording the last release notes shown
+func defaultWhatsNewPath() string {
+	stateDir := cctopStateDir()
+	if stateDir == "" {
+		return ""
+	}
+	return filepath.Join(stateDir, "whatsnew")
+}
+
+// unseenReleaseNotes returns the notes newer than the seen version. Without a seen
+// version only the latest release is shown; an unknown one (e.g. after a downgrade)
+// shows nothing.
+func unseenReleaseNotes(notes []ReleaseNotes, seen string) []ReleaseNotes {
+	if len(notes) == 0 {
+		return nil
+	}
+	if seen == "" {
+		return notes[:1]
+	}
+	for i, release := range notes {
+		if release.Version == seen {
+			return notes[:i]
+		}
+	}
+	return nil
+}
+
+// releasedNotes returns the notes of the given build's release and older ones. Builds
+// without notes of their own, such as dev builds, have none. Tags match with or
+// without the "v" goreleaser strips.
+func releasedNotes(notes []ReleaseNotes, buildVersion string) []ReleaseNotes {
+	for i, release := range notes {
+		if strings.TrimPrefix(release.Version, "v") == strings.TrimPrefix(buildVersion, "v") {
+			return notes[i:]
+		}
+	}
+	return nil
+}
+
+// pendingWhatsNew returns the release notes to show once and marks them as seen,
+// so the panel does not come back after quitting
+func pendingWhatsNew(path string) []ReleaseNotes {
+	if path == "" {
+		return nil
+	}
+	seen := ""
+	if data, err := os.ReadFile(path); err == nil {
+		seen = strings.TrimSpace(string(data))
+	}
+	unseen := unseenReleaseNotes(releasedNotes(releaseNotes, version), seen)
+	if len(unseen) == 0 {
+		return nil
+	}
+	if err := writeFileAtomic(path, []byte(unseen[0].Version+"\n"), 0o644); err != nil {
+		logger.Warnf("what's new: %v", err)
+	}
+	return unseen
+}
+
+// RenderWhatsNew renders release notes as a panel
+func (d *Display) RenderWhatsNew(notes []ReleaseNotes) string {
+	var buffer strings.Builder
+	for _, release := range notes {
+		fmt.Fprintf(&buffer, "%s\n", infoString("What's new in %s", release.Version))
+		for _, note := range release.Notes {
+			fmt.Fprintf(&buffer, "  • %s\n", note)
+		}
+		buffer.WriteString("\n")
+	}
+	return buffer.String()
+}
+
+// runWhatsNew prints the latest release notes, or all with --all
+func runWhatsNew(cmd *cobra.Command, args []string) {
+	notes := releasedNotes(releaseNotes, version)
+	if len(notes) == 0 {
+		fmt.Printf("No release notes for version %s\n", version)
+		return
+	}
+	if !whatsNewAll {
+		notes = notes[:1]
+	}
+	fmt.Print(display.RenderWhatsNew(notes))
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestUnseenReleaseNotes(t *testing.T) {
+	notes := []ReleaseNotes{{Version: "v3"}, {Version: "v2"}, {Version: "v1"}}
+	tests := []struct {
+		seen     string
+		expected []string
+	}{
+		{"", []string{"v3"}},
+		{"v1", []string{"v3", "v2"}},
+		{"v3", nil},
+		{"v9", nil},
+	}
+
+	for _, tt := range tests {
+		var versions []string
+		for _, release := range unseenReleaseNotes(notes, tt.seen) {
+			versions = append(versions, release.Version)
+		}
+		if strings.Join(versions, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("unseenReleaseNotes(%q) = %v, expected %v", tt.seen, versions, tt.expected)
+		}
+	}
+}
+
+func TestReleasedNotes(t *testing.T) {
+	notes := []ReleaseNotes{{Version: "v3"}, {Version: "v2"}, {Version: "v1"}}
+	tests := []struct {
+		build    string
+		expected []string
+	}{
+		{"v3", []string{"v3", "v2", "v1"}},
+		{"2", []string{"v2", "v1"}},
+		{"dev", nil},
+		{"v4", nil},
+	}
+
+	for _, tt := range tests {
+		var versions []string
+		for _, release := range releasedNotes(notes, tt.build) {
+			versions = append(versions, release.Version)
+		}
+		if strings.Join(versions, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("releasedNotes(%q) = %v, expected %v", tt.build, versions, tt.expected)
+		}
+	}
+}
+
+func TestPendingWhatsNewShowsOnce(t *testing.T) {
+	oldNotes, oldVersion := releaseNotes, version
+	defer func() { releaseNotes, version = oldNotes, oldVersion }()
+	releaseNotes = []ReleaseNotes{{Version: "v2", Notes: []string{"new"}}, {Version: "v1", Notes: []string{"old"}}}
+	version = "2"
+	path := filepath.Join(t.TempDir(), "state", "whatsnew")
+
+	if notes := pendingWhatsNew(path); len(notes) != 1 || notes[0].Version != releaseNotes[0].Version {
+		t.Fatalf("pendingWhatsNew() on first run = %v, expected the latest release", notes)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || strings.TrimSpace(string(data)) != releaseNotes[0].Version {
+		t.Errorf("seen version = %q (%v), expected %q", data, err, releaseNotes[0].Version)
+	}
+	if notes := pendingWhatsNew(path); notes != nil {
+		t.Errorf("pendingWhatsNew() on second run = %v, expected nil", notes)
+	}
+}
+
+func TestWhatsNewDismissedByKey(t *testing.T) {
+	oldDisplay := display
+	defer func() { display = oldDisplay }()
+	display = NewDisplay("UTC")
+
+	m := NewModel("auto", 7000)
+	m.whatsNew = []ReleaseNotes{{Version: "v2", Notes: []string{"new"}}}
+	if !strings.Contains(m.View(), "What's new in v2") {
+		t.Fatalf("View() does not show the what's new panel")
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2")})
+	m = updated.(Model)
+	if m.whatsNew != nil || m.view != ViewSession {
+		t.Errorf("first key: whatsNew = %v, view = %d, expected the panel dismissed and the view unchanged", m.whatsNew, m.view)
+	}
+	if strings.Contains(m.View(), "What's new") {
+		t.Errorf("View() still shows the what's new panel after dismissing")
+	}
+}